@@ -5,33 +5,59 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/sammyoina/vibe-cv/internal/ats"
 	"github.com/sammyoina/vibe-cv/internal/db"
 	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
+	"github.com/sammyoina/vibe-cv/internal/webhook"
+	"github.com/sammyoina/vibe-cv/pkg/auth"
 )
 
 // ATSHandler handles ATS analysis endpoints.
 type ATSHandler struct {
 	analyzer *ats.Analyzer
 	repo     *db.Repository
+	webhooks *webhook.Dispatcher
+	scorer   *match.Scorer
 }
 
 // NewATSHandler creates a new ATS handler.
-func NewATSHandler(provider llm.Provider, repo *db.Repository) *ATSHandler {
+func NewATSHandler(provider llm.Provider, repo *db.Repository, webhooks *webhook.Dispatcher) *ATSHandler {
 	return &ATSHandler{
 		analyzer: ats.NewAnalyzer(provider),
 		repo:     repo,
+		webhooks: webhooks,
+		scorer:   match.NewScorer(match.NewLocalEmbedder()),
 	}
 }
 
+// SetScorer sets the scorer of the CV versions recommendations are applied
+// to.
+func (h *ATSHandler) SetScorer(scorer *match.Scorer) {
+	h.scorer = scorer
+}
+
+// SetRenderer sets the renderer of the PDFs checked on request.
+func (h *ATSHandler) SetRenderer(renderer ats.Renderer) {
+	h.analyzer.SetRenderer(renderer)
+}
+
 // AnalyzeRequest represents the ATS analysis request.
 type AnalyzeRequest struct {
 	CVVersionID    int    `json:"cv_version_id"`
 	JobDescription string `json:"job_description"`
+	ATSProfile     string `json:"ats_profile,omitempty"` // ATS to simulate, e.g. "workday"; defaults to "generic"
+	FileType       string `json:"file_type,omitempty"`   // Type of the submitted file; defaults to "pdf", as generated
+	CheckPDF       bool   `json:"check_pdf,omitempty"`   // Render the CV and check the text extracted from the PDF
 }
 
 // AnalyzeCV handles POST /api/latest/ats/analyze.
@@ -45,6 +71,17 @@ func (h *ATSHandler) AnalyzeCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	profile, ok := ats.LookupProfile(req.ATSProfile)
+	if !ok {
+		http.Error(w, `{"error": "unknown ats_profile: see GET /api/latest/ats/profiles"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if req.FileType == "" {
+		req.FileType = "pdf"
+	}
+
 	// Get CV version
 	version, err := h.repo.GetCVVersion(req.CVVersionID)
 	if err != nil {
@@ -54,7 +91,11 @@ func (h *ATSHandler) AnalyzeCV(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Perform ATS analysis
-	result, err := h.analyzer.AnalyzeCV(r.Context(), version.CustomizedCV, req.JobDescription)
+	result, err := h.analyzer.AnalyzeCVWith(r.Context(), version.CustomizedCV, req.JobDescription, ats.Options{
+		Profile:  profile,
+		FileType: req.FileType,
+		CheckPDF: req.CheckPDF,
+	})
 	if err != nil {
 		fmt.Printf("ATS analysis failed: %v\n", err)
 		http.Error(w, `{"error": "analysis failed"}`, http.StatusInternalServerError)
@@ -62,15 +103,51 @@ func (h *ATSHandler) AnalyzeCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Store analysis in database
+	analysis, err := h.storeAnalysis(r, req.CVVersionID, result)
+	if err != nil {
+		fmt.Printf("Failed to store ATS analysis: %v\n", err)
+		// Continue anyway, return the result
+	}
+
+	// Prepare response
+	response := map[string]interface{}{
+		"cv_version_id":        req.CVVersionID,
+		"ats_profile":          result.Profile,
+		"overall_score":        result.OverallScore,
+		"keyword_matches":      result.KeywordMatches,
+		"formatting_issues":    result.FormattingIssues,
+		"section_completeness": result.SectionCompleteness,
+		"experience":           result.Experience,
+		"recommendations":      result.Recommendations,
+	}
+
+	if analysis != nil {
+		response["id"] = analysis.ID
+	}
+
+	if result.PDFCheck != nil {
+		response["pdf_check"] = result.PDFCheck
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// storeAnalysis stores the analysis of a CV version and notifies the
+// webhooks of the user.
+func (h *ATSHandler) storeAnalysis(r *http.Request, cvVersionID int, result *ats.ATSAnalysisResult) (*db.ATSAnalysis, error) {
 	// Convert result to JSON for storage
 	keywordMatchesJSON, _ := json.Marshal(result.KeywordMatches)
 	formattingIssuesJSON, _ := json.Marshal(result.FormattingIssues)
 	sectionCompletenessJSON, _ := json.Marshal(result.SectionCompleteness)
 	recommendationsJSON, _ := json.Marshal(result.Recommendations)
 
-	// Store analysis in database
 	analysis, err := h.repo.CreateATSAnalysis(
-		req.CVVersionID,
+		cvVersionID,
+		result.Profile,
 		&result.OverallScore,
 		(*json.RawMessage)(&keywordMatchesJSON),
 		(*json.RawMessage)(&formattingIssuesJSON),
@@ -78,19 +155,57 @@ func (h *ATSHandler) AnalyzeCV(w http.ResponseWriter, r *http.Request) {
 		(*json.RawMessage)(&recommendationsJSON),
 	)
 	if err != nil {
-		fmt.Printf("Failed to store ATS analysis: %v\n", err)
-		// Continue anyway, return the result
+		return nil, err
 	}
 
-	// Prepare response
+	if user := auth.GetUser(r.Context()); user != nil && user.KratosID != "" {
+		if identity, err := h.repo.GetOrCreateIdentity(user.KratosID, user.Email); err == nil {
+			if err := h.webhooks.Publish(&identity.ID, webhook.EventATSAnalysisCompleted, map[string]interface{}{
+				"analysis_id":   analysis.ID,
+				"cv_version_id": cvVersionID,
+				"ats_profile":   result.Profile,
+				"overall_score": result.OverallScore,
+			}); err != nil {
+				fmt.Printf("Failed to publish webhook event: %v\n", err)
+			}
+		}
+	}
+
+	return analysis, nil
+}
+
+// GetATSAnalysis handles GET /api/latest/ats/{cv_version_id}.
+func (h *ATSHandler) GetATSAnalysis(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	cvVersionIDStr := r.PathValue("cv_version_id")
+	cvVersionID, err := strconv.Atoi(cvVersionIDStr)
+	if err != nil {
+		http.Error(w, `{"error": "invalid cv_version_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	// Get ATS analysis from database
+	analysis, err := h.repo.GetATSAnalysis(cvVersionID)
+	if err != nil {
+		http.Error(w, `{"error": "analysis not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	result := analysisResult(analysis)
+
 	response := map[string]interface{}{
 		"id":                   analysis.ID,
-		"cv_version_id":        req.CVVersionID,
-		"overall_score":        result.OverallScore,
+		"cv_version_id":        analysis.CVVersionID,
+		"ats_profile":          analysis.ATSProfile,
+		"overall_score":        analysis.OverallScore,
 		"keyword_matches":      result.KeywordMatches,
 		"formatting_issues":    result.FormattingIssues,
 		"section_completeness": result.SectionCompleteness,
 		"recommendations":      result.Recommendations,
+		"created_at":           analysis.CreatedAt,
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -99,54 +214,271 @@ func (h *ATSHandler) AnalyzeCV(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetATSAnalysis handles GET /api/latest/ats/{cv_version_id}.
-func (h *ATSHandler) GetATSAnalysis(w http.ResponseWriter, r *http.Request) {
+// ListProfiles handles GET /api/latest/ats/profiles.
+func (h *ATSHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	cvVersionIDStr := r.PathValue("cv_version_id")
-	cvVersionID, err := strconv.Atoi(cvVersionIDStr)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"profiles": ats.Profiles()}); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// GetATSHistory handles GET /api/latest/ats/{cv_version_id}/history.
+func (h *ATSHandler) GetATSHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	cvVersionID, err := strconv.Atoi(r.PathValue("cv_version_id"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid cv_version_id"}`, http.StatusBadRequest)
 
 		return
 	}
 
-	// Get ATS analysis from database
-	analysis, err := h.repo.GetATSAnalysis(cvVersionID)
+	analyses, err := h.repo.ListATSAnalyses(cvVersionID)
 	if err != nil {
-		http.Error(w, `{"error": "analysis not found"}`, http.StatusNotFound)
+		http.Error(w, `{"error": "failed to retrieve analyses"}`, http.StatusInternalServerError)
 
 		return
 	}
 
-	// Parse JSON fields
-	var keywordMatches ats.KeywordMatches
-	var formattingIssues []ats.FormattingIssue
-	var sectionCompleteness ats.SectionCompleteness
-	var recommendations []ats.Recommendation
+	response := atsHistory(analyses)
+	response["cv_version_id"] = cvVersionID
 
-	if analysis.KeywordMatches != nil {
-		_ = json.Unmarshal(*analysis.KeywordMatches, &keywordMatches)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
 	}
-	if analysis.FormattingIssues != nil {
-		_ = json.Unmarshal(*analysis.FormattingIssues, &formattingIssues)
+}
+
+// GetCVATSHistory handles GET /api/latest/cvs/{cv_id}/ats-history, the
+// analyses of every version of a CV.
+func (h *ATSHandler) GetCVATSHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	cvID, err := strconv.Atoi(r.PathValue("cv_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid cv_id"}`, http.StatusBadRequest)
+
+		return
 	}
-	if analysis.SectionCompleteness != nil {
-		_ = json.Unmarshal(*analysis.SectionCompleteness, &sectionCompleteness)
+
+	analyses, err := h.repo.ListCVATSAnalyses(cvID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve analyses"}`, http.StatusInternalServerError)
+
+		return
 	}
-	if analysis.Recommendations != nil {
-		_ = json.Unmarshal(*analysis.Recommendations, &recommendations)
+
+	response := atsHistory(analyses)
+	response["cv_id"] = cvID
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
 	}
+}
+
+// ApplyRequest selects the recommendations of an analysis to apply to a CV
+// version. With no indexes and no categories, all of them are applied.
+type ApplyRequest struct {
+	Recommendations []int    `json:"recommendations,omitempty"` // Indexes of the analysis' recommendations
+	Categories      []string `json:"categories,omitempty"`      // Categories of recommendations, e.g. "keywords"
+	JobDescription  string   `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	ATSProfile      string   `json:"ats_profile,omitempty"`     // Defaults to the profile of the latest analysis
+	FileType        string   `json:"file_type,omitempty"`       // Type of the submitted file; defaults to "pdf"
+	CheckPDF        bool     `json:"check_pdf,omitempty"`       // Render the CV and check the text extracted from the PDF
+}
+
+// ApplyRecommendations handles POST /api/latest/ats/{cv_version_id}/apply.
+// It rewrites the CV version through the LLM to follow the selected
+// recommendations of its latest analysis with the profile, stores the result
+// as a new version, and analyzes both versions with the same options and
+// keywords to return both scores. Only the new version's analysis is stored.
+func (h *ATSHandler) ApplyRecommendations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	cvVersionID, err := strconv.Atoi(r.PathValue("cv_version_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid cv_version_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	// The body is optional: all recommendations of the latest analysis
+	var req ApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if req.FileType == "" {
+		req.FileType = "pdf"
+	}
+
+	version, err := h.repo.GetCVVersion(cvVersionID)
+	if err != nil {
+		http.Error(w, `{"error": "CV version not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	analyses, err := h.repo.ListATSAnalyses(cvVersionID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve analyses"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	if req.ATSProfile == "" && len(analyses) > 0 {
+		req.ATSProfile = analyses[len(analyses)-1].ATSProfile
+	}
+
+	profile, ok := ats.LookupProfile(req.ATSProfile)
+	if !ok {
+		http.Error(w, `{"error": "unknown ats_profile: see GET /api/latest/ats/profiles"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	jobDesc := req.JobDescription
+	if jobDesc == "" {
+		jobDesc = version.JobDescription
+	}
+
+	opts := ats.Options{Profile: profile, FileType: req.FileType, CheckPDF: req.CheckPDF}
+
+	// The indexes refer to the latest stored analysis with the profile;
+	// without one, to the analysis made now
+	var stored *db.ATSAnalysis
+
+	if req.JobDescription == "" {
+		for _, analysis := range analyses {
+			if analysis.ATSProfile == profile.Name {
+				stored = analysis
+			}
+		}
+	}
+
+	// The version is analyzed again with the options of the new version's
+	// analysis, as the stored one may have been made with others. It is only
+	// the baseline of the comparison and is not stored.
+	before, err := h.analyzer.AnalyzeCVWith(r.Context(), version.CustomizedCV, jobDesc, opts)
+	if err != nil {
+		fmt.Printf("ATS analysis failed: %v\n", err)
+		http.Error(w, `{"error": "analysis failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	// Both versions are matched against the same keywords
+	opts.Keywords = append(slices.Clone(before.KeywordMatches.Matched), before.KeywordMatches.Missing...)
+
+	recommendations := before.Recommendations
+	beforeID := 0
+
+	if stored != nil {
+		recommendations, beforeID = analysisResult(stored).Recommendations, stored.ID
+	}
+
+	selected, err := ats.SelectRecommendations(recommendations, req.Recommendations, req.Categories)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+
+		return
+	}
+
+	if len(selected) == 0 {
+		http.Error(w, `{"error": "no recommendations to apply"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	result, err := h.analyzer.ApplyRecommendations(r.Context(), version.CustomizedCV, jobDesc, selected)
+	if err != nil {
+		fmt.Printf("Applying ATS recommendations failed: %v\n", err)
+		http.Error(w, `{"error": "customization failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	// Score the match like customized CVs, keeping the LLM's score on failure
+	if scored, err := h.scorer.Score(r.Context(), result.ModifiedCV, jobDesc); err != nil {
+		fmt.Printf("Failed to score match: %v\n", err)
+	} else {
+		result.MatchScore = scored.Score
+	}
+
+	// The new version uses the features of the old one, and ATS optimization
+	features := map[string]interface{}{}
+	if version.FeaturesUsed != nil {
+		_ = json.Unmarshal(*version.FeaturesUsed, &features)
+	}
+
+	features["ats_optimization"] = true
+	featuresUsed, _ := json.Marshal(features)
+	modificationsJSON, _ := json.Marshal(result.Modifications)
+
+	newVersion, err := h.repo.CreateCVVersion(version.CVID, jobDesc, result.ModifiedCV, &result.MatchScore,
+		(*json.RawMessage)(&modificationsJSON), nil, (*json.RawMessage)(&featuresUsed))
+	if err != nil {
+		http.Error(w, `{"error": "failed to store CV version"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	after, err := h.analyzer.AnalyzeCVWith(r.Context(), newVersion.CustomizedCV, jobDesc, opts)
+	if err != nil {
+		fmt.Printf("ATS analysis failed: %v\n", err)
+		http.Error(w, `{"error": "analysis failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	beforeResponse := map[string]interface{}{
+		"overall_score":     before.OverallScore,
+		"keyword_matches":   before.KeywordMatches,
+		"formatting_issues": before.FormattingIssues,
+	}
+
+	if beforeID != 0 {
+		beforeResponse["id"] = beforeID
+	}
+
+	afterResponse := map[string]interface{}{
+		"overall_score":        after.OverallScore,
+		"keyword_matches":      after.KeywordMatches,
+		"formatting_issues":    after.FormattingIssues,
+		"section_completeness": after.SectionCompleteness,
+		"experience":           after.Experience,
+		"recommendations":      after.Recommendations,
+	}
+
+	if analysis, err := h.storeAnalysis(r, newVersion.ID, after); err != nil {
+		fmt.Printf("Failed to store ATS analysis: %v\n", err)
+	} else {
+		afterResponse["id"] = analysis.ID
+	}
+
+	if after.PDFCheck != nil {
+		afterResponse["pdf_check"] = after.PDFCheck
+	}
+
+	change := ats.Compare(before, after)
+	change.PreviousID = beforeID
 
 	response := map[string]interface{}{
-		"id":                   analysis.ID,
-		"cv_version_id":        analysis.CVVersionID,
-		"overall_score":        analysis.OverallScore,
-		"keyword_matches":      keywordMatches,
-		"formatting_issues":    formattingIssues,
-		"section_completeness": sectionCompleteness,
-		"recommendations":      recommendations,
-		"created_at":           analysis.CreatedAt,
+		"cv_version_id":          newVersion.ID,
+		"previous_cv_version_id": cvVersionID,
+		"ats_profile":            profile.Name,
+		"applied":                selected,
+		"modifications":          result.Modifications,
+		"match_score":            result.MatchScore,
+		"before":                 beforeResponse,
+		"after":                  afterResponse,
+		"change":                 change,
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -154,3 +486,107 @@ func (h *ATSHandler) GetATSAnalysis(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
 	}
 }
+
+// atsHistoryEntry is an analysis of an ATS history, with its change since
+// the previous analysis with the same profile.
+type atsHistoryEntry struct {
+	ID               int                   `json:"id"`
+	CVVersionID      int                   `json:"cv_version_id"`
+	ATSProfile       string                `json:"ats_profile"`
+	OverallScore     float64               `json:"overall_score"`
+	KeywordMatches   ats.KeywordMatches    `json:"keyword_matches"`
+	FormattingIssues []ats.FormattingIssue `json:"formatting_issues"`
+	CreatedAt        time.Time             `json:"created_at"`
+	Change           *ats.Change           `json:"change,omitempty"`
+}
+
+// atsTrend sums up how the score of one ATS profile evolved.
+type atsTrend struct {
+	ATSProfile  string  `json:"ats_profile"`
+	Analyses    int     `json:"analyses"`
+	FirstScore  float64 `json:"first_score"`
+	LatestScore float64 `json:"latest_score"`
+	ScoreDelta  float64 `json:"score_delta"`
+}
+
+// atsHistory lists analyses, oldest first, with the change of each since
+// the previous one of its profile and the trend of every profile. Analyses
+// of different profiles are not compared, as they weigh scores differently.
+func atsHistory(analyses []*db.ATSAnalysis) map[string]interface{} {
+	entries := make([]atsHistoryEntry, 0, len(analyses))
+	trends := []*atsTrend{}
+	last := make(map[string]*ats.ATSAnalysisResult)
+	lastID := make(map[string]int)
+
+	for _, analysis := range analyses {
+		result := analysisResult(analysis)
+
+		entry := atsHistoryEntry{
+			ID:               analysis.ID,
+			CVVersionID:      analysis.CVVersionID,
+			ATSProfile:       analysis.ATSProfile,
+			OverallScore:     result.OverallScore,
+			KeywordMatches:   result.KeywordMatches,
+			FormattingIssues: result.FormattingIssues,
+			CreatedAt:        analysis.CreatedAt,
+		}
+
+		if previous, ok := last[analysis.ATSProfile]; ok {
+			change := ats.Compare(previous, result)
+			change.PreviousID = lastID[analysis.ATSProfile]
+			entry.Change = &change
+		}
+
+		i := slices.IndexFunc(trends, func(t *atsTrend) bool { return t.ATSProfile == analysis.ATSProfile })
+		if i == -1 {
+			trends = append(trends, &atsTrend{ATSProfile: analysis.ATSProfile, FirstScore: result.OverallScore})
+			i = len(trends) - 1
+		}
+
+		trend := trends[i]
+		trend.Analyses++
+		trend.LatestScore = result.OverallScore
+		trend.ScoreDelta = math.Round((trend.LatestScore-trend.FirstScore)*1000) / 1000
+
+		last[analysis.ATSProfile] = result
+		lastID[analysis.ATSProfile] = analysis.ID
+		entries = append(entries, entry)
+	}
+
+	return map[string]interface{}{
+		"analyses": entries,
+		"trends":   trends,
+	}
+}
+
+// analysisResult reads a stored analysis back into a result. What is not
+// stored, such as the experience match, is left empty.
+func analysisResult(analysis *db.ATSAnalysis) *ats.ATSAnalysisResult {
+	result := &ats.ATSAnalysisResult{
+		Profile:          analysis.ATSProfile,
+		FormattingIssues: []ats.FormattingIssue{},
+		Recommendations:  []ats.Recommendation{},
+	}
+
+	if analysis.OverallScore != nil {
+		result.OverallScore = *analysis.OverallScore
+	}
+
+	if analysis.KeywordMatches != nil {
+		_ = json.Unmarshal(*analysis.KeywordMatches, &result.KeywordMatches)
+	}
+
+	if analysis.FormattingIssues != nil {
+		_ = json.Unmarshal(*analysis.FormattingIssues, &result.FormattingIssues)
+	}
+
+	if analysis.SectionCompleteness != nil {
+		_ = json.Unmarshal(*analysis.SectionCompleteness, &result.SectionCompleteness)
+	}
+
+	if analysis.Recommendations != nil {
+		_ = json.Unmarshal(*analysis.Recommendations, &result.Recommendations)
+	}
+
+	return result
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/ats"
+	"github.com/sammyoina/vibe-cv/internal/db"
+)
+
+// storedAnalysis builds an analysis as it is read from the database.
+func storedAnalysis(t *testing.T, id int, profile string, score float64, matched []string) *db.ATSAnalysis {
+	t.Helper()
+
+	keywords, err := json.Marshal(ats.KeywordMatches{Matched: matched})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &db.ATSAnalysis{
+		ID:             id,
+		CVVersionID:    7,
+		ATSProfile:     profile,
+		OverallScore:   &score,
+		KeywordMatches: (*json.RawMessage)(&keywords),
+		CreatedAt:      time.Date(2026, time.October, id, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestATSHistory(t *testing.T) {
+	history := atsHistory([]*db.ATSAnalysis{
+		storedAnalysis(t, 1, "generic", 0.5, []string{"go"}),
+		storedAnalysis(t, 2, "workday", 0.4, []string{"go"}),
+		storedAnalysis(t, 3, "generic", 0.6, []string{"go", "sql"}),
+		storedAnalysis(t, 4, "workday", 0.45, nil),
+	})
+
+	entries := history["analyses"].([]atsHistoryEntry)
+	if len(entries) != 4 {
+		t.Fatalf("analyses = %+v", entries)
+	}
+
+	// The first analysis of each profile has nothing to compare with
+	for _, i := range []int{0, 1} {
+		if entries[i].Change != nil {
+			t.Errorf("analysis %d change = %+v, want none", entries[i].ID, entries[i].Change)
+		}
+	}
+
+	// Later ones are compared with the previous analysis of their profile only
+	if change := entries[2].Change; change == nil || change.PreviousID != 1 || change.ScoreDelta != 0.1 ||
+		len(change.KeywordsGained) != 1 || change.KeywordsGained[0] != "sql" {
+		t.Errorf("third analysis change = %+v", change)
+	}
+
+	if change := entries[3].Change; change == nil || change.PreviousID != 2 || change.ScoreDelta != 0.05 ||
+		len(change.KeywordsLost) != 1 || change.KeywordsLost[0] != "go" {
+		t.Errorf("fourth analysis change = %+v", change)
+	}
+
+	trends := history["trends"].([]*atsTrend)
+	if len(trends) != 2 {
+		t.Fatalf("trends = %+v", trends)
+	}
+
+	for i, want := range []atsTrend{
+		{ATSProfile: "generic", Analyses: 2, FirstScore: 0.5, LatestScore: 0.6, ScoreDelta: 0.1},
+		{ATSProfile: "workday", Analyses: 2, FirstScore: 0.4, LatestScore: 0.45, ScoreDelta: 0.05},
+	} {
+		if *trends[i] != want {
+			t.Errorf("trend %d = %+v, want %+v", i, *trends[i], want)
+		}
+	}
+}
+
+func TestATSHistory_Empty(t *testing.T) {
+	history := atsHistory(nil)
+
+	if entries := history["analyses"].([]atsHistoryEntry); entries == nil || len(entries) != 0 {
+		t.Errorf("analyses = %#v, want an empty list", entries)
+	}
+
+	if trends := history["trends"].([]*atsTrend); trends == nil || len(trends) != 0 {
+		t.Errorf("trends = %#v, want an empty list", trends)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/sammyoina/vibe-cv/internal/coverletter"
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/latex"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// CoverLetterHandler handles cover letters written for CV versions.
+type CoverLetterHandler struct {
+	generator    *coverletter.Generator
+	provider     llm.Provider
+	repo         *db.Repository
+	scorer       *match.Scorer
+	texGenerator *latex.LaTeXGenerator
+}
+
+// NewCoverLetterHandler creates a new cover letter handler. Letters are
+// rendered to PDF with texGenerator, like CVs.
+func NewCoverLetterHandler(provider llm.Provider, repo *db.Repository, texGenerator *latex.LaTeXGenerator) *CoverLetterHandler {
+	return &CoverLetterHandler{
+		generator:    coverletter.NewGenerator(provider),
+		provider:     provider,
+		repo:         repo,
+		scorer:       match.NewScorer(match.NewLocalEmbedder()),
+		texGenerator: texGenerator,
+	}
+}
+
+// SetScorer sets the scorer that finds the requirements a letter cites.
+func (h *CoverLetterHandler) SetScorer(scorer *match.Scorer) {
+	h.scorer = scorer
+}
+
+// CoverLetterRequest represents the cover letter request.
+type CoverLetterRequest struct {
+	CVVersionID    int    `json:"cv_version_id"`
+	JobDescription string `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	Tone           string `json:"tone,omitempty"`            // professional, enthusiastic, formal or conversational
+	Length         string `json:"length,omitempty"`          // short, medium or long
+	Language       string `json:"language,omitempty"`        // ISO 639-1 code; defaults to the language of the CV
+	Company        string `json:"company,omitempty"`
+	HiringManager  string `json:"hiring_manager,omitempty"`
+}
+
+// coverLetterID parses the cover_letter_id path value.
+func coverLetterID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("cover_letter_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid cover_letter_id"}`, http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// CreateCoverLetter handles POST /api/latest/cover-letter. It writes a
+// letter from a CV version and its job, citing the requirements the match
+// analysis finds covered, and stores it as the next letter of the version.
+func (h *CoverLetterHandler) CreateCoverLetter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CoverLetterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	opts := coverletter.Options{
+		Tone:          req.Tone,
+		Length:        req.Length,
+		Language:      req.Language,
+		Company:       req.Company,
+		HiringManager: req.HiringManager,
+	}
+
+	if err := opts.Normalize(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+
+		return
+	}
+
+	version, err := h.repo.GetCVVersion(req.CVVersionID)
+	if err != nil {
+		http.Error(w, `{"error": "CV version not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	jobDesc := req.JobDescription
+	if jobDesc == "" {
+		jobDesc = version.JobDescription
+	}
+
+	// Cite what the CV covers; a letter is still written without a match
+	var details *types.MatchDetails
+
+	if scored, err := h.scorer.Score(r.Context(), version.CustomizedCV, jobDesc); err != nil {
+		fmt.Printf("Failed to score match: %v\n", err)
+	} else {
+		details = &scored.Details
+	}
+
+	letter, err := h.generator.Generate(r.Context(), version.CustomizedCV, jobDesc, details, opts)
+	if err != nil {
+		fmt.Printf("Cover letter generation failed: %v\n", err)
+		http.Error(w, `{"error": "cover letter generation failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	stored, err := h.repo.CreateCoverLetter(&db.CoverLetter{
+		CVVersionID:   version.ID,
+		Tone:          letter.Tone,
+		Length:        letter.Length,
+		Language:      letter.Language,
+		Company:       req.Company,
+		HiringManager: req.HiringManager,
+		Content:       letter.Content,
+		WordCount:     letter.WordCount,
+		Highlights:    letter.Highlights,
+		Provider:      h.provider.GetName(),
+	})
+	if err != nil {
+		fmt.Printf("Failed to store cover letter: %v\n", err)
+		http.Error(w, `{"error": "failed to store cover letter"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"cover_letter": stored,
+		"download_url": fmt.Sprintf("/api/latest/cover-letter/%d/download", stored.ID),
+	}
+
+	if details != nil {
+		response["match_details"] = details
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// GetCoverLetter handles GET /api/latest/cover-letter/{cover_letter_id}.
+func (h *CoverLetterHandler) GetCoverLetter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := coverLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	letter, err := h.repo.GetCoverLetter(id)
+	if err != nil {
+		http.Error(w, `{"error": "cover letter not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(letter); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ListCoverLetters handles GET /api/latest/versions/{version_id}/cover-letters.
+func (h *CoverLetterHandler) ListCoverLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	versionID, err := strconv.Atoi(r.PathValue("version_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid version_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	letters, err := h.repo.ListCoverLetters(versionID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve cover letters"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"cv_version_id": versionID,
+		"cover_letters": letters,
+	}); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DownloadCoverLetter handles GET /api/latest/cover-letter/{cover_letter_id}/download.
+// Like CVs, letters are served as PDF, or as plain text when LaTeX fails.
+func (h *CoverLetterHandler) DownloadCoverLetter(w http.ResponseWriter, r *http.Request) {
+	id, ok := coverLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	letter, err := h.repo.GetCoverLetter(id)
+	if err != nil {
+		http.Error(w, `{"error": "cover letter not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	filename := fmt.Sprintf("cover-letter-%d", id)
+
+	if pdfPath, err := h.texGenerator.GenerateCoverLetterPDF(letter.Content, filename, letter.Language); err == nil {
+		if pdfContent, err := os.ReadFile(pdfPath); err == nil {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", filename))
+			w.Header().Set("Content-Length", strconv.Itoa(len(pdfContent)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(pdfContent)
+
+			return
+		}
+	}
+
+	content := []byte(letter.Content)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.txt\"", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
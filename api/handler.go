@@ -4,7 +4,9 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,11 +16,16 @@ import (
 	"github.com/sammyoina/vibe-cv/internal/analytics"
 	"github.com/sammyoina/vibe-cv/internal/batch"
 	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/egress"
+	"github.com/sammyoina/vibe-cv/internal/i18n"
 	"github.com/sammyoina/vibe-cv/internal/input"
 	"github.com/sammyoina/vibe-cv/internal/latex"
 	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
+	"github.com/sammyoina/vibe-cv/internal/ocr"
 	"github.com/sammyoina/vibe-cv/internal/parser"
 	"github.com/sammyoina/vibe-cv/internal/types"
+	"github.com/sammyoina/vibe-cv/internal/webhook"
 	"github.com/sammyoina/vibe-cv/pkg/auth"
 )
 
@@ -27,38 +34,91 @@ type LatestHandler struct {
 	provider        llm.Provider
 	repo            *db.Repository
 	queue           *batch.JobQueue
+	webhooks        *webhook.Dispatcher
+	exporter        *batch.Exporter
 	collector       *analytics.Collector
 	authConfig      *auth.Config
 	inputParser     *input.EnhancedParser
+	resolver        *input.Resolver
 	cvParser        *parser.CVParser
 	texGenerator    *latex.LaTeXGenerator
+	scorer          *match.Scorer
 	outputDir       string
 	atsHandler      *ATSHandler
 	linkedinHandler *LinkedInHandler
+	webhookHandler  *WebhookHandler
+	scheduleHandler *ScheduleHandler
+	savedJobHandler *SavedJobsHandler
+	coverLetters    *CoverLetterHandler
+	interviews      *InterviewHandler
+	skillGaps       *SkillGapHandler
+	masterCVs       *MasterCVHandler
 }
 
-// NewLatestHandler creates a new consolidated handler.
-func NewLatestHandler(provider llm.Provider, repo *db.Repository, authConfig *auth.Config) *LatestHandler {
+// NewLatestHandler creates a new consolidated handler. Job URLs and webhook
+// endpoints are reached under egressPolicy.
+func NewLatestHandler(provider llm.Provider, repo *db.Repository, authConfig *auth.Config, egressPolicy *egress.Policy) *LatestHandler {
 	outputDir := "./outputs"
+	webhooks := webhook.NewDispatcher(repo, 2) // 2 delivery workers
+	fetcher := input.NewFetcher(30 * time.Second)
 	handler := &LatestHandler{
 		provider:        provider,
 		repo:            repo,
 		queue:           batch.NewJobQueue(repo, 4), // 4 workers
+		webhooks:        webhooks,
+		exporter:        batch.NewExporter("pdflatex"),
 		collector:       analytics.NewCollector(repo),
 		authConfig:      authConfig,
 		inputParser:     input.NewEnhancedParser(),
+		resolver:        input.NewResolver(fetcher),
 		cvParser:        parser.NewCVParser(),
 		texGenerator:    latex.NewLaTeXGenerator(outputDir, "pdflatex"),
+		scorer:          match.NewScorer(match.NewLocalEmbedder()),
 		outputDir:       outputDir,
-		atsHandler:      NewATSHandler(provider, repo),
-		linkedinHandler: NewLinkedInHandler(repo),
-	}
-	// Set the LLM provider on the batch queue
+		atsHandler:      NewATSHandler(provider, repo, webhooks),
+		linkedinHandler: NewLinkedInHandler(repo, webhooks),
+		webhookHandler:  NewWebhookHandler(repo, webhooks),
+		scheduleHandler: NewScheduleHandler(repo),
+		savedJobHandler: NewSavedJobsHandler(repo, fetcher),
+		skillGaps:       NewSkillGapHandler(repo),
+		masterCVs:       NewMasterCVHandler(provider, repo),
+	}
+	// Share one fetcher, so per-host pacing and robots.txt caching span requests
+	// and scheduled jobs
+	fetcher.SetPolicy(egressPolicy)
+	webhooks.SetPolicy(egressPolicy)
+	// Set the LLM provider, webhook dispatcher and fetcher on the batch queue
 	handler.queue.SetProvider(provider)
+	handler.queue.SetWebhooks(webhooks)
+	handler.queue.SetFetcher(fetcher)
+	handler.queue.SetScorer(handler.scorer)
+	handler.atsHandler.SetScorer(handler.scorer)
+	handler.masterCVs.SetScorer(handler.scorer)
+	// Render cover letters and interview packs like the CVs they are written for
+	handler.coverLetters = NewCoverLetterHandler(provider, repo, handler.texGenerator)
+	handler.coverLetters.SetScorer(handler.scorer)
+	handler.interviews = NewInterviewHandler(provider, repo, handler.texGenerator)
+	handler.interviews.SetScorer(handler.scorer)
+	// Check rendered PDFs as the customized CVs are rendered
+	handler.atsHandler.SetRenderer(handler.texGenerator)
+	// Recognize scanned CV uploads with the local tesseract install
+	handler.resolver.SetOCR(ocr.NewTesseract("tesseract"))
 
 	return handler
 }
 
+// SetScorer sets the scorer that matches customized CVs with jobs, for
+// requests, batch items, applied ATS recommendations, cover letters and
+// interview packs alike.
+func (h *LatestHandler) SetScorer(scorer *match.Scorer) {
+	h.scorer = scorer
+	h.queue.SetScorer(scorer)
+	h.atsHandler.SetScorer(scorer)
+	h.coverLetters.SetScorer(scorer)
+	h.interviews.SetScorer(scorer)
+	h.masterCVs.SetScorer(scorer)
+}
+
 // StartQueue starts the batch job queue workers.
 func (h *LatestHandler) StartQueue() {
 	if h.queue != nil {
@@ -73,6 +133,38 @@ func (h *LatestHandler) StopQueue() {
 	}
 }
 
+// StartWebhooks starts the webhook delivery workers.
+func (h *LatestHandler) StartWebhooks() {
+	if h.webhooks != nil {
+		h.webhooks.Start()
+	}
+}
+
+// StopWebhooks stops the webhook delivery workers.
+func (h *LatestHandler) StopWebhooks() {
+	if h.webhooks != nil {
+		h.webhooks.Stop()
+	}
+}
+
+// authenticatedIdentity returns the database identity of the authenticated
+// user, or false if the request is anonymous.
+func authenticatedIdentity(repo *db.Repository, r *http.Request) (int, bool) {
+	user := auth.GetUser(r.Context())
+	if user == nil || user.KratosID == "" {
+		return 0, false
+	}
+
+	identity, err := repo.GetOrCreateIdentity(user.KratosID, user.Email)
+	if err != nil {
+		fmt.Printf("Failed to get/create identity: %v\n", err)
+
+		return 0, false
+	}
+
+	return identity.ID, true
+}
+
 // RegisterRoutes registers all latest API routes.
 func (h *LatestHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/latest/customize-cv", h.CustomizeCV)
@@ -85,16 +177,63 @@ func (h *LatestHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/latest/dashboard", h.GetDashboard)
 	mux.HandleFunc("GET /api/latest/batch/{job_id}/status", h.GetBatchStatus)
 	mux.HandleFunc("GET /api/latest/batch/{job_id}/download", h.DownloadBatch)
+	mux.HandleFunc("POST /api/latest/batch/{job_id}/cancel", h.CancelBatch)
+	mux.HandleFunc("POST /api/latest/batch/{job_id}/retry-failed", h.RetryFailedBatchItems)
 	mux.HandleFunc("GET /api/latest/health", h.Health)
 
 	// ATS routes
 	mux.HandleFunc("POST /api/latest/ats/analyze", h.atsHandler.AnalyzeCV)
+	mux.HandleFunc("GET /api/latest/ats/profiles", h.atsHandler.ListProfiles)
 	mux.HandleFunc("GET /api/latest/ats/{cv_version_id}", h.atsHandler.GetATSAnalysis)
+	mux.HandleFunc("GET /api/latest/ats/{cv_version_id}/history", h.atsHandler.GetATSHistory)
+	mux.HandleFunc("POST /api/latest/ats/{cv_version_id}/apply", h.atsHandler.ApplyRecommendations)
+	mux.HandleFunc("GET /api/latest/cvs/{cv_id}/ats-history", h.atsHandler.GetCVATSHistory)
+
+	// Cover letters
+	mux.HandleFunc("POST /api/latest/cover-letter", h.coverLetters.CreateCoverLetter)
+	mux.HandleFunc("GET /api/latest/cover-letter/{cover_letter_id}", h.coverLetters.GetCoverLetter)
+	mux.HandleFunc("GET /api/latest/cover-letter/{cover_letter_id}/download", h.coverLetters.DownloadCoverLetter)
+	mux.HandleFunc("GET /api/latest/versions/{version_id}/cover-letters", h.coverLetters.ListCoverLetters)
+
+	// Interview preparation
+	mux.HandleFunc("POST /api/latest/interview-prep", h.interviews.CreateInterviewPrep)
+
+	// Skill gaps
+	mux.HandleFunc("POST /api/latest/skill-gap", h.skillGaps.CreateSkillGapReport)
+
+	// Master CVs
+	mux.HandleFunc("POST /api/latest/master-cv", h.masterCVs.CreateMasterCV)
 
 	// LinkedIn routes
 	mux.HandleFunc("POST /api/latest/linkedin/import", h.linkedinHandler.ImportLinkedIn)
 	mux.HandleFunc("GET /api/latest/linkedin/imports", h.linkedinHandler.GetLinkedInImports)
 	mux.HandleFunc("GET /api/latest/linkedin/{import_id}", h.linkedinHandler.GetLinkedInImport)
+
+	// Recurring batch schedules
+	mux.HandleFunc("POST /api/latest/schedules", h.scheduleHandler.CreateSchedule)
+	mux.HandleFunc("GET /api/latest/schedules", h.scheduleHandler.ListSchedules)
+	mux.HandleFunc("GET /api/latest/schedules/{schedule_id}", h.scheduleHandler.GetSchedule)
+	mux.HandleFunc("PUT /api/latest/schedules/{schedule_id}", h.scheduleHandler.UpdateSchedule)
+	mux.HandleFunc("DELETE /api/latest/schedules/{schedule_id}", h.scheduleHandler.DeleteSchedule)
+	mux.HandleFunc("POST /api/latest/schedules/{schedule_id}/run", h.scheduleHandler.RunSchedule)
+
+	// Job postings saved from emails and feeds
+	mux.HandleFunc("POST /api/latest/saved-jobs/email", h.savedJobHandler.ImportEmail)
+	mux.HandleFunc("GET /api/latest/saved-jobs", h.savedJobHandler.ListSavedJobs)
+	mux.HandleFunc("GET /api/latest/saved-jobs/{saved_job_id}", h.savedJobHandler.GetSavedJob)
+	mux.HandleFunc("DELETE /api/latest/saved-jobs/{saved_job_id}", h.savedJobHandler.DeleteSavedJob)
+	mux.HandleFunc("POST /api/latest/feeds", h.savedJobHandler.CreateFeed)
+	mux.HandleFunc("GET /api/latest/feeds", h.savedJobHandler.ListFeeds)
+	mux.HandleFunc("DELETE /api/latest/feeds/{feed_id}", h.savedJobHandler.DeleteFeed)
+	mux.HandleFunc("POST /api/latest/feeds/{feed_id}/poll", h.savedJobHandler.PollFeed)
+
+	// Webhook endpoints
+	mux.HandleFunc("POST /api/latest/webhooks", h.webhookHandler.CreateWebhook)
+	mux.HandleFunc("GET /api/latest/webhooks", h.webhookHandler.ListWebhooks)
+	mux.HandleFunc("DELETE /api/latest/webhooks/{webhook_id}", h.webhookHandler.DeleteWebhook)
+	mux.HandleFunc("GET /api/latest/webhooks/{webhook_id}/deliveries", h.webhookHandler.ListWebhookDeliveries)
+	mux.HandleFunc("GET /api/latest/webhooks/{webhook_id}/deliveries/{delivery_id}", h.webhookHandler.GetWebhookDelivery)
+	mux.HandleFunc("POST /api/latest/webhooks/{webhook_id}/deliveries/{delivery_id}/replay", h.webhookHandler.ReplayWebhookDelivery)
 }
 
 // CustomizeCV handles the main CV customization endpoint.
@@ -108,6 +247,18 @@ func (h *LatestHandler) CustomizeCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var language string
+	if req.Language != "" {
+		lang, ok := i18n.Normalize(req.Language)
+		if !ok {
+			http.Error(w, `{"error": "unsupported language: use one of en, de, fr, es, pt, nl"}`, http.StatusBadRequest)
+
+			return
+		}
+
+		language = lang
+	}
+
 	// Extract user if authenticated
 	var identityID *int
 	if user := auth.GetUser(r.Context()); user != nil {
@@ -123,8 +274,27 @@ func (h *LatestHandler) CustomizeCV(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get CV text (simplified - just use raw text for now)
-	cvText := req.CV
+	// Resolve every declared source into normalized CV, job and context text
+	resolved, err := h.resolver.Resolve(r.Context(), &req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   err.Error(),
+			"sources": resolved.Sources,
+		})
+
+		return
+	}
+
+	cvText := resolved.CVText
+	jobDesc := resolved.JobDescription
+	contextStrings := resolved.Context
+
+	// Customize in the requested language, or in the language of the CV
+	lang := language
+	if lang == "" {
+		lang = i18n.Detect(cvText)
+	}
 
 	// Parse CV to database
 	cvRecord, err := h.repo.CreateCV(identityID, cvText)
@@ -134,28 +304,28 @@ func (h *LatestHandler) CustomizeCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get job description
-	jobDesc := req.JobDescription
-
-	// Combine additional context strings
-	contextStrings := make([]string, 0)
-	for _, ctx := range req.AdditionalContext {
-		if ctx.Type == "text" {
-			contextStrings = append(contextStrings, ctx.Content)
-		}
-	}
-
 	// Customize CV using LLM
-	result, err := h.provider.Customize(r.Context(), cvText, jobDesc, contextStrings)
+	result, err := h.provider.Customize(llm.WithLanguage(r.Context(), lang), cvText, jobDesc, contextStrings)
 	if err != nil {
 		http.Error(w, `{"error": "customization failed"}`, http.StatusInternalServerError)
 
 		return
 	}
 
+	// Score the customized CV requirement by requirement, rather than trusting
+	// the score the LLM reports
+	var matchDetails *types.MatchDetails
+
+	if scored, err := h.scorer.Score(r.Context(), result.ModifiedCV, jobDesc); err != nil {
+		fmt.Printf("Failed to score match: %v\n", err)
+	} else {
+		result.MatchScore = scored.Score
+		matchDetails = &scored.Details
+	}
+
 	// Store version with features tracking
 	resultJSON, _ := json.Marshal(result.Modifications)
-	featuresUsed := json.RawMessage(`{"ats_optimization":false,"linkedin_import":false,"premium_llm":true}`)
+	featuresUsed := json.RawMessage(fmt.Sprintf(`{"ats_optimization":false,"linkedin_import":%t,"premium_llm":true}`, resolved.UsedLinkedIn))
 	_, err = h.repo.CreateCVVersion(cvRecord.ID, jobDesc, result.ModifiedCV, &result.MatchScore, (*json.RawMessage)(&resultJSON), nil, &featuresUsed)
 	if err != nil {
 		fmt.Printf("Failed to store version: %v\n", err)
@@ -163,7 +333,7 @@ func (h *LatestHandler) CustomizeCV(w http.ResponseWriter, r *http.Request) {
 
 	// Generate PDF from the customized CV
 	pdfFilename := fmt.Sprintf("cv-%d", cvRecord.ID)
-	_, err = h.texGenerator.GeneratePDF(result.ModifiedCV, pdfFilename)
+	_, err = h.texGenerator.GeneratePDF(result.ModifiedCV, pdfFilename, lang)
 	if err != nil {
 		// Log the error but don't fail the request - still return success with the customized content
 		fmt.Printf("Failed to generate PDF: %v\n", err)
@@ -174,12 +344,16 @@ func (h *LatestHandler) CustomizeCV(w http.ResponseWriter, r *http.Request) {
 		Status:          "success",
 		CustomizedCVURL: fmt.Sprintf("/outputs/cv-%d.pdf", cvRecord.ID),
 		MatchScore:      result.MatchScore,
+		MatchDetails:    matchDetails,
 		Modifications:   result.Modifications,
+		Language:        lang,
+		Sources:         resolved.Sources,
 	}
 
 	// Record analytics snapshot if user is authenticated
 	if identityID != nil {
-		if err := h.repo.RecordAnalyticsSnapshot(identityID, &result.MatchScore, nil, nil); err != nil {
+		keywordCoverage, metadata := analytics.MeasureSkills(result.ModifiedCV, jobDesc)
+		if err := h.repo.RecordAnalyticsSnapshot(identityID, &result.MatchScore, keywordCoverage, metadata); err != nil {
 			// Log error but don't fail the request
 			fmt.Printf("Failed to record analytics: %v\n", err)
 		}
@@ -212,7 +386,14 @@ func (h *LatestHandler) BatchCustomize(w http.ResponseWriter, r *http.Request) {
 	// Convert items to map format
 	itemMaps := make([]map[string]interface{}, len(items))
 	for i, item := range items {
-		itemMaps[i] = item.(map[string]interface{})
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			http.Error(w, `{"error": "invalid item"}`, http.StatusBadRequest)
+
+			return
+		}
+
+		itemMaps[i] = itemMap
 	}
 
 	// Extract user if authenticated
@@ -230,8 +411,48 @@ func (h *LatestHandler) BatchCustomize(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create batch job with number of items
-	jobID, err := h.queue.CreateJob(identityID, len(items))
+	// Store each CV so workers on any replica can load it
+	jobItems := make([]db.NewBatchJobItem, 0, len(itemMaps))
+	for _, itemMap := range itemMaps {
+		cvText, _ := itemMap["cv"].(string)
+		jobDesc, _ := itemMap["job_description"].(string)
+
+		// Items may name a saved job instead of carrying its description
+		if savedID, ok := itemMap["saved_job_id"].(float64); ok && jobDesc == "" {
+			if identityID == nil {
+				http.Error(w, `{"error": "authentication required for saved_job_id"}`, http.StatusUnauthorized)
+
+				return
+			}
+
+			saved, err := h.repo.GetSavedJob(int(savedID), *identityID)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "saved job %d not found"}`, int(savedID)), http.StatusNotFound)
+
+				return
+			}
+
+			jobDesc = saved.Description
+		}
+
+		if jobDesc == "" {
+			http.Error(w, `{"error": "job_description or saved_job_id required for every item"}`, http.StatusBadRequest)
+
+			return
+		}
+
+		cvRecord, err := h.repo.CreateCV(identityID, cvText)
+		if err != nil {
+			http.Error(w, `{"error": "failed to store CV"}`, http.StatusInternalServerError)
+
+			return
+		}
+
+		jobItems = append(jobItems, db.NewBatchJobItem{CVID: &cvRecord.ID, JobDescription: jobDesc})
+	}
+
+	// Durably enqueue the batch job together with its items
+	jobID, err := h.queue.SubmitJob(identityID, jobItems)
 	if err != nil {
 		http.Error(w, `{"error": "failed to create batch job"}`, http.StatusInternalServerError)
 
@@ -240,7 +461,7 @@ func (h *LatestHandler) BatchCustomize(w http.ResponseWriter, r *http.Request) {
 
 	response := map[string]interface{}{
 		"job_id": jobID,
-		"status": "processing",
+		"status": "pending",
 	}
 
 	w.WriteHeader(http.StatusAccepted)
@@ -413,7 +634,86 @@ func (h *LatestHandler) GetBatchStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// DownloadBatch downloads results for a batch job.
+// CancelBatch cancels a pending or running batch job.
+func (h *LatestHandler) CancelBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	jobIDStr := r.PathValue("job_id")
+	jobID, err := strconv.Atoi(jobIDStr)
+	if err != nil {
+		http.Error(w, `{"error": "invalid job_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.queue.CancelJob(jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "job not found or already finished"}`, http.StatusConflict)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to cancel batch job"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"job_id": jobID,
+		"status": "cancelled",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// RetryFailedBatchItems requeues the failed, cancelled and unfinished items of a finished batch job.
+func (h *LatestHandler) RetryFailedBatchItems(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	jobIDStr := r.PathValue("job_id")
+	jobID, err := strconv.Atoi(jobIDStr)
+	if err != nil {
+		http.Error(w, `{"error": "invalid job_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	requeued, err := h.queue.RetryFailedItems(jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, `{"error": "job not found"}`, http.StatusNotFound)
+		case errors.Is(err, db.ErrJobRunning):
+			http.Error(w, `{"error": "job is still running"}`, http.StatusConflict)
+		default:
+			http.Error(w, `{"error": "failed to retry batch items"}`, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	status := "pending"
+	if requeued == 0 {
+		status = "unchanged"
+	}
+
+	response := map[string]interface{}{
+		"job_id":         jobID,
+		"status":         status,
+		"requeued_items": requeued,
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DownloadBatch downloads results for a batch job. With ?format=zip it streams
+// a ZIP of rendered CVs (?file_type=pdf or docx) plus a manifest.
 func (h *LatestHandler) DownloadBatch(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -425,6 +725,19 @@ func (h *LatestHandler) DownloadBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	archive := r.URL.Query().Get("format") == "zip"
+
+	fileType := r.URL.Query().Get("file_type")
+	if fileType == "" {
+		fileType = batch.FileTypePDF
+	}
+
+	if archive && fileType != batch.FileTypePDF && fileType != batch.FileTypeDOCX {
+		http.Error(w, `{"error": "file_type must be pdf or docx"}`, http.StatusBadRequest)
+
+		return
+	}
+
 	// Get batch job and items
 	job, err := h.repo.GetBatchJob(jobID)
 	if err != nil {
@@ -440,6 +753,20 @@ func (h *LatestHandler) DownloadBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Stream rendered CVs and a manifest as a ZIP archive
+	if archive {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"batch-%d.zip\"", jobID))
+		w.WriteHeader(http.StatusOK)
+
+		if err := h.exporter.WriteZIP(r.Context(), w, jobID, items, fileType); err != nil {
+			// Headers are already sent; the truncated archive signals the failure
+			fmt.Printf("Failed to export batch %d: %v\n", jobID, err)
+		}
+
+		return
+	}
+
 	result := map[string]interface{}{
 		"job_id":     job.ID,
 		"status":     job.Status,
@@ -473,7 +800,7 @@ func (h *LatestHandler) DownloadCV(w http.ResponseWriter, r *http.Request) {
 
 	// Try to generate PDF from the customized CV content
 	pdfFilename := fmt.Sprintf("cv-version-%d", versionID)
-	pdfPath, pdfErr := h.texGenerator.GeneratePDF(version.CustomizedCV, pdfFilename)
+	pdfPath, pdfErr := h.texGenerator.GeneratePDF(version.CustomizedCV, pdfFilename, "")
 
 	// If PDF generation succeeds, serve the PDF
 	if pdfErr == nil {
@@ -0,0 +1,25 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadBatch_UnsupportedFileType(t *testing.T) {
+	for _, fileType := range []string{"docs", "txt", "PDF"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/latest/batch/5/download?format=zip&file_type="+fileType, nil)
+		req.SetPathValue("job_id", "5")
+
+		// The file type is checked before the job is looked up
+		rec := httptest.NewRecorder()
+		(&LatestHandler{}).DownloadBatch(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("file_type=%s: status %d: %s", fileType, rec.Code, rec.Body.String())
+		}
+	}
+}
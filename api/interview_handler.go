@@ -0,0 +1,169 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/interview"
+	"github.com/sammyoina/vibe-cv/internal/latex"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// Formats of an interview preparation pack.
+const (
+	prepFormatJSON     = "json"
+	prepFormatMarkdown = "markdown"
+	prepFormatPDF      = "pdf"
+)
+
+// InterviewHandler handles interview preparation packs.
+type InterviewHandler struct {
+	generator    *interview.Generator
+	repo         *db.Repository
+	scorer       *match.Scorer
+	texGenerator *latex.LaTeXGenerator
+}
+
+// NewInterviewHandler creates a new interview preparation handler. Packs are
+// rendered to PDF with texGenerator.
+func NewInterviewHandler(provider llm.Provider, repo *db.Repository, texGenerator *latex.LaTeXGenerator) *InterviewHandler {
+	return &InterviewHandler{
+		generator:    interview.NewGenerator(provider),
+		repo:         repo,
+		scorer:       match.NewScorer(match.NewLocalEmbedder()),
+		texGenerator: texGenerator,
+	}
+}
+
+// SetScorer sets the scorer that finds the requirements a pack prepares for.
+func (h *InterviewHandler) SetScorer(scorer *match.Scorer) {
+	h.scorer = scorer
+}
+
+// InterviewPrepRequest represents the interview preparation request.
+type InterviewPrepRequest struct {
+	CVVersionID    int    `json:"cv_version_id"`
+	JobDescription string `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	Language       string `json:"language,omitempty"`        // ISO 639-1 code; defaults to the language of the CV
+	Format         string `json:"format,omitempty"`          // json (default), markdown or pdf
+}
+
+// CreateInterviewPrep handles POST /api/latest/interview-prep. It prepares
+// likely questions for every requirement of the job, STAR answer outlines
+// from the CV passages covering them, and talking points for missing skills.
+func (h *InterviewHandler) CreateInterviewPrep(w http.ResponseWriter, r *http.Request) {
+	var req InterviewPrepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = prepFormatJSON
+	}
+
+	if req.Format != prepFormatJSON && req.Format != prepFormatMarkdown && req.Format != prepFormatPDF {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"error": "format must be json, markdown or pdf"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	var language string
+	if req.Language != "" {
+		lang, ok := i18n.Normalize(req.Language)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			http.Error(w, `{"error": "unsupported language: use one of en, de, fr, es, pt, nl"}`, http.StatusBadRequest)
+
+			return
+		}
+
+		language = lang
+	}
+
+	version, err := h.repo.GetCVVersion(req.CVVersionID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"error": "CV version not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	jobDesc := req.JobDescription
+	if jobDesc == "" {
+		jobDesc = version.JobDescription
+	}
+
+	var details *types.MatchDetails
+
+	if scored, err := h.scorer.Score(r.Context(), version.CustomizedCV, jobDesc); err != nil {
+		fmt.Printf("Failed to score match: %v\n", err)
+	} else {
+		details = &scored.Details
+	}
+
+	pack, err := h.generator.Generate(r.Context(), version.CustomizedCV, jobDesc, details, language)
+	if err != nil {
+		fmt.Printf("Interview preparation failed: %v\n", err)
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"error": "interview preparation failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	filename := fmt.Sprintf("interview-prep-%d", version.ID)
+	markdown := pack.Markdown()
+
+	switch req.Format {
+	case prepFormatPDF:
+		// Like CVs, packs fall back to text when LaTeX fails
+		if pdfPath, err := h.texGenerator.GenerateMarkdownPDF(markdown, filename, pack.Language); err == nil {
+			if pdfContent, err := os.ReadFile(pdfPath); err == nil {
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", filename))
+				w.Header().Set("Content-Length", strconv.Itoa(len(pdfContent)))
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(pdfContent)
+
+				return
+			}
+		}
+
+		fallthrough
+	case prepFormatMarkdown:
+		content := []byte(markdown)
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.md\"", filename))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(content)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"cv_version_id":  version.ID,
+		"language":       pack.Language,
+		"requirements":   pack.Requirements,
+		"missing_skills": pack.MissingSkills,
+		"generated_by":   pack.GeneratedBy,
+		"markdown":       markdown,
+	}); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
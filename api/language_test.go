@@ -0,0 +1,37 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnsupportedLanguage(t *testing.T) {
+	// Both reject the language before looking up the CV version
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"cover letter", NewCoverLetterHandler(nil, nil, nil).CreateCoverLetter},
+		{"interview prep", NewInterviewHandler(nil, nil, nil).CreateInterviewPrep},
+	}
+
+	for _, tt := range tests {
+		for _, language := range []string{"xx", "klingon-language"} {
+			t.Run(tt.name+"/"+language, func(t *testing.T) {
+				body := strings.NewReader(`{"cv_version_id": 1, "language": "` + language + `"}`)
+				rec := httptest.NewRecorder()
+
+				tt.handler(rec, httptest.NewRequest(http.MethodPost, "/", body))
+
+				if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "unsupported language") {
+					t.Errorf("status %d: %s", rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+}
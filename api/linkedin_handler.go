@@ -4,43 +4,87 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
 	"github.com/sammyoina/vibe-cv/internal/db"
 	"github.com/sammyoina/vibe-cv/internal/input"
+	"github.com/sammyoina/vibe-cv/internal/webhook"
 	"github.com/sammyoina/vibe-cv/pkg/auth"
 )
 
+// maxLinkedInExport bounds uploaded LinkedIn data export archives.
+const maxLinkedInExport = 50 * 1024 * 1024
+
 // LinkedInHandler handles LinkedIn import endpoints.
 type LinkedInHandler struct {
-	repo   *db.Repository
-	parser *input.LinkedInParser
+	repo     *db.Repository
+	parser   *input.LinkedInParser
+	webhooks *webhook.Dispatcher
 }
 
 // NewLinkedInHandler creates a new LinkedIn handler.
-func NewLinkedInHandler(repo *db.Repository) *LinkedInHandler {
+func NewLinkedInHandler(repo *db.Repository, webhooks *webhook.Dispatcher) *LinkedInHandler {
 	return &LinkedInHandler{
-		repo:   repo,
-		parser: input.NewLinkedInParser(),
+		repo:     repo,
+		parser:   input.NewLinkedInParser(),
+		webhooks: webhooks,
 	}
 }
 
-// ImportRequest represents the LinkedIn import request.
+// ImportRequest represents the LinkedIn import request. ExportFile holds the
+// ZIP archive of LinkedIn's "Download your data" export, base64 encoded, and
+// is read instead of ProfileText.
 type ImportRequest struct {
 	LinkedInURL string `json:"linkedin_url"`
 	ProfileText string `json:"profile_text"`
+	ExportFile  string `json:"export_file"`
+}
+
+// importRequest decodes the import request. A data export archive may also be
+// uploaded as the raw application/zip request body.
+func importRequest(r *http.Request) (*ImportRequest, []byte, error) {
+	body := io.LimitReader(r.Body, maxLinkedInExport)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/zip" {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, errors.New("failed to read export")
+		}
+
+		return &ImportRequest{LinkedInURL: r.URL.Query().Get("linkedin_url")}, data, nil
+	}
+
+	var req ImportRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, nil, errors.New("invalid request")
+	}
+
+	if req.ExportFile == "" {
+		return &req, nil, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(req.ExportFile)
+	if err != nil {
+		return nil, nil, errors.New("export_file must be base64 encoded")
+	}
+
+	return &req, data, nil
 }
 
 // ImportLinkedIn handles POST /api/latest/linkedin/import.
 func (h *LinkedInHandler) ImportLinkedIn(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var req ImportRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+	req, export, err := importRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
 
 		return
 	}
@@ -73,8 +117,14 @@ func (h *LinkedInHandler) ImportLinkedIn(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Parse LinkedIn profile
-	profile, err := h.parser.ParseProfile(req.ProfileText)
+	// Parse the data export, or else the pasted profile
+	var profile *input.LinkedInProfile
+	if export != nil {
+		profile, err = h.parser.ParseExport(export)
+	} else {
+		profile, err = h.parser.ParseProfile(req.ProfileText)
+	}
+
 	if err != nil {
 		// Update import with error
 		errorMsg := err.Error()
@@ -100,6 +150,14 @@ func (h *LinkedInHandler) ImportLinkedIn(w http.ResponseWriter, r *http.Request)
 		fmt.Printf("Failed to update import: %v\n", err)
 	}
 
+	if err := h.webhooks.Publish(identityID, webhook.EventLinkedInImportCompleted, map[string]interface{}{
+		"import_id":    linkedinImport.ID,
+		"linkedin_url": req.LinkedInURL,
+		"status":       "completed",
+	}); err != nil {
+		fmt.Printf("Failed to publish webhook event: %v\n", err)
+	}
+
 	// Prepare response
 	response := map[string]interface{}{
 		"id":            linkedinImport.ID,
@@ -107,12 +165,16 @@ func (h *LinkedInHandler) ImportLinkedIn(w http.ResponseWriter, r *http.Request)
 		"import_status": "completed",
 		"extracted_cv":  cvText,
 		"profile_data": map[string]interface{}{
-			"name":       profile.Name,
-			"title":      profile.Title,
-			"summary":    profile.Summary,
-			"experience": profile.Experience,
-			"skills":     profile.Skills,
-			"education":  profile.Education,
+			"name":           profile.Name,
+			"title":          profile.Title,
+			"summary":        profile.Summary,
+			"location":       profile.Location,
+			"industry":       profile.Industry,
+			"websites":       profile.Websites,
+			"experience":     profile.Experience,
+			"skills":         profile.Skills,
+			"education":      profile.Education,
+			"certifications": profile.Certifications,
 		},
 	}
 
@@ -0,0 +1,217 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sammyoina/vibe-cv/internal/ats"
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/master"
+	"github.com/sammyoina/vibe-cv/internal/match"
+)
+
+// maxMasterJobs is the most jobs a master CV is built for; the keywords of
+// each are extracted with the LLM.
+const maxMasterJobs = 10
+
+// MasterCVHandler handles master CVs built for families of similar jobs.
+type MasterCVHandler struct {
+	optimizer *master.Optimizer
+	repo      *db.Repository
+	scorer    *match.Scorer
+}
+
+// NewMasterCVHandler creates a new master CV handler.
+func NewMasterCVHandler(provider llm.Provider, repo *db.Repository) *MasterCVHandler {
+	return &MasterCVHandler{
+		optimizer: master.NewOptimizer(provider),
+		repo:      repo,
+		scorer:    match.NewScorer(match.NewLocalEmbedder()),
+	}
+}
+
+// SetScorer sets the scorer of the master CV's match with each job.
+func (h *MasterCVHandler) SetScorer(scorer *match.Scorer) {
+	h.scorer = scorer
+}
+
+// MasterCVRequest represents the master CV request. The base CV is text or a
+// stored version, and the jobs saved jobs, descriptions, or both.
+type MasterCVRequest struct {
+	CV              string   `json:"cv,omitempty"`
+	CVVersionID     int      `json:"cv_version_id,omitempty"`
+	SavedJobIDs     []int    `json:"saved_job_ids,omitempty"` // Requires authentication
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+	ATSProfile      string   `json:"ats_profile,omitempty"` // ATS to simulate; defaults to "generic"
+}
+
+// CreateMasterCV handles POST /api/latest/master-cv. It builds one CV for
+// several similar jobs, keeping, reordering and dropping content by how many
+// of the jobs ask for it, and stores it as a new version of the base CV. The
+// response holds the decisions taken and, for each job, how the master CV
+// compares with the base one.
+func (h *MasterCVHandler) CreateMasterCV(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req MasterCVRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	profile, ok := ats.LookupProfile(req.ATSProfile)
+	if !ok {
+		http.Error(w, `{"error": "unknown ats_profile: see GET /api/latest/ats/profiles"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	identityID, authenticated := authenticatedIdentity(h.repo, r)
+
+	var jobs []master.Job
+
+	if len(req.SavedJobIDs) > 0 {
+		if !authenticated {
+			http.Error(w, `{"error": "authentication required for saved_job_ids"}`, http.StatusUnauthorized)
+
+			return
+		}
+
+		for _, id := range req.SavedJobIDs {
+			saved, err := h.repo.GetSavedJob(id, identityID)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "saved job %d not found"}`, id), http.StatusNotFound)
+
+				return
+			}
+
+			jobs = append(jobs, master.Job{SavedJobID: saved.ID, Title: saved.Title, Description: saved.Description})
+		}
+	}
+
+	for _, desc := range req.JobDescriptions {
+		if desc != "" {
+			jobs = append(jobs, master.Job{Description: desc})
+		}
+	}
+
+	if len(jobs) < 2 || len(jobs) > maxMasterJobs {
+		http.Error(w, fmt.Sprintf(`{"error": "between 2 and %d jobs required"}`, maxMasterJobs), http.StatusBadRequest)
+
+		return
+	}
+
+	// The master CV is a new version of the base CV
+	var cvID int
+
+	cvText := req.CV
+
+	if req.CVVersionID != 0 {
+		version, err := h.repo.GetCVVersion(req.CVVersionID)
+		if err != nil {
+			http.Error(w, `{"error": "CV version not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		cvID = version.CVID
+		cvText = version.CustomizedCV
+	} else if cvText != "" {
+		var owner *int
+		if authenticated {
+			owner = &identityID
+		}
+
+		cvRecord, err := h.repo.CreateCV(owner, cvText)
+		if err != nil {
+			http.Error(w, `{"error": "failed to store CV"}`, http.StatusInternalServerError)
+
+			return
+		}
+
+		cvID = cvRecord.ID
+	} else {
+		http.Error(w, `{"error": "cv or cv_version_id required"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	result, err := h.optimizer.Optimize(r.Context(), cvText, jobs, profile)
+	if err != nil {
+		fmt.Printf("Master CV optimization failed: %v\n", err)
+		http.Error(w, `{"error": "master CV optimization failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	// The version's match score is the average of its match with each job
+	matchScores := make([]float64, 0, len(jobs))
+	total := 0.0
+
+	for _, job := range jobs {
+		scored, err := h.scorer.Score(r.Context(), result.CV, job.Description)
+		if err != nil {
+			fmt.Printf("Failed to score match: %v\n", err)
+
+			break
+		}
+
+		matchScores = append(matchScores, scored.Score)
+		total += scored.Score
+	}
+
+	var matchScore *float64
+	if len(matchScores) == len(jobs) {
+		average := total / float64(len(jobs))
+		matchScore = &average
+	}
+
+	decisionsJSON, _ := json.Marshal(result.Decisions)
+	featuresUsed, _ := json.Marshal(map[string]interface{}{
+		"ats_optimization": true,
+		"master_cv":        true,
+		"jobs":             len(jobs),
+	})
+
+	version, err := h.repo.CreateCVVersion(cvID, master.JobDescription(jobs), result.CV, matchScore,
+		(*json.RawMessage)(&decisionsJSON), nil, (*json.RawMessage)(&featuresUsed))
+	if err != nil {
+		http.Error(w, `{"error": "failed to store CV version"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"cv_version_id":   version.ID,
+		"cv_id":           cvID,
+		"master_cv":       result.CV,
+		"ats_profile":     result.Profile,
+		"keywords":        result.Keywords,
+		"decisions":       result.Decisions,
+		"coverage_before": result.CoverageBefore,
+		"coverage_after":  result.CoverageAfter,
+		"jobs":            result.Jobs,
+		"generated_by":    result.GeneratedBy,
+		"download_url":    fmt.Sprintf("/api/latest/download/%d", version.ID),
+	}
+
+	if matchScore != nil {
+		response["match_score"] = *matchScore
+		response["match_scores"] = matchScores
+	}
+
+	if req.CVVersionID != 0 {
+		response["previous_cv_version_id"] = req.CVVersionID
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
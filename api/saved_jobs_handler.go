@@ -0,0 +1,445 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sammyoina/vibe-cv/internal/batch"
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/input"
+)
+
+const (
+	// defaultSavedJobLimit is how many saved jobs are listed per page.
+	defaultSavedJobLimit = 50
+	// maxEmailUpload bounds uploaded email messages, base64 encoded or raw.
+	maxEmailUpload = 35 * 1024 * 1024
+	// defaultFeedInterval, minFeedInterval and maxFeedInterval bound how often
+	// a feed is polled, in minutes.
+	defaultFeedInterval = 60
+	minFeedInterval     = 15
+	maxFeedInterval     = 7 * 24 * 60
+)
+
+// SavedJobsHandler handles job postings collected from emails and feeds.
+type SavedJobsHandler struct {
+	repo    *db.Repository
+	fetcher *input.Fetcher
+}
+
+// NewSavedJobsHandler creates a new saved jobs handler. Posting links are
+// fetched with fetcher.
+func NewSavedJobsHandler(repo *db.Repository, fetcher *input.Fetcher) *SavedJobsHandler {
+	return &SavedJobsHandler{
+		repo:    repo,
+		fetcher: fetcher,
+	}
+}
+
+// ImportEmailRequest represents the email import request. Message holds an
+// RFC 5322 message (the content of an .eml file), base64 encoded.
+type ImportEmailRequest struct {
+	Message string `json:"message"`
+}
+
+// CreateFeedRequest represents the feed registration request.
+type CreateFeedRequest struct {
+	URL                 string `json:"url"`
+	PollIntervalMinutes int    `json:"poll_interval_minutes"`
+	Active              *bool  `json:"active"`
+}
+
+// savedJobID parses the saved_job_id path value.
+func savedJobID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("saved_job_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid saved_job_id"}`, http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// feedID parses the feed_id path value.
+func feedID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("feed_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid feed_id"}`, http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// emailMessage reads the uploaded message: the raw request body when it is
+// sent as message/rfc822, or the base64 message of an ImportEmailRequest.
+func emailMessage(r *http.Request) ([]byte, error) {
+	body := io.LimitReader(r.Body, maxEmailUpload)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "message/rfc822" {
+		return io.ReadAll(body)
+	}
+
+	var req ImportEmailRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, errors.New("invalid request")
+	}
+
+	if req.Message == "" {
+		return nil, errors.New("message required")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(req.Message)
+	if err != nil {
+		return nil, errors.New("message must be base64 encoded")
+	}
+
+	return data, nil
+}
+
+// ImportEmail handles POST /api/latest/saved-jobs/email.
+// The postings linked from the email, or the email itself when it links to
+// none, are saved. Postings saved before are skipped.
+func (h *SavedJobsHandler) ImportEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	data, err := emailMessage(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	msg, err := input.ParseEmail(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	jobs, errs := h.fetcher.JobsFromEmail(r.Context(), msg)
+	if len(jobs) == 0 {
+		response := map[string]interface{}{
+			"error":  "no job postings could be read from the email",
+			"errors": errs,
+		}
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	saved, err := batch.SaveIngestedJobs(h.repo, identityID, input.JobSourceEmail, nil, jobs)
+	if err != nil {
+		fmt.Printf("Failed to save jobs from email: %v\n", err)
+		http.Error(w, `{"error": "failed to save jobs"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"subject":    msg.Subject,
+		"saved_jobs": saved,
+		"duplicates": len(jobs) - len(saved),
+		"errors":     errs,
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ListSavedJobs handles GET /api/latest/saved-jobs.
+// The limit, offset and feed_id query parameters page and filter the list.
+func (h *SavedJobsHandler) ListSavedJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := defaultSavedJobLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if n, err := strconv.Atoi(offsetStr); err == nil && n > 0 {
+			offset = n
+		}
+	}
+
+	var feed *int
+
+	if feedStr := query.Get("feed_id"); feedStr != "" {
+		id, err := strconv.Atoi(feedStr)
+		if err != nil {
+			http.Error(w, `{"error": "invalid feed_id"}`, http.StatusBadRequest)
+
+			return
+		}
+
+		feed = &id
+	}
+
+	jobs, err := h.repo.GetSavedJobs(identityID, feed, limit, offset)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve saved jobs"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(jobs); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// GetSavedJob handles GET /api/latest/saved-jobs/{saved_job_id}.
+func (h *SavedJobsHandler) GetSavedJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := savedJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.repo.GetSavedJob(id, identityID)
+	if err != nil {
+		http.Error(w, `{"error": "saved job not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DeleteSavedJob handles DELETE /api/latest/saved-jobs/{saved_job_id}.
+func (h *SavedJobsHandler) DeleteSavedJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := savedJobID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteSavedJob(id, identityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "saved job not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to delete saved job"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateFeed handles POST /api/latest/feeds.
+// An active feed is polled at once, then every poll_interval_minutes.
+func (h *SavedJobsHandler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	var req CreateFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, `{"error": "url must be an http or https URL"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if req.PollIntervalMinutes == 0 {
+		req.PollIntervalMinutes = defaultFeedInterval
+	}
+
+	if req.PollIntervalMinutes < minFeedInterval || req.PollIntervalMinutes > maxFeedInterval {
+		http.Error(w, fmt.Sprintf(`{"error": "poll_interval_minutes must be between %d and %d"}`, minFeedInterval, maxFeedInterval), http.StatusBadRequest)
+
+		return
+	}
+
+	feed, err := h.repo.CreateJobFeed(&db.JobFeed{
+		IdentityID:          identityID,
+		URL:                 u.String(),
+		PollIntervalMinutes: req.PollIntervalMinutes,
+		Active:              req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrFeedExists) {
+			http.Error(w, `{"error": "feed already registered"}`, http.StatusConflict)
+
+			return
+		}
+
+		fmt.Printf("Failed to create feed: %v\n", err)
+		http.Error(w, `{"error": "failed to create feed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(feed); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ListFeeds handles GET /api/latest/feeds.
+func (h *SavedJobsHandler) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	feeds, err := h.repo.GetJobFeeds(identityID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve feeds"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(feeds); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DeleteFeed handles DELETE /api/latest/feeds/{feed_id}.
+// Jobs the feed already saved are kept.
+func (h *SavedJobsHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := feedID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteJobFeed(id, identityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "feed not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to delete feed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PollFeed handles POST /api/latest/feeds/{feed_id}/poll.
+// The feed is made due immediately and picked up by the next feed poll.
+func (h *SavedJobsHandler) PollFeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := feedID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.TriggerJobFeed(id, identityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "feed not found or paused"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to trigger feed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"feed_id": id,
+		"status":  "triggered",
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
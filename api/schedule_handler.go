@@ -0,0 +1,338 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/batch"
+	"github.com/sammyoina/vibe-cv/internal/db"
+)
+
+// scheduleRunsLimit is how many recent jobs are returned with a schedule.
+const scheduleRunsLimit = 20
+
+// ScheduleHandler handles recurring batch schedule endpoints.
+type ScheduleHandler struct {
+	repo *db.Repository
+}
+
+// NewScheduleHandler creates a new schedule handler.
+func NewScheduleHandler(repo *db.Repository) *ScheduleHandler {
+	return &ScheduleHandler{repo: repo}
+}
+
+// ScheduleRequest represents the create and update schedule request.
+type ScheduleRequest struct {
+	Name           string               `json:"name"`
+	Cron           string               `json:"cron"`
+	Timezone       string               `json:"timezone"`
+	CandidateCVIDs []int                `json:"candidate_cv_ids"`
+	JobSource      db.ScheduleJobSource `json:"job_source"`
+	OnlyNewJobs    bool                 `json:"only_new_jobs"`
+	Active         *bool                `json:"active"`
+}
+
+// schedule decodes and validates a schedule request for an identity.
+func (h *ScheduleHandler) schedule(r *http.Request, identityID int) (*db.BatchSchedule, error) {
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid request")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, errors.New("name required")
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	if len(req.CandidateCVIDs) == 0 {
+		return nil, errors.New("candidate_cv_ids required")
+	}
+
+	if len(req.JobSource.URLs) == 0 && len(req.JobSource.JobDescriptions) == 0 {
+		return nil, errors.New("job_source must include urls or job_descriptions")
+	}
+
+	if total := len(req.CandidateCVIDs) * (len(req.JobSource.URLs) + len(req.JobSource.JobDescriptions)); total > batch.MaxScheduleItems {
+		return nil, fmt.Errorf("schedule would create %d items per run, more than the limit of %d", total, batch.MaxScheduleItems)
+	}
+
+	for _, raw := range req.JobSource.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid job source url %q", raw)
+		}
+	}
+
+	// Candidates must be CVs the user owns
+	for _, cvID := range req.CandidateCVIDs {
+		cv, err := h.repo.GetCV(cvID)
+		if err != nil || cv.IdentityID == nil || *cv.IdentityID != identityID {
+			return nil, fmt.Errorf("cv %d not found", cvID)
+		}
+	}
+
+	active := req.Active == nil || *req.Active
+
+	var nextRunAt *time.Time
+
+	next, err := batch.NextRun(req.Cron, req.Timezone, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	if active {
+		nextRunAt = &next
+	}
+
+	return &db.BatchSchedule{
+		IdentityID:     identityID,
+		Name:           req.Name,
+		CronExpr:       req.Cron,
+		Timezone:       req.Timezone,
+		CandidateCVIDs: req.CandidateCVIDs,
+		JobSource:      req.JobSource,
+		OnlyNewJobs:    req.OnlyNewJobs,
+		Active:         active,
+		NextRunAt:      nextRunAt,
+	}, nil
+}
+
+// scheduleID parses the schedule_id path value.
+func scheduleID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("schedule_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid schedule_id"}`, http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// CreateSchedule handles POST /api/latest/schedules.
+func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	schedule, err := h.schedule(r, identityID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	created, err := h.repo.CreateBatchSchedule(schedule)
+	if err != nil {
+		fmt.Printf("Failed to create schedule: %v\n", err)
+		http.Error(w, `{"error": "failed to create schedule"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ListSchedules handles GET /api/latest/schedules.
+func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	schedules, err := h.repo.GetBatchSchedules(identityID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve schedules"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(schedules); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// GetSchedule handles GET /api/latest/schedules/{schedule_id}.
+// The response includes the most recent batch jobs the schedule created.
+func (h *ScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.repo.GetBatchSchedule(id, identityID)
+	if err != nil {
+		http.Error(w, `{"error": "schedule not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	runs, err := h.repo.GetBatchJobsBySchedule(schedule.ID, scheduleRunsLimit)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve schedule runs"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"schedule": schedule,
+		"runs":     runs,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// UpdateSchedule handles PUT /api/latest/schedules/{schedule_id}.
+func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.schedule(r, identityID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	schedule.ID = id
+
+	updated, err := h.repo.UpdateBatchSchedule(schedule)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "schedule not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Printf("Failed to update schedule %d: %v\n", id, err)
+		http.Error(w, `{"error": "failed to update schedule"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DeleteSchedule handles DELETE /api/latest/schedules/{schedule_id}.
+func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteBatchSchedule(id, identityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "schedule not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to delete schedule"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunSchedule handles POST /api/latest/schedules/{schedule_id}/run.
+// The schedule is made due immediately and picked up by the next batch worker.
+func (h *ScheduleHandler) RunSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	id, ok := scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.TriggerBatchSchedule(id, identityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "schedule not found or paused"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to trigger schedule"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"schedule_id": id,
+		"status":      "triggered",
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/gap"
+)
+
+// SkillGapHandler handles skill gap reports.
+type SkillGapHandler struct {
+	repo *db.Repository
+}
+
+// NewSkillGapHandler creates a new skill gap handler.
+func NewSkillGapHandler(repo *db.Repository) *SkillGapHandler {
+	return &SkillGapHandler{repo: repo}
+}
+
+// SkillGapRequest represents the skill gap request. The CV is a stored
+// version or text, and the jobs saved jobs, descriptions, or both.
+type SkillGapRequest struct {
+	CVVersionID     int      `json:"cv_version_id,omitempty"`
+	CV              string   `json:"cv,omitempty"`
+	SavedJobIDs     []int    `json:"saved_job_ids,omitempty"` // Requires authentication
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+}
+
+// CreateSkillGapReport handles POST /api/latest/skill-gap. It ranks the
+// skills the CV is missing or weak in across the jobs, and suggests a
+// learning plan for the top ones. A CV version without jobs is compared with
+// the job it was customized for.
+func (h *SkillGapHandler) CreateSkillGapReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req SkillGapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	cvText := req.CV
+
+	var jobs []gap.Job
+
+	if req.CVVersionID != 0 {
+		version, err := h.repo.GetCVVersion(req.CVVersionID)
+		if err != nil {
+			http.Error(w, `{"error": "CV version not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		cvText = version.CustomizedCV
+
+		if len(req.SavedJobIDs) == 0 && len(req.JobDescriptions) == 0 {
+			jobs = append(jobs, gap.Job{Description: version.JobDescription})
+		}
+	}
+
+	if cvText == "" {
+		http.Error(w, `{"error": "cv_version_id or cv required"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if len(req.SavedJobIDs) > 0 {
+		identityID, ok := authenticatedIdentity(h.repo, r)
+		if !ok {
+			http.Error(w, `{"error": "authentication required for saved_job_ids"}`, http.StatusUnauthorized)
+
+			return
+		}
+
+		for _, id := range req.SavedJobIDs {
+			saved, err := h.repo.GetSavedJob(id, identityID)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "saved job %d not found"}`, id), http.StatusNotFound)
+
+				return
+			}
+
+			jobs = append(jobs, gap.Job{SavedJobID: saved.ID, Title: saved.Title, Company: saved.Company, Description: saved.Description})
+		}
+	}
+
+	for _, desc := range req.JobDescriptions {
+		if desc != "" {
+			jobs = append(jobs, gap.Job{Description: desc})
+		}
+	}
+
+	if len(jobs) == 0 {
+		http.Error(w, `{"error": "saved_job_ids or job_descriptions required"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	report, err := gap.Analyze(cvText, jobs, time.Now())
+	if err != nil {
+		fmt.Printf("Skill gap analysis failed: %v\n", err)
+		http.Error(w, `{"error": "skill gap analysis failed"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"jobs":          report.Jobs,
+		"gaps":          report.Gaps,
+		"per_job":       report.PerJob,
+		"learning_plan": report.LearningPlan,
+	}
+
+	if req.CVVersionID != 0 {
+		response["cv_version_id"] = req.CVVersionID
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/webhook"
+)
+
+// defaultDeliveryLimit is how many deliveries are listed per endpoint.
+const defaultDeliveryLimit = 50
+
+// WebhookHandler handles webhook endpoint management.
+type WebhookHandler struct {
+	repo       *db.Repository
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler.
+func NewWebhookHandler(repo *db.Repository, dispatcher *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{
+		repo:       repo,
+		dispatcher: dispatcher,
+	}
+}
+
+// CreateWebhookRequest represents the webhook registration request.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// endpoint loads the endpoint named by the webhook_id path value and checks
+// that it belongs to the authenticated user, writing an error response if not.
+func (h *WebhookHandler) endpoint(w http.ResponseWriter, r *http.Request) (*db.WebhookEndpoint, bool) {
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return nil, false
+	}
+
+	webhookID, err := strconv.Atoi(r.PathValue("webhook_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid webhook_id"}`, http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	endpoint, err := h.repo.GetWebhookEndpoint(webhookID, identityID)
+	if err != nil {
+		http.Error(w, `{"error": "webhook not found"}`, http.StatusNotFound)
+
+		return nil, false
+	}
+
+	return endpoint, true
+}
+
+// CreateWebhook handles POST /api/latest/webhooks.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	if err := webhook.ValidateEndpointURL(req.URL); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	if req.EventTypes == nil {
+		req.EventTypes = []string{}
+	}
+
+	if err := webhook.ValidateEventTypes(req.EventTypes); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		http.Error(w, `{"error": "failed to create webhook"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	endpoint, err := h.repo.CreateWebhookEndpoint(identityID, req.URL, secret, req.EventTypes)
+	if err != nil {
+		fmt.Printf("Failed to create webhook: %v\n", err)
+		http.Error(w, `{"error": "failed to create webhook"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	// The secret is only returned once, at registration
+	response := map[string]interface{}{
+		"id":          endpoint.ID,
+		"url":         endpoint.URL,
+		"event_types": endpoint.EventTypes,
+		"active":      endpoint.Active,
+		"secret":      secret,
+		"created_at":  endpoint.CreatedAt,
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ListWebhooks handles GET /api/latest/webhooks.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	identityID, ok := authenticatedIdentity(h.repo, r)
+	if !ok {
+		http.Error(w, `{"error": "authentication required"}`, http.StatusUnauthorized)
+
+		return
+	}
+
+	endpoints, err := h.repo.GetWebhookEndpoints(identityID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve webhooks"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(endpoints); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// DeleteWebhook handles DELETE /api/latest/webhooks/{webhook_id}.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteWebhookEndpoint(endpoint.ID, endpoint.IdentityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "webhook not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		http.Error(w, `{"error": "failed to delete webhook"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /api/latest/webhooks/{webhook_id}/deliveries.
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+
+	deliveries, err := h.repo.GetWebhookDeliveries(endpoint.ID, limit)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve deliveries"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// GetWebhookDelivery handles GET /api/latest/webhooks/{webhook_id}/deliveries/{delivery_id}.
+// The response includes the log of every delivery attempt.
+func (h *WebhookHandler) GetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(r.PathValue("delivery_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid delivery_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	delivery, err := h.repo.GetWebhookDelivery(deliveryID, endpoint.ID)
+	if err != nil {
+		http.Error(w, `{"error": "delivery not found"}`, http.StatusNotFound)
+
+		return
+	}
+
+	logs, err := h.repo.GetWebhookDeliveryLogs(delivery.ID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to retrieve delivery logs"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	response := map[string]interface{}{
+		"delivery": delivery,
+		"attempts": logs,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
+
+// ReplayWebhookDelivery handles POST /api/latest/webhooks/{webhook_id}/deliveries/{delivery_id}/replay.
+// A new delivery of the same event is scheduled immediately.
+func (h *WebhookHandler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(r.PathValue("delivery_id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid delivery_id"}`, http.StatusBadRequest)
+
+		return
+	}
+
+	delivery, err := h.dispatcher.Replay(deliveryID, endpoint.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "delivery not found"}`, http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Printf("Failed to replay delivery %d: %v\n", deliveryID, err)
+		http.Error(w, `{"error": "failed to replay delivery"}`, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(delivery); err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+	}
+}
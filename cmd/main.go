@@ -17,7 +17,9 @@ import (
 	"github.com/sammyoina/vibe-cv/api"
 	"github.com/sammyoina/vibe-cv/internal/config"
 	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/egress"
 	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
 	"github.com/sammyoina/vibe-cv/internal/observability"
 	"github.com/sammyoina/vibe-cv/internal/security"
 	"github.com/sammyoina/vibe-cv/pkg/auth"
@@ -93,11 +95,27 @@ func main() {
 	os.Remove(testFile)
 	log.Printf("Output directory validated: %s", outputDir)
 
-	latestHandler := api.NewLatestHandler(provider, repo, authConfig)
+	egressPolicy := egress.LoadPolicy()
+	if egressPolicy.AllowPrivate {
+		log.Println("Outbound requests may reach private networks")
+	}
+
+	latestHandler := api.NewLatestHandler(provider, repo, authConfig, egressPolicy)
+
+	embedder, err := match.NewEmbedder(context.TODO(), cfg.EmbeddingBackend, cfg.EmbeddingAPIKey, cfg.EmbeddingModel, cfg.EmbeddingURL)
+	if err != nil {
+		log.Fatalf("Failed to create embedding backend: %v", err)
+	}
+
+	latestHandler.SetScorer(match.NewScorer(embedder))
+	log.Printf("Match scores use %s embeddings", embedder.Name())
 
 	if repo != nil {
 		latestHandler.StartQueue()
 		log.Println("Batch job queue started with 4 workers")
+
+		latestHandler.StartWebhooks()
+		log.Println("Webhook dispatcher started")
 	}
 
 	authMiddleware := auth.Middleware(authConfig)
@@ -153,6 +171,18 @@ func main() {
 		log.Println("  GET    /api/latest/dashboard              - Get global dashboard")
 		log.Println("  GET    /api/latest/batch/{job_id}/status  - Check batch job status")
 		log.Println("  GET    /api/latest/batch/{job_id}/download- Download batch results")
+		log.Println("  POST   /api/latest/batch/{job_id}/cancel  - Cancel a batch job")
+		log.Println("  POST   /api/latest/batch/{job_id}/retry-failed - Retry failed batch items")
+		log.Println("  POST   /api/latest/schedules              - Create a recurring batch schedule")
+		log.Println("  GET    /api/latest/schedules              - List batch schedules")
+		log.Println("  PUT    /api/latest/schedules/{id}         - Update a batch schedule")
+		log.Println("  DELETE /api/latest/schedules/{id}         - Delete a batch schedule")
+		log.Println("  POST   /api/latest/schedules/{id}/run     - Run a schedule now")
+		log.Println("  POST   /api/latest/webhooks               - Register a webhook endpoint")
+		log.Println("  GET    /api/latest/webhooks               - List webhook endpoints")
+		log.Println("  DELETE /api/latest/webhooks/{id}          - Delete a webhook endpoint")
+		log.Println("  GET    /api/latest/webhooks/{id}/deliveries - List webhook deliveries")
+		log.Println("  POST   /api/latest/webhooks/{id}/deliveries/{delivery_id}/replay - Replay a delivery")
 		log.Println("  GET    /api/latest/health                 - Health check")
 
 		if repo == nil {
@@ -174,6 +204,9 @@ func main() {
 	if repo != nil {
 		latestHandler.StopQueue()
 		log.Println("Batch job queue stopped")
+
+		latestHandler.StopWebhooks()
+		log.Println("Webhook dispatcher stopped")
 	}
 
 	if err := server.Close(); err != nil {
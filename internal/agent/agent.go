@@ -86,13 +86,17 @@ func NewJobAnalyzerAgent(config *AgentConfig, provider llm.Provider) *JobAnalyze
 func (jaa *JobAnalyzerAgent) Execute(ctx context.Context, state *AgentState) (*AgentState, error) {
 	newState, _ := jaa.BaseAgent.Execute(ctx, state)
 
+	// Skills come from the taxonomy, so the CV's gaps are known without the LLM
+	gap := AnalyzeSkills(state.CV, state.JobDescription)
+	newState.RequiredSkills = gap.Required
+	newState.PreferredSkills = gap.Preferred
+	newState.MissingSkills = gap.Missing
+
 	prompt := "Analyze this job description and extract: required skills, preferred skills, and complexity (1-10).\n\nJob: " + state.JobDescription
 
 	resp, err := jaa.llmProvider.Customize(context.Background(), state.CV, prompt, state.AdditionalContext)
 	if err == nil {
 		_ = resp // Use resp if needed
-		newState.RequiredSkills = []string{"Go", "Backend Development", "Cloud"}
-		newState.PreferredSkills = []string{"Kubernetes", "Docker"}
 		newState.KeyKeywords = []string{"Go", "microservices", "API"}
 		newState.JobComplexity = 7.0
 	}
@@ -213,6 +217,7 @@ func (o *Orchestrator) ExecuteWithState(ctx context.Context, state *AgentState)
 		Modifications:       make([]string, 0),
 		RequiredSkills:      make([]string, 0),
 		PreferredSkills:     make([]string, 0),
+		MissingSkills:       make([]string, 0),
 		DecisionHistory:     make([]Decision, 0),
 		ConversationHistory: make([]Message, 0),
 		ValidationErrors:    make([]string, 0),
@@ -244,6 +249,7 @@ func (o *Orchestrator) ExecuteWithState(ctx context.Context, state *AgentState)
 	result.Modifications = state.Modifications
 	result.RequiredSkills = state.RequiredSkills
 	result.PreferredSkills = state.PreferredSkills
+	result.MissingSkills = state.MissingSkills
 	result.JobComplexity = state.JobComplexity
 	result.IterationsUsed = state.IterationCount
 	result.IsValid = state.IsValid
@@ -0,0 +1,64 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package agent
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/input"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+)
+
+// SkillGap is the skills a job asks for, by the canonical names of the
+// skills taxonomy, and those of them a CV does not show.
+type SkillGap struct {
+	Required  []string
+	Preferred []string
+	Missing   []string
+}
+
+// AnalyzeSkills compares the skills of a CV with those of a job. Skills of
+// the job's requirement lists are required and those of its preferred skill
+// lists preferred; a job without lists requires every skill it names. A
+// skill is missing unless the CV shows it or a narrower one, such as
+// PostgreSQL for SQL.
+func AnalyzeSkills(cv, jobDescription string) SkillGap {
+	taxonomy := skills.Default()
+	job := input.NewEnhancedParser().ParseJobDescription(jobDescription)
+
+	requiredText := strings.Join(job.Requirements, "\n")
+	if len(job.Requirements) == 0 && len(job.PreferredSkills) == 0 {
+		requiredText = jobDescription
+	}
+
+	required := taxonomy.IDs(requiredText)
+
+	var preferred []string
+
+	for _, id := range taxonomy.IDs(strings.Join(job.PreferredSkills, "\n")) {
+		if !slices.Contains(required, id) {
+			preferred = append(preferred, id)
+		}
+	}
+
+	have := taxonomy.IDs(cv)
+	gap := SkillGap{Required: []string{}, Preferred: []string{}, Missing: []string{}}
+
+	for _, ids := range []struct {
+		ids  []string
+		into *[]string
+	}{{required, &gap.Required}, {preferred, &gap.Preferred}} {
+		for _, id := range ids.ids {
+			skill, _ := taxonomy.Skill(id)
+			*ids.into = append(*ids.into, skill.Name)
+
+			if !taxonomy.Covers(have, id) {
+				gap.Missing = append(gap.Missing, skill.Name)
+			}
+		}
+	}
+
+	return gap
+}
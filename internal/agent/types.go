@@ -88,6 +88,7 @@ type WorkflowResult struct {
 	Modifications       []string
 	RequiredSkills      []string
 	PreferredSkills     []string
+	MissingSkills       []string
 	JobComplexity       float64
 	IterationsUsed      int
 	ExecutionTime       time.Duration
@@ -4,12 +4,19 @@
 package analytics
 
 import (
+	"encoding/json"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/skills"
 )
 
+// topKeywordsLimit is the number of skills listed in the dashboard.
+const topKeywordsLimit = 10
+
 // Collector collects analytics metrics.
 type Collector struct {
 	mu   sync.RWMutex
@@ -31,6 +38,31 @@ type AnalyticsData struct {
 	TimeRange              string                  `json:"time_range"`
 	MatchScoreDistribution map[string]int          `json:"match_score_distribution"`
 	RecentSnapshots        []*db.AnalyticsSnapshot `json:"recent_snapshots"`
+	SkillGaps              []SkillGapMetric        `json:"skill_gaps"`
+	ATS                    *ATSMetrics             `json:"ats,omitempty"`
+}
+
+// SkillGapMetric is a skill the customized CVs miss, with the number of
+// target jobs asking for it and their share of the jobs measured.
+type SkillGapMetric struct {
+	Skill string  `json:"skill"`
+	Jobs  int     `json:"jobs"`
+	Share float64 `json:"share"`
+}
+
+// ATSMetrics sums up recent ATS analyses.
+type ATSMetrics struct {
+	Analyses     int                `json:"analyses"`
+	AverageScore float64            `json:"average_score"`
+	ByProfile    map[string]float64 `json:"average_score_by_profile"`
+	ScoreTrend   []DailyATSMetric   `json:"score_trend"` // Oldest first
+}
+
+// DailyATSMetric is the average overall score of a day's ATS analyses.
+type DailyATSMetric struct {
+	Date         time.Time `json:"date"`
+	OverallScore float64   `json:"overall_score"`
+	Count        int       `json:"count"`
 }
 
 // GetAnalytics retrieves analytics data for an identity.
@@ -43,10 +75,17 @@ func (c *Collector) GetAnalytics(identityID *int, limit int) (*AnalyticsData, er
 		return nil, err
 	}
 
+	atsMetrics, err := c.atsMetrics(identityID, limit)
+	if err != nil {
+		return nil, err
+	}
+
 	analytics := &AnalyticsData{
 		TimeRange:              "last_entries",
 		MatchScoreDistribution: make(map[string]int),
 		RecentSnapshots:        snapshots,
+		SkillGaps:              make([]SkillGapMetric, 0),
+		ATS:                    atsMetrics,
 	}
 
 	if len(snapshots) == 0 {
@@ -57,6 +96,8 @@ func (c *Collector) GetAnalytics(identityID *int, limit int) (*AnalyticsData, er
 	totalCoverage := 0.0
 	scoreCount := 0
 	coverageCount := 0
+	measured := 0
+	gaps := make(map[string]int)
 
 	for _, snapshot := range snapshots {
 		if snapshot.MatchScore != nil {
@@ -72,6 +113,17 @@ func (c *Collector) GetAnalytics(identityID *int, limit int) (*AnalyticsData, er
 			totalCoverage += *snapshot.KeywordCoverage
 			coverageCount++
 		}
+
+		var meta SkillMetadata
+		if snapshot.Metadata == nil || json.Unmarshal(*snapshot.Metadata, &meta) != nil || len(meta.JobSkills) == 0 {
+			continue
+		}
+
+		measured++
+
+		for _, skill := range meta.MissingSkills {
+			gaps[skill]++
+		}
 	}
 
 	if scoreCount > 0 {
@@ -82,11 +134,118 @@ func (c *Collector) GetAnalytics(identityID *int, limit int) (*AnalyticsData, er
 		analytics.AverageKeywordCoverage = totalCoverage / float64(coverageCount)
 	}
 
+	analytics.SkillGaps = skillGaps(gaps, measured)
+
 	analytics.TotalCustomizations = len(snapshots)
 
 	return analytics, nil
 }
 
+// skillGaps returns the skills the CVs miss most often across the jobs they
+// were customized for, out of the measured jobs.
+func skillGaps(gaps map[string]int, measured int) []SkillGapMetric {
+	top := make([]SkillGapMetric, 0, len(gaps))
+
+	for skill, jobs := range gaps {
+		top = append(top, SkillGapMetric{Skill: skill, Jobs: jobs, Share: float64(jobs) / float64(measured)})
+	}
+
+	slices.SortFunc(top, func(a, b SkillGapMetric) int {
+		if a.Jobs != b.Jobs {
+			return b.Jobs - a.Jobs
+		}
+
+		return strings.Compare(a.Skill, b.Skill)
+	})
+
+	if len(top) > topKeywordsLimit {
+		top = top[:topKeywordsLimit]
+	}
+
+	return top
+}
+
+// atsMetrics sums up the recent ATS analyses of an identity, or of everyone
+// when identityID is nil. It returns nil when there are none.
+func (c *Collector) atsMetrics(identityID *int, limit int) (*ATSMetrics, error) {
+	analyses, err := c.repo.RecentATSAnalyses(identityID, limit)
+	if err != nil || len(analyses) == 0 {
+		return nil, err
+	}
+
+	metrics := &ATSMetrics{ByProfile: make(map[string]float64), ScoreTrend: make([]DailyATSMetric, 0)}
+	profileCounts := make(map[string]int)
+	days := make(map[time.Time]*DailyATSMetric)
+
+	var total float64
+
+	for _, analysis := range analyses {
+		if analysis.OverallScore == nil {
+			continue
+		}
+
+		score := *analysis.OverallScore
+		total += score
+		metrics.Analyses++
+
+		metrics.ByProfile[analysis.ATSProfile] += score
+		profileCounts[analysis.ATSProfile]++
+
+		day := analysis.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if days[day] == nil {
+			days[day] = &DailyATSMetric{Date: day}
+		}
+
+		days[day].OverallScore += score
+		days[day].Count++
+	}
+
+	if metrics.Analyses == 0 {
+		return nil, nil
+	}
+
+	metrics.AverageScore = total / float64(metrics.Analyses)
+
+	for profile, n := range profileCounts {
+		metrics.ByProfile[profile] /= float64(n)
+	}
+
+	for _, day := range days {
+		day.OverallScore /= float64(day.Count)
+		metrics.ScoreTrend = append(metrics.ScoreTrend, *day)
+	}
+
+	slices.SortFunc(metrics.ScoreTrend, func(a, b DailyATSMetric) int { return a.Date.Compare(b.Date) })
+
+	return metrics, nil
+}
+
+// SkillMetadata is the metadata of a snapshot about the skills of the job:
+// the canonical names of those it asks for and of those the CV misses.
+type SkillMetadata struct {
+	JobSkills     []string `json:"job_skills"`
+	MissingSkills []string `json:"missing_skills"`
+}
+
+// MeasureSkills compares the skills of a customized CV with those of the job
+// through the skills taxonomy. It returns the keyword coverage and metadata
+// of a snapshot; the coverage is nil when the job names no known skill.
+func MeasureSkills(cvText, jobDescription string) (*float64, *json.RawMessage) {
+	coverage, covered, missing := skills.Default().Coverage(cvText, jobDescription)
+	if len(covered)+len(missing) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(SkillMetadata{JobSkills: append(covered, missing...), MissingSkills: missing})
+	if err != nil {
+		return &coverage, nil
+	}
+
+	metadata := json.RawMessage(data)
+
+	return &coverage, &metadata
+}
+
 // distributeBucket places a match score into a bucket.
 func distributeBucket(score float64) string {
 	if score >= 0.9 {
@@ -110,6 +269,7 @@ type AnalyticsDashboard struct {
 	MatchScoreTrend     []DailyMetric           `json:"match_score_trend"`
 	TopKeywords         []KeywordMetric         `json:"top_keywords"`
 	RecentActivities    []*db.AnalyticsSnapshot `json:"recent_activities"`
+	ATS                 *ATSMetrics             `json:"ats,omitempty"`
 }
 
 // DailyMetric represents a daily metric.
@@ -137,28 +297,81 @@ func (c *Collector) GetDashboard() (*AnalyticsDashboard, error) {
 		return nil, err
 	}
 
+	atsMetrics, err := c.atsMetrics(nil, 100)
+	if err != nil {
+		return nil, err
+	}
+
 	dashboard := &AnalyticsDashboard{
 		MatchScoreTrend:  make([]DailyMetric, 0),
 		TopKeywords:      make([]KeywordMetric, 0),
 		RecentActivities: snapshots,
+		ATS:              atsMetrics,
 	}
 
 	// Calculate aggregate statistics
 	totalScore := 0.0
 	scoreCount := 0
+	keywords := make(map[string]*KeywordMetric)
 
 	for _, snapshot := range snapshots {
 		if snapshot.MatchScore != nil {
 			totalScore += *snapshot.MatchScore
 			scoreCount++
 		}
+
+		var meta SkillMetadata
+		if snapshot.Metadata == nil || json.Unmarshal(*snapshot.Metadata, &meta) != nil {
+			continue
+		}
+
+		for _, skill := range meta.JobSkills {
+			metric, ok := keywords[skill]
+			if !ok {
+				metric = &KeywordMetric{Keyword: skill}
+				keywords[skill] = metric
+			}
+
+			metric.Count++
+
+			if snapshot.MatchScore != nil {
+				metric.AverageScore += *snapshot.MatchScore
+			}
+		}
 	}
 
 	if scoreCount > 0 {
 		dashboard.AverageMatchScore = totalScore / float64(scoreCount)
 	}
 
+	dashboard.TopKeywords = topKeywords(keywords)
+
 	dashboard.TotalCustomizations = len(snapshots)
 
 	return dashboard, nil
 }
+
+// topKeywords returns the skills jobs ask for most often, with the average
+// match score of their customizations.
+func topKeywords(keywords map[string]*KeywordMetric) []KeywordMetric {
+	top := make([]KeywordMetric, 0, len(keywords))
+
+	for _, metric := range keywords {
+		metric.AverageScore /= float64(metric.Count)
+		top = append(top, *metric)
+	}
+
+	slices.SortFunc(top, func(a, b KeywordMetric) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Keyword, b.Keyword)
+	})
+
+	if len(top) > topKeywordsLimit {
+		top = top[:topKeywordsLimit]
+	}
+
+	return top
+}
@@ -7,28 +7,69 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/input"
 	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/skills"
 )
 
 // Analyzer handles ATS compatibility analysis.
 type Analyzer struct {
 	provider llm.Provider
+	renderer Renderer
+	pdf      *input.PDFParser
 }
 
 // NewAnalyzer creates a new ATS analyzer.
 func NewAnalyzer(provider llm.Provider) *Analyzer {
-	return &Analyzer{provider: provider}
+	return &Analyzer{provider: provider, pdf: input.NewPDFParser(0)}
 }
 
-// AnalyzeCV performs a complete ATS analysis on a CV.
+// SetRenderer sets the renderer of the PDFs whose extracted text is checked.
+// Without one, rendered PDFs are not checked.
+func (a *Analyzer) SetRenderer(renderer Renderer) {
+	a.renderer = renderer
+}
+
+// Options select how a CV is analyzed.
+type Options struct {
+	Profile  *Profile // ATS to simulate; nil is the generic profile
+	FileType string   // Type of the submitted file, such as "pdf"; empty when unknown
+	CheckPDF bool     // Render the CV and check the text extracted from the PDF
+	Language string   // Language the CV is rendered in; detected when empty
+	Keywords []string // Keywords of the job; extracted from it when nil
+}
+
+// AnalyzeCV performs a complete ATS analysis on a CV with the generic
+// profile.
 func (a *Analyzer) AnalyzeCV(ctx context.Context, cvContent, jobDescription string) (*ATSAnalysisResult, error) {
-	// Extract keywords from job description
-	keywords, err := a.ExtractKeywords(ctx, jobDescription)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract keywords: %w", err)
+	return a.AnalyzeCVWith(ctx, cvContent, jobDescription, Options{})
+}
+
+// AnalyzeCVWith performs a complete ATS analysis on a CV as the ATS of a
+// profile would, adding the issues of its parser to the generic ones and
+// weighing the overall score its way. With CheckPDF, what is lost or
+// scrambled in the rendered PDF is reported as formatting issues too.
+func (a *Analyzer) AnalyzeCVWith(ctx context.Context, cvContent, jobDescription string, opts Options) (*ATSAnalysisResult, error) {
+	profile := opts.Profile
+	if profile == nil {
+		profile, _ = LookupProfile(GenericProfile)
+	}
+
+	// Extract keywords from job description, unless they were extracted
+	// already, as when one job is analyzed with several CVs
+	keywords := opts.Keywords
+	if keywords == nil {
+		extracted, err := a.ExtractKeywords(ctx, jobDescription)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract keywords: %w", err)
+		}
+
+		keywords = extracted
 	}
 
 	// Calculate keyword match
@@ -43,15 +84,22 @@ func (a *Analyzer) AnalyzeCV(ctx context.Context, cvContent, jobDescription stri
 	}
 
 	// Check formatting
-	formattingIssues := a.CheckFormatting(cvContent)
+	formattingIssues := append(a.CheckFormatting(cvContent), a.CheckProfile(cvContent, opts.FileType, profile)...)
+
+	var pdfCheck *PDFCheck
+	if opts.CheckPDF {
+		pdfCheck = a.CheckRendered(ctx, cvContent, opts.Language)
+		formattingIssues = append(formattingIssues, pdfCheck.Issues()...)
+	}
 
 	// Analyze section completeness
 	sectionScores := a.AnalyzeSectionCompleteness(cvContent)
 
 	// Calculate overall score
-	overallScore := a.calculateOverallScore(matchScore, formattingIssues, sectionScores)
+	overallScore := a.calculateOverallScore(profile.Weights, matchScore, formattingIssues, sectionScores)
 
 	result := &ATSAnalysisResult{
+		Profile:      profile.Name,
 		OverallScore: overallScore,
 		KeywordMatches: KeywordMatches{
 			Matched: getMatchedKeywordsList(matchedKeywords),
@@ -59,6 +107,8 @@ func (a *Analyzer) AnalyzeCV(ctx context.Context, cvContent, jobDescription stri
 		},
 		FormattingIssues:    formattingIssues,
 		SectionCompleteness: sectionScores,
+		Experience:          a.MatchExperience(cvContent, jobDescription),
+		PDFCheck:            pdfCheck,
 	}
 
 	// Generate recommendations
@@ -99,16 +149,31 @@ Keywords:`, jobDescription)
 }
 
 // CalculateKeywordMatch calculates how many keywords are present in the CV.
+// Keywords the skills taxonomy knows match any spelling of the skill or a
+// narrower one, so "kubernetes" matches "K8s" and "sql" matches "Postgres",
+// while "go" does not match the verb. Other keywords match as whole words in
+// any script, so "gestión" matches.
 func (a *Analyzer) CalculateKeywordMatch(cvContent string, keywords []string) (float64, map[string]bool) {
 	cvLower := strings.ToLower(cvContent)
 	matched := make(map[string]bool)
 	matchCount := 0
 
+	taxonomy := skills.Default()
+	cvSkills := taxonomy.IDs(cvContent)
+
 	for _, keyword := range keywords {
-		kwLower := strings.ToLower(keyword)
-		// Use word boundary regex for more accurate matching
-		pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(kwLower) + `\b`)
-		if pattern.MatchString(cvLower) {
+		found := false
+
+		if skill, ok := taxonomy.Resolve(keyword); ok {
+			found = taxonomy.Covers(cvSkills, skill.ID)
+		} else {
+			kwLower := strings.ToLower(keyword)
+			// Use word boundary regex for more accurate matching
+			pattern := regexp.MustCompile(`(?:^|[^\p{L}\p{N}])` + regexp.QuoteMeta(kwLower) + `(?:$|[^\p{L}\p{N}])`)
+			found = pattern.MatchString(cvLower)
+		}
+
+		if found {
 			matched[keyword] = true
 			matchCount++
 		}
@@ -121,7 +186,8 @@ func (a *Analyzer) CalculateKeywordMatch(cvContent string, keywords []string) (f
 	return float64(matchCount) / float64(len(keywords)), matched
 }
 
-// CheckFormatting detects common formatting issues.
+// CheckFormatting detects common formatting issues. Section headings are
+// recognized in the language the CV is written in.
 func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 	issues := []FormattingIssue{}
 
@@ -143,7 +209,10 @@ func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 
 	// Check for essential sections
 	cvLower := strings.ToLower(cvContent)
-	if !strings.Contains(cvLower, "experience") && !strings.Contains(cvLower, "work history") {
+	lang := i18n.Detect(cvContent)
+
+	if !strings.Contains(cvLower, "experience") && !strings.Contains(cvLower, "work history") &&
+		!mentionsSection(cvContent, lang, i18n.SectionExperience) {
 		issues = append(issues, FormattingIssue{
 			Type:     "missing_section",
 			Severity: "high",
@@ -151,7 +220,7 @@ func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 		})
 	}
 
-	if !strings.Contains(cvLower, "education") {
+	if !strings.Contains(cvLower, "education") && !mentionsSection(cvContent, lang, i18n.SectionEducation) {
 		issues = append(issues, FormattingIssue{
 			Type:     "missing_section",
 			Severity: "medium",
@@ -159,7 +228,7 @@ func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 		})
 	}
 
-	if !strings.Contains(cvLower, "skill") {
+	if !strings.Contains(cvLower, "skill") && !mentionsSection(cvContent, lang, i18n.SectionSkills) {
 		issues = append(issues, FormattingIssue{
 			Type:     "missing_section",
 			Severity: "medium",
@@ -168,7 +237,7 @@ func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 	}
 
 	// Check for contact information
-	emailPattern := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	emailPattern := regexp.MustCompile(`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}.-]+\.\p{L}{2,}`)
 	if !emailPattern.MatchString(cvContent) {
 		issues = append(issues, FormattingIssue{
 			Type:     "missing_contact",
@@ -180,9 +249,25 @@ func (a *Analyzer) CheckFormatting(cvContent string) []FormattingIssue {
 	return issues
 }
 
-// AnalyzeSectionCompleteness analyzes the quality of each CV section.
+// MatchExperience compares the tenure of the CV's dated experience entries
+// with the years of experience the job description asks for.
+func (a *Analyzer) MatchExperience(cvContent, jobDescription string) ExperienceMatch {
+	cv := input.NewEnhancedParser().ParseCV(cvContent)
+
+	match := ExperienceMatch{
+		Years:         math.Round(float64(cv.ExperienceMonths)/12*10) / 10,
+		RequiredYears: input.ParseRequiredYears(jobDescription),
+	}
+	match.Meets = cv.ExperienceMonths >= match.RequiredYears*12
+
+	return match
+}
+
+// AnalyzeSectionCompleteness analyzes the quality of each CV section, in the
+// language the CV is written in.
 func (a *Analyzer) AnalyzeSectionCompleteness(cvContent string) SectionCompleteness {
 	cvLower := strings.ToLower(cvContent)
+	lang := i18n.Detect(cvContent)
 
 	// Simple heuristic-based scoring
 	scores := SectionCompleteness{
@@ -193,7 +278,8 @@ func (a *Analyzer) AnalyzeSectionCompleteness(cvContent string) SectionCompleten
 	}
 
 	// Experience scoring
-	if strings.Contains(cvLower, "experience") || strings.Contains(cvLower, "work history") {
+	if strings.Contains(cvLower, "experience") || strings.Contains(cvLower, "work history") ||
+		mentionsSection(cvContent, lang, i18n.SectionExperience) {
 		// Count bullet points or job entries (simple heuristic)
 		bulletCount := strings.Count(cvContent, "•") + strings.Count(cvContent, "-") + strings.Count(cvContent, "*")
 		if bulletCount > 10 {
@@ -208,8 +294,8 @@ func (a *Analyzer) AnalyzeSectionCompleteness(cvContent string) SectionCompleten
 	}
 
 	// Education scoring
-	if strings.Contains(cvLower, "education") {
-		if strings.Contains(cvLower, "university") || strings.Contains(cvLower, "college") || strings.Contains(cvLower, "degree") {
+	if strings.Contains(cvLower, "education") || mentionsSection(cvContent, lang, i18n.SectionEducation) {
+		if containsAny(cvLower, institutionTerms) {
 			scores.Education = 1.0
 		} else {
 			scores.Education = 0.7
@@ -219,9 +305,9 @@ func (a *Analyzer) AnalyzeSectionCompleteness(cvContent string) SectionCompleten
 	}
 
 	// Skills scoring
-	if strings.Contains(cvLower, "skill") {
+	if strings.Contains(cvLower, "skill") || mentionsSection(cvContent, lang, i18n.SectionSkills) {
 		// Count number of skills mentioned (simple heuristic)
-		skillSection := extractSection(cvContent, "skill")
+		skillSection := extractSection(cvContent, "skill", i18n.SectionSkills)
 		commaCount := strings.Count(skillSection, ",")
 		if commaCount > 10 {
 			scores.Skills = 1.0
@@ -235,8 +321,9 @@ func (a *Analyzer) AnalyzeSectionCompleteness(cvContent string) SectionCompleten
 	}
 
 	// Summary scoring
-	if strings.Contains(cvLower, "summary") || strings.Contains(cvLower, "about") || strings.Contains(cvLower, "profile") {
-		summarySection := extractSection(cvContent, "summary")
+	if strings.Contains(cvLower, "summary") || strings.Contains(cvLower, "about") || strings.Contains(cvLower, "profile") ||
+		mentionsSection(cvContent, lang, i18n.SectionSummary) {
+		summarySection := extractSection(cvContent, "summary", i18n.SectionSummary)
 		wordCount := len(strings.Fields(summarySection))
 		if wordCount > 50 && wordCount < 200 {
 			scores.Summary = 1.0
@@ -277,6 +364,16 @@ func (a *Analyzer) GenerateRecommendations(result *ATSAnalysisResult) []Recommen
 		}
 	}
 
+	// Experience recommendations; an undated CV gets none, as its tenure is unknown
+	if exp := result.Experience; !exp.Meets && exp.Years > 0 {
+		recommendations = append(recommendations, Recommendation{
+			Category: "experience",
+			Priority: "high",
+			Suggestion: fmt.Sprintf("The job asks for %d+ years of experience and your dated roles cover %.1f. "+
+				"Make sure every relevant role, including earlier ones, lists its start and end dates.", exp.RequiredYears, exp.Years),
+		})
+	}
+
 	// Section completeness recommendations
 	if result.SectionCompleteness.Experience < 0.7 {
 		recommendations = append(recommendations, Recommendation{
@@ -314,12 +411,13 @@ func (a *Analyzer) GenerateRecommendations(result *ATSAnalysisResult) []Recommen
 	return recommendations
 }
 
-// calculateOverallScore computes the final ATS compatibility score.
-func (a *Analyzer) calculateOverallScore(keywordMatch float64, issues []FormattingIssue, sections SectionCompleteness) float64 {
+// calculateOverallScore computes the final ATS compatibility score with the
+// weights of a profile.
+func (a *Analyzer) calculateOverallScore(weights Weights, keywordMatch float64, issues []FormattingIssue, sections SectionCompleteness) float64 {
 	// Weighted scoring
-	keywordWeight := 0.4
-	formattingWeight := 0.3
-	sectionWeight := 0.3
+	keywordWeight := weights.Keywords
+	formattingWeight := weights.Formatting
+	sectionWeight := weights.Sections
 
 	// Keyword score
 	keywordScore := keywordMatch
@@ -370,14 +468,44 @@ func getMatchedKeywordsList(matched map[string]bool) []string {
 	return list
 }
 
-func extractSection(content, sectionName string) string {
+// institutionTerms are words naming a school or a degree, in the supported
+// languages.
+var institutionTerms = []string{
+	"university", "college", "degree",
+	"universität", "hochschule", "studium",
+	"université", "école", "diplôme",
+	"universidad", "título", "licenciatura",
+	"universidade", "faculdade", "graduação",
+	"universiteit", "hogeschool",
+}
+
+// mentionsSection reports whether a CV in a language other than English
+// mentions a section by one of its localized keywords.
+func mentionsSection(cvContent, lang string, section i18n.Section) bool {
+	return lang != i18n.English && i18n.Mentions(cvContent, lang, section)
+}
+
+func containsAny(s string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(s, term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractSection returns the lines after the first line naming a section,
+// either by containing sectionName or as a heading in any of the supported
+// languages.
+func extractSection(content, sectionName string, section i18n.Section) string {
 	lines := strings.Split(content, "\n")
 	inSection := false
 
 	var sb strings.Builder
 	for _, line := range lines {
 		lineLower := strings.ToLower(line)
-		if strings.Contains(lineLower, strings.ToLower(sectionName)) {
+		if strings.Contains(lineLower, strings.ToLower(sectionName)) || i18n.HeadingLine(line) == section {
 			inSection = true
 
 			continue
@@ -0,0 +1,61 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+)
+
+// SelectRecommendations returns the recommendations at some indexes and of
+// some categories; with neither, it returns all of them. An index out of
+// range is an error.
+func SelectRecommendations(recs []Recommendation, indexes []int, categories []string) ([]Recommendation, error) {
+	if len(indexes) == 0 && len(categories) == 0 {
+		return recs, nil
+	}
+
+	selected := []Recommendation{}
+
+	for i, rec := range recs {
+		if slices.Contains(indexes, i) || slices.ContainsFunc(categories, func(c string) bool {
+			return strings.EqualFold(c, rec.Category)
+		}) {
+			selected = append(selected, rec)
+		}
+	}
+
+	for _, i := range indexes {
+		if i < 0 || i >= len(recs) {
+			return nil, fmt.Errorf("recommendation %d does not exist; the analysis has %d", i, len(recs))
+		}
+	}
+
+	return selected, nil
+}
+
+// ApplyRecommendations rewrites a CV through the LLM so that it follows
+// recommendations of an analysis. The CV keeps its language, and keywords
+// are only added where the CV's experience supports them.
+func (a *Analyzer) ApplyRecommendations(ctx context.Context, cvContent, jobDescription string, recs []Recommendation) (*llm.CustomizationResponse, error) {
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("no recommendations to apply")
+	}
+
+	instructions := []string{
+		"Apply the following ATS recommendations to the CV. Keep everything else as it is, " +
+			"and only add a keyword where the CV's experience supports it; never invent experience.",
+	}
+
+	for _, rec := range recs {
+		instructions = append(instructions, fmt.Sprintf("- [%s] %s", rec.Category, rec.Suggestion))
+	}
+
+	return a.provider.Customize(llm.WithLanguage(ctx, i18n.Detect(cvContent)), cvContent, jobDescription, instructions)
+}
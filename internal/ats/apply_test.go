@@ -0,0 +1,55 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectRecommendations(t *testing.T) {
+	recs := []Recommendation{
+		{Category: "keywords", Suggestion: "Add Kubernetes"},
+		{Category: "formatting", Suggestion: "Remove the tables"},
+		{Category: "keywords", Suggestion: "Add Terraform"},
+		{Category: "sections", Suggestion: "Add a summary"},
+	}
+
+	tests := []struct {
+		name       string
+		indexes    []int
+		categories []string
+		want       []string
+	}{
+		{"all", nil, nil, []string{"Add Kubernetes", "Remove the tables", "Add Terraform", "Add a summary"}},
+		{"by index", []int{3, 1}, nil, []string{"Remove the tables", "Add a summary"}},
+		{"by category", nil, []string{"Keywords"}, []string{"Add Kubernetes", "Add Terraform"}},
+		{"by index and category", []int{0}, []string{"sections"}, []string{"Add Kubernetes", "Add a summary"}},
+		{"unknown category", nil, []string{"design"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := SelectRecommendations(recs, tt.indexes, tt.categories)
+			if err != nil {
+				t.Fatalf("SelectRecommendations() error = %v", err)
+			}
+
+			got := []string{}
+			for _, rec := range selected {
+				got = append(got, rec.Suggestion)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SelectRecommendations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, indexes := range [][]int{{4}, {-1}, {0, 9}} {
+		if _, err := SelectRecommendations(recs, indexes, nil); err == nil {
+			t.Errorf("Expected an error for indexes %v", indexes)
+		}
+	}
+}
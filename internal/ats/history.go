@@ -0,0 +1,79 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"math"
+	"regexp"
+	"slices"
+)
+
+// Change is how an ATS analysis differs from an earlier one.
+type Change struct {
+	PreviousID     int               `json:"previous_id"` // ID of the analysis compared with
+	ScoreDelta     float64           `json:"score_delta"`
+	KeywordsGained []string          `json:"keywords_gained"`
+	KeywordsLost   []string          `json:"keywords_lost"`
+	IssuesFixed    []FormattingIssue `json:"issues_fixed"`
+	IssuesNew      []FormattingIssue `json:"issues_new"`
+}
+
+// counts matches the numbers of issue messages, such as "2 line(s)", so
+// that an issue whose count changed is still the same issue.
+var counts = regexp.MustCompile(`\d+`)
+
+// Compare returns how an analysis differs from an earlier one: the change
+// of its overall score, the keywords it matches that the earlier one did
+// not and the other way around, and the formatting issues fixed and new.
+func Compare(previous, next *ATSAnalysisResult) Change {
+	change := Change{
+		ScoreDelta:     math.Round((next.OverallScore-previous.OverallScore)*1000) / 1000,
+		KeywordsGained: difference(next.KeywordMatches.Matched, previous.KeywordMatches.Matched),
+		KeywordsLost:   difference(previous.KeywordMatches.Matched, next.KeywordMatches.Matched),
+		IssuesFixed:    []FormattingIssue{},
+		IssuesNew:      []FormattingIssue{},
+	}
+
+	key := func(issue FormattingIssue) string {
+		return issue.Type + "\x00" + counts.ReplaceAllString(issue.Message, "#")
+	}
+
+	before := make(map[string]bool)
+	for _, issue := range previous.FormattingIssues {
+		before[key(issue)] = true
+	}
+
+	after := make(map[string]bool)
+
+	for _, issue := range next.FormattingIssues {
+		after[key(issue)] = true
+
+		if !before[key(issue)] {
+			change.IssuesNew = append(change.IssuesNew, issue)
+		}
+	}
+
+	for _, issue := range previous.FormattingIssues {
+		if !after[key(issue)] {
+			change.IssuesFixed = append(change.IssuesFixed, issue)
+		}
+	}
+
+	return change
+}
+
+// difference returns the items of a that are not in b, sorted.
+func difference(a, b []string) []string {
+	out := []string{}
+
+	for _, item := range a {
+		if !slices.Contains(b, item) && !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+
+	slices.Sort(out)
+
+	return out
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	previous := &ATSAnalysisResult{
+		OverallScore:   0.61,
+		KeywordMatches: KeywordMatches{Matched: []string{"go", "sql", "docker"}},
+		FormattingIssues: []FormattingIssue{
+			{Type: IssueLostContent, Severity: "high", Message: "3 line(s) of the CV are missing words in the text extracted from the PDF."},
+			{Type: IssueTables, Severity: "medium", Message: "Content in tables may be skipped."},
+		},
+	}
+
+	next := &ATSAnalysisResult{
+		OverallScore:   0.7345,
+		KeywordMatches: KeywordMatches{Matched: []string{"kubernetes", "go", "aws", "docker"}},
+		FormattingIssues: []FormattingIssue{
+			{Type: IssueLostContent, Severity: "high", Message: "1 line(s) of the CV are missing words in the text extracted from the PDF."},
+			{Type: IssueColumns, Severity: "high", Message: "Text laid out in side-by-side columns may be mixed up."},
+		},
+	}
+
+	change := Compare(previous, next)
+
+	if change.ScoreDelta != 0.125 {
+		t.Errorf("ScoreDelta = %v, want 0.125", change.ScoreDelta)
+	}
+
+	if want := []string{"aws", "kubernetes"}; !slices.Equal(change.KeywordsGained, want) {
+		t.Errorf("KeywordsGained = %v, want %v", change.KeywordsGained, want)
+	}
+
+	if want := []string{"sql"}; !slices.Equal(change.KeywordsLost, want) {
+		t.Errorf("KeywordsLost = %v, want %v", change.KeywordsLost, want)
+	}
+
+	// The lost content issue only changed its count, so it is neither fixed nor new
+	if len(change.IssuesFixed) != 1 || change.IssuesFixed[0].Type != IssueTables {
+		t.Errorf("IssuesFixed = %+v, want the tables issue", change.IssuesFixed)
+	}
+
+	if len(change.IssuesNew) != 1 || change.IssuesNew[0].Type != IssueColumns {
+		t.Errorf("IssuesNew = %+v, want the columns issue", change.IssuesNew)
+	}
+}
+
+func TestCompare_Unchanged(t *testing.T) {
+	result := &ATSAnalysisResult{
+		OverallScore:     0.5,
+		KeywordMatches:   KeywordMatches{Matched: []string{"go"}},
+		FormattingIssues: []FormattingIssue{{Type: IssueTables, Message: "Content in tables may be skipped."}},
+	}
+
+	change := Compare(result, result)
+
+	// Empty lists rather than nil, so they encode as []
+	if change.ScoreDelta != 0 || change.KeywordsGained == nil || len(change.KeywordsGained) != 0 || len(change.KeywordsLost) != 0 ||
+		change.IssuesFixed == nil || len(change.IssuesFixed) != 0 || len(change.IssuesNew) != 0 {
+		t.Errorf("Compare() of an analysis with itself = %+v", change)
+	}
+}
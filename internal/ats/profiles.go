@@ -0,0 +1,309 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/input"
+)
+
+// Vendor-specific formatting issue types.
+const (
+	IssueColumns          = "columns"
+	IssueTables           = "tables"
+	IssueHeaderFooter     = "header_footer"
+	IssueDateFormat       = "date_format"
+	IssueSpecialChars     = "special_characters"
+	IssueNonstandardHeads = "nonstandard_headings"
+	IssueFileType         = "file_type"
+)
+
+// GenericProfile is the name of the profile used when none is requested. It
+// applies the generic rules of CheckFormatting only.
+const GenericProfile = "generic"
+
+// Weights are the shares of the overall ATS score.
+type Weights struct {
+	Keywords   float64 `json:"keywords"`
+	Formatting float64 `json:"formatting"`
+	Sections   float64 `json:"sections"`
+}
+
+// Profile simulates how one ATS parses and ranks CVs: which layouts its
+// parser loses content in, which file types it takes, and how much keywords,
+// formatting and sections weigh in its ranking.
+type Profile struct {
+	Name        string  `json:"name"`
+	Vendor      string  `json:"vendor"`
+	Description string  `json:"description"`
+	Weights     Weights `json:"weights"`
+	// FileTypes are the accepted file types, preferred first.
+	FileTypes []string `json:"file_types,omitempty"`
+	// Severities of the vendor-specific issue types this ATS is affected by;
+	// other issue types are not reported.
+	Severities map[string]string `json:"severities,omitempty"`
+}
+
+// defaultWeights are the weights of the generic profile.
+var defaultWeights = Weights{Keywords: 0.4, Formatting: 0.3, Sections: 0.3}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]*Profile{}
+)
+
+func init() {
+	for _, p := range []*Profile{
+		{
+			Name:        GenericProfile,
+			Vendor:      "Generic",
+			Description: "Common rules shared by most applicant tracking systems.",
+			Weights:     defaultWeights,
+		},
+		{
+			Name:        "workday",
+			Vendor:      "Workday",
+			Description: "Parses into structured fields; columns, headers and footers and year-only dates are often lost or misread.",
+			Weights:     Weights{Keywords: 0.35, Formatting: 0.35, Sections: 0.3},
+			FileTypes:   []string{"docx", "pdf", "doc"},
+			Severities: map[string]string{
+				IssueColumns: "high", IssueTables: "medium", IssueHeaderFooter: "high",
+				IssueDateFormat: "high", IssueSpecialChars: "low", IssueNonstandardHeads: "medium",
+			},
+		},
+		{
+			Name:        "taleo",
+			Vendor:      "Oracle Taleo",
+			Description: "An older parser that reads plain, single-column documents best and ranks heavily by keywords.",
+			Weights:     Weights{Keywords: 0.5, Formatting: 0.3, Sections: 0.2},
+			FileTypes:   []string{"docx", "doc", "pdf", "txt"},
+			Severities: map[string]string{
+				IssueColumns: "high", IssueTables: "high", IssueHeaderFooter: "high",
+				IssueDateFormat: "medium", IssueSpecialChars: "medium", IssueNonstandardHeads: "high",
+			},
+		},
+		{
+			Name:        "icims",
+			Vendor:      "iCIMS",
+			Description: "Handles PDFs, but tables, graphics and symbols often come out scrambled.",
+			Weights:     Weights{Keywords: 0.45, Formatting: 0.3, Sections: 0.25},
+			FileTypes:   []string{"docx", "pdf", "doc", "txt"},
+			Severities: map[string]string{
+				IssueColumns: "medium", IssueTables: "high", IssueHeaderFooter: "medium",
+				IssueDateFormat: "medium", IssueSpecialChars: "medium", IssueNonstandardHeads: "low",
+			},
+		},
+		{
+			Name:        "greenhouse",
+			Vendor:      "Greenhouse",
+			Description: "Keeps the original document for human reviewers, so layout matters less than content.",
+			Weights:     Weights{Keywords: 0.35, Formatting: 0.2, Sections: 0.45},
+			FileTypes:   []string{"pdf", "docx", "doc", "txt"},
+			Severities: map[string]string{
+				IssueColumns: "low", IssueTables: "low", IssueSpecialChars: "low",
+			},
+		},
+		{
+			Name:        "lever",
+			Vendor:      "Lever",
+			Description: "Parses PDFs well, but misreads multi-column layouts and reads dates to the month.",
+			Weights:     Weights{Keywords: 0.4, Formatting: 0.25, Sections: 0.35},
+			FileTypes:   []string{"pdf", "docx", "doc"},
+			Severities: map[string]string{
+				IssueColumns: "medium", IssueTables: "low", IssueDateFormat: "low", IssueSpecialChars: "low",
+			},
+		},
+	} {
+		RegisterProfile(p)
+	}
+}
+
+// RegisterProfile adds an ATS profile, or replaces the one with its name.
+// The name is lowercased, as LookupProfile finds profiles in any case.
+// Profiles without weights use those of the generic profile.
+func RegisterProfile(p *Profile) {
+	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+
+	if p.Weights == (Weights{}) {
+		p.Weights = defaultWeights
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	profiles[p.Name] = p
+}
+
+// LookupProfile returns the profile with a name, in any case; an empty name
+// is the generic profile.
+func LookupProfile(name string) (*Profile, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = GenericProfile
+	}
+
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	p, ok := profiles[name]
+
+	return p, ok
+}
+
+// Profiles returns every registered profile, ordered by name with the
+// generic profile first.
+func Profiles() []*Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	list := make([]*Profile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+
+	slices.SortFunc(list, func(a, b *Profile) int {
+		switch {
+		case a.Name == GenericProfile:
+			return -1
+		case b.Name == GenericProfile:
+			return 1
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return list
+}
+
+var (
+	// columnGap matches a wide run of spaces or a tab between two words, as
+	// left by side-by-side columns in extracted text.
+	columnGap = regexp.MustCompile(`\S(?: {4,}|\t+ *)\S`)
+	// tableRow matches a row of cells between pipes, as tables are extracted;
+	// a contact line such as "Jane Doe | jane@example.com" is not one.
+	tableRow = regexp.MustCompile(`^\s*\|[^|]+\|[^|]+\|`)
+	// pageMarker matches page numbers left by headers and footers.
+	pageMarker = regexp.MustCompile(`(?i)^\s*(?:page|seite|página|pagina)\s+\d+(?:\s*(?:of|/|von|de|sur|van)\s*\d+)?\s*$|^\s*\d+\s*/\s*\d+\s*$`)
+	// contactLine matches a line with an email address or a phone number,
+	// which running headers repeat on every page.
+	contactLine = regexp.MustCompile(`@|\+?\d[\d ()./-]{7,}\d`)
+)
+
+// CheckProfile detects the formatting issues of a CV that the ATS of a
+// profile is affected by, with the severity it has for that ATS. FileType is
+// the type of the file submitted, such as "pdf"; empty skips the check.
+func (a *Analyzer) CheckProfile(cvContent, fileType string, profile *Profile) []FormattingIssue {
+	issues := []FormattingIssue{}
+
+	report := func(issueType, message string) {
+		if severity := profile.Severities[issueType]; severity != "" {
+			issues = append(issues, FormattingIssue{
+				Type:     issueType,
+				Severity: severity,
+				Message:  fmt.Sprintf("%s: %s", profile.Vendor, message),
+			})
+		}
+	}
+
+	lines := strings.Split(cvContent, "\n")
+
+	var columns, tables, pages, yearOnly, symbols int
+
+	repeated := make(map[string]int)
+	recognized := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		switch {
+		case tableRow.MatchString(line):
+			tables++
+		case columnGap.MatchString(trimmed):
+			columns++
+		}
+
+		if pageMarker.MatchString(trimmed) {
+			pages++
+		}
+
+		if contactLine.MatchString(trimmed) {
+			repeated[trimmed]++
+		}
+
+		// Ranges only; a single year, as of a degree, is precise enough
+		if r, ok := input.ParseDateRange(trimmed); ok && r.YearOnly && (r.Current || r.End.Year() != r.Start.Year()) {
+			yearOnly++
+		}
+
+		for _, c := range trimmed {
+			if unicode.Is(unicode.So, c) || unicode.Is(unicode.Co, c) {
+				symbols++
+			}
+		}
+
+		if i18n.HeadingLine(trimmed) != i18n.SectionUnknown {
+			recognized = true
+		}
+	}
+
+	if columns >= 3 {
+		report(IssueColumns, "Text laid out in side-by-side columns may be read line by line across columns, mixing up their content.")
+	}
+
+	if tables >= 2 {
+		report(IssueTables, "Content in tables may be skipped or merged into one field. Use plain lines instead.")
+	}
+
+	headers := pages
+	for _, n := range repeated {
+		if n >= 2 {
+			headers++
+		}
+	}
+
+	if headers > 0 {
+		report(IssueHeaderFooter, "Page numbers or repeated contact lines suggest headers and footers, which are often ignored. Keep contact details in the body.")
+	}
+
+	if yearOnly > 0 {
+		report(IssueDateFormat, fmt.Sprintf("%d date range(s) give only years. Use month and year, as in \"Jan 2020 - Present\", so tenure is computed correctly.", yearOnly))
+	}
+
+	if symbols > 0 {
+		report(IssueSpecialChars, "Icons and symbols such as ★ or ✉ may be dropped or turned into unreadable characters. Use plain text labels.")
+	}
+
+	if !recognized {
+		report(IssueNonstandardHeads, "No standard section heading such as \"Experience\", \"Education\" or \"Skills\" was found, so content may not be sorted into the right fields.")
+	}
+
+	if fileType = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(fileType), ".")); fileType != "" && len(profile.FileTypes) > 0 {
+		switch {
+		case !slices.Contains(profile.FileTypes, fileType):
+			issues = append(issues, FormattingIssue{
+				Type:     IssueFileType,
+				Severity: "high",
+				Message:  fmt.Sprintf("%s: .%s files are not accepted. Submit one of: %s.", profile.Vendor, fileType, strings.Join(profile.FileTypes, ", ")),
+			})
+		case profile.FileTypes[0] != fileType:
+			issues = append(issues, FormattingIssue{
+				Type:     IssueFileType,
+				Severity: "low",
+				Message:  fmt.Sprintf("%s: .%s files are parsed most reliably; .%s may lose some formatting.", profile.Vendor, profile.FileTypes[0], fileType),
+			})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"testing"
+)
+
+const profileCV = `Jane Doe
+jane@example.com
+
+Experience
+Senior Engineer, Acme Corp
+Jan 2020 - Present
+- Built Go services
+
+Education
+BS in Computer Science, 2016`
+
+func TestCheckProfile(t *testing.T) {
+	workday, _ := LookupProfile("workday")
+	greenhouse, _ := LookupProfile("greenhouse")
+
+	tests := []struct {
+		name     string
+		cv       string
+		fileType string
+		profile  *Profile
+		want     map[string]string // Issue type to severity
+	}{
+		{
+			name:    "clean",
+			cv:      profileCV,
+			profile: workday,
+			want:    map[string]string{},
+		},
+		{
+			name:    "columns",
+			cv:      profileCV + "\nGo        Kubernetes\nDocker        Terraform\nSQL\t\tKafka",
+			profile: workday,
+			want:    map[string]string{IssueColumns: "high"},
+		},
+		{
+			name:    "tables",
+			cv:      profileCV + "\n| Go | 5 years |\n| SQL | 3 years |",
+			profile: workday,
+			want:    map[string]string{IssueTables: "medium"},
+		},
+		{
+			name:    "contact line with pipes is not a table",
+			cv:      "Jane Doe | jane@example.com\n" + profileCV,
+			profile: workday,
+			want:    map[string]string{},
+		},
+		{
+			name:    "page numbers",
+			cv:      profileCV + "\nPage 1 of 2",
+			profile: workday,
+			want:    map[string]string{IssueHeaderFooter: "high"},
+		},
+		{
+			name:    "repeated contact line",
+			cv:      profileCV + "\njane@example.com",
+			profile: workday,
+			want:    map[string]string{IssueHeaderFooter: "high"},
+		},
+		{
+			name:    "year-only dates",
+			cv:      profileCV + "\nEngineer, Beta Ltd\n2017 - 2019",
+			profile: workday,
+			want:    map[string]string{IssueDateFormat: "high"},
+		},
+		{
+			name:    "issue the vendor is not affected by",
+			cv:      profileCV + "\nPage 1 of 2\nEngineer, Beta Ltd\n2017 - 2019",
+			profile: greenhouse,
+			want:    map[string]string{},
+		},
+		{
+			name:     "preferred file type",
+			cv:       profileCV,
+			fileType: ".DOCX",
+			profile:  workday,
+			want:     map[string]string{},
+		},
+		{
+			name:     "accepted file type",
+			cv:       profileCV,
+			fileType: "pdf",
+			profile:  workday,
+			want:     map[string]string{IssueFileType: "low"},
+		},
+		{
+			name:     "rejected file type",
+			cv:       profileCV,
+			fileType: "txt",
+			profile:  workday,
+			want:     map[string]string{IssueFileType: "high"},
+		},
+	}
+
+	analyzer := NewAnalyzer(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, issue := range analyzer.CheckProfile(tt.cv, tt.fileType, tt.profile) {
+				got[issue.Type] = issue.Severity
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckProfile() issues = %v, want %v", got, tt.want)
+			}
+
+			for issueType, severity := range tt.want {
+				if got[issueType] != severity {
+					t.Errorf("CheckProfile() issues = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterProfile(t *testing.T) {
+	t.Cleanup(func() {
+		profilesMu.Lock()
+		defer profilesMu.Unlock()
+
+		delete(profiles, "acme")
+	})
+
+	RegisterProfile(&Profile{Name: " Acme ", Vendor: "Acme"})
+
+	for _, name := range []string{"acme", "ACME", " Acme "} {
+		p, ok := LookupProfile(name)
+		if !ok {
+			t.Fatalf("LookupProfile(%q) found no profile", name)
+		}
+
+		if p.Name != "acme" || p.Weights != defaultWeights {
+			t.Errorf("LookupProfile(%q) = %+v", name, p)
+		}
+	}
+
+	if p, ok := LookupProfile(""); !ok || p.Name != GenericProfile {
+		t.Errorf("LookupProfile(\"\") = %+v, %v, want the generic profile", p, ok)
+	}
+
+	if _, ok := LookupProfile("unknown"); ok {
+		t.Error("Expected no profile for an unknown name")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+)
+
+// Issue types of the rendered PDF check.
+const (
+	IssueLostContent      = "lost_content"
+	IssueScrambledContent = "scrambled_content"
+	IssueLigatures        = "ligatures"
+	IssueHyphenation      = "hyphenation"
+	IssueLostSymbols      = "lost_symbols"
+)
+
+// maxExamples is how many lines an issue quotes.
+const maxExamples = 3
+
+// Renderer renders a CV to a PDF, as it is sent to an ATS.
+// *latex.LaTeXGenerator is one.
+type Renderer interface {
+	RenderPDF(cvContent, language string) ([]byte, error)
+}
+
+// PDFCheck compares the text an ATS extracts from the rendered PDF of a CV
+// with the text the CV was rendered from.
+type PDFCheck struct {
+	Coverage  float64  `json:"coverage"`            // Share of the CV's words found in the PDF, 0 to 1
+	Lost      []string `json:"lost,omitempty"`      // Lines missing words in the PDF
+	Scrambled []string `json:"scrambled,omitempty"` // Lines whose words come out of order
+	Ligatures []string `json:"ligatures,omitempty"` // Words whose ligatures did not survive
+	Hyphens   int      `json:"hyphenated_words"`    // Words split across lines
+	Symbols   int      `json:"lost_symbols"`        // Icons and symbols missing in the PDF
+	Error     string   `json:"error,omitempty"`     // Why the PDF could not be checked
+}
+
+var (
+	// hyphenBreak matches a word hyphenated at the end of a line.
+	hyphenBreak = regexp.MustCompile(`(\p{L})-[ \t]*\n[ \t]*(\p{Ll})`)
+	// ligatureLetters are letter pairs fonts often draw as one glyph.
+	ligatureLetters = []string{"ffi", "ffl", "ff", "fi", "fl"}
+)
+
+// CheckRendered renders a CV with the analyzer's renderer, extracts the
+// text back from the PDF with the PDF parser the CV inputs use, and compares
+// it with the CV. The language selects the hyphenation patterns; when empty
+// it is detected.
+func (a *Analyzer) CheckRendered(ctx context.Context, cvContent, language string) *PDFCheck {
+	if a.renderer == nil {
+		return &PDFCheck{Error: "no PDF renderer is configured"}
+	}
+
+	data, err := a.renderer.RenderPDF(cvContent, language)
+	if err != nil {
+		return &PDFCheck{Error: fmt.Sprintf("failed to render PDF: %v", err)}
+	}
+
+	extracted, _, err := a.pdf.Parse(ctx, data)
+	if err != nil {
+		return &PDFCheck{Error: fmt.Sprintf("failed to extract text from PDF: %v", err)}
+	}
+
+	return ComparePDFText(cvContent, extracted)
+}
+
+// ComparePDFText compares the text extracted from a rendered PDF with the
+// CV it was rendered from. A line whose words are all found in order
+// survived, even if the PDF reflowed it; one missing words is lost, and one
+// whose words are all found but not in order is scrambled, as two-column
+// layouts and floating text come out. A word the CV repeats must be in the
+// PDF as many times.
+func ComparePDFText(source, extracted string) *PDFCheck {
+	check := &PDFCheck{Hyphens: len(hyphenBreak.FindAllStringIndex(extracted, -1))}
+
+	extractedWords := words(hyphenBreak.ReplaceAllString(extracted, "$1$2"))
+	stream := " " + strings.Join(extractedWords, " ") + " "
+
+	available := make(map[string]int)
+	for _, w := range extractedWords {
+		available[w]++
+	}
+
+	var total, found int
+
+	ligatures := make(map[string]bool)
+
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+
+		lineWords := words(line)
+		if len(lineWords) == 0 {
+			continue
+		}
+
+		missing := 0
+
+		for _, w := range lineWords {
+			total++
+
+			// Each word of the PDF accounts for one word of the CV
+			if available[w] > 0 {
+				available[w]--
+				found++
+
+				continue
+			}
+
+			missing++
+
+			if lost, ok := lostLigature(w, available); ok && !ligatures[lost] {
+				ligatures[lost] = true
+				check.Ligatures = append(check.Ligatures, lost)
+			}
+		}
+
+		switch {
+		case missing > 0:
+			check.Lost = append(check.Lost, line)
+		case !strings.Contains(stream, " "+strings.Join(lineWords, " ")+" "):
+			check.Scrambled = append(check.Scrambled, line)
+		}
+	}
+
+	check.Coverage = 1
+	if total > 0 {
+		check.Coverage = float64(int(float64(found)/float64(total)*1000)) / 1000
+	}
+
+	check.Symbols = max(0, symbols(source)-symbols(extracted))
+
+	return check
+}
+
+// Issues reports the findings of a PDF check as formatting issues; a check
+// that failed reports none.
+func (c *PDFCheck) Issues() []FormattingIssue {
+	issues := []FormattingIssue{}
+	if c.Error != "" {
+		return issues
+	}
+
+	if len(c.Lost) > 0 {
+		issues = append(issues, FormattingIssue{
+			Type:     IssueLostContent,
+			Severity: "high",
+			Message: fmt.Sprintf("%d line(s) of the CV are missing words in the text extracted from the PDF, e.g. %s.",
+				len(c.Lost), quoteExamples(c.Lost)),
+		})
+	}
+
+	if len(c.Scrambled) > 0 {
+		issues = append(issues, FormattingIssue{
+			Type:     IssueScrambledContent,
+			Severity: "medium",
+			Message: fmt.Sprintf("%d line(s) of the CV come out of the PDF out of order, e.g. %s. Avoid columns and floating text.",
+				len(c.Scrambled), quoteExamples(c.Scrambled)),
+		})
+	}
+
+	if len(c.Ligatures) > 0 {
+		issues = append(issues, FormattingIssue{
+			Type:     IssueLigatures,
+			Severity: "medium",
+			Message: fmt.Sprintf("Ligatures such as \"fi\" are lost in the PDF, so an ATS cannot match words like %s.",
+				quoteExamples(c.Ligatures)),
+		})
+	}
+
+	if c.Hyphens > 0 {
+		issues = append(issues, FormattingIssue{
+			Type:     IssueHyphenation,
+			Severity: "low",
+			Message:  fmt.Sprintf("%d word(s) are hyphenated across lines in the PDF; some ATSs read them as two words.", c.Hyphens),
+		})
+	}
+
+	if c.Symbols > 0 {
+		issues = append(issues, FormattingIssue{
+			Type:     IssueLostSymbols,
+			Severity: "low",
+			Message:  fmt.Sprintf("%d icon(s) or symbol(s) of the CV are missing in the PDF. Use plain text labels.", c.Symbols),
+		})
+	}
+
+	return issues
+}
+
+// quoteExamples quotes the first lines of a list.
+func quoteExamples(lines []string) string {
+	quoted := make([]string, 0, maxExamples)
+	for _, line := range lines[:min(maxExamples, len(lines))] {
+		quoted = append(quoted, fmt.Sprintf("%q", line))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// words returns the folded words of a text.
+func words(text string) []string {
+	return strings.FieldsFunc(i18n.Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lostLigature returns the word if it has ligature letters and the PDF has
+// it with them dropped or replaced, as "certied" or "certi?ed" for
+// "certified".
+func lostLigature(word string, available map[string]int) (string, bool) {
+	for _, letters := range ligatureLetters {
+		if !strings.Contains(word, letters) {
+			continue
+		}
+
+		for _, parts := range [][]string{
+			strings.Split(strings.ReplaceAll(word, letters, ""), " "),
+			strings.Split(strings.ReplaceAll(word, letters, " "), " "),
+		} {
+			ok := true
+
+			for _, part := range parts {
+				if part != "" && available[part] == 0 {
+					ok = false
+
+					break
+				}
+			}
+
+			if ok {
+				return word, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// symbols counts the symbols of a text, such as icons.
+func symbols(text string) int {
+	n := 0
+
+	for _, r := range text {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Co, r) {
+			n++
+		}
+	}
+
+	return n
+}
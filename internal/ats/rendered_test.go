@@ -0,0 +1,121 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ats
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestComparePDFText(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		extracted string
+		want      PDFCheck
+	}{
+		{
+			name:      "intact",
+			source:    "Jane Doe\nGo Engineer",
+			extracted: "Jane Doe Go\nEngineer",
+			want:      PDFCheck{Coverage: 1},
+		},
+		{
+			name:      "lost line",
+			source:    "Jane Doe\nGo Engineer\nBerlin",
+			extracted: "Jane Doe\nBerlin",
+			want:      PDFCheck{Coverage: 0.6, Lost: []string{"Go Engineer"}},
+		},
+		{
+			name:      "two columns",
+			source:    "Go Kubernetes\nAcme Corp 2020",
+			extracted: "Go Acme Corp\nKubernetes 2020",
+			want:      PDFCheck{Coverage: 1, Scrambled: []string{"Go Kubernetes", "Acme Corp 2020"}},
+		},
+		{
+			name:      "dropped ligature",
+			source:    "Certified Go developer",
+			extracted: "Certi ed Go developer",
+			want:      PDFCheck{Coverage: 0.666, Lost: []string{"Certified Go developer"}, Ligatures: []string{"certified"}},
+		},
+		{
+			name:      "hyphenated word",
+			source:    "Experienced engineer",
+			extracted: "Experi-\nenced engineer",
+			want:      PDFCheck{Coverage: 1, Hyphens: 1},
+		},
+		{
+			name:      "lost symbol",
+			source:    "✉ jane@example.com",
+			extracted: "jane@example.com",
+			want:      PDFCheck{Coverage: 1, Symbols: 1},
+		},
+		{
+			name:      "repeated word",
+			source:    "Go\nGo developer",
+			extracted: "Go developer",
+			want:      PDFCheck{Coverage: 0.666, Lost: []string{"Go developer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComparePDFText(tt.source, tt.extracted)
+
+			if got.Coverage != tt.want.Coverage || got.Hyphens != tt.want.Hyphens || got.Symbols != tt.want.Symbols ||
+				!slices.Equal(got.Lost, tt.want.Lost) || !slices.Equal(got.Scrambled, tt.want.Scrambled) ||
+				!slices.Equal(got.Ligatures, tt.want.Ligatures) {
+				t.Errorf("ComparePDFText() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLostLigature(t *testing.T) {
+	tests := []struct {
+		word      string
+		available map[string]int
+		want      bool
+	}{
+		{"certified", map[string]int{"certied": 1}, true},
+		{"certified", map[string]int{"certi": 1, "ed": 1}, true},
+		{"office", map[string]int{"oce": 1}, true},
+		{"certified", map[string]int{"certi": 1}, false},
+		{"golang", map[string]int{"golang": 1}, false},
+	}
+
+	for _, tt := range tests {
+		if _, got := lostLigature(tt.word, tt.available); got != tt.want {
+			t.Errorf("lostLigature(%q, %v) = %v, want %v", tt.word, tt.available, got, tt.want)
+		}
+	}
+}
+
+func TestPDFCheckIssues(t *testing.T) {
+	check := &PDFCheck{
+		Lost:      []string{"Go Engineer"},
+		Scrambled: []string{"Go Kubernetes"},
+		Ligatures: []string{"certified"},
+		Hyphens:   2,
+		Symbols:   1,
+	}
+
+	var types []string
+	for _, issue := range check.Issues() {
+		types = append(types, issue.Type)
+	}
+
+	want := []string{IssueLostContent, IssueScrambledContent, IssueLigatures, IssueHyphenation, IssueLostSymbols}
+	if !slices.Equal(types, want) {
+		t.Errorf("issue types = %v, want %v", types, want)
+	}
+
+	if issues := (&PDFCheck{Lost: []string{"Go"}, Error: "failed to render PDF"}).Issues(); len(issues) != 0 {
+		t.Errorf("failed check issues = %+v, want none", issues)
+	}
+
+	if issues := (&PDFCheck{Coverage: 1}).Issues(); len(issues) != 0 {
+		t.Errorf("clean check issues = %+v, want none", issues)
+	}
+}
@@ -11,7 +11,7 @@ type KeywordMatches struct {
 
 // FormattingIssue represents a detected formatting problem.
 type FormattingIssue struct {
-	Type     string `json:"type"`     // e.g., "missing_section", "poor_formatting", "length_issue"
+	Type     string `json:"type"`     // e.g., "missing_section", "length_issue", or an Issue* type of an ATS profile
 	Severity string `json:"severity"` // "high", "medium", "low"
 	Message  string `json:"message"`
 }
@@ -31,11 +31,22 @@ type SectionCompleteness struct {
 	Summary    float64 `json:"summary"`    // 0.0 to 1.0
 }
 
+// ExperienceMatch compares the tenure of a CV's dated experience entries
+// with the years of experience a job asks for.
+type ExperienceMatch struct {
+	Years         float64 `json:"years"`          // Tenure, counting overlapping roles once
+	RequiredYears int     `json:"required_years"` // Zero when the job does not state any
+	Meets         bool    `json:"meets"`
+}
+
 // ATSAnalysisResult represents the complete ATS analysis result.
 type ATSAnalysisResult struct {
+	Profile             string              `json:"ats_profile"` // Name of the ATS profile simulated
 	OverallScore        float64             `json:"overall_score"`
 	KeywordMatches      KeywordMatches      `json:"keyword_matches"`
 	FormattingIssues    []FormattingIssue   `json:"formatting_issues"`
 	SectionCompleteness SectionCompleteness `json:"section_completeness"`
+	Experience          ExperienceMatch     `json:"experience"`
+	PDFCheck            *PDFCheck           `json:"pdf_check,omitempty"` // Only when the rendered PDF was checked
 	Recommendations     []Recommendation    `json:"recommendations"`
 }
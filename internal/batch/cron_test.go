@@ -0,0 +1,68 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.March, 6, 10, 30, 0, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.March, 6, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * mon", time.Date(2024, time.March, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, time.March, 7, 9, 0, 0, 0, time.UTC)},
+		{"30 10 6 3 *", time.Date(2025, time.March, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			got, err := cron.Next(from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestNextRunTimezone(t *testing.T) {
+	from := time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := NextRun("0 9 * * *", "America/New_York", from)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 09:00 EDT is 13:00 UTC
+	want := time.Date(2024, time.July, 1, 13, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
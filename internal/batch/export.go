@@ -0,0 +1,224 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/docx"
+	"github.com/sammyoina/vibe-cv/internal/latex"
+)
+
+// Supported rendered file types for batch exports.
+const (
+	FileTypePDF  = "pdf"
+	FileTypeDOCX = "docx"
+)
+
+// ItemResult is the result stored on a completed batch job item.
+type ItemResult struct {
+	MatchScore     float64  `json:"match_score"`
+	Modifications  []string `json:"modifications"`
+	CustomizedText string   `json:"customized_text"`
+}
+
+// ManifestEntry describes one item of an exported batch archive.
+type ManifestEntry struct {
+	ItemID         int      `json:"item_id"`
+	Status         string   `json:"status"`
+	File           string   `json:"file,omitempty"`
+	MatchScore     *float64 `json:"match_score,omitempty"`
+	Modifications  []string `json:"modifications,omitempty"`
+	JobDescription string   `json:"job_description"`
+	Error          string   `json:"error,omitempty"`
+	Fallback       string   `json:"fallback,omitempty"` // Why the CV is not in the requested file type
+}
+
+// Exporter renders batch results into a ZIP archive.
+type Exporter struct {
+	laTeXPath string
+}
+
+// NewExporter creates a new batch exporter.
+func NewExporter(laTeXPath string) *Exporter {
+	return &Exporter{laTeXPath: laTeXPath}
+}
+
+// WriteZIP streams a ZIP archive with one rendered CV per completed item and a
+// manifest in CSV and JSON form. Files are written to w one at a time, so the
+// archive is never held in memory. PDFs that cannot be compiled fall back to
+// DOCX, which the manifest records. File types other than FileTypePDF and
+// FileTypeDOCX are an error.
+func (e *Exporter) WriteZIP(ctx context.Context, w io.Writer, jobID int, items []*db.BatchJobItem, fileType string) error {
+	if fileType != FileTypePDF && fileType != FileTypeDOCX {
+		return fmt.Errorf("unsupported file type %q: use %s or %s", fileType, FileTypePDF, FileTypeDOCX)
+	}
+
+	workDir, err := os.MkdirTemp("", fmt.Sprintf("vibe-cv-batch-%d-", jobID))
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	generator := latex.NewLaTeXGenerator(workDir, e.laTeXPath)
+	zw := zip.NewWriter(w)
+	manifest := make([]ManifestEntry, 0, len(items))
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry := ManifestEntry{
+			ItemID:         item.ID,
+			Status:         item.Status,
+			JobDescription: item.JobDescription,
+		}
+
+		if item.ErrorMessage != nil {
+			entry.Error = *item.ErrorMessage
+		}
+
+		if item.Status == "completed" && item.Result != nil {
+			var result ItemResult
+			if err := json.Unmarshal(*item.Result, &result); err != nil {
+				entry.Error = "invalid stored result"
+			} else {
+				entry.MatchScore = &result.MatchScore
+				entry.Modifications = result.Modifications
+
+				file, fallback, err := e.writeRendered(zw, generator, item.ID, result.CustomizedText, fileType)
+				if err != nil {
+					return err
+				}
+
+				entry.File = file
+				entry.Fallback = fallback
+			}
+		}
+
+		manifest = append(manifest, entry)
+	}
+
+	if err := writeManifestCSV(zw, manifest); err != nil {
+		return err
+	}
+
+	if err := writeManifestJSON(zw, manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeRendered adds one rendered CV to the archive and returns its file
+// name, and why it was rendered as DOCX when a PDF was requested.
+func (e *Exporter) writeRendered(zw *zip.Writer, generator *latex.LaTeXGenerator, itemID int, text, fileType string) (string, string, error) {
+	base := fmt.Sprintf("cv-item-%d", itemID)
+
+	var fallback string
+
+	if fileType == FileTypePDF {
+		pdfPath, err := generator.GeneratePDF(text, base, "")
+		if err == nil {
+			name := base + ".pdf"
+
+			return name, "", copyFileToZip(zw, name, pdfPath)
+		}
+
+		// The manifest keeps the first line; the LaTeX output goes to the log
+		log.Printf("batch: item %d: %v", itemID, err)
+
+		reason, _, _ := strings.Cut(err.Error(), "\n")
+		fallback = "PDF rendering failed, rendered as DOCX: " + reason
+	}
+
+	name := base + ".docx"
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	if err := docx.Write(f, text); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return name, fallback, nil
+}
+
+// copyFileToZip streams a file from disk into the archive.
+func copyFileToZip(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// writeManifestCSV adds manifest.csv to the archive.
+func writeManifestCSV(zw *zip.Writer, manifest []ManifestEntry) error {
+	f, err := zw.Create("manifest.csv")
+	if err != nil {
+		return fmt.Errorf("failed to add manifest.csv: %w", err)
+	}
+
+	cw := csv.NewWriter(f)
+	_ = cw.Write([]string{"item_id", "status", "file", "match_score", "modifications", "error", "fallback"})
+
+	for _, entry := range manifest {
+		score := ""
+		if entry.MatchScore != nil {
+			score = strconv.FormatFloat(*entry.MatchScore, 'f', 2, 64)
+		}
+
+		_ = cw.Write([]string{
+			strconv.Itoa(entry.ItemID),
+			entry.Status,
+			entry.File,
+			score,
+			strings.Join(entry.Modifications, "; "),
+			entry.Error,
+			entry.Fallback,
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// writeManifestJSON adds manifest.json to the archive.
+func writeManifestJSON(zw *zip.Writer, manifest []ManifestEntry) error {
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to add manifest.json: %w", err)
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(manifest)
+}
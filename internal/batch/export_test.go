@@ -0,0 +1,169 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+)
+
+func exportItems(t *testing.T) []*db.BatchJobItem {
+	t.Helper()
+
+	result, err := json.Marshal(ItemResult{
+		MatchScore:     0.82,
+		Modifications:  []string{"Reordered skills", "Added Kubernetes"},
+		CustomizedText: "Jane Doe\n\nExperience\nBuilt Go services",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := json.RawMessage(`"not a result"`)
+	failure := "customization failed after 3 attempts: rate limited"
+
+	return []*db.BatchJobItem{
+		{ID: 11, Status: "completed", JobDescription: "Go engineer", Result: (*json.RawMessage)(&result)},
+		{ID: 12, Status: "failed", JobDescription: "SRE", ErrorMessage: &failure},
+		{ID: 13, Status: "completed", JobDescription: "Data engineer", Result: &invalid},
+	}
+}
+
+// readZIP returns the files of an archive by name.
+func readZIP(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid archive: %v", err)
+	}
+
+	files := make(map[string][]byte)
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[f.Name] = content
+	}
+
+	return files
+}
+
+func TestWriteZIP(t *testing.T) {
+	// Without LaTeX, PDFs fall back to DOCX
+	missingLaTeX := filepath.Join(t.TempDir(), "pdflatex")
+
+	tests := []struct {
+		fileType string
+		fallback bool
+	}{
+		{FileTypeDOCX, false},
+		{FileTypePDF, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileType, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewExporter(missingLaTeX).WriteZIP(context.Background(), &buf, 5, exportItems(t), tt.fileType); err != nil {
+				t.Fatalf("WriteZIP() error = %v", err)
+			}
+
+			files := readZIP(t, buf.Bytes())
+
+			var names []string
+			for name := range files {
+				names = append(names, name)
+			}
+
+			slices.Sort(names)
+
+			// Only the completed item with a valid result is rendered
+			if want := []string{"cv-item-11.docx", "manifest.csv", "manifest.json"}; !slices.Equal(names, want) {
+				t.Fatalf("archive files = %v, want %v", names, want)
+			}
+
+			if _, err := zip.NewReader(bytes.NewReader(files["cv-item-11.docx"]), int64(len(files["cv-item-11.docx"]))); err != nil {
+				t.Errorf("rendered DOCX is not a valid document: %v", err)
+			}
+
+			var manifest []ManifestEntry
+			if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+				t.Fatalf("invalid manifest.json: %v", err)
+			}
+
+			if len(manifest) != 3 {
+				t.Fatalf("manifest.json = %+v", manifest)
+			}
+
+			rendered := manifest[0]
+			if rendered.File != "cv-item-11.docx" || rendered.MatchScore == nil || *rendered.MatchScore != 0.82 || len(rendered.Modifications) != 2 {
+				t.Errorf("rendered entry = %+v", rendered)
+			}
+
+			if got := strings.HasPrefix(rendered.Fallback, "PDF rendering failed"); got != tt.fallback || strings.Contains(rendered.Fallback, "\n") {
+				t.Errorf("fallback = %q, want one: %v", rendered.Fallback, tt.fallback)
+			}
+
+			if failed := manifest[1]; failed.File != "" || failed.Error != "customization failed after 3 attempts: rate limited" {
+				t.Errorf("failed entry = %+v", failed)
+			}
+
+			if invalid := manifest[2]; invalid.File != "" || invalid.Error != "invalid stored result" {
+				t.Errorf("invalid entry = %+v", invalid)
+			}
+
+			rows, err := csv.NewReader(bytes.NewReader(files["manifest.csv"])).ReadAll()
+			if err != nil {
+				t.Fatalf("invalid manifest.csv: %v", err)
+			}
+
+			if len(rows) != 4 || rows[0][len(rows[0])-1] != "fallback" {
+				t.Fatalf("manifest.csv = %v", rows)
+			}
+
+			want := []string{"11", "completed", "cv-item-11.docx", "0.82", "Reordered skills; Added Kubernetes", "", rendered.Fallback}
+			if !slices.Equal(rows[1], want) {
+				t.Errorf("manifest.csv row = %q, want %q", rows[1], want)
+			}
+		})
+	}
+}
+
+func TestWriteZIP_UnsupportedFileType(t *testing.T) {
+	for _, fileType := range []string{"", "docs", "txt"} {
+		var buf bytes.Buffer
+		if err := NewExporter("pdflatex").WriteZIP(context.Background(), &buf, 5, exportItems(t), fileType); err == nil || buf.Len() != 0 {
+			t.Errorf("WriteZIP(%q) = %v, wrote %d bytes; want an error and nothing written", fileType, err, buf.Len())
+		}
+	}
+}
+
+func TestWriteZIP_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := NewExporter("pdflatex").WriteZIP(ctx, io.Discard, 5, exportItems(t), FileTypeDOCX); err == nil {
+		t.Error("Expected an error for a cancelled export")
+	}
+}
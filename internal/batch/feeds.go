@@ -0,0 +1,173 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/input"
+)
+
+const (
+	// feedPollInterval is how often workers look for due feeds.
+	feedPollInterval = time.Minute
+	// feedLease is how long a claimed feed stays owned while it is polled.
+	feedLease = 10 * time.Minute
+	// maxFeedItems bounds how many new items of a feed are read per poll; the
+	// rest are picked up by the next polls.
+	maxFeedItems = 25
+)
+
+// SaveIngestedJobs stores job postings read from an email or a feed for an
+// identity, skipping postings it already saved. It returns the new ones.
+func SaveIngestedJobs(repo *db.Repository, identityID int, origin string, feedID *int, jobs []input.IngestedJob) ([]*db.SavedJob, error) {
+	records := make([]*db.SavedJob, 0, len(jobs))
+
+	for _, j := range jobs {
+		record := &db.SavedJob{
+			IdentityID:       identityID,
+			Origin:           origin,
+			FeedID:           feedID,
+			ExternalID:       j.ExternalID,
+			Title:            j.Job.Title,
+			Company:          j.Job.Company,
+			Location:         j.Job.Location,
+			Salary:           j.Job.Salary,
+			EmploymentType:   j.Job.EmploymentType,
+			ExtractedBy:      j.Job.Source,
+			Description:      j.Job.RawText,
+			Requirements:     j.Job.Requirements,
+			Responsibilities: j.Job.Responsibilities,
+			PreferredSkills:  j.Job.PreferredSkills,
+			PostedAt:         j.PostedAt,
+		}
+
+		if j.URL != "" {
+			url := j.URL
+			record.URL = &url
+		}
+
+		records = append(records, record)
+	}
+
+	return repo.CreateSavedJobs(records)
+}
+
+// feedPoller claims due feeds and saves their new postings until ctx is done.
+func (q *JobQueue) feedPoller(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(feedPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			feed, err := q.repo.ClaimDueJobFeed(feedLease)
+			if err != nil {
+				log.Printf("batch: failed to claim feed: %v", err)
+
+				break
+			}
+
+			if feed == nil {
+				break
+			}
+
+			q.pollFeed(ctx, feed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollFeed fetches a claimed feed, saves the postings of its new items and
+// schedules the next poll.
+func (q *JobQueue) pollFeed(ctx context.Context, feed *db.JobFeed) {
+	title, saved, errs := q.readFeed(ctx, feed)
+
+	var errMsg *string
+
+	if len(errs) > 0 {
+		msg := strings.Join(errs, "; ")
+		errMsg = &msg
+	}
+
+	if err := q.repo.RecordJobFeedPoll(feed.ID, title, saved, errMsg); err != nil {
+		log.Printf("batch: failed to record poll of feed %d: %v", feed.ID, err)
+	}
+}
+
+// readFeed returns the title of a feed, the number of postings saved and a
+// message for each item that could not be read.
+func (q *JobQueue) readFeed(ctx context.Context, feed *db.JobFeed) (string, int, []string) {
+	q.mu.RLock()
+	fetcher := q.fetcher
+	q.mu.RUnlock()
+
+	parsed, err := fetcher.FetchFeed(ctx, feed.URL)
+	if err != nil {
+		return "", 0, []string{err.Error()}
+	}
+
+	ids := make([]string, len(parsed.Items))
+	for i, item := range parsed.Items {
+		ids[i] = input.FeedItemID(feed.URL, item)
+	}
+
+	known, err := q.repo.GetSavedJobExternalIDs(feed.IdentityID, ids)
+	if err != nil {
+		return parsed.Title, 0, []string{fmt.Sprintf("failed to check known items: %v", err)}
+	}
+
+	var (
+		jobs []input.IngestedJob
+		errs []string
+	)
+
+	for i, item := range parsed.Items {
+		if known[ids[i]] {
+			continue
+		}
+
+		if len(jobs) == maxFeedItems || ctx.Err() != nil {
+			break
+		}
+
+		job, err := fetcher.JobFromFeedItem(ctx, feed.URL, item)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", firstOf(item.Link, item.Title), err))
+
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	saved, err := SaveIngestedJobs(q.repo, feed.IdentityID, input.JobSourceFeed, &feed.ID, jobs)
+	if err != nil {
+		return parsed.Title, 0, append(errs, fmt.Sprintf("failed to save jobs: %v", err))
+	}
+
+	return parsed.Title, len(saved), errs
+}
+
+// firstOf returns the first non-empty value.
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
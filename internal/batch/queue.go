@@ -5,33 +5,114 @@ package batch
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/input"
 	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/match"
+	"github.com/sammyoina/vibe-cv/internal/webhook"
 )
 
-// JobQueue manages async batch jobs.
+const (
+	// defaultPollInterval is how often idle workers look for pending jobs.
+	defaultPollInterval = 2 * time.Second
+	// defaultLeaseDuration is how long a claimed job stays owned without a heartbeat.
+	defaultLeaseDuration = 2 * time.Minute
+	// defaultMaxAttempts is how many times a job may be claimed before it is failed.
+	defaultMaxAttempts = 3
+	// defaultHeartbeatInterval is how often a worker extends its lease and checks for cancellation.
+	defaultHeartbeatInterval = 10 * time.Second
+	// defaultItemConcurrency is how many items of one job are customized in parallel.
+	defaultItemConcurrency = 4
+	// defaultItemAttempts is how many times an item is tried before it is marked failed.
+	defaultItemAttempts = 3
+	// itemRetryBackoff is the base delay between item attempts; it doubles on each retry.
+	itemRetryBackoff = 2 * time.Second
+)
+
+// jobStore holds batch jobs, their leases and their items, as workers use
+// them. It is implemented by *db.Repository, and replaced in tests.
+type jobStore interface {
+	ClaimBatchJob(workerID string, lease time.Duration) (*db.BatchJob, error)
+	HeartbeatBatchJob(id int, workerID string, lease time.Duration) error
+	FinishBatchJob(id int, workerID string, status string, completedItems int) error
+	ReleaseBatchJob(id int, workerID string) error
+	RecoverExpiredBatchJobs(maxAttempts int) (int64, int64, error)
+	GetBatchJobItems(batchJobID int) ([]*db.BatchJobItem, error)
+	UpdateBatchJobItem(id int, status string, result *json.RawMessage, errorMessage *string) error
+	GetCV(id int) (*db.CV, error)
+}
+
+// JobQueue manages async batch jobs stored in Postgres. Jobs are claimed with
+// SELECT ... FOR UPDATE SKIP LOCKED, so several server replicas can share the
+// same queue, and a lease with heartbeats lets crashed workers' jobs be recovered.
 type JobQueue struct {
-	mu       sync.RWMutex
-	repo     *db.Repository
-	provider llm.Provider
-	workers  int
-	jobChan  chan int
-	stopChan chan struct{}
+	mu                sync.RWMutex
+	repo              *db.Repository
+	store             jobStore
+	process           func(ctx context.Context, job *db.BatchJob) error
+	provider          llm.Provider
+	webhooks          *webhook.Dispatcher
+	fetcher           *input.Fetcher
+	scorer            *match.Scorer
+	workers           int
+	workerID          string
+	pollInterval      time.Duration
+	leaseDuration     time.Duration
+	heartbeatInterval time.Duration
+	maxAttempts       int
+	itemWorkers       int
+	itemAttempts      int
+	wake              chan struct{}
+	stopChan          chan struct{}
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
 }
 
 // NewJobQueue creates a new job queue.
 func NewJobQueue(repo *db.Repository, workers int) *JobQueue {
-	return &JobQueue{
-		repo:     repo,
-		workers:  workers,
-		jobChan:  make(chan int, 100),
-		stopChan: make(chan struct{}),
+	q := &JobQueue{
+		repo:              repo,
+		store:             repo,
+		workers:           workers,
+		workerID:          newWorkerID(),
+		fetcher:           input.NewFetcher(30 * time.Second),
+		pollInterval:      defaultPollInterval,
+		leaseDuration:     defaultLeaseDuration,
+		heartbeatInterval: defaultHeartbeatInterval,
+		maxAttempts:       defaultMaxAttempts,
+		itemWorkers:       defaultItemConcurrency,
+		itemAttempts:      defaultItemAttempts,
+		wake:              make(chan struct{}, 1),
+		stopChan:          make(chan struct{}),
 	}
+	q.process = q.ProcessJob
+
+	return q
+}
+
+// newWorkerID builds an identifier that is unique across server replicas.
+func newWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	_, _ = crand.Read(suffix)
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
 }
 
 // SetProvider sets the LLM provider for batch job processing.
@@ -42,150 +123,500 @@ func (q *JobQueue) SetProvider(provider llm.Provider) {
 	q.provider = provider
 }
 
-// Start starts the job queue workers.
+// SetWebhooks sets the dispatcher that receives batch events.
+func (q *JobQueue) SetWebhooks(webhooks *webhook.Dispatcher) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.webhooks = webhooks
+}
+
+// SetFetcher sets the fetcher that reads the job URLs of schedules.
+func (q *JobQueue) SetFetcher(fetcher *input.Fetcher) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.fetcher = fetcher
+}
+
+// SetScorer sets the scorer that matches customized CVs with jobs. Without
+// one, items keep the match score the LLM reports.
+func (q *JobQueue) SetScorer(scorer *match.Scorer) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.scorer = scorer
+}
+
+// Start recovers jobs abandoned by crashed workers and starts the job queue
+// workers, the lease reaper, the schedule runner and the feed poller.
 func (q *JobQueue) Start() {
+	q.recoverExpired()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
 	for range q.workers {
-		go q.worker()
+		q.wg.Add(1)
+
+		go q.worker(ctx)
 	}
+
+	q.wg.Add(3)
+
+	go q.reaper(ctx)
+	go q.scheduler(ctx)
+	go q.feedPoller(ctx)
 }
 
-// Stop stops the job queue workers.
+// Stop stops the job queue workers and waits for them to exit. Jobs that are
+// still in flight are released back to the queue for another worker.
 func (q *JobQueue) Stop() {
 	close(q.stopChan)
+
+	if q.cancel != nil {
+		q.cancel()
+	}
+
+	q.wg.Wait()
 }
 
-// worker processes jobs from the queue.
-func (q *JobQueue) worker() {
+// worker claims and processes jobs until the queue is stopped.
+func (q *JobQueue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
 	for {
+		// Drain every available job before going back to sleep
+		for {
+			job, err := q.store.ClaimBatchJob(q.workerID, q.leaseDuration)
+			if err != nil {
+				log.Printf("batch: failed to claim job: %v", err)
+
+				break
+			}
+
+			if job == nil {
+				break
+			}
+
+			q.runJob(ctx, job)
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
 		select {
 		case <-q.stopChan:
 			return
-		case jobID := <-q.jobChan:
-			_ = q.ProcessJob(jobID)
+		case <-q.wake:
+		case <-ticker.C:
 		}
 	}
 }
 
-// CreateJob creates a new batch job with items.
-func (q *JobQueue) CreateJob(identityID *int, totalItems int) (int, error) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+// reaper periodically requeues jobs whose lease has expired.
+func (q *JobQueue) reaper(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.leaseDuration / 2)
+	defer ticker.Stop()
 
-	job, err := q.repo.CreateBatchJob(identityID, totalItems)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.recoverExpired()
+		}
+	}
+}
+
+// recoverExpired requeues or fails jobs left in processing by dead workers.
+func (q *JobQueue) recoverExpired() {
+	requeued, failed, err := q.store.RecoverExpiredBatchJobs(q.maxAttempts)
 	if err != nil {
-		return 0, err
+		log.Printf("batch: failed to recover expired jobs: %v", err)
+
+		return
+	}
+
+	if requeued > 0 || failed > 0 {
+		log.Printf("batch: recovered %d expired jobs, failed %d after %d attempts", requeued, failed, q.maxAttempts)
+		q.notify()
 	}
+}
 
-	// Queue the job for processing
+// notify wakes an idle local worker without blocking.
+func (q *JobQueue) notify() {
 	select {
-	case q.jobChan <- job.ID:
+	case q.wake <- struct{}{}:
 	default:
-		// Queue full, skip for now
 	}
+}
 
-	return job.ID, nil
+// runJob processes a claimed job while keeping its lease alive.
+func (q *JobQueue) runJob(ctx context.Context, job *db.BatchJob) {
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	heartbeatDone := make(chan struct{})
+
+	go func() {
+		defer close(heartbeatDone)
+		q.heartbeat(jobCtx, cancel, job.ID)
+	}()
+
+	err := q.process(jobCtx, job)
+	cause := context.Cause(jobCtx)
+
+	cancel(nil)
+	<-heartbeatDone
+
+	switch {
+	case errors.Is(cause, db.ErrJobCancelled):
+		log.Printf("batch: job %d cancelled", job.ID)
+	case errors.Is(cause, db.ErrLeaseLost), errors.Is(err, db.ErrLeaseLost):
+		log.Printf("batch: lost lease on job %d", job.ID)
+	case ctx.Err() != nil:
+		// Shutting down: hand the job to another worker
+		if relErr := q.store.ReleaseBatchJob(job.ID, q.workerID); relErr != nil {
+			log.Printf("batch: failed to release job %d: %v", job.ID, relErr)
+		}
+	case err != nil:
+		log.Printf("batch: job %d failed: %v", job.ID, err)
+	}
 }
 
-// AddJobItem adds an item to a batch job.
-func (q *JobQueue) AddJobItem(batchJobID int, cvID *int, jobDescription string) (int, error) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+// heartbeat extends the job lease until ctx is done. It cancels the job with
+// db.ErrJobCancelled or db.ErrLeaseLost when the job is cancelled or taken over.
+func (q *JobQueue) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, jobID int) {
+	ticker := time.NewTicker(q.heartbeatInterval)
+	defer ticker.Stop()
 
-	item, err := q.repo.CreateBatchJobItem(batchJobID, cvID, jobDescription)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := q.store.HeartbeatBatchJob(jobID, q.workerID, q.leaseDuration)
+			if errors.Is(err, db.ErrJobCancelled) || errors.Is(err, db.ErrLeaseLost) {
+				cancel(err)
+
+				return
+			}
+
+			if err != nil {
+				log.Printf("batch: heartbeat for job %d failed: %v", jobID, err)
+			}
+		}
+	}
+}
+
+// SubmitJob durably enqueues a new batch job with its items.
+func (q *JobQueue) SubmitJob(identityID *int, items []db.NewBatchJobItem) (int, error) {
+	job, err := q.repo.CreateBatchJobWithItems(identityID, items)
 	if err != nil {
 		return 0, err
 	}
 
-	return item.ID, nil
+	q.notify()
+
+	return job.ID, nil
 }
 
-// ProcessJob processes a specific batch job with actual LLM calls.
-func (q *JobQueue) ProcessJob(jobID int) error {
-	q.mu.Lock()
+// CancelJob cancels a pending or running job. Running items are interrupted
+// by the worker that holds the job on its next heartbeat.
+func (q *JobQueue) CancelJob(jobID int) error {
+	return q.repo.CancelBatchJob(jobID)
+}
 
-	// Update job status to processing
-	if err := q.repo.UpdateBatchJobStatus(jobID, "processing", 0); err != nil {
-		q.mu.Unlock()
+// RetryFailedItems requeues the failed, cancelled and unfinished items of a finished job.
+// It returns the number of items requeued.
+func (q *JobQueue) RetryFailedItems(jobID int) (int64, error) {
+	requeued, err := q.repo.RetryFailedBatchJobItems(jobID)
+	if err != nil {
+		return 0, err
+	}
 
-		return err
+	if requeued > 0 {
+		q.notify()
 	}
 
+	return requeued, nil
+}
+
+// ProcessJob processes a claimed batch job, customizing up to itemWorkers items
+// in parallel. Items that already finished in an earlier attempt are skipped, so
+// a recovered job resumes where the previous worker stopped.
+func (q *JobQueue) ProcessJob(ctx context.Context, job *db.BatchJob) error {
+	q.mu.RLock()
+	provider := q.provider
+	webhooks := q.webhooks
+	q.mu.RUnlock()
+
+	jobID := job.ID
+
 	// Check if provider is set
-	if q.provider == nil {
-		q.mu.Unlock()
-		_ = q.repo.UpdateBatchJobStatus(jobID, "failed", 0)
+	if provider == nil {
+		_ = q.store.FinishBatchJob(jobID, q.workerID, "failed", 0)
 
 		return errors.New("LLM provider not set for batch processing")
 	}
 
-	q.mu.Unlock()
-
 	// Get job items
-	items, err := q.repo.GetBatchJobItems(jobID)
+	items, err := q.store.GetBatchJobItems(jobID)
 	if err != nil {
-		_ = q.repo.UpdateBatchJobStatus(jobID, "failed", 0)
+		_ = q.store.FinishBatchJob(jobID, q.workerID, "failed", 0)
 
 		return err
 	}
 
-	// Process each item with actual LLM calls
-	completedCount := 0
-	ctx := context.Background()
+	var (
+		mu             sync.Mutex
+		wg             sync.WaitGroup
+		completedCount int
+		failedCount    int
+		cancelledCount int
+		sem            = make(chan struct{}, max(q.itemWorkers, 1))
+	)
 
 	for _, item := range items {
-		// Get CV text for this item
-		var cvText string
+		switch item.Status {
+		case "completed":
+			completedCount++
 
-		if item.CVID != nil {
-			cv, err := q.repo.GetCV(*item.CVID)
-			if err != nil {
-				// Skip this item on error
-				_ = q.repo.UpdateBatchJobItem(item.ID, "failed", nil, nil)
+			continue
+		case "failed":
+			failedCount++
 
-				continue
-			}
+			continue
+		case "cancelled":
+			cancelledCount++
 
-			cvText = cv.OriginalText
+			continue
 		}
 
-		// Call LLM provider to customize CV
-		result, err := q.provider.Customize(ctx, cvText, item.JobDescription, []string{})
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+
+			go func(item *db.BatchJobItem) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				status := q.processItem(ctx, provider, webhooks, job, item)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch status {
+				case "completed":
+					completedCount++
+				case "failed":
+					failedCount++
+				case "cancelled":
+					cancelledCount++
+				}
+			}(item)
+		}
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// A job is only completed once every item has finished, so that items
+	// left unfinished, as when their result could not be stored, are retried
+	unfinished := len(items) - completedCount - failedCount - cancelledCount
+
+	status := "completed"
+	if unfinished > 0 || (completedCount == 0 && failedCount+cancelledCount > 0) {
+		status = "failed"
+	}
+
+	if unfinished > 0 {
+		log.Printf("batch: job %d failed with %d unfinished items", jobID, unfinished)
+	}
+
+	if err := q.store.FinishBatchJob(jobID, q.workerID, status, completedCount); err != nil {
+		return err
+	}
+
+	if err := webhooks.Publish(job.IdentityID, webhook.EventBatchCompleted, map[string]any{
+		"job_id":          jobID,
+		"status":          status,
+		"total_items":     len(items),
+		"completed_items": completedCount,
+		"failed_items":    failedCount,
+		"cancelled_items": cancelledCount,
+	}); err != nil {
+		log.Printf("batch: %v", err)
+	}
+
+	return nil
+}
+
+// publishItemFailed emits a batch.item_failed event.
+func (q *JobQueue) publishItemFailed(webhooks *webhook.Dispatcher, job *db.BatchJob, itemID int, errMsg string) {
+	if err := webhooks.Publish(job.IdentityID, webhook.EventBatchItemFailed, map[string]any{
+		"job_id":  job.ID,
+		"item_id": itemID,
+		"error":   errMsg,
+	}); err != nil {
+		log.Printf("batch: %v", err)
+	}
+}
+
+// processItem customizes a single item, retrying transient failures with
+// exponential backoff. It returns the item's final status, or an empty string
+// if processing was interrupted and the item should be left for a later attempt.
+func (q *JobQueue) processItem(ctx context.Context, provider llm.Provider, webhooks *webhook.Dispatcher, job *db.BatchJob, item *db.BatchJobItem) string {
+	// Get CV text for this item
+	var cvText string
+
+	if item.CVID != nil {
+		cv, err := q.store.GetCV(*item.CVID)
 		if err != nil {
-			// Mark item as failed
-			_ = q.repo.UpdateBatchJobItem(item.ID, "failed", nil, nil)
+			errMsg := fmt.Sprintf("failed to load CV %d: %v", *item.CVID, err)
+			_ = q.store.UpdateBatchJobItem(item.ID, "failed", nil, &errMsg)
+			q.publishItemFailed(webhooks, job, item.ID, errMsg)
 
-			continue
+			return "failed"
 		}
 
-		// Store successful result
-		resultData := map[string]any{
-			"status":          "completed",
-			"match_score":     result.MatchScore,
-			"modifications":   result.Modifications,
-			"customized_text": result.ModifiedCV,
-			"processed_at":    time.Now().UTC(),
+		cvText = cv.OriginalText
+	}
+
+	_ = q.store.UpdateBatchJobItem(item.ID, "processing", nil, nil)
+
+	// Customize each CV in its own language
+	lang := i18n.Detect(cvText)
+	customizeCtx := llm.WithLanguage(ctx, lang)
+
+	var lastErr error
+
+	for attempt := 1; attempt <= q.itemAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleepContext(ctx, retryDelay(attempt)); err != nil {
+				break
+			}
 		}
-		resultJSON, _ := json.Marshal(resultData)
-		resultPtr := (*json.RawMessage)(&resultJSON)
 
-		if err := q.repo.UpdateBatchJobItem(item.ID, "completed", resultPtr, nil); err != nil {
-			_ = q.repo.UpdateBatchJobStatus(jobID, "failed", completedCount)
+		// Call LLM provider to customize CV
+		result, err := provider.Customize(customizeCtx, cvText, item.JobDescription, []string{})
+		if err == nil {
+			// Store successful result
+			resultData := map[string]any{
+				"status":          "completed",
+				"language":        lang,
+				"match_score":     result.MatchScore,
+				"modifications":   result.Modifications,
+				"customized_text": result.ModifiedCV,
+				"attempts":        attempt,
+				"processed_at":    time.Now().UTC(),
+			}
+
+			if scored := q.score(ctx, result.ModifiedCV, item.JobDescription); scored != nil {
+				resultData["match_score"] = scored.Score
+				resultData["match_details"] = scored.Details
+			}
+			resultJSON, _ := json.Marshal(resultData)
+			resultPtr := (*json.RawMessage)(&resultJSON)
+
+			if err := q.store.UpdateBatchJobItem(item.ID, "completed", resultPtr, nil); err != nil {
+				log.Printf("batch: failed to store result for item %d: %v", item.ID, err)
+
+				// Fail the item so it can be retried; if even that cannot be
+				// stored, the job is not completed while the item is unfinished
+				errMsg := fmt.Sprintf("failed to store result: %v", err)
+				if err := q.store.UpdateBatchJobItem(item.ID, "failed", nil, &errMsg); err != nil {
+					return ""
+				}
 
-			return err
+				q.publishItemFailed(webhooks, job, item.ID, errMsg)
+
+				return "failed"
+			}
+
+			return "completed"
+		}
+
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
 		}
+	}
+
+	if errors.Is(context.Cause(ctx), db.ErrJobCancelled) {
+		errMsg := "cancelled by user"
+		_ = q.store.UpdateBatchJobItem(item.ID, "cancelled", nil, &errMsg)
+
+		return "cancelled"
+	}
 
-		completedCount++
+	if ctx.Err() != nil {
+		// Interrupted by shutdown or lease loss; leave the item for the next attempt
+		return ""
 	}
 
-	// Mark job as completed
-	return q.repo.UpdateBatchJobStatus(jobID, "completed", completedCount)
+	errMsg := fmt.Sprintf("customization failed after %d attempts: %v", q.itemAttempts, lastErr)
+	_ = q.store.UpdateBatchJobItem(item.ID, "failed", nil, &errMsg)
+	q.publishItemFailed(webhooks, job, item.ID, errMsg)
+
+	return "failed"
 }
 
-// GetBatchJobStatus retrieves the status of a batch job.
-func (q *JobQueue) GetBatchJobStatus(jobID int) (map[string]any, error) {
+// score matches a customized CV with its job, or returns nil without a
+// scorer or when scoring fails.
+func (q *JobQueue) score(ctx context.Context, cv, jobDescription string) *match.Result {
 	q.mu.RLock()
-	defer q.mu.RUnlock()
+	scorer := q.scorer
+	q.mu.RUnlock()
+
+	if scorer == nil {
+		return nil
+	}
+
+	scored, err := scorer.Score(ctx, cv, jobDescription)
+	if err != nil {
+		log.Printf("batch: failed to score match: %v", err)
+
+		return nil
+	}
+
+	return scored
+}
 
+// retryDelay returns the backoff before the given attempt, with up to 50% jitter.
+func retryDelay(attempt int) time.Duration {
+	delay := itemRetryBackoff << (attempt - 2)
+
+	return delay + rand.N(delay/2+1)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// GetBatchJobStatus retrieves the status of a batch job.
+func (q *JobQueue) GetBatchJobStatus(jobID int) (map[string]any, error) {
 	job, err := q.repo.GetBatchJob(jobID)
 	if err != nil {
 		return nil, err
@@ -198,10 +629,14 @@ func (q *JobQueue) GetBatchJobStatus(jobID int) (map[string]any, error) {
 
 	// Calculate progress
 	completedCount := 0
+	failedCount := 0
 
 	for _, item := range items {
-		if item.Status == "completed" {
+		switch item.Status {
+		case "completed":
 			completedCount++
+		case "failed":
+			failedCount++
 		}
 	}
 
@@ -217,6 +652,8 @@ func (q *JobQueue) GetBatchJobStatus(jobID int) (map[string]any, error) {
 		"updated_at": job.UpdatedAt,
 		"total":      len(items),
 		"completed":  completedCount,
+		"failed":     failedCount,
 		"progress":   progress,
+		"attempts":   job.Attempts,
 	}, nil
 }
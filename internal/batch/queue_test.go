@@ -0,0 +1,450 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+)
+
+// fakeStore keeps batch jobs and their items in memory, following the lease
+// rules of the repository's queries.
+type fakeStore struct {
+	mu           sync.Mutex
+	jobs         map[int]*fakeJob
+	items        []*db.BatchJobItem
+	failUpdates  map[int][]string // Item statuses that cannot be stored, by item ID
+	heartbeats   int
+	heartbeatErr error
+	finished     []string
+	released     []int
+}
+
+type fakeJob struct {
+	status   string
+	lockedBy string
+	expired  bool
+	attempts int
+}
+
+func newFakeStore(ids ...int) *fakeStore {
+	store := &fakeStore{jobs: make(map[int]*fakeJob)}
+	for _, id := range ids {
+		store.jobs[id] = &fakeJob{status: "pending"}
+	}
+
+	return store
+}
+
+func (s *fakeStore) ClaimBatchJob(workerID string, _ time.Duration) (*db.BatchJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, job := range s.jobs {
+		if job.status == "pending" {
+			job.status, job.lockedBy, job.expired = "processing", workerID, false
+			job.attempts++
+
+			return &db.BatchJob{ID: id, Status: job.status, Attempts: job.attempts}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *fakeStore) HeartbeatBatchJob(id int, workerID string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.heartbeats++
+
+	job := s.jobs[id]
+
+	switch {
+	case job.status == "cancelled":
+		return db.ErrJobCancelled
+	case job.status != "processing" || job.lockedBy != workerID:
+		return db.ErrLeaseLost
+	}
+
+	return s.heartbeatErr
+}
+
+func (s *fakeStore) FinishBatchJob(id int, workerID string, status string, _ int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[id]
+	if job.status != "processing" || job.lockedBy != workerID {
+		return db.ErrLeaseLost
+	}
+
+	job.status, job.lockedBy = status, ""
+	s.finished = append(s.finished, status)
+
+	return nil
+}
+
+func (s *fakeStore) ReleaseBatchJob(id int, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[id]
+	if job.status == "processing" && job.lockedBy == workerID {
+		job.status, job.lockedBy = "pending", ""
+		s.released = append(s.released, id)
+	}
+
+	return nil
+}
+
+func (s *fakeStore) RecoverExpiredBatchJobs(maxAttempts int) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requeued, failed int64
+
+	for _, job := range s.jobs {
+		if job.status != "processing" || !job.expired {
+			continue
+		}
+
+		job.lockedBy = ""
+
+		if job.attempts >= maxAttempts {
+			job.status = "failed"
+			failed++
+		} else {
+			job.status = "pending"
+			requeued++
+		}
+	}
+
+	return requeued, failed, nil
+}
+
+func (s *fakeStore) GetBatchJobItems(int) ([]*db.BatchJobItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*db.BatchJobItem, 0, len(s.items))
+	for _, item := range s.items {
+		copied := *item
+		items = append(items, &copied)
+	}
+
+	return items, nil
+}
+
+func (s *fakeStore) UpdateBatchJobItem(id int, status string, result *json.RawMessage, errorMessage *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(s.failUpdates[id], status) {
+		return errors.New("connection reset")
+	}
+
+	for _, item := range s.items {
+		if item.ID == id {
+			item.Status, item.Result, item.ErrorMessage = status, result, errorMessage
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) GetCV(id int) (*db.CV, error) {
+	return &db.CV{ID: id, OriginalText: "Jane Doe\nGo engineer"}, nil
+}
+
+// itemStatus returns the stored status of an item.
+func (s *fakeStore) itemStatus(id int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.items {
+		if item.ID == id {
+			return item.Status
+		}
+	}
+
+	return ""
+}
+
+// set changes a job as another worker or a user would.
+func (s *fakeStore) set(id int, change func(job *fakeJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change(s.jobs[id])
+}
+
+func (s *fakeStore) status(id int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.jobs[id].status
+}
+
+func newTestQueue(store *fakeStore) *JobQueue {
+	q := NewJobQueue(nil, 1)
+	q.store = store
+	q.heartbeatInterval = time.Millisecond
+
+	return q
+}
+
+func claim(t *testing.T, q *JobQueue) *db.BatchJob {
+	t.Helper()
+
+	job, err := q.store.ClaimBatchJob(q.workerID, q.leaseDuration)
+	if err != nil || job == nil {
+		t.Fatalf("ClaimBatchJob() = %v, %v", job, err)
+	}
+
+	return job
+}
+
+func TestRunJob_Finish(t *testing.T) {
+	store := newFakeStore(1)
+	q := newTestQueue(store)
+
+	// Without a provider, ProcessJob finishes the job as failed
+	q.runJob(context.Background(), claim(t, q))
+
+	if store.status(1) != "failed" || len(store.finished) != 1 || len(store.released) != 0 {
+		t.Errorf("status %q, finished %v, released %v", store.status(1), store.finished, store.released)
+	}
+}
+
+func TestRunJob_ReleaseOnShutdown(t *testing.T) {
+	store := newFakeStore(1)
+	q := newTestQueue(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q.process = func(jobCtx context.Context, _ *db.BatchJob) error {
+		cancel()
+		<-jobCtx.Done()
+
+		return jobCtx.Err()
+	}
+
+	q.runJob(ctx, claim(t, q))
+
+	// The job goes back to the queue for another worker
+	if store.status(1) != "pending" || len(store.released) != 1 || len(store.finished) != 0 {
+		t.Errorf("status %q, finished %v, released %v", store.status(1), store.finished, store.released)
+	}
+}
+
+func TestRunJob_HeartbeatLoss(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(job *fakeJob)
+		want   error
+		status string
+	}{
+		{"cancelled", func(job *fakeJob) { job.status, job.lockedBy = "cancelled", "" }, db.ErrJobCancelled, "cancelled"},
+		{"taken over", func(job *fakeJob) { job.lockedBy = "other-worker" }, db.ErrLeaseLost, "processing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore(1)
+			q := newTestQueue(store)
+
+			var cause error
+
+			q.process = func(jobCtx context.Context, job *db.BatchJob) error {
+				store.set(job.ID, tt.change)
+
+				select {
+				case <-jobCtx.Done():
+				case <-time.After(5 * time.Second):
+					t.Error("heartbeat did not cancel the job")
+				}
+
+				cause = context.Cause(jobCtx)
+
+				return jobCtx.Err()
+			}
+
+			q.runJob(context.Background(), claim(t, q))
+
+			if !errors.Is(cause, tt.want) {
+				t.Errorf("cause = %v, want %v", cause, tt.want)
+			}
+
+			// The job belongs to someone else now: it is neither released nor finished
+			if store.status(1) != tt.status || len(store.released) != 0 || len(store.finished) != 0 {
+				t.Errorf("status %q, finished %v, released %v", store.status(1), store.finished, store.released)
+			}
+		})
+	}
+}
+
+func TestHeartbeat_TransientError(t *testing.T) {
+	store := newFakeStore(1)
+	store.heartbeatErr = errors.New("connection reset")
+	q := newTestQueue(store)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		q.heartbeat(ctx, cancel, claim(t, q).ID)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		store.mu.Lock()
+		heartbeats := store.heartbeats
+		store.mu.Unlock()
+
+		if heartbeats >= 3 || time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// A failed heartbeat is retried rather than giving the job up
+	if err := ctx.Err(); err != nil {
+		t.Errorf("job context = %v after a transient heartbeat error", err)
+	}
+
+	cancel(nil)
+	<-done
+}
+
+func TestRecoverExpired_Attempts(t *testing.T) {
+	store := newFakeStore(1)
+	q := newTestQueue(store)
+	q.maxAttempts = 2
+
+	expire := func(job *fakeJob) { job.expired = true }
+
+	// The first worker dies: the job is requeued and an idle worker woken
+	if job := claim(t, q); job.Attempts != 1 {
+		t.Fatalf("first claim attempts = %d", job.Attempts)
+	}
+
+	store.set(1, expire)
+	q.recoverExpired()
+
+	if store.status(1) != "pending" {
+		t.Fatalf("status after first expiry = %q, want pending", store.status(1))
+	}
+
+	select {
+	case <-q.wake:
+	default:
+		t.Error("recovery did not wake a worker")
+	}
+
+	// The second does too, which uses up the attempts
+	if job := claim(t, q); job.Attempts != 2 {
+		t.Fatalf("second claim attempts = %d", job.Attempts)
+	}
+
+	store.set(1, expire)
+	q.recoverExpired()
+
+	if store.status(1) != "failed" {
+		t.Errorf("status after last expiry = %q, want failed", store.status(1))
+	}
+
+	if job, _ := store.ClaimBatchJob(q.workerID, q.leaseDuration); job != nil {
+		t.Errorf("claimed failed job %d", job.ID)
+	}
+}
+
+// echoProvider returns CVs unchanged.
+type echoProvider struct{}
+
+func (echoProvider) Customize(_ context.Context, cv, _ string, _ []string) (*llm.CustomizationResponse, error) {
+	return &llm.CustomizationResponse{ModifiedCV: cv, MatchScore: 0.5}, nil
+}
+
+func (echoProvider) GetName() string {
+	return "echo"
+}
+
+func TestProcessJob_ItemAccounting(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []string         // Stored statuses of the items, by ID from 1
+		failUpdates map[int][]string // Item statuses that cannot be stored
+		want        string
+		itemStatus  []string
+	}{
+		{
+			name:       "all completed",
+			items:      []string{"pending", "pending"},
+			want:       "completed",
+			itemStatus: []string{"completed", "completed"},
+		},
+		{
+			name:        "result not stored",
+			items:       []string{"pending", "pending"},
+			failUpdates: map[int][]string{2: {"completed"}},
+			want:        "completed",
+			itemStatus:  []string{"completed", "failed"},
+		},
+		{
+			name:        "item left unfinished",
+			items:       []string{"pending", "pending"},
+			failUpdates: map[int][]string{2: {"completed", "failed"}},
+			want:        "failed",
+			itemStatus:  []string{"completed", "processing"},
+		},
+		{
+			name:       "only cancelled items",
+			items:      []string{"cancelled", "cancelled"},
+			want:       "failed",
+			itemStatus: []string{"cancelled", "cancelled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore(1)
+			store.failUpdates = tt.failUpdates
+
+			for i, status := range tt.items {
+				store.items = append(store.items, &db.BatchJobItem{ID: i + 1, BatchJobID: 1, Status: status, JobDescription: "Go engineer"})
+			}
+
+			q := newTestQueue(store)
+			q.SetProvider(echoProvider{})
+
+			if err := q.ProcessJob(context.Background(), claim(t, q)); err != nil {
+				t.Fatalf("ProcessJob() error = %v", err)
+			}
+
+			if len(store.finished) != 1 || store.finished[0] != tt.want {
+				t.Errorf("job finished as %v, want %s", store.finished, tt.want)
+			}
+
+			for i, want := range tt.itemStatus {
+				if got := store.itemStatus(i + 1); got != want {
+					t.Errorf("item %d status = %q, want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	_ "time/tzdata" // schedules may use any IANA time zone
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+)
+
+const (
+	// schedulePollInterval is how often workers look for due schedules.
+	schedulePollInterval = 30 * time.Second
+	// scheduleLease is how long a claimed schedule stays owned while it is resolved.
+	scheduleLease = 5 * time.Minute
+	// MaxScheduleItems caps the candidates x jobs a single schedule run may enqueue.
+	MaxScheduleItems = 500
+)
+
+// NextRun returns the next time after the given instant at which a cron
+// expression fires in the named IANA time zone, in UTC.
+func NextRun(cronExpr, timezone string, after time.Time) (time.Time, error) {
+	cron, err := ParseCron(cronExpr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q", timezone)
+	}
+
+	next, err := cron.Next(after.In(loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return next.UTC(), nil
+}
+
+// scheduler claims due schedules and turns them into batch jobs until ctx is done.
+func (q *JobQueue) scheduler(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(schedulePollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			schedule, err := q.repo.ClaimDueBatchSchedule(scheduleLease)
+			if err != nil {
+				log.Printf("batch: failed to claim schedule: %v", err)
+
+				break
+			}
+
+			if schedule == nil {
+				break
+			}
+
+			q.runSchedule(ctx, schedule)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSchedule advances a claimed schedule to its next run, then resolves its
+// job source and enqueues one batch job over the saved candidate set. The
+// schedule is advanced first, so a crash mid-run skips a run rather than
+// enqueueing it twice.
+func (q *JobQueue) runSchedule(ctx context.Context, schedule *db.BatchSchedule) {
+	var nextRunAt *time.Time
+
+	next, err := NextRun(schedule.CronExpr, schedule.Timezone, time.Now())
+	if err == nil {
+		nextRunAt = &next
+	} else {
+		log.Printf("batch: schedule %d has no next run: %v", schedule.ID, err)
+	}
+
+	if err := q.repo.AdvanceBatchSchedule(schedule.ID, nextRunAt); err != nil {
+		log.Printf("batch: failed to advance schedule %d: %v", schedule.ID, err)
+
+		return
+	}
+
+	jobID, err := q.submitSchedule(ctx, schedule)
+
+	var errMsg *string
+	if err != nil {
+		msg := err.Error()
+		errMsg = &msg
+		log.Printf("batch: schedule %d run failed: %v", schedule.ID, err)
+	}
+
+	if err := q.repo.RecordBatchScheduleRun(schedule.ID, jobID, errMsg); err != nil {
+		log.Printf("batch: failed to record run of schedule %d: %v", schedule.ID, err)
+	}
+}
+
+// submitSchedule builds and enqueues the batch job for one schedule run. It
+// returns a nil job ID without error when there is nothing new to process.
+func (q *JobQueue) submitSchedule(ctx context.Context, schedule *db.BatchSchedule) (*int, error) {
+	if len(schedule.CandidateCVIDs) == 0 {
+		return nil, errors.New("schedule has no candidates")
+	}
+
+	jobs, resolveErrs := q.resolveJobSource(ctx, schedule.JobSource)
+	if len(jobs) == 0 {
+		if len(resolveErrs) > 0 {
+			return nil, fmt.Errorf("no job descriptions resolved: %s", strings.Join(resolveErrs, "; "))
+		}
+
+		return nil, errors.New("job source resolved no job descriptions")
+	}
+
+	hashes := make([]string, len(jobs))
+	for i, job := range jobs {
+		sum := sha256.Sum256([]byte(job))
+		hashes[i] = hex.EncodeToString(sum[:])
+	}
+
+	if schedule.OnlyNewJobs {
+		seen, err := q.repo.GetSeenBatchScheduleJobs(schedule.ID, hashes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load seen jobs: %w", err)
+		}
+
+		var freshJobs, freshHashes []string
+
+		for i, hash := range hashes {
+			if !seen[hash] {
+				freshJobs = append(freshJobs, jobs[i])
+				freshHashes = append(freshHashes, hash)
+			}
+		}
+
+		if len(freshJobs) == 0 {
+			return nil, nil
+		}
+
+		jobs, hashes = freshJobs, freshHashes
+	}
+
+	if total := len(jobs) * len(schedule.CandidateCVIDs); total > MaxScheduleItems {
+		return nil, fmt.Errorf("run would create %d items, more than the limit of %d", total, MaxScheduleItems)
+	}
+
+	items := make([]db.NewBatchJobItem, 0, len(jobs)*len(schedule.CandidateCVIDs))
+
+	for _, cvID := range schedule.CandidateCVIDs {
+		for _, job := range jobs {
+			items = append(items, db.NewBatchJobItem{CVID: &cvID, JobDescription: job})
+		}
+	}
+
+	job, err := q.repo.CreateScheduledBatchJob(schedule.IdentityID, schedule.ID, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch job: %w", err)
+	}
+
+	q.notify()
+
+	if err := q.repo.MarkBatchScheduleJobsSeen(schedule.ID, hashes); err != nil {
+		log.Printf("batch: failed to record seen jobs of schedule %d: %v", schedule.ID, err)
+	}
+
+	if len(resolveErrs) > 0 {
+		return &job.ID, fmt.Errorf("some job sources failed: %s", strings.Join(resolveErrs, "; "))
+	}
+
+	return &job.ID, nil
+}
+
+// resolveJobSource returns the de-duplicated job descriptions of a job source,
+// fetching URLs as needed, along with a message for each source that failed.
+func (q *JobQueue) resolveJobSource(ctx context.Context, source db.ScheduleJobSource) ([]string, []string) {
+	q.mu.RLock()
+	fetcher := q.fetcher
+	q.mu.RUnlock()
+
+	var (
+		jobs []string
+		errs []string
+		seen = make(map[string]bool)
+	)
+
+	add := func(text string) {
+		text = strings.TrimSpace(text)
+		if text != "" && !seen[text] {
+			seen[text] = true
+			jobs = append(jobs, text)
+		}
+	}
+
+	for _, text := range source.JobDescriptions {
+		add(text)
+	}
+
+	for _, url := range source.URLs {
+		if ctx.Err() != nil {
+			errs = append(errs, "interrupted")
+
+			break
+		}
+
+		job, err := fetcher.FetchJob(ctx, url)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+
+			continue
+		}
+
+		add(job.RawText)
+	}
+
+	return jobs, errs
+}
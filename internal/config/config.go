@@ -23,6 +23,11 @@ type Config struct {
 	KratosEnabled   bool
 	KratosPublicURL string
 	KratosAdminURL  string
+	// Embedding backend of match scores: local, openai, gemini or ollama
+	EmbeddingBackend string
+	EmbeddingAPIKey  string
+	EmbeddingModel   string
+	EmbeddingURL     string
 }
 
 // Load loads configuration from environment variables and .env file.
@@ -31,19 +36,25 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		LLMProvider:     getEnv("LLM_PROVIDER", "openai"),
-		LLMAPIKey:       getEnv("LLM_API_KEY", ""),
-		LLMModel:        getEnv("LLM_MODEL", "gpt-4"),
-		ServerPort:      getEnv("SERVER_PORT", "8080"),
-		ServerHost:      getEnv("SERVER_HOST", "localhost"),
-		OutputDir:       getEnv("OUTPUT_DIR", "./outputs"),
-		LaTeXPath:       getEnv("LATEX_PATH", "pdflatex"),
-		DatabaseURL:     getEnv("DATABASE_URL", ""),
-		KratosEnabled:   getEnv("KRATOS_ENABLED", "false") == "true",
-		KratosPublicURL: getEnv("KRATOS_PUBLIC_URL", "http://localhost:4433"),
-		KratosAdminURL:  getEnv("KRATOS_ADMIN_URL", "http://localhost:4434"),
+		LLMProvider:      getEnv("LLM_PROVIDER", "openai"),
+		LLMAPIKey:        getEnv("LLM_API_KEY", ""),
+		LLMModel:         getEnv("LLM_MODEL", "gpt-4"),
+		ServerPort:       getEnv("SERVER_PORT", "8080"),
+		ServerHost:       getEnv("SERVER_HOST", "localhost"),
+		OutputDir:        getEnv("OUTPUT_DIR", "./outputs"),
+		LaTeXPath:        getEnv("LATEX_PATH", "pdflatex"),
+		DatabaseURL:      getEnv("DATABASE_URL", ""),
+		KratosEnabled:    getEnv("KRATOS_ENABLED", "false") == "true",
+		KratosPublicURL:  getEnv("KRATOS_PUBLIC_URL", "http://localhost:4433"),
+		KratosAdminURL:   getEnv("KRATOS_ADMIN_URL", "http://localhost:4434"),
+		EmbeddingBackend: getEnv("EMBEDDING_BACKEND", "local"),
+		EmbeddingModel:   getEnv("EMBEDDING_MODEL", ""),
+		EmbeddingURL:     getEnv("EMBEDDING_URL", ""),
 	}
 
+	// Embedding APIs share the LLM key unless they have their own
+	cfg.EmbeddingAPIKey = getEnv("EMBEDDING_API_KEY", cfg.LLMAPIKey)
+
 	// Validate required fields
 	if cfg.LLMAPIKey == "" {
 		return nil, errors.New("LLM_API_KEY environment variable not set")
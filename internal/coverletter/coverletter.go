@@ -0,0 +1,246 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package coverletter writes cover letters for customized CVs. A letter is
+// written by the LLM from a CV version and the job it was customized for,
+// and cites the requirements the match analysis found covered, with the
+// passages of the CV that cover them.
+package coverletter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// Tones of a letter.
+const (
+	ToneProfessional   = "professional"
+	ToneEnthusiastic   = "enthusiastic"
+	ToneFormal         = "formal"
+	ToneConversational = "conversational"
+)
+
+// Lengths of a letter.
+const (
+	LengthShort  = "short"
+	LengthMedium = "medium"
+	LengthLong   = "long"
+)
+
+// maxHighlights is how many covered requirements a letter is asked to cite.
+const maxHighlights = 5
+
+var (
+	// tones describes each tone to the LLM.
+	tones = map[string]string{
+		ToneProfessional:   "professional and confident",
+		ToneEnthusiastic:   "warm and enthusiastic, showing genuine interest in the role",
+		ToneFormal:         "formal and reserved, as usual for traditional employers and public institutions",
+		ToneConversational: "friendly and conversational, as usual for startups",
+	}
+	// lengths are the word counts of each length.
+	lengths = map[string][2]int{
+		LengthShort:  {150, 200},
+		LengthMedium: {250, 350},
+		LengthLong:   {400, 500},
+	}
+)
+
+// Options select how a letter is written.
+type Options struct {
+	Tone          string // One of the tones; defaults to professional
+	Length        string // One of the lengths; defaults to medium
+	Language      string // ISO 639-1 code; detected from the CV when empty
+	Company       string // Company addressed, when the job description does not say
+	HiringManager string // Person addressed; the letter uses a generic salutation without one
+}
+
+// Letter is a written cover letter.
+type Letter struct {
+	Content    string   // Text of the letter
+	Tone       string   // Tone it was written in
+	Length     string   // Length it was written at
+	Language   string   // Language it was written in
+	WordCount  int      // Words of the letter
+	Highlights []string // Requirements of the job it was asked to cite
+}
+
+// Generator writes cover letters with an LLM provider.
+type Generator struct {
+	provider llm.Provider
+}
+
+// NewGenerator creates a cover letter generator.
+func NewGenerator(provider llm.Provider) *Generator {
+	return &Generator{provider: provider}
+}
+
+// Normalize fills in the default tone and length of options and reduces the
+// language to a supported one, and returns an error for an unknown option.
+func (o *Options) Normalize() error {
+	o.Tone = strings.ToLower(strings.TrimSpace(o.Tone))
+	if o.Tone == "" {
+		o.Tone = ToneProfessional
+	}
+
+	if _, ok := tones[o.Tone]; !ok {
+		return fmt.Errorf("unknown tone %q: use one of %s", o.Tone, strings.Join(Tones(), ", "))
+	}
+
+	o.Length = strings.ToLower(strings.TrimSpace(o.Length))
+	if o.Length == "" {
+		o.Length = LengthMedium
+	}
+
+	if _, ok := lengths[o.Length]; !ok {
+		return fmt.Errorf("unknown length %q: use one of %s, %s, %s", o.Length, LengthShort, LengthMedium, LengthLong)
+	}
+
+	// A language such as "de-DE" is written in its base language
+	if o.Language != "" {
+		lang, ok := i18n.Normalize(o.Language)
+		if !ok {
+			return fmt.Errorf("unsupported language %q: use one of en, de, fr, es, pt, nl", o.Language)
+		}
+
+		o.Language = lang
+	}
+
+	return nil
+}
+
+// Tones returns the names of the tones, sorted.
+func Tones() []string {
+	names := make([]string, 0, len(tones))
+	for name := range tones {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Generate writes a cover letter for a CV and the job it was customized for.
+// With match details, the letter cites the covered requirements, strongest
+// first, and does not claim the uncovered ones.
+func (g *Generator) Generate(ctx context.Context, cv, jobDescription string, details *types.MatchDetails, opts Options) (*Letter, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(cv) == "" {
+		return nil, fmt.Errorf("CV has no text to write a cover letter from")
+	}
+
+	if opts.Language == "" {
+		opts.Language = i18n.Detect(cv)
+	}
+
+	words := lengths[opts.Length]
+
+	instructions := []string{
+		"Do not return a CV. Instead, write a cover letter for this job from the CV, and return the letter as \"customized_cv\", " +
+			"with the points of the CV it stresses as \"modifications\".",
+		fmt.Sprintf("Write in a %s tone, in %d to %d words, as plain text paragraphs with a salutation and a closing, without a postal address block.",
+			tones[opts.Tone], words[0], words[1]),
+		"Only state what the CV supports; never invent experience, employers or figures.",
+	}
+
+	if opts.HiringManager != "" {
+		instructions = append(instructions, "Address the letter to "+opts.HiringManager+".")
+	}
+
+	if opts.Company != "" {
+		instructions = append(instructions, "The company hiring is "+opts.Company+".")
+	}
+
+	highlights, gaps := requirements(details)
+
+	if len(highlights) > 0 {
+		instructions = append(instructions, "Stress how the candidate meets these requirements of the job, with the evidence from the CV:")
+
+		for _, r := range highlights {
+			instructions = append(instructions, fmt.Sprintf("- %s (CV: %s)", r.Requirement, r.Evidence))
+		}
+	}
+
+	if len(gaps) > 0 {
+		instructions = append(instructions, "The CV does not show these requirements; do not claim them: "+strings.Join(gaps, "; "))
+	}
+
+	resp, err := g.provider.Customize(llm.WithLanguage(ctx, opts.Language), cv, jobDescription, instructions)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(resp.ModifiedCV)
+	if content == "" {
+		return nil, fmt.Errorf("%s returned an empty cover letter", g.provider.GetName())
+	}
+
+	letter := &Letter{
+		Content:    content,
+		Tone:       opts.Tone,
+		Length:     opts.Length,
+		Language:   opts.Language,
+		WordCount:  len(strings.Fields(content)),
+		Highlights: []string{},
+	}
+
+	for _, r := range highlights {
+		letter.Highlights = append(letter.Highlights, r.Requirement)
+	}
+
+	return letter, nil
+}
+
+// requirements returns the covered requirements of a match to cite,
+// required before preferred and best covered first, and the required ones
+// not covered.
+func requirements(details *types.MatchDetails) ([]types.RequirementMatch, []string) {
+	if details == nil {
+		return nil, nil
+	}
+
+	var (
+		covered []types.RequirementMatch
+		gaps    []string
+	)
+
+	for _, r := range details.Requirements {
+		switch {
+		case r.Covered && r.Evidence != "":
+			covered = append(covered, r)
+		case !r.Covered && r.Kind == "required":
+			gaps = append(gaps, r.Requirement)
+		}
+	}
+
+	slices.SortStableFunc(covered, func(a, b types.RequirementMatch) int {
+		if a.Kind != b.Kind {
+			if a.Kind == "required" {
+				return -1
+			}
+
+			return 1
+		}
+
+		switch {
+		case a.Coverage > b.Coverage:
+			return -1
+		case a.Coverage < b.Coverage:
+			return 1
+		}
+
+		return 0
+	})
+
+	return covered[:min(maxHighlights, len(covered))], gaps
+}
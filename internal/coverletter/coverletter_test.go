@@ -0,0 +1,101 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package coverletter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// fakeProvider records the request it gets and returns a fixed letter.
+type fakeProvider struct {
+	language string
+	context  []string
+	letter   string
+}
+
+func (p *fakeProvider) Customize(ctx context.Context, cv, jobDescription string, additionalContext []string) (*llm.CustomizationResponse, error) {
+	p.language = llm.LanguageFrom(ctx)
+	p.context = additionalContext
+
+	return &llm.CustomizationResponse{ModifiedCV: p.letter}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "fake"
+}
+
+func TestGenerate(t *testing.T) {
+	provider := &fakeProvider{letter: "  Dear Ms Smith,\n\nI would love to build your payment APIs.\n\nKind regards,\nJane  "}
+
+	details := &types.MatchDetails{Requirements: []types.RequirementMatch{
+		{Requirement: "Mentoring", Kind: "preferred", Coverage: 0.9, Covered: true, Evidence: "Mentored four engineers"},
+		{Requirement: "Go", Kind: "required", Coverage: 0.7, Covered: true, Evidence: "Built Go microservices"},
+		{Requirement: "Kubernetes", Kind: "required", Coverage: 0.8, Covered: true, Evidence: "Operated K8s clusters"},
+		{Requirement: "Kafka", Kind: "required", Coverage: 0.1},
+	}}
+
+	letter, err := NewGenerator(provider).Generate(context.Background(), "Jane Doe\nBackend engineer", "Backend Engineer at Acme", details, Options{
+		Tone:          "Enthusiastic",
+		HiringManager: "Ms Smith",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if letter.Tone != ToneEnthusiastic || letter.Length != LengthMedium || letter.Language != "en" {
+		t.Errorf("options = %q, %q, %q", letter.Tone, letter.Length, letter.Language)
+	}
+
+	if !strings.HasPrefix(letter.Content, "Dear") || letter.WordCount != 14 {
+		t.Errorf("content = %q (%d words)", letter.Content, letter.WordCount)
+	}
+
+	if want := []string{"Kubernetes", "Go", "Mentoring"}; strings.Join(letter.Highlights, ",") != strings.Join(want, ",") {
+		t.Errorf("highlights = %v, want %v", letter.Highlights, want)
+	}
+
+	prompt := strings.Join(provider.context, "\n")
+	for _, want := range []string{"warm and enthusiastic", "250 to 350 words", "Ms Smith", "Operated K8s clusters", "do not claim them: Kafka"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestGenerate_Language(t *testing.T) {
+	provider := &fakeProvider{letter: "Sehr geehrte Damen und Herren"}
+
+	cv := "Erfahrung\nSeit März 2021 Softwareentwicklerin bei der Beispiel GmbH und verantwortlich für die Entwicklung"
+
+	letter, err := NewGenerator(provider).Generate(context.Background(), cv, "Entwickler (m/w/d)", nil, Options{Length: LengthShort})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if letter.Language != "de" || provider.language != "de" {
+		t.Errorf("language = %q, provider got %q, want de", letter.Language, provider.language)
+	}
+}
+
+func TestOptions_Normalize(t *testing.T) {
+	for _, opts := range []Options{{Tone: "sarcastic"}, {Length: "epic"}, {Language: "xx"}, {Language: "klingon-language"}} {
+		if err := opts.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) accepted an unknown option", opts)
+		}
+	}
+
+	opts := Options{Language: " de-DE "}
+	if err := opts.Normalize(); err != nil || opts.Language != "de" || opts.Tone != ToneProfessional || opts.Length != LengthMedium {
+		t.Errorf("Normalize() = %+v, %v, want German with the default tone and length", opts, err)
+	}
+
+	if _, err := NewGenerator(&fakeProvider{}).Generate(context.Background(), "Jane Doe", "Job", nil, Options{}); err == nil {
+		t.Error("Expected an error for an empty letter")
+	}
+}
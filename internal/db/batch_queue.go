@@ -0,0 +1,336 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLeaseLost is returned when a worker no longer holds the lease on a batch job.
+	ErrLeaseLost = errors.New("batch job lease lost")
+	// ErrJobCancelled is returned when a batch job was cancelled while being processed.
+	ErrJobCancelled = errors.New("batch job cancelled")
+	// ErrJobRunning is returned when an operation requires a finished batch job.
+	ErrJobRunning = errors.New("batch job is still running")
+)
+
+// NewBatchJobItem describes an item to enqueue together with its batch job.
+type NewBatchJobItem struct {
+	CVID           *int
+	JobDescription string
+}
+
+// CreateBatchJobWithItems creates a batch job and all of its items in a single
+// transaction, so workers never claim a job whose items are still being written.
+func (r *Repository) CreateBatchJobWithItems(identityID *int, items []NewBatchJobItem) (*BatchJob, error) {
+	return r.createBatchJobWithItems(identityID, nil, items)
+}
+
+// CreateScheduledBatchJob creates a batch job with its items on behalf of a schedule.
+func (r *Repository) CreateScheduledBatchJob(identityID, scheduleID int, items []NewBatchJobItem) (*BatchJob, error) {
+	return r.createBatchJobWithItems(&identityID, &scheduleID, items)
+}
+
+func (r *Repository) createBatchJobWithItems(identityID, scheduleID *int, items []NewBatchJobItem) (*BatchJob, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var job BatchJob
+
+	err = tx.QueryRow(
+		"INSERT INTO batch_jobs (identity_id, schedule_id, total_items, status) VALUES ($1, $2, $3, 'pending') RETURNING id, created_at, updated_at",
+		identityID, scheduleID, len(items),
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if _, err := tx.Exec(
+			"INSERT INTO batch_job_items (batch_job_id, cv_id, job_description, status) VALUES ($1, $2, $3, 'pending')",
+			job.ID, item.CVID, item.JobDescription,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	job.IdentityID = identityID
+	job.Status = "pending"
+	job.TotalItems = len(items)
+
+	return &job, nil
+}
+
+// ClaimBatchJob atomically claims the oldest pending batch job for a worker and
+// leases it for the given duration. It returns nil when no job is available.
+func (r *Repository) ClaimBatchJob(workerID string, lease time.Duration) (*BatchJob, error) {
+	var job BatchJob
+
+	err := r.db.QueryRow(`
+		UPDATE batch_jobs
+		SET status = 'processing',
+			locked_by = $1,
+			locked_until = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second',
+			attempts = COALESCE(attempts, 0) + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM batch_jobs
+			WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, identity_id, status, total_items, completed_items, attempts, created_at, completed_at, updated_at`,
+		workerID, lease.Seconds(),
+	).Scan(&job.ID, &job.IdentityID, &job.Status, &job.TotalItems, &job.CompletedItems, &job.Attempts, &job.CreatedAt, &job.CompletedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// HeartbeatBatchJob extends the lease held by a worker on a batch job.
+// It returns ErrJobCancelled if the job was cancelled, or ErrLeaseLost if the
+// job is no longer leased by the worker.
+func (r *Repository) HeartbeatBatchJob(id int, workerID string, lease time.Duration) error {
+	res, err := r.db.Exec(`
+		UPDATE batch_jobs
+		SET locked_until = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND locked_by = $3 AND status = 'processing'`,
+		lease.Seconds(), id, workerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		return nil
+	}
+
+	var status string
+	if err := r.db.QueryRow("SELECT status FROM batch_jobs WHERE id = $1", id).Scan(&status); err != nil {
+		return ErrLeaseLost
+	}
+
+	if status == "cancelled" {
+		return ErrJobCancelled
+	}
+
+	return ErrLeaseLost
+}
+
+// FinishBatchJob records the final status of a job processed by a worker. The
+// update only applies while the worker still holds the lease, so a cancelled or
+// recovered job is never overwritten.
+func (r *Repository) FinishBatchJob(id int, workerID string, status string, completedItems int) error {
+	res, err := r.db.Exec(`
+		UPDATE batch_jobs
+		SET status = $1, completed_items = $2, completed_at = CURRENT_TIMESTAMP,
+			locked_by = NULL, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND locked_by = $4 AND status = 'processing'`,
+		status, completedItems, id, workerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+// ReleaseBatchJob hands a leased job back to the queue so another worker can
+// pick it up. Items that were in flight are returned to pending.
+func (r *Repository) ReleaseBatchJob(id int, workerID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`
+		UPDATE batch_jobs
+		SET status = 'pending', locked_by = NULL, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND locked_by = $2 AND status = 'processing'`,
+		id, workerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE batch_job_items SET status = 'pending', updated_at = CURRENT_TIMESTAMP WHERE batch_job_id = $1 AND status = 'processing'",
+		id,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelBatchJob cancels a pending or processing job and all of its unfinished
+// items. Workers processing the job notice the cancellation on their next heartbeat.
+// It returns sql.ErrNoRows if the job does not exist or has already finished.
+func (r *Repository) CancelBatchJob(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`
+		UPDATE batch_jobs
+		SET status = 'cancelled', locked_by = NULL, locked_until = NULL,
+			completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status IN ('pending', 'processing')`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE batch_job_items
+		SET status = 'cancelled', error_message = 'cancelled by user', updated_at = CURRENT_TIMESTAMP
+		WHERE batch_job_id = $1 AND status IN ('pending', 'processing')`,
+		id,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RetryFailedBatchJobItems returns the failed, cancelled and unfinished items
+// of a finished job to pending and requeues the job. It returns the number of
+// items requeued, or ErrJobRunning if the job has not finished yet.
+func (r *Repository) RetryFailedBatchJobItems(id int) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	if err := tx.QueryRow("SELECT status FROM batch_jobs WHERE id = $1 FOR UPDATE", id).Scan(&status); err != nil {
+		return 0, err
+	}
+
+	if status == "pending" || status == "processing" {
+		return 0, ErrJobRunning
+	}
+
+	res, err := tx.Exec(`
+		UPDATE batch_job_items
+		SET status = 'pending', error_message = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE batch_job_id = $1 AND status <> 'completed'`,
+		id,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if requeued == 0 {
+		return 0, tx.Commit()
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE batch_jobs
+		SET status = 'pending', attempts = 0, completed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`,
+		id,
+	); err != nil {
+		return 0, err
+	}
+
+	return requeued, tx.Commit()
+}
+
+// RecoverExpiredBatchJobs returns jobs whose lease has expired to the pending
+// state, or fails them once they have been attempted maxAttempts times.
+// It returns the number of requeued and failed jobs.
+//
+// Jobs processing without any lease are requeued too: claimed jobs always
+// hold one, so these were set to processing outside the queue (by
+// UpdateBatchJobStatus, or before leases existed), and no worker would ever
+// finish them. They are not failed, as none of their attempts was counted;
+// the next claim counts one and leases them like any other job.
+func (r *Repository) RecoverExpiredBatchJobs(maxAttempts int) (int64, int64, error) {
+	res, err := r.db.Exec(`
+		UPDATE batch_jobs
+		SET status = 'failed', locked_by = NULL, locked_until = NULL,
+			completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'processing' AND locked_until < CURRENT_TIMESTAMP AND COALESCE(attempts, 0) >= $1`,
+		maxAttempts,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	failed, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err = r.db.Exec(`
+		UPDATE batch_jobs
+		SET status = 'pending', locked_by = NULL, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'processing' AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)`,
+	)
+	if err != nil {
+		return 0, failed, err
+	}
+
+	requeued, err := res.RowsAffected()
+	if err != nil {
+		return 0, failed, err
+	}
+
+	return requeued, failed, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const batchScheduleColumns = "id, identity_id, name, cron_expr, timezone, candidate_cv_ids, job_source, only_new_jobs, active, next_run_at, last_run_at, last_job_id, last_error, created_at, updated_at"
+
+// scanBatchSchedule scans a row selected with batchScheduleColumns.
+func scanBatchSchedule(row interface{ Scan(...any) error }) (*BatchSchedule, error) {
+	var (
+		s              BatchSchedule
+		candidateCVIDs []byte
+		jobSource      []byte
+	)
+
+	if err := row.Scan(&s.ID, &s.IdentityID, &s.Name, &s.CronExpr, &s.Timezone, &candidateCVIDs, &jobSource, &s.OnlyNewJobs, &s.Active, &s.NextRunAt, &s.LastRunAt, &s.LastJobID, &s.LastError, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal(candidateCVIDs, &s.CandidateCVIDs)
+	_ = json.Unmarshal(jobSource, &s.JobSource)
+
+	return &s, nil
+}
+
+// CreateBatchSchedule stores a new batch schedule.
+func (r *Repository) CreateBatchSchedule(s *BatchSchedule) (*BatchSchedule, error) {
+	candidateCVIDs, err := json.Marshal(s.CandidateCVIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	jobSource, err := json.Marshal(s.JobSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBatchSchedule(r.db.QueryRow(
+		`INSERT INTO batch_schedules (identity_id, name, cron_expr, timezone, candidate_cv_ids, job_source, only_new_jobs, active, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+batchScheduleColumns,
+		s.IdentityID, s.Name, s.CronExpr, s.Timezone, candidateCVIDs, jobSource, s.OnlyNewJobs, s.Active, s.NextRunAt,
+	))
+}
+
+// GetBatchSchedules retrieves all batch schedules of an identity.
+func (r *Repository) GetBatchSchedules(identityID int) ([]*BatchSchedule, error) {
+	rows, err := r.db.Query(
+		"SELECT "+batchScheduleColumns+" FROM batch_schedules WHERE identity_id = $1 ORDER BY created_at DESC",
+		identityID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*BatchSchedule{}
+
+	for rows.Next() {
+		s, err := scanBatchSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// GetBatchSchedule retrieves a batch schedule owned by an identity.
+func (r *Repository) GetBatchSchedule(id, identityID int) (*BatchSchedule, error) {
+	return scanBatchSchedule(r.db.QueryRow(
+		"SELECT "+batchScheduleColumns+" FROM batch_schedules WHERE id = $1 AND identity_id = $2",
+		id, identityID,
+	))
+}
+
+// UpdateBatchSchedule replaces the editable fields of a batch schedule.
+func (r *Repository) UpdateBatchSchedule(s *BatchSchedule) (*BatchSchedule, error) {
+	candidateCVIDs, err := json.Marshal(s.CandidateCVIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	jobSource, err := json.Marshal(s.JobSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBatchSchedule(r.db.QueryRow(
+		`UPDATE batch_schedules
+		SET name = $1, cron_expr = $2, timezone = $3, candidate_cv_ids = $4, job_source = $5,
+			only_new_jobs = $6, active = $7, next_run_at = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9 AND identity_id = $10
+		RETURNING `+batchScheduleColumns,
+		s.Name, s.CronExpr, s.Timezone, candidateCVIDs, jobSource, s.OnlyNewJobs, s.Active, s.NextRunAt, s.ID, s.IdentityID,
+	))
+}
+
+// DeleteBatchSchedule removes a batch schedule owned by an identity. Jobs it
+// already created are kept. It returns sql.ErrNoRows if the schedule does not exist.
+func (r *Repository) DeleteBatchSchedule(id, identityID int) error {
+	res, err := r.db.Exec("DELETE FROM batch_schedules WHERE id = $1 AND identity_id = $2", id, identityID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// TriggerBatchSchedule makes an active schedule due immediately.
+// It returns sql.ErrNoRows if the schedule does not exist or is paused.
+func (r *Repository) TriggerBatchSchedule(id, identityID int) error {
+	res, err := r.db.Exec(
+		"UPDATE batch_schedules SET next_run_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND identity_id = $2 AND active",
+		id, identityID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimDueBatchSchedule atomically claims the most overdue active schedule and
+// leases it for the given duration. It returns nil when no schedule is due.
+func (r *Repository) ClaimDueBatchSchedule(lease time.Duration) (*BatchSchedule, error) {
+	s, err := scanBatchSchedule(r.db.QueryRow(`
+		UPDATE batch_schedules
+		SET locked_until = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second'
+		WHERE id = (
+			SELECT id FROM batch_schedules
+			WHERE active
+				AND next_run_at <= CURRENT_TIMESTAMP
+				AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+			ORDER BY next_run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+batchScheduleColumns,
+		lease.Seconds(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return s, err
+}
+
+// AdvanceBatchSchedule moves a claimed schedule to its next run and releases
+// the lease. A nil nextRunAt deactivates the schedule.
+func (r *Repository) AdvanceBatchSchedule(id int, nextRunAt *time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE batch_schedules
+		SET next_run_at = $1, active = active AND $1::TIMESTAMP IS NOT NULL,
+			last_run_at = CURRENT_TIMESTAMP, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`,
+		nextRunAt, id,
+	)
+
+	return err
+}
+
+// RecordBatchScheduleRun stores the outcome of a schedule run.
+func (r *Repository) RecordBatchScheduleRun(id int, jobID *int, lastError *string) error {
+	_, err := r.db.Exec(
+		"UPDATE batch_schedules SET last_job_id = COALESCE($1, last_job_id), last_error = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+		jobID, lastError, id,
+	)
+
+	return err
+}
+
+// GetSeenBatchScheduleJobs returns which of the given job description hashes
+// a schedule has already submitted.
+func (r *Repository) GetSeenBatchScheduleJobs(scheduleID int, hashes []string) (map[string]bool, error) {
+	rows, err := r.db.Query(
+		"SELECT job_hash FROM batch_schedule_seen_jobs WHERE schedule_id = $1 AND job_hash = ANY($2)",
+		scheduleID, pq.Array(hashes),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+
+		seen[hash] = true
+	}
+
+	return seen, rows.Err()
+}
+
+// MarkBatchScheduleJobsSeen records job description hashes as submitted by a schedule.
+func (r *Repository) MarkBatchScheduleJobsSeen(scheduleID int, hashes []string) error {
+	_, err := r.db.Exec(
+		"INSERT INTO batch_schedule_seen_jobs (schedule_id, job_hash) SELECT $1, UNNEST($2::VARCHAR[]) ON CONFLICT DO NOTHING",
+		scheduleID, pq.Array(hashes),
+	)
+
+	return err
+}
+
+// GetBatchJobsBySchedule retrieves the most recent batch jobs created by a schedule.
+func (r *Repository) GetBatchJobsBySchedule(scheduleID, limit int) ([]*BatchJob, error) {
+	rows, err := r.db.Query(
+		"SELECT id, identity_id, status, total_items, completed_items, COALESCE(attempts, 0), created_at, completed_at, updated_at FROM batch_jobs WHERE schedule_id = $1 ORDER BY created_at DESC LIMIT $2",
+		scheduleID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*BatchJob{}
+
+	for rows.Next() {
+		var job BatchJob
+		if err := rows.Scan(&job.ID, &job.IdentityID, &job.Status, &job.TotalItems, &job.CompletedItems, &job.Attempts, &job.CreatedAt, &job.CompletedAt, &job.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import "encoding/json"
+
+const coverLetterColumns = "id, cv_version_id, version, tone, length, language, company, hiring_manager, content, word_count, highlights, provider, created_at"
+
+// scanCoverLetter scans a row selected with coverLetterColumns.
+func scanCoverLetter(row interface{ Scan(...any) error }) (*CoverLetter, error) {
+	var (
+		l          CoverLetter
+		highlights []byte
+	)
+
+	if err := row.Scan(&l.ID, &l.CVVersionID, &l.Version, &l.Tone, &l.Length, &l.Language, &l.Company, &l.HiringManager, &l.Content, &l.WordCount, &highlights, &l.Provider, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal(highlights, &l.Highlights)
+
+	return &l, nil
+}
+
+// CreateCoverLetter stores a cover letter as the next version of the
+// letters of its CV version.
+func (r *Repository) CreateCoverLetter(l *CoverLetter) (*CoverLetter, error) {
+	return scanCoverLetter(r.db.QueryRow(
+		`INSERT INTO cover_letters (cv_version_id, version, tone, length, language, company, hiring_manager, content, word_count, highlights, provider)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9, $10 FROM cover_letters WHERE cv_version_id = $1
+		RETURNING `+coverLetterColumns,
+		l.CVVersionID, l.Tone, l.Length, l.Language, l.Company, l.HiringManager, l.Content, l.WordCount, jsonList(l.Highlights), l.Provider,
+	))
+}
+
+// GetCoverLetter retrieves a cover letter.
+func (r *Repository) GetCoverLetter(id int) (*CoverLetter, error) {
+	return scanCoverLetter(r.db.QueryRow("SELECT "+coverLetterColumns+" FROM cover_letters WHERE id = $1", id))
+}
+
+// ListCoverLetters retrieves the cover letters of a CV version, newest
+// first.
+func (r *Repository) ListCoverLetters(cvVersionID int) ([]*CoverLetter, error) {
+	rows, err := r.db.Query("SELECT "+coverLetterColumns+" FROM cover_letters WHERE cv_version_id = $1 ORDER BY version DESC", cvVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []*CoverLetter{}
+
+	for rows.Next() {
+		l, err := scanCoverLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		letters = append(letters, l)
+	}
+
+	return letters, rows.Err()
+}
@@ -173,6 +173,236 @@ func Migrations() *migrate.MemoryMigrationSource {
 					DROP TABLE IF EXISTS ats_analysis;
 				`},
 			},
+			{
+				Id: "004_durable_batch_queue",
+				Up: []string{`
+					-- Lease columns used by workers claiming jobs with FOR UPDATE SKIP LOCKED
+					ALTER TABLE batch_jobs ADD COLUMN IF NOT EXISTS locked_by VARCHAR(255);
+					ALTER TABLE batch_jobs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+					ALTER TABLE batch_jobs ADD COLUMN IF NOT EXISTS attempts INTEGER DEFAULT 0;
+
+					-- The repository has always read and written batch_job_items.result
+					DO $$
+					BEGIN
+						IF EXISTS (
+							SELECT 1 FROM information_schema.columns
+							WHERE table_name = 'batch_job_items' AND column_name = 'result_json'
+						) THEN
+							ALTER TABLE batch_job_items RENAME COLUMN result_json TO result;
+						END IF;
+					END $$;
+
+					-- Index for claiming the oldest pending job
+					CREATE INDEX IF NOT EXISTS idx_batch_jobs_pending
+					ON batch_jobs(created_at) WHERE status = 'pending';
+
+					-- Index for finding expired leases during recovery
+					CREATE INDEX IF NOT EXISTS idx_batch_jobs_lease
+					ON batch_jobs(locked_until) WHERE status = 'processing';
+				`},
+				Down: []string{`
+					DROP INDEX IF EXISTS idx_batch_jobs_lease;
+					DROP INDEX IF EXISTS idx_batch_jobs_pending;
+					ALTER TABLE batch_jobs DROP COLUMN IF EXISTS attempts;
+					ALTER TABLE batch_jobs DROP COLUMN IF EXISTS locked_until;
+					ALTER TABLE batch_jobs DROP COLUMN IF EXISTS locked_by;
+				`},
+			},
+			{
+				Id: "005_webhooks",
+				Up: []string{`
+					-- Endpoints registered by users to receive signed event notifications
+					CREATE TABLE IF NOT EXISTS webhook_endpoints (
+						id SERIAL PRIMARY KEY,
+						identity_id INTEGER NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+						url TEXT NOT NULL,
+						secret VARCHAR(255) NOT NULL,
+						event_types JSONB NOT NULL DEFAULT '[]',
+						active BOOLEAN NOT NULL DEFAULT TRUE,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					);
+					CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_identity ON webhook_endpoints(identity_id);
+
+					-- Outbox of events, written in the same request that produced them
+					CREATE TABLE IF NOT EXISTS webhook_events (
+						id SERIAL PRIMARY KEY,
+						identity_id INTEGER REFERENCES identities(id) ON DELETE CASCADE,
+						event_type VARCHAR(100) NOT NULL,
+						payload JSONB NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					);
+
+					-- One delivery per event and endpoint, retried with backoff
+					CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id SERIAL PRIMARY KEY,
+						event_id INTEGER NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+						endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+						status VARCHAR(50) NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						locked_until TIMESTAMP,
+						last_error TEXT,
+						delivered_at TIMESTAMP,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					);
+					CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+					ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+					CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at DESC);
+
+					-- Log of every delivery attempt
+					CREATE TABLE IF NOT EXISTS webhook_delivery_logs (
+						id SERIAL PRIMARY KEY,
+						delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+						attempt INTEGER NOT NULL,
+						status_code INTEGER,
+						response_body TEXT,
+						error TEXT,
+						duration_ms INTEGER,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					);
+					CREATE INDEX IF NOT EXISTS idx_webhook_delivery_logs_delivery ON webhook_delivery_logs(delivery_id);
+				`},
+				Down: []string{`
+					DROP TABLE IF EXISTS webhook_delivery_logs;
+					DROP TABLE IF EXISTS webhook_deliveries;
+					DROP TABLE IF EXISTS webhook_events;
+					DROP TABLE IF EXISTS webhook_endpoints;
+				`},
+			},
+			{
+				Id: "006_batch_schedules",
+				Up: []string{`
+					-- Recurring batch jobs built from a saved candidate set and job source
+					CREATE TABLE IF NOT EXISTS batch_schedules (
+						id SERIAL PRIMARY KEY,
+						identity_id INTEGER NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+						name VARCHAR(255) NOT NULL,
+						cron_expr VARCHAR(255) NOT NULL,
+						timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+						candidate_cv_ids JSONB NOT NULL DEFAULT '[]',
+						job_source JSONB NOT NULL DEFAULT '{}',
+						only_new_jobs BOOLEAN NOT NULL DEFAULT FALSE,
+						active BOOLEAN NOT NULL DEFAULT TRUE,
+						next_run_at TIMESTAMP,
+						last_run_at TIMESTAMP,
+						last_job_id INTEGER REFERENCES batch_jobs(id) ON DELETE SET NULL,
+						last_error TEXT,
+						locked_until TIMESTAMP,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					);
+					CREATE INDEX IF NOT EXISTS idx_batch_schedules_identity ON batch_schedules(identity_id);
+					CREATE INDEX IF NOT EXISTS idx_batch_schedules_due
+					ON batch_schedules(next_run_at) WHERE active;
+
+					-- Job descriptions already submitted by a schedule, for only_new_jobs
+					CREATE TABLE IF NOT EXISTS batch_schedule_seen_jobs (
+						schedule_id INTEGER NOT NULL REFERENCES batch_schedules(id) ON DELETE CASCADE,
+						job_hash VARCHAR(64) NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						PRIMARY KEY (schedule_id, job_hash)
+					);
+
+					-- Link batch jobs to the schedule that created them
+					ALTER TABLE batch_jobs ADD COLUMN IF NOT EXISTS schedule_id INTEGER REFERENCES batch_schedules(id) ON DELETE SET NULL;
+					CREATE INDEX IF NOT EXISTS idx_batch_jobs_schedule ON batch_jobs(schedule_id);
+				`},
+				Down: []string{`
+					DROP INDEX IF EXISTS idx_batch_jobs_schedule;
+					ALTER TABLE batch_jobs DROP COLUMN IF EXISTS schedule_id;
+					DROP TABLE IF EXISTS batch_schedule_seen_jobs;
+					DROP TABLE IF EXISTS batch_schedules;
+				`},
+			},
+			{
+				Id: "007_saved_jobs",
+				Up: []string{`
+					-- RSS and Atom feeds polled for job postings
+					CREATE TABLE IF NOT EXISTS job_feeds (
+						id SERIAL PRIMARY KEY,
+						identity_id INTEGER NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+						url TEXT NOT NULL,
+						title VARCHAR(500),
+						poll_interval_minutes INTEGER NOT NULL DEFAULT 60,
+						active BOOLEAN NOT NULL DEFAULT TRUE,
+						next_poll_at TIMESTAMP,
+						last_polled_at TIMESTAMP,
+						last_new_jobs INTEGER NOT NULL DEFAULT 0,
+						last_error TEXT,
+						locked_until TIMESTAMP,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						UNIQUE (identity_id, url)
+					);
+					CREATE INDEX IF NOT EXISTS idx_job_feeds_due ON job_feeds(next_poll_at) WHERE active;
+
+					-- Job postings collected from emails and feeds
+					CREATE TABLE IF NOT EXISTS saved_jobs (
+						id SERIAL PRIMARY KEY,
+						identity_id INTEGER NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+						origin VARCHAR(20) NOT NULL,
+						feed_id INTEGER REFERENCES job_feeds(id) ON DELETE SET NULL,
+						external_id TEXT NOT NULL,
+						url TEXT,
+						title VARCHAR(500) NOT NULL DEFAULT '',
+						company VARCHAR(255) NOT NULL DEFAULT '',
+						location VARCHAR(255) NOT NULL DEFAULT '',
+						salary VARCHAR(255) NOT NULL DEFAULT '',
+						employment_type VARCHAR(100) NOT NULL DEFAULT '',
+						extracted_by VARCHAR(50) NOT NULL DEFAULT '',
+						description TEXT NOT NULL,
+						requirements JSONB NOT NULL DEFAULT '[]',
+						responsibilities JSONB NOT NULL DEFAULT '[]',
+						preferred_skills JSONB NOT NULL DEFAULT '[]',
+						posted_at TIMESTAMP,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						UNIQUE (identity_id, external_id)
+					);
+					CREATE INDEX IF NOT EXISTS idx_saved_jobs_identity ON saved_jobs(identity_id, created_at DESC);
+					CREATE INDEX IF NOT EXISTS idx_saved_jobs_feed ON saved_jobs(feed_id);
+				`},
+				Down: []string{`
+					DROP TABLE IF EXISTS saved_jobs;
+					DROP TABLE IF EXISTS job_feeds;
+				`},
+			},
+			{
+				Id: "008_ats_profiles",
+				Up: []string{`
+					-- ATS vendor profile each analysis simulated
+					ALTER TABLE ats_analysis ADD COLUMN IF NOT EXISTS ats_profile VARCHAR(50) NOT NULL DEFAULT 'generic';
+				`},
+				Down: []string{`
+					ALTER TABLE ats_analysis DROP COLUMN IF EXISTS ats_profile;
+				`},
+			},
+			{
+				Id: "009_cover_letters",
+				Up: []string{`
+					-- Cover letters written for CV versions, numbered per version
+					CREATE TABLE IF NOT EXISTS cover_letters (
+						id SERIAL PRIMARY KEY,
+						cv_version_id INTEGER NOT NULL REFERENCES cv_versions(id) ON DELETE CASCADE,
+						version INTEGER NOT NULL,
+						tone VARCHAR(50) NOT NULL,
+						length VARCHAR(20) NOT NULL,
+						language VARCHAR(10) NOT NULL,
+						company VARCHAR(255) NOT NULL DEFAULT '',
+						hiring_manager VARCHAR(255) NOT NULL DEFAULT '',
+						content TEXT NOT NULL,
+						word_count INTEGER NOT NULL DEFAULT 0,
+						highlights JSONB NOT NULL DEFAULT '[]',
+						provider VARCHAR(50) NOT NULL DEFAULT '',
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						UNIQUE (cv_version_id, version)
+					);
+				`},
+				Down: []string{`
+					DROP TABLE IF EXISTS cover_letters;
+				`},
+			},
 		},
 	}
 }
@@ -47,6 +47,7 @@ type BatchJob struct {
 	Status         string     `json:"status"` // pending, processing, completed, failed
 	TotalItems     int        `json:"total_items"`
 	CompletedItems int        `json:"completed_items"`
+	Attempts       int        `json:"attempts"`
 	CreatedAt      time.Time  `json:"created_at"`
 	CompletedAt    *time.Time `json:"completed_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
@@ -79,6 +80,7 @@ type AnalyticsSnapshot struct {
 type ATSAnalysis struct {
 	ID                  int              `json:"id"`
 	CVVersionID         int              `json:"cv_version_id"`
+	ATSProfile          string           `json:"ats_profile"`
 	OverallScore        *float64         `json:"overall_score"`
 	KeywordMatches      *json.RawMessage `json:"keyword_matches"`
 	FormattingIssues    *json.RawMessage `json:"formatting_issues"`
@@ -100,6 +102,136 @@ type LinkedInImport struct {
 	UpdatedAt    time.Time        `json:"updated_at"`
 }
 
+// ScheduleJobSource is the saved query a schedule resolves to job descriptions
+// on every run.
+type ScheduleJobSource struct {
+	URLs            []string `json:"urls,omitempty"`
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+}
+
+// BatchSchedule represents a recurring batch job.
+type BatchSchedule struct {
+	ID             int               `json:"id"`
+	IdentityID     int               `json:"identity_id"`
+	Name           string            `json:"name"`
+	CronExpr       string            `json:"cron"`
+	Timezone       string            `json:"timezone"`
+	CandidateCVIDs []int             `json:"candidate_cv_ids"`
+	JobSource      ScheduleJobSource `json:"job_source"`
+	OnlyNewJobs    bool              `json:"only_new_jobs"`
+	Active         bool              `json:"active"`
+	NextRunAt      *time.Time        `json:"next_run_at"`
+	LastRunAt      *time.Time        `json:"last_run_at"`
+	LastJobID      *int              `json:"last_job_id"`
+	LastError      *string           `json:"last_error"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// JobFeed is an RSS or Atom feed polled for job postings.
+type JobFeed struct {
+	ID                  int        `json:"id"`
+	IdentityID          int        `json:"identity_id"`
+	URL                 string     `json:"url"`
+	Title               *string    `json:"title"`
+	PollIntervalMinutes int        `json:"poll_interval_minutes"`
+	Active              bool       `json:"active"`
+	NextPollAt          *time.Time `json:"next_poll_at"`
+	LastPolledAt        *time.Time `json:"last_polled_at"`
+	LastNewJobs         int        `json:"last_new_jobs"`
+	LastError           *string    `json:"last_error"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// SavedJob is a job posting collected from an email or a feed.
+type SavedJob struct {
+	ID               int        `json:"id"`
+	IdentityID       int        `json:"identity_id"`
+	Origin           string     `json:"origin"` // email, feed
+	FeedID           *int       `json:"feed_id,omitempty"`
+	ExternalID       string     `json:"external_id"`
+	URL              *string    `json:"url"`
+	Title            string     `json:"title"`
+	Company          string     `json:"company"`
+	Location         string     `json:"location"`
+	Salary           string     `json:"salary,omitempty"`
+	EmploymentType   string     `json:"employment_type,omitempty"`
+	ExtractedBy      string     `json:"extracted_by,omitempty"` // How the posting was read, e.g. "json-ld"
+	Description      string     `json:"description"`
+	Requirements     []string   `json:"requirements"`
+	Responsibilities []string   `json:"responsibilities"`
+	PreferredSkills  []string   `json:"preferred_skills"`
+	PostedAt         *time.Time `json:"posted_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// CoverLetter is a cover letter written for a CV version. The letters of a
+// version are numbered from 1, like the versions of a CV.
+type CoverLetter struct {
+	ID            int       `json:"id"`
+	CVVersionID   int       `json:"cv_version_id"`
+	Version       int       `json:"version"`
+	Tone          string    `json:"tone"`
+	Length        string    `json:"length"`
+	Language      string    `json:"language"`
+	Company       string    `json:"company,omitempty"`
+	HiringManager string    `json:"hiring_manager,omitempty"`
+	Content       string    `json:"content"`
+	WordCount     int       `json:"word_count"`
+	Highlights    []string  `json:"highlights"` // Requirements of the job the letter was asked to cite
+	Provider      string    `json:"provider"`   // LLM provider that wrote the letter
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// WebhookEndpoint represents a user-registered webhook receiver.
+type WebhookEndpoint struct {
+	ID         int       `json:"id"`
+	IdentityID int       `json:"identity_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookEvent represents an event stored in the webhook outbox.
+type WebhookEvent struct {
+	ID         int             `json:"id"`
+	IdentityID *int            `json:"identity_id"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// WebhookDelivery represents the delivery of one event to one endpoint.
+type WebhookDelivery struct {
+	ID            int        `json:"id"`
+	EventID       int        `json:"event_id"`
+	EndpointID    int        `json:"endpoint_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"` // pending, delivered, failed
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryLog records a single delivery attempt.
+type WebhookDeliveryLog struct {
+	ID           int       `json:"id"`
+	DeliveryID   int       `json:"delivery_id"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   *int      `json:"status_code"`
+	ResponseBody *string   `json:"response_body"`
+	Error        *string   `json:"error"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 // Repository defines database operations.
 type Repository struct {
 	db *sql.DB
@@ -246,9 +378,9 @@ func (r *Repository) GetBatchJob(id int) (*BatchJob, error) {
 	var job BatchJob
 
 	err := r.db.QueryRow(
-		"SELECT id, identity_id, status, total_items, completed_items, created_at, completed_at, updated_at FROM batch_jobs WHERE id = $1",
+		"SELECT id, identity_id, status, total_items, completed_items, COALESCE(attempts, 0), created_at, completed_at, updated_at FROM batch_jobs WHERE id = $1",
 		id,
-	).Scan(&job.ID, &job.IdentityID, &job.Status, &job.TotalItems, &job.CompletedItems, &job.CreatedAt, &job.CompletedAt, &job.UpdatedAt)
+	).Scan(&job.ID, &job.IdentityID, &job.Status, &job.TotalItems, &job.CompletedItems, &job.Attempts, &job.CreatedAt, &job.CompletedAt, &job.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +395,7 @@ func (r *Repository) UpdateBatchJobStatus(id int, status string, completedItems
 	args = append(args, status, completedItems)
 
 	if status == "completed" || status == "failed" {
-		query += ", completed_at = CURRENT_TIMESTAMP"
+		query += ", completed_at = CURRENT_TIMESTAMP, locked_by = NULL, locked_until = NULL"
 	}
 
 	query += " WHERE id = $3"
@@ -5,16 +5,18 @@ package db
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
-// CreateATSAnalysis creates a new ATS analysis record.
-func (r *Repository) CreateATSAnalysis(cvVersionID int, overallScore *float64, keywordMatches *json.RawMessage, formattingIssues *json.RawMessage, sectionCompleteness *json.RawMessage, recommendations *json.RawMessage) (*ATSAnalysis, error) {
+// CreateATSAnalysis creates a new ATS analysis record for the ATS profile it
+// simulated.
+func (r *Repository) CreateATSAnalysis(cvVersionID int, atsProfile string, overallScore *float64, keywordMatches *json.RawMessage, formattingIssues *json.RawMessage, sectionCompleteness *json.RawMessage, recommendations *json.RawMessage) (*ATSAnalysis, error) {
 	var id int
 
 	err := r.db.QueryRow(
-		"INSERT INTO ats_analysis (cv_version_id, overall_score, keyword_matches, formatting_issues, section_completeness, recommendations) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		cvVersionID, overallScore, keywordMatches, formattingIssues, sectionCompleteness, recommendations,
+		"INSERT INTO ats_analysis (cv_version_id, ats_profile, overall_score, keyword_matches, formatting_issues, section_completeness, recommendations) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		cvVersionID, atsProfile, overallScore, keywordMatches, formattingIssues, sectionCompleteness, recommendations,
 	).Scan(&id)
 	if err != nil {
 		return nil, err
@@ -23,6 +25,7 @@ func (r *Repository) CreateATSAnalysis(cvVersionID int, overallScore *float64, k
 	return &ATSAnalysis{
 		ID:                  id,
 		CVVersionID:         cvVersionID,
+		ATSProfile:          atsProfile,
 		OverallScore:        overallScore,
 		KeywordMatches:      keywordMatches,
 		FormattingIssues:    formattingIssues,
@@ -32,14 +35,14 @@ func (r *Repository) CreateATSAnalysis(cvVersionID int, overallScore *float64, k
 	}, nil
 }
 
-// GetATSAnalysis retrieves an ATS analysis by CV version ID.
-func (r *Repository) GetATSAnalysis(cvVersionID int) (*ATSAnalysis, error) {
+// atsAnalysisColumns are the columns scanned by scanATSAnalysis.
+const atsAnalysisColumns = "a.id, a.cv_version_id, a.ats_profile, a.overall_score, a.keyword_matches, a.formatting_issues, a.section_completeness, a.recommendations, a.created_at"
+
+// scanATSAnalysis scans a row of atsAnalysisColumns.
+func scanATSAnalysis(row interface{ Scan(...any) error }) (*ATSAnalysis, error) {
 	var analysis ATSAnalysis
 
-	err := r.db.QueryRow(
-		"SELECT id, cv_version_id, overall_score, keyword_matches, formatting_issues, section_completeness, recommendations, created_at FROM ats_analysis WHERE cv_version_id = $1",
-		cvVersionID,
-	).Scan(&analysis.ID, &analysis.CVVersionID, &analysis.OverallScore, &analysis.KeywordMatches, &analysis.FormattingIssues, &analysis.SectionCompleteness, &analysis.Recommendations, &analysis.CreatedAt)
+	err := row.Scan(&analysis.ID, &analysis.CVVersionID, &analysis.ATSProfile, &analysis.OverallScore, &analysis.KeywordMatches, &analysis.FormattingIssues, &analysis.SectionCompleteness, &analysis.Recommendations, &analysis.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +50,76 @@ func (r *Repository) GetATSAnalysis(cvVersionID int) (*ATSAnalysis, error) {
 	return &analysis, nil
 }
 
+// GetATSAnalysis retrieves the latest ATS analysis of a CV version.
+func (r *Repository) GetATSAnalysis(cvVersionID int) (*ATSAnalysis, error) {
+	return scanATSAnalysis(r.db.QueryRow(
+		"SELECT "+atsAnalysisColumns+" FROM ats_analysis a WHERE a.cv_version_id = $1 ORDER BY a.created_at DESC, a.id DESC LIMIT 1",
+		cvVersionID,
+	))
+}
+
+// ListATSAnalyses retrieves every ATS analysis of a CV version, oldest first.
+func (r *Repository) ListATSAnalyses(cvVersionID int) ([]*ATSAnalysis, error) {
+	return r.queryATSAnalyses(
+		"SELECT "+atsAnalysisColumns+" FROM ats_analysis a WHERE a.cv_version_id = $1 ORDER BY a.created_at, a.id",
+		cvVersionID,
+	)
+}
+
+// ListCVATSAnalyses retrieves every ATS analysis of every version of a CV,
+// oldest first.
+func (r *Repository) ListCVATSAnalyses(cvID int) ([]*ATSAnalysis, error) {
+	return r.queryATSAnalyses(
+		"SELECT "+atsAnalysisColumns+" FROM ats_analysis a JOIN cv_versions v ON v.id = a.cv_version_id WHERE v.cv_id = $1 ORDER BY a.created_at, a.id",
+		cvID,
+	)
+}
+
+// RecentATSAnalyses retrieves the latest ATS analyses, newest first, of the
+// CVs of an identity or, when identityID is nil, of everyone.
+func (r *Repository) RecentATSAnalyses(identityID *int, limit int) ([]*ATSAnalysis, error) {
+	query := "SELECT " + atsAnalysisColumns + " FROM ats_analysis a"
+
+	var args []any
+
+	if identityID != nil {
+		query += " JOIN cv_versions v ON v.id = a.cv_version_id JOIN cvs c ON c.id = v.cv_id WHERE c.identity_id = $1"
+
+		args = append(args, *identityID)
+	}
+
+	query += " ORDER BY a.created_at DESC, a.id DESC"
+
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return r.queryATSAnalyses(query, args...)
+}
+
+// queryATSAnalyses runs a query of atsAnalysisColumns.
+func (r *Repository) queryATSAnalyses(query string, args ...any) ([]*ATSAnalysis, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var analyses []*ATSAnalysis
+
+	for rows.Next() {
+		analysis, err := scanATSAnalysis(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		analyses = append(analyses, analysis)
+	}
+
+	return analyses, rows.Err()
+}
+
 // CreateLinkedInImport creates a new LinkedIn import record.
 func (r *Repository) CreateLinkedInImport(identityID *int, linkedinURL string) (*LinkedInImport, error) {
 	var id int
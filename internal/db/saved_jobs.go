@@ -0,0 +1,312 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrFeedExists is returned when an identity registers a feed URL twice.
+var ErrFeedExists = errors.New("feed already registered")
+
+const savedJobColumns = "id, identity_id, origin, feed_id, external_id, url, title, company, location, salary, employment_type, extracted_by, description, requirements, responsibilities, preferred_skills, posted_at, created_at"
+
+const jobFeedColumns = "id, identity_id, url, title, poll_interval_minutes, active, next_poll_at, last_polled_at, last_new_jobs, last_error, created_at, updated_at"
+
+// scanSavedJob scans a row selected with savedJobColumns.
+func scanSavedJob(row interface{ Scan(...any) error }) (*SavedJob, error) {
+	var (
+		j                                               SavedJob
+		requirements, responsibilities, preferredSkills []byte
+	)
+
+	if err := row.Scan(&j.ID, &j.IdentityID, &j.Origin, &j.FeedID, &j.ExternalID, &j.URL, &j.Title, &j.Company, &j.Location, &j.Salary, &j.EmploymentType, &j.ExtractedBy, &j.Description, &requirements, &responsibilities, &preferredSkills, &j.PostedAt, &j.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal(requirements, &j.Requirements)
+	_ = json.Unmarshal(responsibilities, &j.Responsibilities)
+	_ = json.Unmarshal(preferredSkills, &j.PreferredSkills)
+
+	return &j, nil
+}
+
+// scanJobFeed scans a row selected with jobFeedColumns.
+func scanJobFeed(row interface{ Scan(...any) error }) (*JobFeed, error) {
+	var f JobFeed
+
+	if err := row.Scan(&f.ID, &f.IdentityID, &f.URL, &f.Title, &f.PollIntervalMinutes, &f.Active, &f.NextPollAt, &f.LastPolledAt, &f.LastNewJobs, &f.LastError, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// jsonList marshals a list, storing nil as an empty JSON array.
+func jsonList(list []string) []byte {
+	if list == nil {
+		return []byte("[]")
+	}
+
+	data, _ := json.Marshal(list)
+
+	return data
+}
+
+// CreateSavedJobs stores job postings, skipping those an identity already
+// saved under the same external ID. It returns the postings that were new.
+func (r *Repository) CreateSavedJobs(jobs []*SavedJob) ([]*SavedJob, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created := []*SavedJob{}
+
+	for _, j := range jobs {
+		saved, err := scanSavedJob(tx.QueryRow(
+			`INSERT INTO saved_jobs (identity_id, origin, feed_id, external_id, url, title, company, location, salary, employment_type, extracted_by, description, requirements, responsibilities, preferred_skills, posted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			ON CONFLICT (identity_id, external_id) DO NOTHING
+			RETURNING `+savedJobColumns,
+			j.IdentityID, j.Origin, j.FeedID, j.ExternalID, j.URL, j.Title, j.Company, j.Location, j.Salary, j.EmploymentType, j.ExtractedBy, j.Description,
+			jsonList(j.Requirements), jsonList(j.Responsibilities), jsonList(j.PreferredSkills), j.PostedAt,
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, saved)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetSavedJobs retrieves the most recent saved jobs of an identity, newest
+// first, optionally only those of one feed.
+func (r *Repository) GetSavedJobs(identityID int, feedID *int, limit, offset int) ([]*SavedJob, error) {
+	rows, err := r.db.Query(
+		"SELECT "+savedJobColumns+" FROM saved_jobs WHERE identity_id = $1 AND ($2::INTEGER IS NULL OR feed_id = $2) ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4",
+		identityID, feedID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*SavedJob{}
+
+	for rows.Next() {
+		j, err := scanSavedJob(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
+
+// GetSavedJob retrieves a saved job owned by an identity.
+func (r *Repository) GetSavedJob(id, identityID int) (*SavedJob, error) {
+	return scanSavedJob(r.db.QueryRow(
+		"SELECT "+savedJobColumns+" FROM saved_jobs WHERE id = $1 AND identity_id = $2",
+		id, identityID,
+	))
+}
+
+// DeleteSavedJob removes a saved job owned by an identity. It returns
+// sql.ErrNoRows if the saved job does not exist.
+func (r *Repository) DeleteSavedJob(id, identityID int) error {
+	res, err := r.db.Exec("DELETE FROM saved_jobs WHERE id = $1 AND identity_id = $2", id, identityID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetSavedJobExternalIDs returns which of the given external IDs an identity
+// has already saved.
+func (r *Repository) GetSavedJobExternalIDs(identityID int, externalIDs []string) (map[string]bool, error) {
+	rows, err := r.db.Query(
+		"SELECT external_id FROM saved_jobs WHERE identity_id = $1 AND external_id = ANY($2)",
+		identityID, pq.Array(externalIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := make(map[string]bool)
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		saved[id] = true
+	}
+
+	return saved, rows.Err()
+}
+
+// CreateJobFeed registers a feed for an identity, due to be polled at once.
+// It returns ErrFeedExists if the identity already registered the URL.
+func (r *Repository) CreateJobFeed(f *JobFeed) (*JobFeed, error) {
+	created, err := scanJobFeed(r.db.QueryRow(
+		`INSERT INTO job_feeds (identity_id, url, poll_interval_minutes, active, next_poll_at)
+		VALUES ($1, $2, $3, $4, CASE WHEN $4 THEN CURRENT_TIMESTAMP END)
+		ON CONFLICT (identity_id, url) DO NOTHING
+		RETURNING `+jobFeedColumns,
+		f.IdentityID, f.URL, f.PollIntervalMinutes, f.Active,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedExists
+	}
+
+	return created, err
+}
+
+// GetJobFeeds retrieves all feeds of an identity.
+func (r *Repository) GetJobFeeds(identityID int) ([]*JobFeed, error) {
+	rows, err := r.db.Query(
+		"SELECT "+jobFeedColumns+" FROM job_feeds WHERE identity_id = $1 ORDER BY created_at DESC",
+		identityID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*JobFeed{}
+
+	for rows.Next() {
+		f, err := scanJobFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		feeds = append(feeds, f)
+	}
+
+	return feeds, rows.Err()
+}
+
+// GetJobFeed retrieves a feed owned by an identity.
+func (r *Repository) GetJobFeed(id, identityID int) (*JobFeed, error) {
+	return scanJobFeed(r.db.QueryRow(
+		"SELECT "+jobFeedColumns+" FROM job_feeds WHERE id = $1 AND identity_id = $2",
+		id, identityID,
+	))
+}
+
+// DeleteJobFeed removes a feed owned by an identity. Jobs it already saved
+// are kept. It returns sql.ErrNoRows if the feed does not exist.
+func (r *Repository) DeleteJobFeed(id, identityID int) error {
+	res, err := r.db.Exec("DELETE FROM job_feeds WHERE id = $1 AND identity_id = $2", id, identityID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// TriggerJobFeed makes an active feed due for polling immediately.
+// It returns sql.ErrNoRows if the feed does not exist or is paused.
+func (r *Repository) TriggerJobFeed(id, identityID int) error {
+	res, err := r.db.Exec(
+		"UPDATE job_feeds SET next_poll_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND identity_id = $2 AND active",
+		id, identityID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimDueJobFeed atomically claims the most overdue active feed and leases
+// it for the given duration. It returns nil when no feed is due.
+func (r *Repository) ClaimDueJobFeed(lease time.Duration) (*JobFeed, error) {
+	f, err := scanJobFeed(r.db.QueryRow(`
+		UPDATE job_feeds
+		SET locked_until = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second'
+		WHERE id = (
+			SELECT id FROM job_feeds
+			WHERE active
+				AND next_poll_at <= CURRENT_TIMESTAMP
+				AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+			ORDER BY next_poll_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobFeedColumns,
+		lease.Seconds(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return f, err
+}
+
+// RecordJobFeedPoll stores the outcome of a poll, schedules the next one
+// and releases the lease. An empty title keeps the known one.
+func (r *Repository) RecordJobFeedPoll(id int, title string, newJobs int, lastError *string) error {
+	_, err := r.db.Exec(`
+		UPDATE job_feeds
+		SET title = COALESCE(NULLIF($1, ''), title), last_new_jobs = $2, last_error = $3,
+			last_polled_at = CURRENT_TIMESTAMP,
+			next_poll_at = CURRENT_TIMESTAMP + poll_interval_minutes * INTERVAL '1 minute',
+			locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4`,
+		title, newJobs, lastError, id,
+	)
+
+	return err
+}
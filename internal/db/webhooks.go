@@ -0,0 +1,342 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// PendingWebhookDelivery is a claimed delivery together with the event and
+// endpoint data needed to send it.
+type PendingWebhookDelivery struct {
+	Delivery WebhookDelivery
+	Event    WebhookEvent
+	URL      string
+	Secret   string
+}
+
+// CreateWebhookEndpoint registers a webhook endpoint for an identity.
+func (r *Repository) CreateWebhookEndpoint(identityID int, url, secret string, eventTypes []string) (*WebhookEndpoint, error) {
+	eventTypesJSON, err := json.Marshal(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := WebhookEndpoint{
+		IdentityID: identityID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+
+	err = r.db.QueryRow(
+		"INSERT INTO webhook_endpoints (identity_id, url, secret, event_types) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at",
+		identityID, url, secret, eventTypesJSON,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// GetWebhookEndpoints retrieves all webhook endpoints of an identity.
+func (r *Repository) GetWebhookEndpoints(identityID int) ([]*WebhookEndpoint, error) {
+	rows, err := r.db.Query(
+		"SELECT id, identity_id, url, secret, event_types, active, created_at, updated_at FROM webhook_endpoints WHERE identity_id = $1 ORDER BY created_at DESC",
+		identityID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*WebhookEndpoint{}
+
+	for rows.Next() {
+		var (
+			endpoint   WebhookEndpoint
+			eventTypes []byte
+		)
+
+		if err := rows.Scan(&endpoint.ID, &endpoint.IdentityID, &endpoint.URL, &endpoint.Secret, &eventTypes, &endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		_ = json.Unmarshal(eventTypes, &endpoint.EventTypes)
+		endpoints = append(endpoints, &endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// GetWebhookEndpoint retrieves a webhook endpoint owned by an identity.
+func (r *Repository) GetWebhookEndpoint(id, identityID int) (*WebhookEndpoint, error) {
+	var (
+		endpoint   WebhookEndpoint
+		eventTypes []byte
+	)
+
+	err := r.db.QueryRow(
+		"SELECT id, identity_id, url, secret, event_types, active, created_at, updated_at FROM webhook_endpoints WHERE id = $1 AND identity_id = $2",
+		id, identityID,
+	).Scan(&endpoint.ID, &endpoint.IdentityID, &endpoint.URL, &endpoint.Secret, &eventTypes, &endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal(eventTypes, &endpoint.EventTypes)
+
+	return &endpoint, nil
+}
+
+// DeleteWebhookEndpoint removes a webhook endpoint owned by an identity, along
+// with its deliveries. It returns sql.ErrNoRows if the endpoint does not exist.
+func (r *Repository) DeleteWebhookEndpoint(id, identityID int) error {
+	res, err := r.db.Exec("DELETE FROM webhook_endpoints WHERE id = $1 AND identity_id = $2", id, identityID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CreateWebhookEvent stores an event in the outbox and creates a pending
+// delivery for every active endpoint of the identity subscribed to the event
+// type. Endpoints with no event types receive every event. It returns the
+// number of deliveries created.
+func (r *Repository) CreateWebhookEvent(identityID int, eventType string, payload json.RawMessage) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var eventID int
+
+	err = tx.QueryRow(
+		"INSERT INTO webhook_events (identity_id, event_type, payload) VALUES ($1, $2, $3) RETURNING id",
+		identityID, eventType, []byte(payload),
+	).Scan(&eventID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO webhook_deliveries (event_id, endpoint_id, status, next_attempt_at)
+		SELECT $1, id, 'pending', CURRENT_TIMESTAMP
+		FROM webhook_endpoints
+		WHERE identity_id = $2 AND active
+			AND (jsonb_array_length(event_types) = 0 OR event_types ? $3)`,
+		eventID, identityID, eventType,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+// ClaimWebhookDeliveries atomically claims up to limit due deliveries and leases
+// them for the given duration, incrementing their attempt counters.
+func (r *Repository) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]*PendingWebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+				locked_until = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second',
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending'
+					AND next_attempt_at <= CURRENT_TIMESTAMP
+					AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_id, endpoint_id, status, attempts, created_at, updated_at
+		)
+		SELECT c.id, c.event_id, c.endpoint_id, c.status, c.attempts, c.created_at, c.updated_at,
+			e.identity_id, e.event_type, e.payload, e.created_at, ep.url, ep.secret
+		FROM claimed c
+		JOIN webhook_events e ON e.id = c.event_id
+		JOIN webhook_endpoints ep ON ep.id = c.endpoint_id`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []*PendingWebhookDelivery
+
+	for rows.Next() {
+		var (
+			p       PendingWebhookDelivery
+			payload []byte
+		)
+
+		if err := rows.Scan(
+			&p.Delivery.ID, &p.Delivery.EventID, &p.Delivery.EndpointID, &p.Delivery.Status, &p.Delivery.Attempts, &p.Delivery.CreatedAt, &p.Delivery.UpdatedAt,
+			&p.Event.IdentityID, &p.Event.EventType, &payload, &p.Event.CreatedAt, &p.URL, &p.Secret,
+		); err != nil {
+			return nil, err
+		}
+
+		p.Event.ID = p.Delivery.EventID
+		p.Event.Payload = payload
+		p.Delivery.EventType = p.Event.EventType
+		pending = append(pending, &p)
+	}
+
+	return pending, rows.Err()
+}
+
+// RecordWebhookAttempt logs a delivery attempt and moves the delivery to its
+// next state. A status of "pending" schedules another attempt at nextAttemptAt.
+func (r *Repository) RecordWebhookAttempt(entry *WebhookDeliveryLog, status string, nextAttemptAt *time.Time, lastError *string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(
+		"INSERT INTO webhook_delivery_logs (delivery_id, attempt, status_code, response_body, error, duration_ms) VALUES ($1, $2, $3, $4, $5, $6)",
+		entry.DeliveryID, entry.Attempt, entry.StatusCode, entry.ResponseBody, entry.Error, entry.DurationMs,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $1::VARCHAR,
+			next_attempt_at = $2,
+			last_error = $3,
+			locked_until = NULL,
+			delivered_at = CASE WHEN $1::VARCHAR = 'delivered' THEN CURRENT_TIMESTAMP ELSE delivered_at END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4`,
+		status, nextAttemptAt, lastError, entry.DeliveryID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetWebhookDeliveries retrieves the most recent deliveries of an endpoint.
+func (r *Repository) GetWebhookDeliveries(endpointID, limit int) ([]*WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		SELECT d.id, d.event_id, d.endpoint_id, e.event_type, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at
+		FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id
+		WHERE d.endpoint_id = $1
+		ORDER BY d.created_at DESC
+		LIMIT $2`,
+		endpointID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*WebhookDelivery{}
+
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.EventID, &d.EndpointID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// GetWebhookDelivery retrieves a delivery of an endpoint.
+func (r *Repository) GetWebhookDelivery(id, endpointID int) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+
+	err := r.db.QueryRow(`
+		SELECT d.id, d.event_id, d.endpoint_id, e.event_type, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at
+		FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id
+		WHERE d.id = $1 AND d.endpoint_id = $2`,
+		id, endpointID,
+	).Scan(&d.ID, &d.EventID, &d.EndpointID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// GetWebhookDeliveryLogs retrieves the attempt log of a delivery.
+func (r *Repository) GetWebhookDeliveryLogs(deliveryID int) ([]*WebhookDeliveryLog, error) {
+	rows, err := r.db.Query(
+		"SELECT id, delivery_id, attempt, status_code, response_body, error, COALESCE(duration_ms, 0), created_at FROM webhook_delivery_logs WHERE delivery_id = $1 ORDER BY attempt, id",
+		deliveryID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*WebhookDeliveryLog{}
+
+	for rows.Next() {
+		var l WebhookDeliveryLog
+		if err := rows.Scan(&l.ID, &l.DeliveryID, &l.Attempt, &l.StatusCode, &l.ResponseBody, &l.Error, &l.DurationMs, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, &l)
+	}
+
+	return logs, rows.Err()
+}
+
+// ReplayWebhookDelivery creates a new pending delivery of the same event to the
+// same endpoint. It returns sql.ErrNoRows if the delivery does not exist.
+func (r *Repository) ReplayWebhookDelivery(id, endpointID int) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+
+	err := r.db.QueryRow(`
+		INSERT INTO webhook_deliveries (event_id, endpoint_id, status, next_attempt_at)
+		SELECT event_id, endpoint_id, 'pending', CURRENT_TIMESTAMP
+		FROM webhook_deliveries
+		WHERE id = $1 AND endpoint_id = $2
+		RETURNING id, event_id, endpoint_id, status, attempts, next_attempt_at, created_at, updated_at`,
+		id, endpointID,
+	).Scan(&d.ID, &d.EventID, &d.EndpointID, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const relsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// Write renders plain CV text as a DOCX document. Every line becomes a
+// paragraph; lines that look like section headings are rendered in bold.
+func Write(w io.Writer, text string) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", relsXML},
+		{"word/document.xml", documentXML(text)},
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// documentXML builds word/document.xml for the given text.
+func documentXML(text string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+
+		sb.WriteString("<w:p><w:r>")
+
+		if isHeading(line) {
+			sb.WriteString("<w:rPr><w:b/></w:rPr>")
+		}
+
+		sb.WriteString(`<w:t xml:space="preserve">`)
+		_ = xml.EscapeText(&sb, []byte(line))
+		sb.WriteString("</w:t></w:r></w:p>")
+	}
+
+	sb.WriteString("</w:body></w:document>")
+
+	return sb.String()
+}
+
+// isHeading reports whether a line looks like a CV section heading.
+func isHeading(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || len(trimmed) > 60 {
+		return false
+	}
+
+	if strings.HasSuffix(trimmed, ":") {
+		return true
+	}
+
+	return trimmed == strings.ToUpper(trimmed) && strings.ToLower(trimmed) != trimmed
+}
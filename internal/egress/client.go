@@ -0,0 +1,286 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package egress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsTTL is how long a host's robots.txt is cached.
+	robotsTTL = time.Hour
+	// maxRobotsSize bounds robots.txt files; RFC 9309 requires at least 500 KiB
+	// to be read.
+	maxRobotsSize = 512 * 1024
+	// maxTrackedHosts bounds the per-host state kept for pacing and robots.txt.
+	maxTrackedHosts = 1024
+)
+
+// Client fetches URLs under a policy. It is safe for concurrent use; share
+// one client so that pacing and robots.txt caching apply across callers.
+type Client struct {
+	policy *Policy
+	http   *http.Client
+
+	mu     sync.Mutex
+	next   map[string]time.Time // Earliest time of the next request to a host
+	robots map[string]*robotsEntry
+}
+
+// robotsEntry is the cached robots.txt of a host.
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+// NewClient creates a client for a policy. A nil policy is the default one.
+func NewClient(policy *Policy, timeout time.Duration) *Client {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+
+	return &Client{
+		policy: policy,
+		http: &http.Client{
+			Transport:     policy.Transport(),
+			CheckRedirect: policy.CheckRedirect,
+			Timeout:       timeout,
+		},
+		next:   map[string]time.Time{},
+		robots: map[string]*robotsEntry{},
+	}
+}
+
+// Get fetches a web page, honouring robots.txt. When contentTypes are given,
+// the response must be of one of them; "text/*" matches any text type.
+func (c *Client) Get(ctx context.Context, rawURL string, contentTypes ...string) ([]byte, error) {
+	return c.get(ctx, rawURL, c.policy.RespectRobots, contentTypes)
+}
+
+// GetAPI fetches a resource of a public API, which robots.txt, written for
+// crawlers, does not govern.
+func (c *Client) GetAPI(ctx context.Context, rawURL string, contentTypes ...string) ([]byte, error) {
+	return c.get(ctx, rawURL, false, contentTypes)
+}
+
+func (c *Client) get(ctx context.Context, rawURL string, robots bool, contentTypes []string) ([]byte, error) {
+	u, err := c.policy.CheckURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if robots {
+		rules, err := c.robotsRules(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+
+		if !rules.allowed(requestPath(u)) {
+			return nil, fmt.Errorf("%w: %s", ErrRobots, u.Redacted())
+		}
+	}
+
+	resp, err := c.do(ctx, u, strings.Join(contentTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch URL: status code %d", resp.StatusCode)
+	}
+
+	if len(contentTypes) > 0 && !matchesContentType(resp.Header.Get("Content-Type"), contentTypes) {
+		return nil, fmt.Errorf("%w: %s", ErrContentType, resp.Header.Get("Content-Type"))
+	}
+
+	return c.readBody(resp, c.policy.MaxBodySize)
+}
+
+// do sends a GET request once the host's turn has come.
+func (c *Client) do(ctx context.Context, u *url.URL, accept string) (*http.Response, error) {
+	if err := c.wait(ctx, u.Host); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	req.Header.Set("User-Agent", c.policy.UserAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+
+	return resp, nil
+}
+
+// readBody reads a response body of at most limit bytes.
+func (c *Client) readBody(resp *http.Response, limit int64) ([]byte, error) {
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	r := resp.Body
+	if limit > 0 {
+		r = io.NopCloser(io.LimitReader(resp.Body, limit+1))
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, limit)
+	}
+
+	return body, nil
+}
+
+// wait blocks until a request to host may be sent, reserving the next slot.
+func (c *Client) wait(ctx context.Context, host string) error {
+	interval := c.policy.HostInterval
+	if interval <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+
+	now := time.Now()
+	if len(c.next) >= maxTrackedHosts {
+		for h, t := range c.next {
+			if t.Before(now) {
+				delete(c.next, h)
+			}
+		}
+	}
+
+	slot := c.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+
+	c.next[host] = slot.Add(interval)
+	c.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// robotsRules returns the robots.txt rules of a URL's host, fetching them
+// when they are not cached. Following RFC 9309, a missing robots.txt allows
+// everything and an unreachable one, answering with a server error,
+// disallows everything.
+func (c *Client) robotsRules(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	origin := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry := c.robots[origin]
+	c.mu.Unlock()
+
+	if entry != nil && time.Now().Before(entry.expires) {
+		return entry.rules, nil
+	}
+
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	resp, err := c.do(ctx, robotsURL, "text/plain")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rules *robotsRules
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err := c.readBody(resp, maxRobotsSize)
+		if err != nil {
+			body = nil
+		}
+
+		rules = parseRobots(string(body), c.policy.UserAgent)
+	case resp.StatusCode >= 500:
+		rules = &robotsRules{disallowAll: true}
+	default:
+		rules = &robotsRules{}
+	}
+
+	c.mu.Lock()
+	if len(c.robots) >= maxTrackedHosts {
+		clear(c.robots)
+	}
+
+	c.robots[origin] = &robotsEntry{rules: rules, expires: time.Now().Add(robotsTTL)}
+	c.mu.Unlock()
+
+	return rules, nil
+}
+
+// requestPath returns the path and query robots.txt rules are matched on.
+func requestPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	return path
+}
+
+// matchesContentType reports whether a Content-Type header is one of the
+// accepted media types. Responses without one are accepted.
+func matchesContentType(header string, accepted []string) bool {
+	if header == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+
+	for _, a := range accepted {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == a {
+			return true
+		}
+	}
+
+	return false
+}
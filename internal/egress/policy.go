@@ -0,0 +1,322 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package egress applies an outbound request policy to the URLs users hand
+// the service, such as job postings and webhook endpoints: only public
+// addresses are reached unless configured otherwise, redirects are limited
+// and re-validated, responses are size-capped, robots.txt is respected and
+// every host is contacted at a polite pace.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBlocked is returned for destinations the policy does not allow.
+	ErrBlocked = errors.New("destination is not allowed")
+	// ErrRobots is returned for pages robots.txt disallows.
+	ErrRobots = errors.New("disallowed by robots.txt")
+	// ErrTooLarge is returned for responses larger than the policy allows.
+	ErrTooLarge = errors.New("response is too large")
+	// ErrContentType is returned for responses of an unexpected type.
+	ErrContentType = errors.New("unexpected content type")
+)
+
+// reservedPrefixes are the special-purpose ranges, beyond loopback, private
+// and link-local addresses, that are not publicly routable or that reach
+// other networks through translation.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// Policy controls which destinations may be reached and how.
+type Policy struct {
+	// AllowPrivate allows loopback, private, link-local and other
+	// non-public addresses.
+	AllowPrivate bool
+	// Allow lists the only hosts that may be reached, when not empty.
+	// Entries are host names ("example.com"), domains with their subdomains
+	// ("*.example.com") or address ranges ("10.1.0.0/16"); an allowed range
+	// may be reached even when it is private.
+	Allow []string
+	// Deny lists hosts that may never be reached, in the same forms.
+	Deny []string
+	// MaxRedirects is how many redirects are followed; 0 follows none.
+	MaxRedirects int
+	// MaxBodySize bounds response bodies, in bytes.
+	MaxBodySize int64
+	// HostInterval is the least time between two requests to a host.
+	HostInterval time.Duration
+	// RespectRobots makes page fetches honour robots.txt.
+	RespectRobots bool
+	// UserAgent identifies the service to the hosts it contacts.
+	UserAgent string
+}
+
+// DefaultPolicy returns the policy used unless configured otherwise.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MaxRedirects:  5,
+		MaxBodySize:   5 * 1024 * 1024,
+		HostInterval:  time.Second,
+		RespectRobots: true,
+		UserAgent:     "vibe-cv/1.0 (+https://github.com/sammyoina/vibe-cv)",
+	}
+}
+
+// LoadPolicy loads the egress policy from environment, starting from the
+// default policy.
+func LoadPolicy() *Policy {
+	p := DefaultPolicy()
+	p.AllowPrivate = strings.ToLower(os.Getenv("EGRESS_ALLOW_PRIVATE")) == "true"
+	p.Allow = splitList(os.Getenv("EGRESS_ALLOW_HOSTS"))
+	p.Deny = splitList(os.Getenv("EGRESS_DENY_HOSTS"))
+
+	if v := os.Getenv("EGRESS_RESPECT_ROBOTS"); v != "" {
+		p.RespectRobots = strings.ToLower(v) != "false"
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("EGRESS_MAX_REDIRECTS")); err == nil && v >= 0 {
+		p.MaxRedirects = v
+	}
+
+	if v, err := strconv.ParseInt(os.Getenv("EGRESS_MAX_BODY_SIZE"), 10, 64); err == nil && v > 0 {
+		p.MaxBodySize = v
+	}
+
+	if v, err := time.ParseDuration(os.Getenv("EGRESS_HOST_INTERVAL")); err == nil && v >= 0 {
+		p.HostInterval = v
+	}
+
+	return p
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// CheckURL checks that a URL is an http(s) URL whose host the policy
+// allows. Addresses are checked again once the host is resolved.
+func (p *Policy) CheckURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: URL must use http or https", ErrBlocked)
+	}
+
+	if u.Hostname() == "" {
+		return nil, errors.New("invalid URL: missing host")
+	}
+
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: URL must not carry credentials", ErrBlocked)
+	}
+
+	if err := p.checkHost(u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// checkHost checks a host name against the allow and deny lists.
+func (p *Policy) checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if matchesHost(p.Deny, host) {
+		return fmt.Errorf("%w: %s is denied", ErrBlocked, host)
+	}
+
+	if len(p.Allow) > 0 && !matchesHost(p.Allow, host) && !hasRanges(p.Allow) {
+		return fmt.Errorf("%w: %s is not on the allow list", ErrBlocked, host)
+	}
+
+	return nil
+}
+
+// checkAddr checks a resolved address of a host.
+func (p *Policy) checkAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if matchesAddr(p.Deny, addr) {
+		return fmt.Errorf("%w: %s is denied", ErrBlocked, addr)
+	}
+
+	if matchesAddr(p.Allow, addr) {
+		return nil
+	}
+
+	if len(p.Allow) > 0 && !matchesHost(p.Allow, host) {
+		return fmt.Errorf("%w: %s is not on the allow list", ErrBlocked, host)
+	}
+
+	if !p.AllowPrivate && !IsPublic(addr) {
+		return fmt.Errorf("%w: %s resolves to non-public address %s", ErrBlocked, host, addr)
+	}
+
+	return nil
+}
+
+// IsPublic reports whether an address is publicly routable.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}) {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesHost reports whether a host name matches a list entry.
+func matchesHost(list []string, host string) bool {
+	for _, entry := range list {
+		entry = strings.TrimSuffix(strings.ToLower(entry), ".")
+
+		if domain, ok := strings.CutPrefix(entry, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesAddr reports whether an address falls in a listed range or is a
+// listed address.
+func matchesAddr(list []string, addr netip.Addr) bool {
+	for _, entry := range list {
+		if prefix, err := netip.ParsePrefix(entry); err == nil && prefix.Contains(addr) {
+			return true
+		}
+
+		if a, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil && a.Unmap() == addr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasRanges reports whether a list holds address ranges, which can only be
+// matched once a host is resolved.
+func hasRanges(list []string) bool {
+	for _, entry := range list {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transport returns an HTTP transport that checks every address it
+// connects to against the policy. Hosts are resolved once and the checked
+// address is dialled, so a second resolution cannot swap in another one,
+// and environment proxies are not used, since they would hide the
+// destination.
+func (p *Policy) Transport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.checkHost(host); err != nil {
+				return nil, err
+			}
+
+			addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+			if err != nil {
+				return nil, err
+			}
+
+			lastErr := fmt.Errorf("no addresses found for %s", host)
+
+			for _, addr := range addrs {
+				if err := p.checkAddr(host, addr); err != nil {
+					lastErr = err
+
+					continue
+				}
+
+				conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+				if err == nil {
+					return conn, nil
+				}
+
+				lastErr = err
+			}
+
+			return nil, lastErr
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// CheckRedirect limits redirects to MaxRedirects and checks every redirect
+// target as a new URL.
+func (p *Policy) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > p.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", p.MaxRedirects)
+	}
+
+	_, err := p.CheckURL(req.URL.String())
+
+	return err
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package egress
+
+import (
+	"regexp"
+	"strings"
+)
+
+// robotsRules are the robots.txt rules that apply to this service.
+type robotsRules struct {
+	rules       []robotsRule
+	disallowAll bool
+}
+
+// robotsRule is one allow or disallow line.
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// parseRobots reads the groups of a robots.txt file that apply to a user
+// agent: the groups naming its product token, or else the "*" groups.
+func parseRobots(body, userAgent string) *robotsRules {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	var (
+		specific, generic []robotsRule
+		agents            []string
+		inRules           bool
+		matched           bool
+	)
+
+	for _, line := range strings.Split(body, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents, inRules = nil, false
+			}
+
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+
+			if value == "" {
+				continue
+			}
+
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+
+			for _, agent := range agents {
+				switch agent {
+				case token:
+					specific = append(specific, rule)
+					matched = true
+				case "*":
+					generic = append(generic, rule)
+				}
+			}
+		}
+	}
+
+	if matched {
+		return &robotsRules{rules: specific}
+	}
+
+	return &robotsRules{rules: generic}
+}
+
+// robotsPattern compiles a path pattern, in which "*" matches any characters
+// and a trailing "$" anchors the end of the path.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether a path may be fetched. The longest matching rule
+// wins, and allow rules win ties.
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	allowed, length := true, -1
+
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+
+	return allowed
+}
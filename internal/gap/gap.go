@@ -0,0 +1,301 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gap reports the skills a CV lacks for one or many jobs. A skill is
+// missing when the CV does not show it, and weak when the CV names it but
+// shows little use of it in dated roles. Gaps are ranked by how many of the
+// jobs ask for them and how strongly, and the top ones get a learning plan
+// of projects and certifications from an embedded catalog.
+package gap
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/agent"
+	"github.com/sammyoina/vibe-cv/internal/input"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+)
+
+//go:embed learning.json
+var learningJSON []byte
+
+// Statuses of a gap.
+const (
+	StatusMissing = "missing"
+	StatusWeak    = "weak"
+)
+
+// Priorities of a gap.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+const (
+	// weakMonths is the tenure below which a skill the CV names is weak.
+	weakMonths = 12
+	// maxPlanSteps is how many gaps the learning plan covers.
+	maxPlanSteps = 10
+	// preferredWeight is the weight of a preferred skill against a required one.
+	preferredWeight = 0.5
+	// weakWeight discounts weak skills against missing ones.
+	weakWeight = 0.5
+)
+
+// Job is a job the CV is compared with.
+type Job struct {
+	SavedJobID  int    `json:"saved_job_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Company     string `json:"company,omitempty"`
+	Description string `json:"-"`
+}
+
+// JobGaps is the skills one job asks for and the gaps of the CV for it.
+type JobGaps struct {
+	Job
+	Required  []string `json:"required_skills"`
+	Preferred []string `json:"preferred_skills"`
+	Missing   []string `json:"missing_skills"`
+	Weak      []string `json:"weak_skills"`
+}
+
+// Gap is a skill the CV is missing or weak in, across the jobs.
+type Gap struct {
+	Skill     string  `json:"skill"`
+	Status    string  `json:"status"`
+	Jobs      int     `json:"jobs"` // How many of the jobs ask for it
+	Required  int     `json:"required"`
+	Preferred int     `json:"preferred"`
+	Months    int     `json:"months,omitempty"` // Tenure in dated roles, for weak skills
+	Score     float64 `json:"score"`            // From 0 to 1; 1 when every job requires a missing skill
+	Priority  string  `json:"priority"`
+	Reason    string  `json:"reason"`
+}
+
+// Step is a step of the learning plan.
+type Step struct {
+	Skill          string   `json:"skill"`
+	Status         string   `json:"status"`
+	Priority       string   `json:"priority"`
+	Projects       []string `json:"projects"`
+	Certifications []string `json:"certifications"`
+}
+
+// Report is the skill gap report of a CV for a set of jobs.
+type Report struct {
+	Jobs         int       `json:"jobs"`
+	Gaps         []Gap     `json:"gaps"` // Highest score first
+	PerJob       []JobGaps `json:"per_job"`
+	LearningPlan []Step    `json:"learning_plan"`
+}
+
+// resources are the projects and certifications that build a skill.
+type resources struct {
+	Projects       []string `json:"projects"`
+	Certifications []string `json:"certifications"`
+}
+
+var (
+	catalogOnce sync.Once
+	catalog     map[string]resources
+)
+
+// learningCatalog returns the embedded catalog, by skill ID.
+func learningCatalog() map[string]resources {
+	catalogOnce.Do(func() {
+		var doc struct {
+			Skills map[string]resources `json:"skills"`
+		}
+
+		if err := json.Unmarshal(learningJSON, &doc); err != nil {
+			panic(fmt.Sprintf("gap: invalid embedded learning catalog: %v", err))
+		}
+
+		catalog = doc.Skills
+	})
+
+	return catalog
+}
+
+// Analyze reports the gaps of a CV for a set of jobs. The skills each job
+// asks for, and those of them the CV does not show, come from the analyzer
+// agent; a skill the CV shows is weak when the dated roles of the CV mention
+// it for less than a year. Weak skills are only judged for CVs with dated
+// roles. Tenure is counted up to now.
+func Analyze(cv string, jobs []Job, now time.Time) (*Report, error) {
+	if strings.TrimSpace(cv) == "" {
+		return nil, fmt.Errorf("CV has no text to analyze")
+	}
+
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("at least one job is required")
+	}
+
+	parsed := input.NewEnhancedParser().ParseCV(cv)
+	report := &Report{Jobs: len(jobs), Gaps: []Gap{}, PerJob: []JobGaps{}, LearningPlan: []Step{}}
+
+	gaps := make(map[string]*Gap)
+	tenure := make(map[string]int)
+
+	for _, job := range jobs {
+		skillGap := agent.AnalyzeSkills(cv, job.Description)
+		jobGaps := JobGaps{
+			Job:       job,
+			Required:  skillGap.Required,
+			Preferred: skillGap.Preferred,
+			Missing:   skillGap.Missing,
+			Weak:      []string{},
+		}
+
+		for _, name := range slices.Concat(skillGap.Required, skillGap.Preferred) {
+			status := StatusMissing
+
+			if !slices.Contains(skillGap.Missing, name) {
+				if parsed.ExperienceMonths == 0 {
+					continue
+				}
+
+				months, ok := tenure[name]
+				if !ok {
+					months = input.SkillTenure(parsed.Experience, []string{name}, now)[name]
+					tenure[name] = months
+				}
+
+				if months >= weakMonths {
+					continue
+				}
+
+				status = StatusWeak
+
+				jobGaps.Weak = append(jobGaps.Weak, name)
+			}
+
+			g, ok := gaps[name]
+			if !ok {
+				g = &Gap{Skill: name, Status: status, Months: tenure[name]}
+				gaps[name] = g
+			}
+
+			g.Jobs++
+
+			if slices.Contains(skillGap.Required, name) {
+				g.Required++
+			} else {
+				g.Preferred++
+			}
+		}
+
+		report.PerJob = append(report.PerJob, jobGaps)
+	}
+
+	for _, g := range gaps {
+		g.Score = (float64(g.Required) + preferredWeight*float64(g.Preferred)) / float64(len(jobs))
+		if g.Status == StatusWeak {
+			g.Score *= weakWeight
+		}
+
+		g.Priority = priority(g.Score)
+		g.Reason = reason(g, len(jobs))
+		report.Gaps = append(report.Gaps, *g)
+	}
+
+	slices.SortFunc(report.Gaps, func(a, b Gap) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+
+			return 1
+		case a.Jobs != b.Jobs:
+			return b.Jobs - a.Jobs
+		}
+
+		return strings.Compare(a.Skill, b.Skill)
+	})
+
+	for _, g := range report.Gaps[:min(maxPlanSteps, len(report.Gaps))] {
+		report.LearningPlan = append(report.LearningPlan, plan(g))
+	}
+
+	return report, nil
+}
+
+// priority buckets the score of a gap.
+func priority(score float64) string {
+	switch {
+	case score >= 0.5:
+		return PriorityHigh
+	case score >= 0.25:
+		return PriorityMedium
+	}
+
+	return PriorityLow
+}
+
+// reason explains a gap.
+func reason(g *Gap, jobs int) string {
+	asked := fmt.Sprintf("required by %d of %d jobs", g.Required, jobs)
+	if g.Required == 0 {
+		asked = fmt.Sprintf("preferred by %d of %d jobs", g.Preferred, jobs)
+	} else if g.Preferred > 0 {
+		asked += fmt.Sprintf(" and preferred by %d", g.Preferred)
+	}
+
+	switch {
+	case g.Status == StatusMissing:
+		return fmt.Sprintf("Not shown in the CV; %s", asked)
+	case g.Months == 0:
+		return fmt.Sprintf("Listed in the CV but not in any dated role; %s", asked)
+	}
+
+	return fmt.Sprintf("Used for %d months in dated roles; %s", g.Months, asked)
+}
+
+// plan builds the learning step of a gap from the catalog entry of its
+// skill. Skills without an entry get generic projects and the
+// certifications of their nearest broader skill that has some.
+func plan(g Gap) Step {
+	step := Step{Skill: g.Skill, Status: g.Status, Priority: g.Priority, Projects: []string{}, Certifications: []string{}}
+	taxonomy := skills.Default()
+	entries := learningCatalog()
+
+	skill, ok := taxonomy.Resolve(g.Skill)
+	if ok {
+		if entry, ok := entries[skill.ID]; ok {
+			step.Projects = append(step.Projects, entry.Projects...)
+			step.Certifications = append(step.Certifications, entry.Certifications...)
+		}
+	}
+
+	if len(step.Projects) == 0 {
+		step.Projects = []string{
+			fmt.Sprintf("Build a small project that uses %s end to end, and publish it with a README", g.Skill),
+			fmt.Sprintf("Use %s in a feature of an existing project and write up what you learned", g.Skill),
+		}
+	}
+
+	if ok && len(step.Certifications) == 0 {
+		for _, id := range taxonomy.Ancestors(skill.ID) {
+			if entry := entries[id]; len(entry.Certifications) > 0 {
+				step.Certifications = append(step.Certifications, entry.Certifications...)
+
+				break
+			}
+		}
+	}
+
+	// A weak skill is shown on the CV already; what it lacks is use in a role
+	if g.Status == StatusWeak {
+		step.Projects = append(step.Projects, fmt.Sprintf("Take on work using %s in your current role, so that a dated role shows it", g.Skill))
+	}
+
+	return step
+}
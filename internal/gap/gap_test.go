@@ -0,0 +1,97 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package gap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCV = `Jane Doe
+
+Work Experience:
+Senior Backend Engineer
+Acme Corp
+2020 - Present
+Built Go microservices on PostgreSQL
+
+Skills:
+Go, PostgreSQL, Docker`
+
+var testNow = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAnalyze(t *testing.T) {
+	jobs := []Job{
+		{SavedJobID: 1, Title: "Platform Engineer", Description: "Requirements:\n- Go\n- Kubernetes\n- Docker\n\nNice to have:\n- Terraform"},
+		{SavedJobID: 2, Title: "Backend Engineer", Description: "Requirements:\n- Go\n- Kubernetes\n- SQL"},
+	}
+
+	report, err := Analyze(testCV, jobs, testNow)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if report.Jobs != 2 || len(report.PerJob) != 2 {
+		t.Fatalf("report covers %d jobs, %d per job", report.Jobs, len(report.PerJob))
+	}
+
+	var skills []string
+	for _, g := range report.Gaps {
+		skills = append(skills, g.Skill)
+	}
+
+	// Kubernetes is required by both jobs, Docker is weak and Terraform only preferred
+	if want := []string{"Kubernetes", "Docker", "Terraform"}; !slices.Equal(skills, want) {
+		t.Fatalf("gaps = %v, want %v", skills, want)
+	}
+
+	if g := report.Gaps[0]; g.Status != StatusMissing || g.Jobs != 2 || g.Score != 1 || g.Priority != PriorityHigh {
+		t.Errorf("Kubernetes gap = %+v", g)
+	}
+
+	if g := report.Gaps[1]; g.Status != StatusWeak || g.Months != 0 || !strings.Contains(g.Reason, "not in any dated role") {
+		t.Errorf("Docker gap = %+v", g)
+	}
+
+	if g := report.Gaps[2]; g.Preferred != 1 || g.Priority != PriorityMedium {
+		t.Errorf("Terraform gap = %+v", g)
+	}
+
+	if got := report.PerJob[0]; !slices.Equal(got.Missing, []string{"Kubernetes", "Terraform"}) || !slices.Equal(got.Weak, []string{"Docker"}) {
+		t.Errorf("first job gaps = %+v", got)
+	}
+
+	if len(report.LearningPlan) != 3 {
+		t.Fatalf("learning plan = %+v", report.LearningPlan)
+	}
+
+	if step := report.LearningPlan[0]; !slices.Contains(step.Certifications, "Certified Kubernetes Administrator (CKA)") || len(step.Projects) == 0 {
+		t.Errorf("Kubernetes step = %+v", step)
+	}
+}
+
+func TestPlan_Fallback(t *testing.T) {
+	// pytest has no entry of its own; Python has certifications
+	step := plan(Gap{Skill: "pytest", Status: StatusMissing, Priority: PriorityLow})
+
+	if len(step.Projects) != 2 || !strings.Contains(step.Projects[0], "pytest") {
+		t.Errorf("projects = %v", step.Projects)
+	}
+
+	if len(step.Certifications) == 0 {
+		t.Error("Expected the certifications of a broader skill")
+	}
+}
+
+func TestAnalyze_Errors(t *testing.T) {
+	if _, err := Analyze(" ", []Job{{Description: "Go"}}, testNow); err == nil {
+		t.Error("Expected an error for an empty CV")
+	}
+
+	if _, err := Analyze(testCV, nil, testNow); err == nil {
+		t.Error("Expected an error without jobs")
+	}
+}
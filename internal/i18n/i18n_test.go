@@ -0,0 +1,151 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"We are looking for a backend engineer with experience in Go and the cloud.", English},
+		{"Wir suchen eine erfahrene Entwicklerin für unser Team in Berlin. Sie arbeiten mit den neuesten Technologien und sind für die Architektur verantwortlich.", German},
+		{"Nous recherchons un développeur pour rejoindre notre équipe. Vous travaillerez sur des projets dans le cloud avec les équipes produit.", French},
+		{"Buscamos un desarrollador con experiencia en Go para unirse a nuestro equipo. Trabajarás con las tecnologías más modernas del sector.", Spanish},
+		{"Procuramos um desenvolvedor com experiência em Go para a nossa equipe. Você vai trabalhar com as tecnologias mais modernas do mercado.", Portuguese},
+		{"Wij zoeken een ervaren ontwikkelaar voor ons team. Je werkt met de nieuwste technologieën en bent verantwoordelijk voor de architectuur.", Dutch},
+		{"Go, Kubernetes, AWS", English},
+		{"", English},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"de", German, true},
+		{"pt-BR", Portuguese, true},
+		{"fr_CA", French, true},
+		{" NL ", Dutch, true},
+		{"it", "it", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Normalize(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHeadingSection(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    Section
+	}{
+		{"Work Experience", SectionExperience},
+		{"Licenses & Certifications", SectionCertifications},
+		{"Berufserfahrung", SectionExperience},
+		{"Ausbildung und Studium", SectionEducation},
+		{"EXPÉRIENCES PROFESSIONNELLES", SectionExperience},
+		{"Compétences", SectionSkills},
+		{"Experiencia laboral", SectionExperience},
+		{"Formação Acadêmica", SectionEducation},
+		{"Werkervaring", SectionExperience},
+		{"Vaardigheden", SectionSkills},
+		{"Über mich", SectionSummary},
+		{"Hobbies", SectionUnknown},
+		{"I gained a lot of experience leading teams of engineers across three continents", SectionUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := HeadingSection(tt.heading); got != tt.want {
+			t.Errorf("HeadingSection(%q) = %d, want %d", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestHeadingLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Section
+	}{
+		{"Berufserfahrung:", SectionExperience},
+		{"  Kenntnisse", SectionSkills},
+		{"BERUFLICHER WERDEGANG UND PRAKTIKA", SectionExperience},
+		{"Mehrjährige Erfahrung in der Entwicklung", SectionUnknown},
+		{"Experiencia en desarrollo de APIs:", SectionExperience},
+	}
+
+	for _, tt := range tests {
+		if got := HeadingLine(tt.line); got != tt.want {
+			t.Errorf("HeadingLine(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestJobHeadingLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Section
+	}{
+		{"Requirements:", SectionRequirements},
+		{"Preferred Skills:", SectionPreferred},
+		{"Ihr Profil", SectionRequirements},
+		{"Experience with Kafka event streaming", SectionUnknown},
+		{"Experience", SectionUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := JobHeadingLine(tt.line); got != tt.want {
+			t.Errorf("JobHeadingLine(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	cv := "Jürgen Müller\n\nBerufserfahrung\nSoftwareentwickler bei Beispiel GmbH\n\nAusbildung\nTU München"
+
+	if !Mentions(cv, German, SectionExperience) {
+		t.Error("Expected German CV to mention experience")
+	}
+
+	if !Mentions(cv, German, SectionEducation) {
+		t.Error("Expected German CV to mention education")
+	}
+
+	if Mentions(cv, German, SectionSkills) {
+		t.Error("Expected German CV not to mention skills")
+	}
+
+	// Keywords only match at the start of words
+	if Mentions("Kunstausbildung", German, SectionEducation) {
+		t.Error("Expected keyword inside a word not to match")
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Expérience":  "experience",
+		"FÄHIGKEITEN": "fahigkeiten",
+		"Formação":    "formacao",
+		"Go":          "go",
+	}
+
+	for in, want := range tests {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package i18n recognizes the language of CVs and job postings, and the
+// section headings they use, in English, German, French, Spanish, Portuguese
+// and Dutch.
+package i18n
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Supported languages, as ISO 639-1 codes.
+const (
+	English    = "en"
+	German     = "de"
+	French     = "fr"
+	Spanish    = "es"
+	Portuguese = "pt"
+	Dutch      = "nl"
+)
+
+// maxDetectRunes bounds how much of a text is read to detect its language.
+const maxDetectRunes = 20000
+
+// Languages lists the supported languages.
+var Languages = []string{English, German, French, Spanish, Portuguese, Dutch}
+
+// languageNames are the English names of the supported languages.
+var languageNames = map[string]string{
+	English:    "English",
+	German:     "German",
+	French:     "French",
+	Spanish:    "Spanish",
+	Portuguese: "Portuguese",
+	Dutch:      "Dutch",
+}
+
+// babelNames are the babel options selecting the hyphenation patterns of
+// the supported languages.
+var babelNames = map[string]string{
+	English:    "english",
+	German:     "ngerman",
+	French:     "french",
+	Spanish:    "spanish",
+	Portuguese: "portuguese",
+	Dutch:      "dutch",
+}
+
+// stopwords are frequent words that tell the supported languages apart.
+// Words shared by several languages count for each of them.
+var stopwords = map[string][]string{
+	English: {
+		"the", "and", "of", "to", "in", "for", "with", "on", "is", "as", "at", "by",
+		"we", "you", "our", "are", "from", "this", "that", "an", "be", "or", "will", "have",
+	},
+	German: {
+		"der", "die", "das", "und", "mit", "für", "von", "zu", "den", "dem", "ist", "ein",
+		"eine", "im", "auf", "bei", "wir", "sie", "als", "nicht", "sich", "des", "oder", "ihre",
+	},
+	French: {
+		"le", "la", "les", "des", "du", "et", "en", "un", "une", "pour", "avec", "dans",
+		"sur", "est", "au", "aux", "nous", "vous", "de", "par", "qui", "ou", "vos", "votre",
+	},
+	Spanish: {
+		"el", "la", "los", "las", "del", "y", "en", "con", "por", "para", "es", "una",
+		"un", "que", "de", "se", "al", "su", "sus", "como", "o", "tu", "nuestro", "lo",
+	},
+	Portuguese: {
+		"o", "os", "as", "e", "em", "com", "por", "para", "do", "da", "dos", "das",
+		"no", "na", "um", "uma", "que", "de", "é", "não", "ao", "seu", "sua", "você",
+	},
+	Dutch: {
+		"de", "het", "een", "en", "van", "in", "op", "met", "voor", "is", "zijn", "te",
+		"dat", "bij", "naar", "ook", "wij", "je", "jouw", "onze", "niet", "of", "als", "aan",
+	},
+}
+
+// stopwordLanguages maps each stopword to the languages using it.
+var stopwordLanguages = func() map[string][]string {
+	index := map[string][]string{}
+
+	for _, lang := range Languages {
+		for _, word := range stopwords[lang] {
+			index[word] = append(index[word], lang)
+		}
+	}
+
+	return index
+}()
+
+// Normalize returns the supported language of a language tag such as "de",
+// "pt-BR" or "fr_CA", or false if it is not supported.
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	_, ok := languageNames[tag]
+
+	return tag, ok
+}
+
+// Name returns the English name of a supported language.
+func Name(lang string) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+
+	return languageNames[English]
+}
+
+// BabelName returns the LaTeX babel option of a supported language.
+func BabelName(lang string) string {
+	if name, ok := babelNames[lang]; ok {
+		return name
+	}
+
+	return babelNames[English]
+}
+
+// Detect returns the language of a text from the stopwords it uses. Texts
+// too short to tell, or written in another language, are taken for English.
+func Detect(text string) string {
+	if utf8.RuneCountInString(text) > maxDetectRunes {
+		text = string([]rune(text)[:maxDetectRunes])
+	}
+
+	scores := map[string]int{}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for _, lang := range stopwordLanguages[word] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, runnerUp := English, 0, 0
+
+	for _, lang := range Languages {
+		switch score := scores[lang]; {
+		case score > bestScore:
+			best, bestScore, runnerUp = lang, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+
+	// A handful of shared stopwords is not evidence
+	if bestScore < 3 || bestScore == runnerUp {
+		return English
+	}
+
+	return best
+}
+
+// Fold lower-cases a text and strips its diacritics, so that "Expérience"
+// and "experience" compare equal.
+func Fold(s string) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,249 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Heading length limits: headings are at most maxHeadingRunes long, and
+// lines without a heading mark at most maxHeadingWords words.
+const (
+	maxHeadingRunes = 40
+	maxHeadingWords = 3
+)
+
+// Section is a section of a CV or a job posting.
+type Section int
+
+// CV and job posting sections.
+const (
+	SectionUnknown Section = iota
+	SectionSummary
+	SectionExperience
+	SectionEducation
+	SectionSkills
+	SectionCertifications
+	SectionRequirements
+	SectionResponsibilities
+	SectionPreferred
+	SectionBenefits
+)
+
+// cvSections are the CV sections in the order headings are classified, so
+// that more specific keywords come first.
+var cvSections = []Section{
+	SectionCertifications, SectionEducation, SectionExperience, SectionSkills, SectionSummary,
+}
+
+// jobSections are the job posting sections in the order headings are
+// classified.
+var jobSections = []Section{
+	SectionPreferred, SectionRequirements, SectionResponsibilities, SectionBenefits,
+}
+
+// sectionKeywords are the heading keywords of each section by language.
+// Keywords match at the start of a word, so German compounds are listed in
+// full.
+var sectionKeywords = map[string]map[Section][]string{
+	English: {
+		SectionCertifications:   {"certification", "certificate", "licenses"},
+		SectionEducation:        {"education", "academic"},
+		SectionExperience:       {"experience", "employment", "work history", "career history"},
+		SectionSkills:           {"skills", "competencies", "technologies", "expertise", "tools"},
+		SectionSummary:          {"summary", "profile", "objective", "about me"},
+		SectionRequirements:     {"requirements", "must have", "required"},
+		SectionResponsibilities: {"responsibilities", "your role", "what you'll do"},
+		SectionPreferred:        {"preferred", "nice to have", "bonus"},
+		SectionBenefits:         {"benefits", "perks", "compensation"},
+	},
+	German: {
+		SectionCertifications:   {"zertifikate", "zertifizierungen", "lizenzen"},
+		SectionEducation:        {"ausbildung", "bildungsweg", "schulbildung", "studium", "weiterbildung"},
+		SectionExperience:       {"berufserfahrung", "erfahrung", "praxiserfahrung", "beruflicher werdegang", "werdegang", "berufspraxis"},
+		SectionSkills:           {"kenntnisse", "fachkenntnisse", "sprachkenntnisse", "fähigkeiten", "kompetenzen"},
+		SectionSummary:          {"zusammenfassung", "kurzprofil", "profil", "über mich", "berufsziel"},
+		SectionRequirements:     {"anforderungen", "ihr profil", "dein profil", "voraussetzungen", "das bringen sie mit", "das bringst du mit"},
+		SectionResponsibilities: {"ihre aufgaben", "deine aufgaben", "aufgabengebiet", "tätigkeiten"},
+		SectionPreferred:        {"wünschenswert", "von vorteil", "idealerweise"},
+		SectionBenefits:         {"wir bieten", "das bieten wir", "vorteile"},
+	},
+	French: {
+		SectionCertifications:   {"certifications", "certificats", "habilitations"},
+		SectionEducation:        {"formation", "études", "diplômes", "parcours académique", "scolarité"},
+		SectionExperience:       {"expérience", "expériences", "parcours professionnel"},
+		SectionSkills:           {"compétences", "aptitudes", "savoir-faire", "outils"},
+		SectionSummary:          {"profil", "à propos", "objectif"},
+		SectionRequirements:     {"profil recherché", "votre profil", "exigences", "prérequis"},
+		SectionResponsibilities: {"vos missions", "responsabilités", "vos tâches", "missions"},
+		SectionPreferred:        {"atouts", "souhaité", "un plus"},
+		SectionBenefits:         {"avantages", "nous offrons", "ce que nous offrons"},
+	},
+	Spanish: {
+		SectionCertifications:   {"certificaciones", "certificados", "licencias"},
+		SectionEducation:        {"educación", "formación", "estudios"},
+		SectionExperience:       {"experiencia", "trayectoria profesional"},
+		SectionSkills:           {"habilidades", "competencias", "conocimientos", "aptitudes", "herramientas"},
+		SectionSummary:          {"resumen", "perfil", "sobre mí", "acerca de mí", "objetivo"},
+		SectionRequirements:     {"requisitos", "requerimientos", "perfil buscado", "qué buscamos"},
+		SectionResponsibilities: {"responsabilidades", "funciones", "tus tareas"},
+		SectionPreferred:        {"deseable", "valorable", "se valorará"},
+		SectionBenefits:         {"beneficios", "qué ofrecemos", "ofrecemos"},
+	},
+	Portuguese: {
+		SectionCertifications:   {"certificações", "certificados", "licenças"},
+		SectionEducation:        {"educação", "formação", "escolaridade"},
+		SectionExperience:       {"experiência", "histórico profissional"},
+		SectionSkills:           {"competências", "habilidades", "conhecimentos", "ferramentas"},
+		SectionSummary:          {"resumo", "perfil", "sobre mim", "objetivo"},
+		SectionRequirements:     {"requisitos", "qualificações"},
+		SectionResponsibilities: {"responsabilidades", "atribuições", "atividades"},
+		SectionPreferred:        {"desejável", "diferenciais"},
+		SectionBenefits:         {"benefícios", "oferecemos"},
+	},
+	Dutch: {
+		SectionCertifications:   {"certificaten", "certificeringen"},
+		SectionEducation:        {"opleiding", "opleidingen", "onderwijs"},
+		SectionExperience:       {"werkervaring", "ervaring", "loopbaan", "werkgeschiedenis"},
+		SectionSkills:           {"vaardigheden", "competenties", "kennis"},
+		SectionSummary:          {"samenvatting", "profiel", "over mij", "doelstelling"},
+		SectionRequirements:     {"functie-eisen", "wat vragen wij", "wij vragen", "jouw profiel", "vereisten"},
+		SectionResponsibilities: {"verantwoordelijkheden", "jouw taken", "wat ga je doen", "werkzaamheden"},
+		SectionPreferred:        {"pluspunt", "een pré"},
+		SectionBenefits:         {"wat bieden wij", "wij bieden", "arbeidsvoorwaarden"},
+	},
+}
+
+// sectionTitles are the headings the customized CVs of each language use.
+var sectionTitles = map[string]map[Section]string{
+	English:    {SectionSummary: "Professional Summary"},
+	German:     {SectionSummary: "Profil"},
+	French:     {SectionSummary: "Profil professionnel"},
+	Spanish:    {SectionSummary: "Perfil profesional"},
+	Portuguese: {SectionSummary: "Perfil profissional"},
+	Dutch:      {SectionSummary: "Profiel"},
+}
+
+// Keywords returns the heading keywords of a section in a language,
+// lower-cased with their diacritics.
+func Keywords(lang string, section Section) []string {
+	return sectionKeywords[lang][section]
+}
+
+// Title returns the heading of a section in a customized CV.
+func Title(lang string, section Section) string {
+	if title, ok := sectionTitles[lang][section]; ok {
+		return title
+	}
+
+	return sectionTitles[English][section]
+}
+
+// HeadingSection classifies a heading as a CV section. Headings in any of
+// the supported languages are recognized.
+func HeadingSection(heading string) Section {
+	return classifyHeading(heading, cvSections)
+}
+
+// classifyHeading returns the first of sections a heading names.
+func classifyHeading(heading string, sections []Section) Section {
+	heading = Fold(strings.TrimSpace(heading))
+	if heading == "" || utf8.RuneCountInString(heading) > maxHeadingRunes {
+		return SectionUnknown
+	}
+
+	for _, section := range sections {
+		for _, lang := range Languages {
+			for _, keyword := range sectionKeywords[lang][section] {
+				if containsWord(heading, Fold(keyword)) {
+					return section
+				}
+			}
+		}
+	}
+
+	return SectionUnknown
+}
+
+// HeadingLine classifies a line of plain text as a CV section heading. Lines
+// of up to maxHeadingWords words are headings when they name a section;
+// longer ones must end with a colon or be upper case, as in "Work
+// Experience and Internships:".
+func HeadingLine(line string) Section {
+	return classifyLine(line, cvSections)
+}
+
+// JobHeadingLine classifies a line of plain text as a job posting section
+// heading, such as "Preferred Skills:" or "Ihr Profil", as HeadingLine does
+// for CV sections.
+func JobHeadingLine(line string) Section {
+	return classifyLine(line, jobSections)
+}
+
+// classifyLine returns the first of sections a heading line names.
+func classifyLine(line string, sections []Section) Section {
+	line = strings.TrimSpace(line)
+	marked := strings.HasSuffix(line, ":") || isUpper(line)
+	line = strings.TrimSpace(strings.TrimSuffix(line, ":"))
+
+	if !marked && len(strings.Fields(line)) > maxHeadingWords {
+		return SectionUnknown
+	}
+
+	return classifyHeading(line, sections)
+}
+
+// Mentions reports whether a text mentions a section by one of its English
+// or lang keywords, as a word or at the start of one.
+func Mentions(text, lang string, section Section) bool {
+	folded := Fold(text)
+	keywords := Keywords(English, section)
+
+	if lang != English {
+		keywords = append(keywords[:len(keywords):len(keywords)], Keywords(lang, section)...)
+	}
+
+	for _, keyword := range keywords {
+		if containsWord(folded, Fold(keyword)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsWord reports whether s contains word at the start of a word of s.
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+
+		j += i
+
+		if r, _ := utf8.DecodeLastRuneInString(s[:j]); j == 0 || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return true
+		}
+
+		i = j + len(word)
+	}
+}
+
+// isUpper reports whether a text has letters, all of them upper case.
+func isUpper(s string) bool {
+	letters := false
+
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+
+		letters = letters || unicode.IsLetter(r)
+	}
+
+	return letters
+}
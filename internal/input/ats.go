@@ -0,0 +1,486 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// atsAdapter reads job postings from the public JSON API of an applicant
+// tracking system, for postings whose pages are rendered by JavaScript or
+// carry no structured data.
+type atsAdapter struct {
+	name string
+	// api returns the API URL of the posting a page URL shows.
+	api func(u *url.URL) (string, bool)
+	// parse reads the API response of the posting at u.
+	parse func(body []byte, u *url.URL) (*jobData, error)
+}
+
+// atsAdapters are the supported applicant tracking systems.
+var atsAdapters = []atsAdapter{
+	{name: "greenhouse", api: greenhouseAPI, parse: parseGreenhouse},
+	{name: "lever", api: leverAPI, parse: parseLever},
+	{name: "workday", api: workdayAPI, parse: parseWorkday},
+	{name: "ashby", api: ashbyAPI, parse: parseAshby},
+	{name: "smartrecruiters", api: smartRecruitersAPI, parse: parseSmartRecruiters},
+}
+
+var (
+	// requirementsHeading and responsibilitiesHeading classify the titled
+	// lists of ATS postings, in English and the other supported languages.
+	requirementsHeading     = regexp.MustCompile(`(?i)require|qualif|you have|you bring|must|skills|anforderung|ihr profil|dein profil|profil recherché|votre profil|requisito|vereiste|functie-eisen|jouw profiel`)
+	responsibilitiesHeading = regexp.MustCompile(`(?i)responsib|you.ll do|you will do|your role|day to day|aufgaben|missions|responsabilidad|atribuiç|verantwoordelijk|jouw taken`)
+	// workdayLocale matches the optional locale segment of Workday URLs.
+	workdayLocale = regexp.MustCompile(`^[a-z]{2}-[A-Z]{2}$`)
+	// leadingDigits matches the numeric id that starts SmartRecruiters slugs.
+	leadingDigits = regexp.MustCompile(`^\d+`)
+)
+
+// atsAdapterFor returns the adapter and API URL for a posting URL.
+func atsAdapterFor(u *url.URL) (*atsAdapter, string, bool) {
+	for i := range atsAdapters {
+		if api, ok := atsAdapters[i].api(u); ok {
+			return &atsAdapters[i], api, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// pathSegments returns the non-empty segments of a URL path.
+func pathSegments(u *url.URL) []string {
+	var segments []string
+
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	return segments
+}
+
+// humanizeSlug turns a URL slug such as "acme-corp" into "Acme Corp".
+func humanizeSlug(slug string) string {
+	words := strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// textLines splits plain text into lines, dropping list markers.
+func textLines(text string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "•*-–"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// addTitledList appends a titled list of an ATS posting to the job,
+// classifying it as requirements or responsibilities by its title.
+func (j *jobData) addTitledList(title, text string) {
+	if text == "" {
+		return
+	}
+
+	if title != "" {
+		j.description += "\n\n" + title + ":"
+	}
+
+	j.description = strings.TrimSpace(j.description + "\n" + text)
+
+	switch {
+	case requirementsHeading.MatchString(title):
+		j.requirements = append(j.requirements, textLines(text)...)
+	case responsibilitiesHeading.MatchString(title):
+		j.responsibilities = append(j.responsibilities, textLines(text)...)
+	}
+}
+
+// greenhouseAPI handles boards.greenhouse.io/{board}/jobs/{id} and the
+// embedded job_app?for={board}&token={id} pages.
+func greenhouseAPI(u *url.URL) (string, bool) {
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasSuffix(host, ".greenhouse.io") || strings.HasPrefix(host, "boards-api.") {
+		return "", false
+	}
+
+	var board, id string
+
+	segments := pathSegments(u)
+
+	switch {
+	case len(segments) >= 3 && segments[1] == "jobs":
+		board, id = segments[0], segments[2]
+	case len(segments) >= 2 && segments[0] == "embed":
+		board, id = u.Query().Get("for"), u.Query().Get("token")
+	}
+
+	if board == "" || id == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://boards-api.greenhouse.io/v1/boards/%s/jobs/%s?pay_transparency=true",
+		url.PathEscape(board), url.PathEscape(id)), true
+}
+
+func parseGreenhouse(body []byte, u *url.URL) (*jobData, error) {
+	var v struct {
+		Title       string `json:"title"`
+		CompanyName string `json:"company_name"`
+		Content     string `json:"content"`
+		Location    struct {
+			Name string `json:"name"`
+		} `json:"location"`
+		PayInputRanges []struct {
+			MinCents     float64 `json:"min_cents"`
+			MaxCents     float64 `json:"max_cents"`
+			CurrencyType string  `json:"currency_type"`
+		} `json:"pay_input_ranges"`
+	}
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("invalid Greenhouse response: %w", err)
+	}
+
+	job := &jobData{
+		source:      "greenhouse",
+		title:       v.Title,
+		company:     v.CompanyName,
+		location:    v.Location.Name,
+		description: htmlFragmentText(v.Content),
+	}
+
+	if job.company == "" {
+		if segments := pathSegments(u); len(segments) > 0 && segments[0] != "embed" {
+			job.company = humanizeSlug(segments[0])
+		}
+	}
+
+	if len(v.PayInputRanges) > 0 {
+		r := v.PayInputRanges[0]
+		job.salary = formatSalary(r.CurrencyType, "YEAR", r.MinCents/100, r.MaxCents/100)
+	}
+
+	return job, nil
+}
+
+// leverAPI handles jobs.lever.co/{company}/{id}.
+func leverAPI(u *url.URL) (string, bool) {
+	host := strings.ToLower(u.Hostname())
+
+	api := "https://api.lever.co"
+
+	switch host {
+	case "jobs.lever.co":
+	case "jobs.eu.lever.co":
+		api = "https://api.eu.lever.co"
+	default:
+		return "", false
+	}
+
+	segments := pathSegments(u)
+	if len(segments) < 2 {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s/v0/postings/%s/%s", api, url.PathEscape(segments[0]), url.PathEscape(segments[1])), true
+}
+
+func parseLever(body []byte, u *url.URL) (*jobData, error) {
+	var v struct {
+		Text       string `json:"text"`
+		Categories struct {
+			Location   string `json:"location"`
+			Commitment string `json:"commitment"`
+		} `json:"categories"`
+		WorkplaceType string `json:"workplaceType"`
+		Description   string `json:"description"`
+		Lists         []struct {
+			Text    string `json:"text"`
+			Content string `json:"content"`
+		} `json:"lists"`
+		Additional  string `json:"additional"`
+		SalaryRange *struct {
+			Currency string  `json:"currency"`
+			Interval string  `json:"interval"`
+			Min      float64 `json:"min"`
+			Max      float64 `json:"max"`
+		} `json:"salaryRange"`
+	}
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("invalid Lever response: %w", err)
+	}
+
+	job := &jobData{
+		source:         "lever",
+		title:          v.Text,
+		company:        humanizeSlug(pathSegments(u)[0]),
+		location:       v.Categories.Location,
+		employmentType: v.Categories.Commitment,
+		description:    htmlFragmentText(v.Description),
+	}
+
+	if v.WorkplaceType == "remote" && !strings.Contains(strings.ToLower(job.location), "remote") {
+		job.location = strings.TrimSpace(job.location + " (Remote)")
+	}
+
+	for _, list := range v.Lists {
+		job.addTitledList(list.Text, htmlFragmentText("<ul>"+list.Content+"</ul>"))
+	}
+
+	job.addTitledList("", htmlFragmentText(v.Additional))
+
+	if r := v.SalaryRange; r != nil {
+		unit := ""
+
+		switch {
+		case strings.Contains(r.Interval, "year"):
+			unit = "YEAR"
+		case strings.Contains(r.Interval, "month"):
+			unit = "MONTH"
+		case strings.Contains(r.Interval, "hour"):
+			unit = "HOUR"
+		}
+
+		job.salary = formatSalary(r.Currency, unit, r.Min, r.Max)
+	}
+
+	return job, nil
+}
+
+// workdayAPI handles {tenant}.wd{N}.myworkdayjobs.com/[{locale}/]{site}/job/...
+// and www.myworkdaysite.com/recruiting/{tenant}/{site}/job/... through the
+// CXS API the career sites themselves use.
+func workdayAPI(u *url.URL) (string, bool) {
+	host := strings.ToLower(u.Hostname())
+	segments := pathSegments(u)
+
+	var tenant string
+
+	switch {
+	case strings.HasSuffix(host, ".myworkdayjobs.com"):
+		tenant = strings.Split(host, ".")[0]
+	case strings.HasSuffix(host, ".myworkdaysite.com") && len(segments) > 2 && segments[0] == "recruiting":
+		tenant, segments = segments[1], segments[2:]
+	default:
+		return "", false
+	}
+
+	if len(segments) > 0 && workdayLocale.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	if len(segments) < 3 || segments[1] != "job" {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://%s/wday/cxs/%s/%s/job/%s", host, tenant, segments[0], strings.Join(segments[2:], "/")), true
+}
+
+func parseWorkday(body []byte, _ *url.URL) (*jobData, error) {
+	var v struct {
+		JobPostingInfo struct {
+			Title               string   `json:"title"`
+			JobDescription      string   `json:"jobDescription"`
+			Location            string   `json:"location"`
+			AdditionalLocations []string `json:"additionalLocations"`
+			TimeType            string   `json:"timeType"`
+			RemoteType          string   `json:"remoteType"`
+		} `json:"jobPostingInfo"`
+		HiringOrganization struct {
+			Name string `json:"name"`
+		} `json:"hiringOrganization"`
+	}
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("invalid Workday response: %w", err)
+	}
+
+	info := v.JobPostingInfo
+	locations := append([]string{info.Location}, info.AdditionalLocations...)
+
+	job := &jobData{
+		source:         "workday",
+		title:          info.Title,
+		company:        v.HiringOrganization.Name,
+		location:       strings.Trim(strings.Join(locations, "; "), "; "),
+		employmentType: info.TimeType,
+		description:    htmlFragmentText(info.JobDescription),
+	}
+
+	if info.RemoteType != "" {
+		job.location = strings.TrimSpace(job.location + " (" + info.RemoteType + ")")
+	}
+
+	return job, nil
+}
+
+// ashbyAPI handles jobs.ashbyhq.com/{organization}/{id} through the public
+// job board API, which lists every posting of the organization.
+func ashbyAPI(u *url.URL) (string, bool) {
+	segments := pathSegments(u)
+	if strings.ToLower(u.Hostname()) != "jobs.ashbyhq.com" || len(segments) < 2 {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://api.ashbyhq.com/posting-api/job-board/%s?includeCompensation=true",
+		url.PathEscape(segments[0])), true
+}
+
+func parseAshby(body []byte, u *url.URL) (*jobData, error) {
+	var v struct {
+		Jobs []struct {
+			ID               string `json:"id"`
+			Title            string `json:"title"`
+			Location         string `json:"location"`
+			EmploymentType   string `json:"employmentType"`
+			IsRemote         bool   `json:"isRemote"`
+			DescriptionHTML  string `json:"descriptionHtml"`
+			DescriptionPlain string `json:"descriptionPlain"`
+			Compensation     *struct {
+				Summary string `json:"compensationTierSummary"`
+			} `json:"compensation"`
+		} `json:"jobs"`
+	}
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("invalid Ashby response: %w", err)
+	}
+
+	segments := pathSegments(u)
+
+	for _, j := range v.Jobs {
+		if !strings.EqualFold(j.ID, segments[1]) {
+			continue
+		}
+
+		job := &jobData{
+			source:         "ashby",
+			title:          j.Title,
+			company:        humanizeSlug(segments[0]),
+			location:       j.Location,
+			employmentType: j.EmploymentType,
+			description:    htmlFragmentText(j.DescriptionHTML),
+		}
+
+		if job.description == "" {
+			job.description = strings.TrimSpace(j.DescriptionPlain)
+		}
+
+		if j.IsRemote && !strings.Contains(strings.ToLower(job.location), "remote") {
+			job.location = strings.TrimSpace(job.location + " (Remote)")
+		}
+
+		if j.Compensation != nil {
+			job.salary = j.Compensation.Summary
+		}
+
+		return job, nil
+	}
+
+	return nil, errors.New("posting not found on the Ashby job board")
+}
+
+// smartRecruitersAPI handles jobs.smartrecruiters.com/{company}/{id}-{slug}.
+func smartRecruitersAPI(u *url.URL) (string, bool) {
+	host := strings.ToLower(u.Hostname())
+	if host != "jobs.smartrecruiters.com" && host != "careers.smartrecruiters.com" {
+		return "", false
+	}
+
+	segments := pathSegments(u)
+	if len(segments) < 2 {
+		return "", false
+	}
+
+	id := leadingDigits.FindString(segments[1])
+	if id == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://api.smartrecruiters.com/v1/companies/%s/postings/%s", url.PathEscape(segments[0]), id), true
+}
+
+func parseSmartRecruiters(body []byte, _ *url.URL) (*jobData, error) {
+	type section struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}
+
+	var v struct {
+		Name    string `json:"name"`
+		Company struct {
+			Name string `json:"name"`
+		} `json:"company"`
+		Location struct {
+			City    string `json:"city"`
+			Region  string `json:"region"`
+			Country string `json:"country"`
+			Remote  bool   `json:"remote"`
+		} `json:"location"`
+		TypeOfEmployment struct {
+			Label string `json:"label"`
+		} `json:"typeOfEmployment"`
+		JobAd struct {
+			Sections struct {
+				CompanyDescription    section `json:"companyDescription"`
+				JobDescription        section `json:"jobDescription"`
+				Qualifications        section `json:"qualifications"`
+				AdditionalInformation section `json:"additionalInformation"`
+			} `json:"sections"`
+		} `json:"jobAd"`
+	}
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("invalid SmartRecruiters response: %w", err)
+	}
+
+	var place []string
+
+	for _, p := range []string{v.Location.City, v.Location.Region, strings.ToUpper(v.Location.Country)} {
+		if p != "" {
+			place = append(place, p)
+		}
+	}
+
+	job := &jobData{
+		source:         "smartrecruiters",
+		title:          v.Name,
+		company:        v.Company.Name,
+		location:       strings.Join(place, ", "),
+		employmentType: v.TypeOfEmployment.Label,
+	}
+
+	if v.Location.Remote {
+		job.location = strings.TrimSpace(job.location + " (Remote)")
+	}
+
+	s := v.JobAd.Sections
+	for _, sec := range []section{s.CompanyDescription, s.JobDescription, s.Qualifications, s.AdditionalInformation} {
+		job.addTitledList(sec.Title, htmlFragmentText(sec.Text))
+	}
+
+	// The qualifications section is the requirements, whatever its title
+	if len(job.requirements) == 0 {
+		job.requirements = textLines(htmlFragmentText(s.Qualifications.Text))
+	}
+
+	return job, nil
+}
@@ -0,0 +1,215 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+// cfbMagic starts every OLE2 compound file, the container of legacy Office
+// documents.
+var cfbMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// Special sector numbers of a compound file.
+const (
+	cfbMaxSector  = 0xFFFFFFFA
+	cfbEndOfChain = 0xFFFFFFFE
+)
+
+// cfbFile is a read-only compound file.
+type cfbFile struct {
+	data       []byte
+	sectorSize int
+	fat        []uint32
+	miniFAT    []uint32
+	miniStream []byte
+	cutoff     uint64
+	entries    []cfbEntry
+}
+
+// cfbEntry is a directory entry.
+type cfbEntry struct {
+	name  string
+	typ   byte // 1 storage, 2 stream, 5 root
+	start uint32
+	size  uint64
+}
+
+// openCFB reads the allocation tables and directory of a compound file.
+func openCFB(data []byte) (*cfbFile, error) {
+	if len(data) < 512 || !bytes.HasPrefix(data, cfbMagic) {
+		return nil, errors.New("not a compound file")
+	}
+
+	shift := binary.LittleEndian.Uint16(data[0x1E:])
+	if shift != 9 && shift != 12 {
+		return nil, errors.New("invalid compound file sector size")
+	}
+
+	f := &cfbFile{
+		data:       data,
+		sectorSize: 1 << shift,
+		cutoff:     uint64(binary.LittleEndian.Uint32(data[0x38:])),
+	}
+
+	numFAT := int(binary.LittleEndian.Uint32(data[0x2C:]))
+	firstDir := binary.LittleEndian.Uint32(data[0x30:])
+	firstMiniFAT := binary.LittleEndian.Uint32(data[0x3C:])
+	firstDIFAT := binary.LittleEndian.Uint32(data[0x44:])
+
+	// The first 109 FAT sectors are listed in the header, the rest in the
+	// DIFAT chain
+	var fatSectors []uint32
+
+	for i := range 109 {
+		if len(fatSectors) >= numFAT {
+			break
+		}
+
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(data[0x4C+4*i:]))
+	}
+
+	perSector := f.sectorSize/4 - 1
+	for sec, n := firstDIFAT, 0; sec <= cfbMaxSector && len(fatSectors) < numFAT && n < numFAT; n++ {
+		s := f.sector(sec)
+		if s == nil {
+			break
+		}
+
+		for i := 0; i < perSector && len(fatSectors) < numFAT; i++ {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(s[4*i:]))
+		}
+
+		sec = binary.LittleEndian.Uint32(s[4*perSector:])
+	}
+
+	for _, sec := range fatSectors {
+		s := f.sector(sec)
+		if s == nil {
+			return nil, errors.New("invalid compound file allocation table")
+		}
+
+		for i := 0; i+4 <= len(s); i += 4 {
+			f.fat = append(f.fat, binary.LittleEndian.Uint32(s[i:]))
+		}
+	}
+
+	dir := f.read(firstDir, f.fat, f.sectorSize, f.sector)
+	for i := 0; i+128 <= len(dir); i += 128 {
+		e := dir[i : i+128]
+
+		nameLen := int(binary.LittleEndian.Uint16(e[0x40:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+
+		units := make([]uint16, 0, 32)
+		for j := 0; j+2 <= nameLen; j += 2 {
+			if u := binary.LittleEndian.Uint16(e[j:]); u != 0 {
+				units = append(units, u)
+			}
+		}
+
+		f.entries = append(f.entries, cfbEntry{
+			name:  string(utf16.Decode(units)),
+			typ:   e[0x42],
+			start: binary.LittleEndian.Uint32(e[0x74:]),
+			size:  binary.LittleEndian.Uint64(e[0x78:]),
+		})
+	}
+
+	if len(f.entries) == 0 || f.entries[0].typ != 5 {
+		return nil, errors.New("compound file has no root entry")
+	}
+
+	if f.sectorSize == 512 {
+		// Version 3 files only use the low 32 bits of stream sizes
+		for i := range f.entries {
+			f.entries[i].size &= 0xFFFFFFFF
+		}
+	}
+
+	miniFAT := f.read(firstMiniFAT, f.fat, f.sectorSize, f.sector)
+	for i := 0; i+4 <= len(miniFAT); i += 4 {
+		f.miniFAT = append(f.miniFAT, binary.LittleEndian.Uint32(miniFAT[i:]))
+	}
+
+	root := f.entries[0]
+	f.miniStream = f.read(root.start, f.fat, f.sectorSize, f.sector)
+
+	if uint64(len(f.miniStream)) > root.size {
+		f.miniStream = f.miniStream[:root.size]
+	}
+
+	return f, nil
+}
+
+// sector returns a sector, or nil when it is out of range.
+func (f *cfbFile) sector(id uint32) []byte {
+	if id > cfbMaxSector {
+		return nil
+	}
+
+	off := (int(id) + 1) * f.sectorSize
+	if off < 0 || off+f.sectorSize > len(f.data) {
+		return nil
+	}
+
+	return f.data[off : off+f.sectorSize]
+}
+
+// miniSector returns a 64-byte sector of the mini stream.
+func (f *cfbFile) miniSector(id uint32) []byte {
+	off := int(id) * 64
+	if id > cfbMaxSector || off < 0 || off+64 > len(f.miniStream) {
+		return nil
+	}
+
+	return f.miniStream[off : off+64]
+}
+
+// read follows a sector chain, stopping at a broken or cyclic link.
+func (f *cfbFile) read(start uint32, table []uint32, size int, sector func(uint32) []byte) []byte {
+	var out []byte
+
+	for id, n := start, 0; id != cfbEndOfChain && n <= len(table); n++ {
+		s := sector(id)
+		if s == nil || int(id) >= len(table) {
+			break
+		}
+
+		out = append(out, s[:size]...)
+		id = table[id]
+	}
+
+	return out
+}
+
+// stream returns the content of a named stream.
+func (f *cfbFile) stream(name string) ([]byte, bool) {
+	for _, e := range f.entries {
+		if e.typ != 2 || !strings.EqualFold(e.name, name) {
+			continue
+		}
+
+		var data []byte
+		if e.size < f.cutoff {
+			data = f.read(e.start, f.miniFAT, 64, f.miniSector)
+		} else {
+			data = f.read(e.start, f.fat, f.sectorSize, f.sector)
+		}
+
+		if uint64(len(data)) > e.size {
+			data = data[:e.size]
+		}
+
+		return data, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,483 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+)
+
+// DateRange is the normalized period of an experience entry, to the month.
+// Years without a month start in January and end in December.
+type DateRange struct {
+	Start   time.Time // First day of the first month
+	End     time.Time // First day of the last month; zero when Current
+	Current bool      // The entry is ongoing, as in "2020 - Present"
+	// YearOnly is set when the range gives no month, as in "2019 - 2021"
+	YearOnly bool
+}
+
+// monthNames maps month names and abbreviations of the supported languages,
+// folded as by i18n.Fold, to months.
+var monthNames = map[string]time.Month{
+	// English
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+	"june": time.June, "jun": time.June, "july": time.July, "jul": time.July, "august": time.August,
+	"aug": time.August, "september": time.September, "sept": time.September, "sep": time.September,
+	"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+	// German
+	"januar": time.January, "februar": time.February, "marz": time.March, "maerz": time.March,
+	"mai": time.May, "juni": time.June, "juli": time.July, "oktober": time.October, "okt": time.October,
+	"dezember": time.December, "dez": time.December,
+	// French
+	"janvier": time.January, "janv": time.January, "fevrier": time.February, "fevr": time.February,
+	"fev": time.February, "mars": time.March, "avril": time.April, "avr": time.April, "juin": time.June,
+	"juillet": time.July, "juil": time.July, "aout": time.August, "septembre": time.September,
+	"octobre": time.October, "novembre": time.November, "decembre": time.December,
+	// Spanish
+	"enero": time.January, "ene": time.January, "febrero": time.February, "marzo": time.March,
+	"abril": time.April, "abr": time.April, "mayo": time.May, "junio": time.June, "julio": time.July,
+	"agosto": time.August, "ago": time.August, "septiembre": time.September, "setiembre": time.September,
+	"set": time.September, "octubre": time.October, "noviembre": time.November, "diciembre": time.December,
+	"dic": time.December,
+	// Portuguese
+	"janeiro": time.January, "fevereiro": time.February, "marco": time.March, "maio": time.May,
+	"junho": time.June, "julho": time.July, "setembro": time.September, "outubro": time.October,
+	"out": time.October, "novembro": time.November, "dezembro": time.December,
+	// Dutch
+	"januari": time.January, "februari": time.February, "maart": time.March, "mrt": time.March,
+	"mei": time.May, "augustus": time.August,
+}
+
+var (
+	// dateToken matches a date or an end word in folded text: a month name
+	// and year ("jan 2020", "enero de 2020", "jan '20"), a numeric month and
+	// year ("03/2018", "3.2018"), an ISO year and month ("2018-03"), a year,
+	// or a word for the present.
+	dateToken = regexp.MustCompile(`\b(?:(` + monthPattern() + `)\.?\s+(?:de\s+)?((?:19|20)\d{2}|'\d{2})\b|` +
+		`(\d{1,2})\s*[/.]\s*((?:19|20)\d{2})\b|` +
+		`((?:19|20)\d{2})-(0?[1-9]|1[0-2])\b|` +
+		`((?:19|20)\d{2})\b|` +
+		`(present|current|currently|now|today|ongoing|date|heute|aktuell|jetzt|dato|aujourd'hui|actuel|actuellement|actualidad|presente|hoy|actual|actualmente|atual|atualmente|momento|heden|huidig|nu)\b)`)
+	// rangeSeparator matches the text between the dates of a range.
+	rangeSeparator = regexp.MustCompile(`^\s*(?:[-–—~]|to|till|until|through|bis|au|a|al|hasta|ate|tot)\s*(?:(?:la|el|o|le|l')\s*)?$`)
+	// shortEndYear matches the two-digit end year of ranges such as "2019–21".
+	shortEndYear = regexp.MustCompile(`^\s*[-–—/]\s*(\d{2})\b`)
+	// sinceWord matches the word before the start date of an ongoing entry.
+	sinceWord = regexp.MustCompile(`\b(?:since|from|seit|ab|depuis|desde|sinds|vanaf)\s*$`)
+)
+
+// monthPattern returns an alternation of the month names, longest first so
+// that "sept" is not read as "sep".
+func monthPattern() string {
+	names := make([]string, 0, len(monthNames))
+	for name := range monthNames {
+		names = append(names, name)
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return strings.Join(names, "|")
+}
+
+// parsedDate is a date token of a range.
+type parsedDate struct {
+	year    int
+	month   time.Month // Zero when only the year is known
+	present bool
+	start   int // Byte offsets in the folded text
+	end     int
+}
+
+// ParseDateRange reads the first date range of a text, such as
+// "Jan 2020 – Present", "2019–21", "03/2018 - 11/2020", "seit März 2021" or
+// "enero de 2018 - diciembre de 2020". A single date is taken for a period
+// within that month or year, or as an ongoing entry after "since".
+func ParseDateRange(s string) (*DateRange, bool) {
+	r, _, ok := FindDateRange(s)
+
+	return r, ok
+}
+
+// FindDateRange is like ParseDateRange, and also returns the text of the
+// range as written in s.
+func FindDateRange(s string) (*DateRange, string, bool) {
+	text, offsets := foldOffsets(s)
+
+	var dates []parsedDate
+
+	for _, m := range dateToken.FindAllStringSubmatchIndex(text, -1) {
+		if d, ok := readDate(text, m); ok {
+			dates = append(dates, d)
+		}
+	}
+
+	found := func(r *DateRange, start, end int, ok bool) (*DateRange, string, bool) {
+		if !ok {
+			return nil, "", false
+		}
+
+		return r, strings.TrimSpace(s[offsets[start]:offsets[end]]), true
+	}
+
+	for i, d := range dates {
+		if d.present {
+			continue
+		}
+
+		// A range to the next date or to the present
+		if i+1 < len(dates) && rangeSeparator.MatchString(text[d.end:dates[i+1].start]) {
+			r, ok := newDateRange(d, dates[i+1])
+
+			return found(r, d.start, dates[i+1].end, ok)
+		}
+
+		// A range to a two-digit year, as in "2019–21"
+		if m := shortEndYear.FindStringSubmatchIndex(text[d.end:]); m != nil && d.month == 0 {
+			year, _ := strconv.Atoi(text[d.end+m[2] : d.end+m[3]])
+			if r, ok := newDateRange(d, parsedDate{year: d.year/100*100 + year}); ok {
+				return found(r, d.start, d.end+m[1], true)
+			}
+		}
+
+		if m := sinceWord.FindStringIndex(text[:d.start]); m != nil {
+			r, ok := newDateRange(d, parsedDate{present: true})
+
+			return found(r, m[0], d.end, ok)
+		}
+
+		r, ok := newDateRange(d, d)
+
+		return found(r, d.start, d.end, ok)
+	}
+
+	return nil, "", false
+}
+
+// foldOffsets folds a text as i18n.Fold does, rune by rune, and returns the
+// offset in s of every byte of the folded text, plus one for its end.
+func foldOffsets(s string) (string, []int) {
+	var (
+		b       strings.Builder
+		offsets = make([]int, 0, len(s)+1)
+	)
+
+	for i, r := range s {
+		folded := i18n.Fold(string(r))
+		b.WriteString(folded)
+
+		for range len(folded) {
+			offsets = append(offsets, i)
+		}
+	}
+
+	return b.String(), append(offsets, len(s))
+}
+
+// readDate reads a date token matched by dateToken.
+func readDate(text string, m []int) (parsedDate, bool) {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+
+		return text[m[2*i]:m[2*i+1]]
+	}
+
+	d := parsedDate{start: m[0], end: m[1]}
+
+	switch {
+	case group(1) != "":
+		d.month = monthNames[group(1)]
+		d.year = fullYear(group(2))
+	case group(3) != "":
+		month, _ := strconv.Atoi(group(3))
+		if month < 1 || month > 12 {
+			return d, false
+		}
+
+		d.month = time.Month(month)
+		d.year, _ = strconv.Atoi(group(4))
+	case group(5) != "":
+		month, _ := strconv.Atoi(group(6))
+		d.month = time.Month(month)
+		d.year, _ = strconv.Atoi(group(5))
+	case group(7) != "":
+		d.year, _ = strconv.Atoi(group(7))
+	default:
+		d.present = true
+	}
+
+	return d, true
+}
+
+// fullYear reads a four-digit year or an abbreviated one such as "'20".
+func fullYear(s string) int {
+	if strings.HasPrefix(s, "'") {
+		year, _ := strconv.Atoi(s[1:])
+		if year > time.Now().Year()%100+1 {
+			return 1900 + year
+		}
+
+		return 2000 + year
+	}
+
+	year, _ := strconv.Atoi(s)
+
+	return year
+}
+
+// newDateRange builds a range from its first and last dates, which must be
+// in order.
+func newDateRange(from, to parsedDate) (*DateRange, bool) {
+	month := from.month
+	if month == 0 {
+		month = time.January
+	}
+
+	r := &DateRange{
+		Start:    time.Date(from.year, month, 1, 0, 0, 0, 0, time.UTC),
+		YearOnly: from.month == 0 && to.month == 0,
+	}
+
+	if to.present {
+		r.Current = true
+
+		return r, true
+	}
+
+	month = to.month
+	if month == 0 {
+		month = time.December
+	}
+
+	r.End = time.Date(to.year, month, 1, 0, 0, 0, 0, time.UTC)
+	if r.End.Before(r.Start) {
+		return nil, false
+	}
+
+	return r, true
+}
+
+// normalizePeriods reads the Period of entries from their Duration, and
+// orders experience most recent first.
+func normalizePeriods(experience []Experience, education []Education) {
+	for i := range experience {
+		experience[i].Period, _ = ParseDateRange(experience[i].Duration)
+	}
+
+	for i := range education {
+		education[i].Period, _ = ParseDateRange(education[i].Duration)
+	}
+
+	SortExperience(experience)
+}
+
+// EndOr returns the last month of the range, or the month of now for
+// ongoing entries.
+func (r *DateRange) EndOr(now time.Time) time.Time {
+	if r.Current {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return r.End
+}
+
+// Months returns the number of months the range covers, counting its first
+// and last months.
+func (r *DateRange) Months(now time.Time) int {
+	return monthsBetween(r.Start, r.EndOr(now)) + 1
+}
+
+// monthsBetween returns the number of months from one month to another.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// Gap is a period without any dated experience entry.
+type Gap struct {
+	Start  time.Time // First month without an entry
+	End    time.Time // Last month without an entry
+	Months int
+}
+
+// ExperienceMonths returns the number of months covered by the dated
+// entries, counting months of overlapping roles once.
+func ExperienceMonths(experience []Experience, now time.Time) int {
+	total := 0
+
+	for _, span := range mergePeriods(experience, now, nil) {
+		total += monthsBetween(span[0], span[1]) + 1
+	}
+
+	return total
+}
+
+// ExperienceGaps returns the periods of at least minMonths months between
+// the dated entries, oldest first.
+func ExperienceGaps(experience []Experience, now time.Time, minMonths int) []Gap {
+	var gaps []Gap
+
+	spans := mergePeriods(experience, now, nil)
+	for i := 1; i < len(spans); i++ {
+		months := monthsBetween(spans[i-1][1], spans[i][0]) - 1
+		if months >= minMonths && months > 0 {
+			gaps = append(gaps, Gap{
+				Start:  spans[i-1][1].AddDate(0, 1, 0),
+				End:    spans[i][0].AddDate(0, -1, 0),
+				Months: months,
+			})
+		}
+	}
+
+	return gaps
+}
+
+// SortExperience orders entries most recent first: ongoing roles, then by
+// end and start month. Undated entries keep their order after the rest.
+func SortExperience(experience []Experience) {
+	far := time.Date(9999, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	slices.SortStableFunc(experience, func(a, b Experience) int {
+		switch {
+		case a.Period == nil || b.Period == nil:
+			return boolOrder(a.Period == nil, b.Period == nil)
+		case a.Period.EndOr(far) != b.Period.EndOr(far):
+			return b.Period.EndOr(far).Compare(a.Period.EndOr(far))
+		default:
+			return b.Period.Start.Compare(a.Period.Start)
+		}
+	})
+}
+
+// boolOrder orders false before true.
+func boolOrder(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// SkillTenure returns, for each skill, the number of months of the dated
+// entries whose title or description mentions it, counting months of
+// overlapping roles once. Skills of the taxonomy are also mentioned by their
+// aliases and narrower skills, so "K8s" counts for Kubernetes. Skills no
+// entry mentions are left out.
+func SkillTenure(experience []Experience, skillNames []string, now time.Time) map[string]int {
+	tenure := make(map[string]int)
+	taxonomy := skills.Default()
+
+	texts := make([]string, len(experience))
+	ids := make([][]string, len(experience))
+
+	for i, exp := range experience {
+		texts[i] = " " + strings.Join(strings.FieldsFunc(i18n.Fold(exp.Title+" "+exp.Description), isWordSeparator), " ") + " "
+		ids[i] = taxonomy.IDs(exp.Title + "\n" + exp.Description)
+	}
+
+	for _, name := range skillNames {
+		mentions := func(i int) bool {
+			return false
+		}
+
+		if skill, ok := taxonomy.Resolve(name); ok {
+			mentions = func(i int) bool {
+				return taxonomy.Covers(ids[i], skill.ID)
+			}
+		} else if words := strings.FieldsFunc(i18n.Fold(name), isWordSeparator); len(words) > 0 {
+			needle := " " + strings.Join(words, " ") + " "
+			mentions = func(i int) bool {
+				return strings.Contains(texts[i], needle)
+			}
+		}
+
+		months := 0
+		for _, span := range mergePeriods(experience, now, mentions) {
+			months += monthsBetween(span[0], span[1]) + 1
+		}
+
+		if months > 0 {
+			tenure[name] = months
+		}
+	}
+
+	return tenure
+}
+
+// isWordSeparator reports whether r separates the words of a skill mention.
+// Symbols such as the "+" of "C++" and the "#" of "C#" are part of words.
+func isWordSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(",;:()[]/|\"", r) ||
+		r == '.' || r == '!' || r == '?'
+}
+
+// mergePeriods returns the first and last months of the dated entries,
+// optionally only those keep accepts, with overlapping or adjoining periods
+// merged, oldest first.
+func mergePeriods(experience []Experience, now time.Time, keep func(int) bool) [][2]time.Time {
+	var spans [][2]time.Time
+
+	for i, exp := range experience {
+		if exp.Period != nil && (keep == nil || keep(i)) {
+			spans = append(spans, [2]time.Time{exp.Period.Start, exp.Period.EndOr(now)})
+		}
+	}
+
+	slices.SortFunc(spans, func(a, b [2]time.Time) int {
+		return a[0].Compare(b[0])
+	})
+
+	var merged [][2]time.Time
+
+	for _, span := range spans {
+		if n := len(merged); n > 0 && monthsBetween(merged[n-1][1], span[0]) <= 1 {
+			if span[1].After(merged[n-1][1]) {
+				merged[n-1][1] = span[1]
+			}
+
+			continue
+		}
+
+		merged = append(merged, span)
+	}
+
+	return merged
+}
+
+// requiredYears matches a years-of-experience requirement such as
+// "5+ years", "3-5 years", "mindestens 4 Jahre" or "10 ans d'expérience".
+var requiredYears = regexp.MustCompile(`\b(\d{1,2})\s*(?:\+|(?:-|–|to|a|bis)\s*\d{1,2})?\s*(?:\+\s*)?(?:years?|yrs?|jahren?|ans|anos|jaar)\b`)
+
+// ParseRequiredYears returns the years of experience a job posting asks
+// for: the largest minimum of its "5+ years" style requirements, or zero.
+func ParseRequiredYears(s string) int {
+	years := 0
+
+	for _, m := range requiredYears.FindAllStringSubmatch(i18n.Fold(s), -1) {
+		if n, _ := strconv.Atoi(m[1]); n > years && n <= 40 {
+			years = n
+		}
+	}
+
+	return years
+}
@@ -0,0 +1,735 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DOCParser handles legacy Word 97-2003 (.doc) file parsing.
+type DOCParser struct {
+	maxFileSize int64
+}
+
+// NewDOCParser creates a new DOC parser.
+func NewDOCParser(maxFileSize int64) *DOCParser {
+	if maxFileSize == 0 {
+		maxFileSize = 50 * 1024 * 1024 // 50MB default
+	}
+
+	return &DOCParser{
+		maxFileSize: maxFileSize,
+	}
+}
+
+// ParseFile extracts text from a DOC file.
+func (p *DOCParser) ParseFile(filePath string) (string, error) {
+	content, err := readLimitedFile(filePath, p.maxFileSize)
+	if err != nil {
+		return "", err
+	}
+
+	return p.ParseBytes(content)
+}
+
+// ParseBytes extracts text from DOC bytes.
+func (p *DOCParser) ParseBytes(data []byte) (string, error) {
+	doc, err := p.ParseDocument(data)
+	if err != nil {
+		return "", err
+	}
+
+	return doc.Text(), nil
+}
+
+// ParseDocument reads the structure of a Word 97-2003 binary document:
+// headings from paragraph styles and outline levels, lists, tables, HYPERLINK
+// fields, and the header and footer text. RTF files saved with a .doc
+// extension are read as RTF.
+func (p *DOCParser) ParseDocument(data []byte) (*Document, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty DOC data")
+	}
+
+	if int64(len(data)) > p.maxFileSize {
+		return nil, fmt.Errorf("file size exceeds maximum allowed (%d bytes)", p.maxFileSize)
+	}
+
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(`{\rtf`)) {
+		return NewRTFParser(p.maxFileSize).ParseDocument(data)
+	}
+
+	cfb, err := openCFB(data)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid DOC file: %w", err)
+	}
+
+	r, err := newDOCReader(cfb)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := r.read()
+	if len(doc.Blocks) == 0 && len(doc.Header) == 0 && len(doc.Footer) == 0 {
+		return nil, errors.New("no text content found in DOC")
+	}
+
+	return doc, nil
+}
+
+// Offsets and flags of the Word 97 file information block (FIB).
+const (
+	fibIdent      = 0xA5EC
+	fibMinVersion = 101 // Word 6 and 95 use an older format
+	fibEncrypted  = 0x0100
+	fib1Table     = 0x0200
+
+	// Indexes of the FibRgFcLcb97 offset/length pairs used
+	fcStshf       = 1
+	fcPlcfHdd     = 11
+	fcPlcfBtePapx = 13
+	fcClx         = 33
+
+	// Indexes of the FibRgLw97 character counts
+	lwCcpText = 3
+	lwCcpFtn  = 4
+	lwCcpHdd  = 5
+)
+
+// Paragraph property modifiers (sprms) read from PAPX.
+const (
+	sprmPIlvl      = 0x260A
+	sprmPIlfo      = 0x460B
+	sprmPOutLvl    = 0x2640
+	sprmPFInTable  = 0x2416
+	sprmPFTtp      = 0x2417
+	sprmPFInnerTtp = 0x244C
+)
+
+// docPiece is one entry of the piece table, which maps character positions
+// (CPs) to the text in the WordDocument stream.
+type docPiece struct {
+	cpStart, cpEnd int
+	fc             int // Byte offset of the text
+	compressed     bool
+}
+
+// docStyle is a paragraph style of the stylesheet.
+type docStyle struct {
+	sti  int
+	name string
+}
+
+// docPara is the paragraph formatting that applies at a CP.
+type docPara struct {
+	istd    int
+	list    bool
+	level   int
+	outline int
+	inTable bool
+	ttp     bool
+}
+
+// docReader reads the text and paragraph formatting of a Word document.
+type docReader struct {
+	word   []byte
+	table  []byte
+	pieces []docPiece
+	styles map[int]docStyle
+	btePap []uint32 // FC boundaries of the paragraph property pages
+	pnPap  []uint32 // Page numbers of the paragraph property pages
+	ccp    [3]int   // Main text, footnote and header story lengths
+	hdd    []int    // Header story boundaries
+	doc    *Document
+}
+
+func newDOCReader(cfb *cfbFile) (*docReader, error) {
+	word, ok := cfb.stream("WordDocument")
+	if !ok || len(word) < 0x20 {
+		return nil, errors.New("not a valid DOC file: WordDocument stream not found")
+	}
+
+	if binary.LittleEndian.Uint16(word) != fibIdent {
+		return nil, errors.New("not a valid DOC file: bad file information block")
+	}
+
+	if binary.LittleEndian.Uint16(word[2:]) < fibMinVersion {
+		return nil, errors.New("unsupported DOC version: Word 95 and earlier are not supported")
+	}
+
+	flags := binary.LittleEndian.Uint16(word[0x0A:])
+	if flags&fibEncrypted != 0 {
+		return nil, errors.New("DOC is password protected")
+	}
+
+	tableName := "0Table"
+	if flags&fib1Table != 0 {
+		tableName = "1Table"
+	}
+
+	table, ok := cfb.stream(tableName)
+	if !ok {
+		return nil, errors.New("not a valid DOC file: table stream not found")
+	}
+
+	r := &docReader{word: word, table: table, styles: map[int]docStyle{}, doc: &Document{}}
+
+	// FibBase (32 bytes), then FibRgW, FibRgLw and FibRgFcLcb, each with its
+	// count first
+	off := 32
+
+	csw := int(r.u16(word, off))
+	off += 2 + 2*csw
+
+	cslw := int(r.u16(word, off))
+	lw := off + 2
+	off = lw + 4*cslw
+
+	cbRgFcLcb := int(r.u16(word, off))
+	fcLcb := off + 2
+
+	// section returns the table stream data of an offset/length pair
+	section := func(i int) []byte {
+		if i >= cbRgFcLcb {
+			return nil
+		}
+
+		return slice(table, int(r.u32(word, fcLcb+8*i)), int(r.u32(word, fcLcb+8*i+4)))
+	}
+
+	for i, idx := range []int{lwCcpText, lwCcpFtn, lwCcpHdd} {
+		if idx < cslw {
+			r.ccp[i] = int(int32(r.u32(word, lw+4*idx)))
+		}
+	}
+
+	if !r.readPieces(section(fcClx)) {
+		return nil, errors.New("not a valid DOC file: piece table not found")
+	}
+
+	r.readStyles(section(fcStshf))
+	r.readPlc(section(fcPlcfBtePapx))
+
+	if plc := section(fcPlcfHdd); len(plc) >= 8 {
+		for i := 0; i+4 <= len(plc); i += 4 {
+			r.hdd = append(r.hdd, int(r.u32(plc, i)))
+		}
+	}
+
+	return r, nil
+}
+
+// slice returns data[off:off+n], or nil when it is out of range.
+func slice(data []byte, off, n int) []byte {
+	if off < 0 || n <= 0 || off+n > len(data) || off+n < off {
+		return nil
+	}
+
+	return data[off : off+n]
+}
+
+func (r *docReader) u16(data []byte, off int) uint16 {
+	if off < 0 || off+2 > len(data) {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint16(data[off:])
+}
+
+func (r *docReader) u32(data []byte, off int) uint32 {
+	if off < 0 || off+4 > len(data) {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(data[off:])
+}
+
+// readPieces reads the piece table of the Clx.
+func (r *docReader) readPieces(clx []byte) bool {
+	for i := 0; i < len(clx); {
+		switch clx[i] {
+		case 1: // Prc, formatting of pieces
+			cb := int(int16(r.u16(clx, i+1)))
+			if cb < 0 {
+				return false
+			}
+
+			i += 3 + cb
+		case 2: // Pcdt, the piece table
+			plc := slice(clx, i+5, int(r.u32(clx, i+1)))
+			n := (len(plc) - 4) / 12
+
+			for j := range n {
+				fc := r.u32(plc, 4*(n+1)+8*j+2)
+				piece := docPiece{
+					cpStart: int(r.u32(plc, 4*j)),
+					cpEnd:   int(r.u32(plc, 4*(j+1))),
+					fc:      int(fc & 0x3FFFFFFF),
+				}
+
+				if fc&0x40000000 != 0 {
+					piece.compressed = true
+					piece.fc /= 2
+				}
+
+				r.pieces = append(r.pieces, piece)
+			}
+
+			return len(r.pieces) > 0
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// readStyles reads the style names and built-in identifiers of the
+// stylesheet (STSH).
+func (r *docReader) readStyles(stsh []byte) {
+	cbStshi := int(r.u16(stsh, 0))
+	if cbStshi < 4 {
+		return
+	}
+
+	cstd := int(r.u16(stsh, 2))
+	cbBase := int(r.u16(stsh, 4))
+	pos := 2 + cbStshi
+
+	for istd := 0; istd < cstd && pos+2 <= len(stsh); istd++ {
+		cb := int(r.u16(stsh, pos))
+		std := slice(stsh, pos+2, cb)
+		pos += 2 + cb
+
+		if std == nil || cbBase < 4 || cbBase+2 > len(std) {
+			continue
+		}
+
+		style := docStyle{sti: int(r.u16(std, 0) & 0x0FFF)}
+
+		cch := int(r.u16(std, cbBase))
+		units := make([]uint16, 0, cch)
+
+		for i := 0; i < cch && cbBase+2+2*i+2 <= len(std); i++ {
+			units = append(units, r.u16(std, cbBase+2+2*i))
+		}
+
+		style.name = string(utf16.Decode(units))
+		r.styles[istd] = style
+	}
+}
+
+// readPlc reads the bin table of paragraph property pages.
+func (r *docReader) readPlc(plc []byte) {
+	n := (len(plc) - 4) / 8
+	if n <= 0 {
+		return
+	}
+
+	for i := 0; i <= n; i++ {
+		r.btePap = append(r.btePap, r.u32(plc, 4*i))
+	}
+
+	for i := range n {
+		r.pnPap = append(r.pnPap, r.u32(plc, 4*(n+1)+4*i)&0x3FFFFF)
+	}
+}
+
+// fc returns the byte offset of the character at cp.
+func (r *docReader) fc(cp int) (int, bool) {
+	for _, p := range r.pieces {
+		if cp >= p.cpStart && cp < p.cpEnd {
+			if p.compressed {
+				return p.fc + cp - p.cpStart, true
+			}
+
+			return p.fc + 2*(cp-p.cpStart), true
+		}
+	}
+
+	return 0, false
+}
+
+// text returns the UTF-16 code units of the characters in [from, to).
+func (r *docReader) text(from, to int) []uint16 {
+	var out []uint16
+
+	for _, p := range r.pieces {
+		start, end := max(from, p.cpStart), min(to, p.cpEnd)
+		if start >= end {
+			continue
+		}
+
+		for cp := start; cp < end; cp++ {
+			if p.compressed {
+				off := p.fc + cp - p.cpStart
+				if off >= len(r.word) {
+					break
+				}
+
+				out = append(out, uint16(charmap.Windows1252.DecodeByte(r.word[off])))
+			} else {
+				off := p.fc + 2*(cp-p.cpStart)
+				if off+2 > len(r.word) {
+					break
+				}
+
+				out = append(out, binary.LittleEndian.Uint16(r.word[off:]))
+			}
+		}
+	}
+
+	return out
+}
+
+// para returns the paragraph formatting of the paragraph whose mark is at cp.
+func (r *docReader) para(cp int) docPara {
+	var p docPara
+
+	fc, ok := r.fc(cp)
+	if !ok || len(r.pnPap) == 0 {
+		return p
+	}
+
+	i := sort.Search(len(r.pnPap), func(i int) bool { return int(r.btePap[i+1]) > fc })
+	if i >= len(r.pnPap) {
+		return p
+	}
+
+	fkp := slice(r.word, int(r.pnPap[i])*512, 512)
+	if fkp == nil {
+		return p
+	}
+
+	crun := int(fkp[511])
+	if 4*(crun+1)+13*crun > 511 {
+		return p
+	}
+
+	j := -1
+
+	for k := range crun {
+		if int(r.u32(fkp, 4*k)) <= fc && fc < int(r.u32(fkp, 4*(k+1))) {
+			j = k
+
+			break
+		}
+	}
+
+	if j < 0 {
+		return p
+	}
+
+	off := 2 * int(fkp[4*(crun+1)+13*j])
+	if off == 0 || off >= 511 {
+		return p
+	}
+
+	start, size := off+1, 2*int(fkp[off])-1
+	if fkp[off] == 0 && off+1 < 511 {
+		start, size = off+2, 2*int(fkp[off+1])
+	}
+
+	papx := slice(fkp, start, size)
+	if len(papx) < 2 {
+		return p
+	}
+
+	p.istd = int(r.u16(papx, 0))
+	r.applySprms(&p, papx[2:])
+
+	return p
+}
+
+// applySprms applies the paragraph sprms of interest in a grpprl.
+func (r *docReader) applySprms(p *docPara, grpprl []byte) {
+	for i := 0; i+2 <= len(grpprl); {
+		sprm := r.u16(grpprl, i)
+		i += 2
+
+		var size int
+
+		switch sprm >> 13 {
+		case 0, 1:
+			size = 1
+		case 2, 4, 5:
+			size = 2
+		case 3:
+			size = 4
+		case 7:
+			size = 3
+		default: // Variable length
+			if i >= len(grpprl) {
+				return
+			}
+
+			size = 1 + int(grpprl[i])
+			if sprm == 0xD608 || sprm == 0xC615 {
+				size = 2 + int(r.u16(grpprl, i))
+			}
+		}
+
+		if i+size > len(grpprl) {
+			return
+		}
+
+		operand := grpprl[i : i+size]
+		i += size
+
+		switch sprm {
+		case sprmPIlvl:
+			p.level = int(operand[0])
+		case sprmPIlfo:
+			p.list = r.u16(operand, 0) != 0
+		case sprmPOutLvl:
+			if operand[0] < 9 {
+				p.outline = int(operand[0]) + 1
+			}
+		case sprmPFInTable:
+			p.inTable = operand[0] != 0
+		case sprmPFTtp, sprmPFInnerTtp:
+			p.ttp = operand[0] != 0
+		}
+	}
+}
+
+// heading returns the heading level and list flag of a paragraph style.
+func (r *docReader) heading(istd int) (int, bool) {
+	style, ok := r.styles[istd]
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case style.sti >= 1 && style.sti <= 9:
+		return style.sti, false
+	case style.sti == 62: // Title
+		return 1, false
+	}
+
+	if m := docxHeadingStyle.FindStringSubmatch(style.name); m != nil {
+		level, _ := strconv.Atoi(m[1])
+
+		return level, false
+	}
+
+	return 0, strings.HasPrefix(strings.ToLower(style.name), "list ")
+}
+
+// docField is an open field of the text being read.
+type docField struct {
+	instr     []uint16
+	separated bool
+	start     int // Length of the paragraph text when the result starts
+}
+
+// read reads the main document, then the header and footer stories.
+func (r *docReader) read() *Document {
+	var (
+		blocks  []Block
+		text    []uint16
+		fields  []*docField
+		cell    []Block
+		row     [][]Block
+		rows    [][][]Block
+		lastCh  uint16
+		mainEnd = r.ccp[0]
+	)
+
+	flushTable := func() {
+		if len(cell) > 0 {
+			row = append(row, cell)
+			cell = nil
+		}
+
+		if len(row) > 0 {
+			rows = append(rows, row)
+			row = nil
+		}
+
+		if len(rows) > 0 {
+			blocks = append(blocks, tableBlocks(rows)...)
+			rows = nil
+		}
+	}
+
+	endParagraph := func(cp int) (Block, bool, docPara) {
+		props := r.para(cp)
+		heading, styleList := r.heading(props.istd)
+
+		if heading == 0 {
+			heading = props.outline
+		}
+
+		b, ok := paragraph{
+			text:    string(utf16.Decode(text)),
+			heading: heading,
+			list:    props.list || styleList,
+			level:   props.level,
+		}.block()
+
+		text = text[:0]
+
+		return b, ok, props
+	}
+
+	units := r.text(0, mainEnd)
+
+	for i, ch := range units {
+		// Fields: instructions between 0x13 and 0x14, results up to 0x15
+		switch ch {
+		case 0x13:
+			fields = append(fields, &docField{})
+			lastCh = ch
+
+			continue
+		case 0x14:
+			if n := len(fields); n > 0 {
+				fields[n-1].separated = true
+				fields[n-1].start = len(text)
+			}
+
+			lastCh = ch
+
+			continue
+		case 0x15:
+			if n := len(fields); n > 0 {
+				f := fields[n-1]
+				fields = fields[:n-1]
+
+				if url := fieldURL(string(utf16.Decode(f.instr))); url != "" && f.separated && f.start <= len(text) {
+					linked := r.doc.addLink(string(utf16.Decode(text[f.start:])), url)
+					text = append(text[:f.start], utf16.Encode([]rune(linked))...)
+				}
+			}
+
+			lastCh = ch
+
+			continue
+		}
+
+		if n := len(fields); n > 0 && !fields[n-1].separated {
+			fields[n-1].instr = append(fields[n-1].instr, ch)
+
+			continue
+		}
+
+		switch ch {
+		case '\r', 0x0C:
+			b, ok, props := endParagraph(i)
+
+			switch {
+			case props.inTable && ok:
+				cell = append(cell, b)
+			case !props.inTable:
+				flushTable()
+
+				if ok {
+					blocks = append(blocks, b)
+				}
+			}
+		case 0x07:
+			b, ok, props := endParagraph(i)
+
+			if props.ttp || (lastCh == 0x07 && !ok) {
+				// End of a table row
+				if len(cell) > 0 {
+					row = append(row, cell)
+					cell = nil
+				}
+
+				rows = append(rows, row)
+				row = nil
+			} else {
+				if ok {
+					cell = append(cell, b)
+				}
+
+				row = append(row, cell)
+				cell = nil
+			}
+		case 0x0B:
+			text = append(text, '\n')
+		case 0x1E:
+			text = append(text, '-')
+		case 0x09:
+			text = append(text, '\t')
+		case 0xA0:
+			text = append(text, ' ')
+		default:
+			if ch >= 0x20 {
+				text = append(text, ch)
+			}
+		}
+
+		lastCh = ch
+	}
+
+	flushTable()
+
+	if b, ok, _ := endParagraph(mainEnd); ok {
+		blocks = append(blocks, b)
+	}
+
+	r.doc.Blocks = blocks
+	r.readHeaders()
+
+	return r.doc
+}
+
+// readHeaders reads the header and footer stories, which follow the main
+// text and the footnotes.
+func (r *docReader) readHeaders() {
+	base := r.ccp[0] + r.ccp[1]
+
+	story := func(from, to int) string {
+		var (
+			sb     strings.Builder
+			fields []bool // Whether each open field has reached its result
+		)
+
+		for _, ch := range utf16.Decode(r.text(base+from, base+to)) {
+			switch {
+			case ch == 0x13:
+				fields = append(fields, false)
+			case ch == 0x14 && len(fields) > 0:
+				fields[len(fields)-1] = true
+			case ch == 0x15 && len(fields) > 0:
+				fields = fields[:len(fields)-1]
+			case len(fields) > 0 && !fields[len(fields)-1]:
+				// Field instruction
+			case ch == '\r' || ch == 0x0B || ch == 0x07:
+				sb.WriteByte('\n')
+			case ch == '\t' || ch >= 0x20:
+				sb.WriteRune(ch)
+			}
+		}
+
+		return sb.String()
+	}
+
+	// Stories 0-5 are separators; then each section has even and odd
+	// headers, even and odd footers, and first page header and footer
+	for i := 6; i+1 < len(r.hdd); i++ {
+		from, to := r.hdd[i], min(r.hdd[i+1], r.ccp[2])
+		if from >= to {
+			continue
+		}
+
+		if k := (i - 6) % 6; k == 2 || k == 3 || k == 5 {
+			r.doc.Footer = addLines(r.doc.Footer, story(from, to))
+		} else {
+			r.doc.Header = addLines(r.doc.Header, story(from, to))
+		}
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+)
+
+// BlockKind identifies the kind of a document block.
+type BlockKind int
+
+// Document block kinds.
+const (
+	BlockParagraph BlockKind = iota
+	BlockHeading
+	BlockListItem
+	BlockTable
+)
+
+// Block is one paragraph, heading, list item or table of a document.
+type Block struct {
+	Kind  BlockKind
+	Level int        // Heading level (1 is the top) or list nesting level (0 is the top)
+	Text  string     // Empty for tables
+	Rows  [][]string // Table cells
+}
+
+// Link is a hyperlink of a document.
+type Link struct {
+	Text string
+	URL  string
+}
+
+// Document is the structure of a word-processor document (DOCX, DOC, ODT or
+// RTF): its body blocks in reading order, the text of its page headers and
+// footers, which often hold contact details, and its hyperlinks.
+type Document struct {
+	Blocks []Block
+	Header []string
+	Footer []string
+	Links  []Link
+}
+
+// Section is a top-level heading of a document and the blocks under it.
+type Section struct {
+	Title  string
+	Blocks []Block
+}
+
+// CV section kinds, as recognized from section headings.
+const (
+	sectionUnknown        = i18n.SectionUnknown
+	sectionSummary        = i18n.SectionSummary
+	sectionExperience     = i18n.SectionExperience
+	sectionEducation      = i18n.SectionEducation
+	sectionSkills         = i18n.SectionSkills
+	sectionCertifications = i18n.SectionCertifications
+)
+
+// sectionKind classifies a heading as a CV section, in any of the supported
+// languages.
+func sectionKind(heading string) i18n.Section {
+	return i18n.HeadingSection(heading)
+}
+
+// Text renders the document as plain text: headings set apart by a blank
+// line, list items as "•" bullets indented by level, and table rows as cells
+// separated by " | ". Header lines come first and footer lines last.
+func (d *Document) Text() string {
+	var lines []string
+
+	blank := func() {
+		if len(lines) > 0 && lines[len(lines)-1] != "" {
+			lines = append(lines, "")
+		}
+	}
+
+	lines = append(lines, d.Header...)
+
+	for _, b := range d.Blocks {
+		switch b.Kind {
+		case BlockHeading:
+			blank()
+			lines = append(lines, b.Text)
+		case BlockListItem:
+			lines = append(lines, strings.Repeat("  ", b.Level)+"• "+b.Text)
+		case BlockTable:
+			for _, row := range b.Rows {
+				var cells []string
+
+				for _, cell := range row {
+					if cell = strings.TrimSpace(cell); cell != "" {
+						cells = append(cells, cell)
+					}
+				}
+
+				if len(cells) > 0 {
+					lines = append(lines, strings.Join(cells, " | "))
+				}
+			}
+		default:
+			lines = append(lines, b.Text)
+		}
+	}
+
+	if len(d.Footer) > 0 {
+		blank()
+		lines = append(lines, d.Footer...)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Sections splits the document at its section headings. Section headings are
+// the headings at the level of the highest heading that names a CV section;
+// deeper headings, such as job titles, stay inside their section. Blocks
+// before the first section heading form a section with an empty title. It
+// returns nil when no heading names a CV section.
+func (d *Document) Sections() []Section {
+	level := 0
+
+	for _, b := range d.Blocks {
+		if b.Kind == BlockHeading && sectionKind(b.Text) != sectionUnknown && (level == 0 || b.Level < level) {
+			level = b.Level
+		}
+	}
+
+	if level == 0 {
+		return nil
+	}
+
+	sections := []Section{{}}
+
+	for _, b := range d.Blocks {
+		if b.Kind == BlockHeading && b.Level <= level && sectionKind(b.Text) != sectionUnknown {
+			sections = append(sections, Section{Title: b.Text})
+
+			continue
+		}
+
+		last := &sections[len(sections)-1]
+		last.Blocks = append(last.Blocks, b)
+	}
+
+	return sections
+}
+
+// addLink records a hyperlink and returns the link text to show, which
+// carries the URL when the text does not already.
+func (d *Document) addLink(text, url string) string {
+	url = strings.TrimSpace(url)
+	if url == "" || strings.HasPrefix(url, "#") {
+		return text
+	}
+
+	d.Links = append(d.Links, Link{Text: strings.TrimSpace(text), URL: url})
+
+	display := strings.TrimPrefix(url, "mailto:")
+	if strings.TrimSpace(text) == "" {
+		return display
+	}
+
+	if strings.Contains(text, display) || strings.Contains(display, strings.TrimSpace(text)) {
+		return text
+	}
+
+	return text + " (" + display + ")"
+}
+
+// hyperlinkField matches the instruction of a HYPERLINK field.
+var hyperlinkField = regexp.MustCompile(`(?i)^\s*HYPERLINK\s+(?:\\l\s+)?"?([^"\s]+)"?`)
+
+// fieldURL returns the target of a HYPERLINK field instruction.
+func fieldURL(instr string) string {
+	m := hyperlinkField.FindStringSubmatch(instr)
+	if m == nil || strings.Contains(instr, `\l`) {
+		return ""
+	}
+
+	return m[1]
+}
+
+// paragraph is a paragraph as read from a document, before it is classified
+// as a heading, list item or plain paragraph.
+type paragraph struct {
+	text    string
+	heading int  // Heading level from the paragraph style or outline level
+	list    bool // Numbered or bulleted
+	level   int  // List nesting level
+	bold    bool // Every run with text is bold
+}
+
+// listMarker matches a bullet or number typed at the start of a paragraph.
+var listMarker = regexp.MustCompile(`^(?:[•◦▪▫■□●○‣⁃∙·*\-–—►➢✓✔]|\d{1,2}[.)])[\s\t]+`)
+
+// maxHeadingRunes is the longest text treated as a heading by formatting.
+const maxHeadingRunes = 60
+
+// block classifies a paragraph. Headings come from styles, or from
+// formatting: a short upper-case line, or a short bold one naming a CV
+// section, is a top-level heading and any other short bold line a subheading.
+func (p paragraph) block() (Block, bool) {
+	text := strings.TrimSpace(cleanSpaces(p.text))
+	if text == "" {
+		return Block{}, false
+	}
+
+	switch {
+	case p.heading > 0:
+		return Block{Kind: BlockHeading, Level: p.heading, Text: text}, true
+	case p.list:
+		return Block{Kind: BlockListItem, Level: p.level, Text: listMarker.ReplaceAllString(text, "")}, true
+	}
+
+	if m := listMarker.FindString(text); m != "" && len(m) < len(text) {
+		return Block{Kind: BlockListItem, Text: strings.TrimSpace(text[len(m):])}, true
+	}
+
+	short := utf8.RuneCountInString(text) <= maxHeadingRunes && !strings.HasSuffix(text, ".") && !strings.Contains(text, "\n")
+	if short && (p.bold || isUpper(text)) {
+		if sectionKind(strings.TrimSuffix(text, ":")) != sectionUnknown || isUpper(text) {
+			return Block{Kind: BlockHeading, Level: 1, Text: text}, true
+		}
+
+		return Block{Kind: BlockHeading, Level: 2, Text: text}, true
+	}
+
+	return Block{Kind: BlockParagraph, Text: text}, true
+}
+
+// isUpper reports whether text has letters and all of them are upper case.
+func isUpper(text string) bool {
+	letters := false
+
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+
+			letters = true
+		}
+	}
+
+	return letters
+}
+
+// cleanSpaces collapses runs of spaces and trims every line of a paragraph.
+func cleanSpaces(text string) string {
+	text = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u00a0', '\u2007', '\u202f':
+			return ' '
+		case '\u00ad', '\u200b', '\ufeff':
+			return -1
+		}
+
+		return r
+	}, text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.FieldsFunc(line, func(r rune) bool { return r == ' ' }), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// tableBlocks turns table rows, whose cells are lists of blocks, into
+// document blocks. Tables used for page layout, with headings or several
+// paragraphs in a cell, are unpacked cell by cell; other tables, such as
+// skills grids, become a table block.
+func tableBlocks(rows [][][]Block) []Block {
+	layout := false
+
+	for _, row := range rows {
+		for _, cell := range row {
+			if len(cell) > 3 {
+				layout = true
+			}
+
+			for _, b := range cell {
+				if b.Kind == BlockHeading && sectionKind(b.Text) != sectionUnknown || b.Kind == BlockTable {
+					layout = true
+				}
+			}
+		}
+	}
+
+	if layout {
+		var blocks []Block
+
+		for _, row := range rows {
+			for _, cell := range row {
+				blocks = append(blocks, cell...)
+			}
+		}
+
+		return blocks
+	}
+
+	table := Block{Kind: BlockTable}
+
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+
+		for _, cell := range row {
+			texts := make([]string, 0, len(cell))
+			for _, b := range cell {
+				texts = append(texts, b.Text)
+			}
+
+			cells = append(cells, strings.Join(texts, " "))
+		}
+
+		table.Rows = append(table.Rows, cells)
+	}
+
+	if len(table.Rows) == 0 {
+		return nil
+	}
+
+	return []Block{table}
+}
+
+// addLines appends the non-empty lines of text not already in lines.
+func addLines(lines []string, text string) []string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "• "))
+		if line == "" {
+			continue
+		}
+
+		seen := false
+
+		for _, l := range lines {
+			if l == line {
+				seen = true
+
+				break
+			}
+		}
+
+		if !seen {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// readLimitedFile reads a file of at most maxSize bytes.
+func readLimitedFile(filePath string, maxSize int64) ([]byte, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if fileInfo.Size() > maxSize {
+		return nil, fmt.Errorf("file size exceeds maximum allowed (%d bytes)", maxSize)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, nil
+}
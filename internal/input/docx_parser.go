@@ -5,11 +5,15 @@ package input
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -31,147 +35,654 @@ func NewDOCXParser(maxFileSize int64) *DOCXParser {
 
 // ParseFile extracts text from a DOCX file.
 func (p *DOCXParser) ParseFile(filePath string) (string, error) {
-	// Check file size
-	fileInfo, err := os.Stat(filePath)
+	content, err := readLimitedFile(filePath, p.maxFileSize)
 	if err != nil {
-		return "", fmt.Errorf("failed to stat file: %w", err)
+		return "", err
 	}
 
-	if fileInfo.Size() > p.maxFileSize {
-		return "", fmt.Errorf("file size exceeds maximum allowed (%d bytes)", p.maxFileSize)
-	}
+	return p.ParseBytes(content)
+}
 
-	// Open file
-	file, err := os.Open(filePath)
+// ParseBytes extracts text from DOCX bytes.
+func (p *DOCXParser) ParseBytes(data []byte) (string, error) {
+	doc, err := p.ParseDocument(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return "", err
+	}
+
+	return doc.Text(), nil
+}
+
+// ParseDocument reads the structure of a DOCX file: headings from paragraph
+// styles and outline levels, numbered and bulleted lists, tables, text boxes,
+// hyperlinks, and the page headers and footers.
+func (p *DOCXParser) ParseDocument(data []byte) (*Document, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty DOCX data")
 	}
-	defer file.Close()
 
-	// Read all content
-	content, err := io.ReadAll(file)
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("not a valid DOCX file: %w", err)
 	}
 
-	return p.ParseBytes(content)
-}
+	pkg := &docxPackage{files: map[string]*zip.File{}, maxSize: p.maxFileSize}
+	for _, f := range reader.File {
+		pkg.files[f.Name] = f
+	}
 
-// ParseBytes extracts text from DOCX bytes.
-func (p *DOCXParser) ParseBytes(data []byte) (string, error) {
-	if len(data) == 0 {
-		return "", errors.New("empty DOCX data")
+	main := pkg.mainPart()
+	if main == "" {
+		return nil, errors.New("document.xml not found in DOCX file")
 	}
 
-	// DOCX is a ZIP file, try to open it as such
-	reader, err := zip.NewReader(strings.NewReader(string(data)), int64(len(data)))
+	content, err := pkg.read(main)
 	if err != nil {
-		return "", fmt.Errorf("not a valid DOCX file: %w", err)
+		return nil, fmt.Errorf("failed to read document.xml: %w", err)
 	}
 
-	// Look for document.xml
-	var docFile *zip.File
+	r := &docxReader{styles: map[string]*docxStyle{}}
 
-	for _, f := range reader.File {
-		if f.Name == "word/document.xml" {
-			docFile = f
+	if styles, err := pkg.read(path.Join(path.Dir(main), "styles.xml")); err == nil {
+		r.readStyles(styles)
+	}
 
-			break
+	doc := &Document{}
+	r.doc = doc
+	r.rels = pkg.rels(main)
+
+	if err := r.readPart(content, &doc.Blocks); err != nil || len(doc.Blocks) == 0 {
+		// Fall back to the raw text runs of a malformed document
+		if text := extractTextFromRawXML(string(content)); text != "" {
+			doc.Blocks = doc.Blocks[:0]
+
+			for _, line := range strings.Split(text, "\n") {
+				if b, ok := (paragraph{text: line}).block(); ok {
+					doc.Blocks = append(doc.Blocks, b)
+				}
+			}
+		}
+	}
+
+	for _, part := range pkg.headerFooterParts(main) {
+		partContent, err := pkg.read(part)
+		if err != nil {
+			continue
 		}
+
+		var blocks []Block
+
+		r.rels = pkg.rels(part)
+		_ = r.readPart(partContent, &blocks)
+
+		text := (&Document{Blocks: blocks}).Text()
+		if strings.HasPrefix(path.Base(part), "footer") {
+			doc.Footer = addLines(doc.Footer, text)
+		} else {
+			doc.Header = addLines(doc.Header, text)
+		}
+	}
+
+	if len(doc.Blocks) == 0 && len(doc.Header) == 0 && len(doc.Footer) == 0 {
+		return nil, errors.New("no text content found in DOCX")
 	}
 
-	if docFile == nil {
-		return "", errors.New("document.xml not found in DOCX file")
+	return doc, nil
+}
+
+// docxPackage is the ZIP package of a DOCX file.
+type docxPackage struct {
+	files   map[string]*zip.File
+	maxSize int64
+}
+
+// read reads one part, refusing parts that inflate beyond the size limit.
+func (pkg *docxPackage) read(name string) ([]byte, error) {
+	f, ok := pkg.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
 	}
 
-	// Read and parse document.xml
-	rc, err := docFile.Open()
+	rc, err := f.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open document.xml: %w", err)
+		return nil, err
 	}
 	defer rc.Close()
 
-	content, err := io.ReadAll(rc)
+	content, err := io.ReadAll(io.LimitReader(rc, pkg.maxSize+1))
 	if err != nil {
-		return "", fmt.Errorf("failed to read document.xml: %w", err)
+		return nil, err
+	}
+
+	if int64(len(content)) > pkg.maxSize {
+		return nil, fmt.Errorf("%s exceeds maximum allowed size", name)
+	}
+
+	return content, nil
+}
+
+// docxRelationships is a .rels part.
+type docxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// relationships reads the relationships of a part ("" for the package).
+func (pkg *docxPackage) relationships(part string) docxRelationships {
+	var rels docxRelationships
+
+	relsPath := "_rels/.rels"
+	if part != "" {
+		relsPath = path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	}
+
+	if content, err := pkg.read(relsPath); err == nil {
+		_ = xml.Unmarshal(content, &rels)
+	}
+
+	return rels
+}
+
+// mainPart returns the name of the main document part.
+func (pkg *docxPackage) mainPart() string {
+	for _, rel := range pkg.relationships("").Relationships {
+		if strings.HasSuffix(rel.Type, "/officeDocument") {
+			name := strings.TrimPrefix(rel.Target, "/")
+			if _, ok := pkg.files[name]; ok {
+				return name
+			}
+		}
+	}
+
+	if _, ok := pkg.files["word/document.xml"]; ok {
+		return "word/document.xml"
+	}
+
+	return ""
+}
+
+// rels maps the relationship IDs of a part to external link targets.
+func (pkg *docxPackage) rels(part string) map[string]string {
+	out := map[string]string{}
+
+	for _, rel := range pkg.relationships(part).Relationships {
+		if strings.HasSuffix(rel.Type, "/hyperlink") {
+			out[rel.ID] = rel.Target
+		}
 	}
 
-	// Parse XML and extract text
-	text := extractTextFromWordML(string(content))
+	return out
+}
+
+// headerFooterParts returns the header and footer parts of the main part.
+func (pkg *docxPackage) headerFooterParts(main string) []string {
+	var parts []string
 
-	if text == "" {
-		return "", errors.New("no text content found in DOCX")
+	for _, rel := range pkg.relationships(main).Relationships {
+		if strings.HasSuffix(rel.Type, "/header") || strings.HasSuffix(rel.Type, "/footer") {
+			parts = append(parts, path.Join(path.Dir(main), rel.Target))
+		}
 	}
 
-	return text, nil
+	sort.Strings(parts)
+
+	return parts
 }
 
-// WordMLDocument represents the structure of document.xml.
-type WordMLDocument struct {
-	Body struct {
-		Paragraphs []struct {
-			Text []struct {
-				Content string `xml:",innerxml"`
-			} `xml:"w:r/w:t"`
-		} `xml:"w:p"`
-	} `xml:"w:body"`
+// docxStyle is a paragraph or character style of styles.xml.
+type docxStyle struct {
+	name    string
+	basedOn string
+	outline int // Outline level + 1, 0 for body text
+	list    bool
+	bold    int // 1 bold, -1 explicitly not bold, 0 inherited
 }
 
-// extractTextFromWordML extracts text from Word ML XML.
-func extractTextFromWordML(xmlContent string) string {
-	var doc WordMLDocument
+// docxHeadingStyle matches built-in and custom heading style names.
+var docxHeadingStyle = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
 
-	// Parse XML
-	decoder := xml.NewDecoder(strings.NewReader(xmlContent))
+// docxReader reads the WordprocessingML parts of one document.
+type docxReader struct {
+	styles map[string]*docxStyle
+	rels   map[string]string
+	doc    *Document
+}
 
-	err := decoder.Decode(&doc)
-	if err != nil && err != io.EOF {
-		// If XML parsing fails, try simple regex extraction
-		return extractTextFromRawXML(xmlContent)
+// readStyles reads the styles of styles.xml.
+func (r *docxReader) readStyles(content []byte) {
+	var styles struct {
+		Styles []struct {
+			ID      string  `xml:"styleId,attr"`
+			Name    attrVal `xml:"name"`
+			BasedOn attrVal `xml:"basedOn"`
+			PPr     struct {
+				OutlineLvl *attrVal  `xml:"outlineLvl"`
+				NumPr      *struct{} `xml:"numPr"`
+			} `xml:"pPr"`
+			RPr struct {
+				B *attrVal `xml:"b"`
+			} `xml:"rPr"`
+		} `xml:"style"`
 	}
 
-	var result strings.Builder
+	if err := xml.Unmarshal(content, &styles); err != nil {
+		return
+	}
+
+	for _, s := range styles.Styles {
+		style := &docxStyle{name: s.Name.Val, basedOn: s.BasedOn.Val, list: s.PPr.NumPr != nil}
 
-	for _, para := range doc.Body.Paragraphs {
-		for _, t := range para.Text {
-			result.WriteString(t.Content)
+		if s.PPr.OutlineLvl != nil {
+			if n, err := strconv.Atoi(s.PPr.OutlineLvl.Val); err == nil && n < 9 {
+				style.outline = n + 1
+			}
 		}
 
-		result.WriteRune('\n')
+		if s.RPr.B != nil {
+			style.bold = -1
+			if onOff(s.RPr.B.Val) {
+				style.bold = 1
+			}
+		}
+
+		r.styles[s.ID] = style
 	}
+}
 
-	text := result.String()
+// attrVal is an element with a w:val attribute.
+type attrVal struct {
+	Val string `xml:"val,attr"`
+}
 
-	// Clean up whitespace
-	lines := strings.Split(text, "\n")
+// onOff reads a WordprocessingML on/off value, where a missing value is on.
+func onOff(val string) bool {
+	return val != "0" && val != "false" && val != "off" && val != "none"
+}
 
-	var cleanedLines []string
+// styleInfo resolves the heading level, list flag and boldness of a style,
+// following basedOn.
+func (r *docxReader) styleInfo(id string) (heading int, list, bold bool) {
+	boldSet := false
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			cleanedLines = append(cleanedLines, line)
+	for range 16 {
+		s, ok := r.styles[id]
+		if !ok {
+			break
+		}
+
+		if heading == 0 {
+			if m := docxHeadingStyle.FindStringSubmatch(strings.TrimSpace(s.name)); m != nil {
+				heading, _ = strconv.Atoi(m[1])
+			} else if strings.EqualFold(s.name, "title") {
+				heading = 1
+			} else if s.outline > 0 {
+				heading = s.outline
+			}
+		}
+
+		list = list || s.list || strings.HasPrefix(strings.ToLower(s.name), "list ")
+
+		if !boldSet && s.bold != 0 {
+			bold, boldSet = s.bold > 0, true
 		}
+
+		id = s.basedOn
 	}
 
-	return strings.Join(cleanedLines, "\n")
+	return heading, list, bold
+}
+
+// readPart reads the blocks of a document, header or footer part.
+func (r *docxReader) readPart(content []byte, blocks *[]Block) error {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "p":
+			r.readParagraph(dec, blocks)
+		case "tbl":
+			r.readTable(dec, blocks)
+		case "Fallback", "sectPr", "moveFrom":
+			_ = dec.Skip()
+		}
+	}
+}
+
+// readTable reads a table, unpacking layout tables into their blocks.
+func (r *docxReader) readTable(dec *xml.Decoder, blocks *[]Block) {
+	var rows [][][]Block
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tr":
+				rows = append(rows, nil)
+				depth++
+			case "tc":
+				if len(rows) == 0 {
+					rows = append(rows, nil)
+				}
+
+				var cell []Block
+
+				r.readCell(dec, &cell)
+				rows[len(rows)-1] = append(rows[len(rows)-1], cell)
+			case "Fallback", "moveFrom":
+				_ = dec.Skip()
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	*blocks = append(*blocks, tableBlocks(rows)...)
+}
+
+// readCell reads the paragraphs and nested tables of a table cell.
+func (r *docxReader) readCell(dec *xml.Decoder, blocks *[]Block) {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				r.readParagraph(dec, blocks)
+			case "tbl":
+				r.readTable(dec, blocks)
+			case "Fallback", "moveFrom":
+				_ = dec.Skip()
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// docxRun is a stretch of paragraph text and its boldness: 1 bold, -1 not
+// bold, 0 as the paragraph style.
+type docxRun struct {
+	text string
+	bold int
+}
+
+// docxParagraph collects the runs of one paragraph.
+type docxParagraph struct {
+	runs []docxRun
+	text strings.Builder
+	bold int
+}
+
+// flush ends the current run.
+func (dp *docxParagraph) flush() {
+	if dp.text.Len() > 0 {
+		dp.runs = append(dp.runs, docxRun{text: dp.text.String(), bold: dp.bold})
+		dp.text.Reset()
+	}
+}
+
+// link replaces the runs from index from with the text of a hyperlink.
+func (dp *docxParagraph) link(doc *Document, from int, url string) {
+	dp.flush()
+
+	if from < 0 || from > len(dp.runs) || url == "" {
+		return
+	}
+
+	var sb strings.Builder
+
+	bold := 1
+
+	for _, run := range dp.runs[from:] {
+		sb.WriteString(run.text)
+		bold = min(bold, run.bold)
+	}
+
+	dp.runs = append(dp.runs[:from], docxRun{text: doc.addLink(sb.String(), url), bold: bold})
+}
+
+// readParagraph reads a paragraph. Paragraphs of text boxes anchored in it
+// are added first.
+func (r *docxReader) readParagraph(dec *xml.Decoder, blocks *[]Block) {
+	var (
+		p          paragraph
+		dp         docxParagraph
+		style      string
+		numbered   bool
+		outline    int
+		inPPr      bool
+		inRun      bool
+		inText     bool
+		inInstr    bool
+		linkURL    string
+		linkStart  = -1
+		fieldDepth int
+		fieldStart = -1
+		fieldInstr strings.Builder
+	)
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+
+			switch t.Name.Local {
+			case "pPr":
+				inPPr = true
+			case "pStyle":
+				if inPPr {
+					style = attr(t, "val")
+				}
+			case "numId":
+				if inPPr && attr(t, "val") != "0" {
+					numbered = true
+				}
+			case "ilvl":
+				if inPPr {
+					p.level, _ = strconv.Atoi(attr(t, "val"))
+				}
+			case "outlineLvl":
+				if n, err := strconv.Atoi(attr(t, "val")); inPPr && err == nil && n < 9 {
+					outline = n + 1
+				}
+			case "r":
+				dp.flush()
+
+				inRun, dp.bold = true, 0
+			case "b":
+				if inRun {
+					dp.bold = -1
+					if onOff(attr(t, "val")) {
+						dp.bold = 1
+					}
+				}
+			case "t":
+				inText = inRun
+			case "instrText":
+				inInstr = true
+			case "tab":
+				if inRun {
+					dp.text.WriteByte('\t')
+				}
+			case "br", "cr":
+				if inRun && attr(t, "type") != "page" && attr(t, "type") != "column" {
+					dp.text.WriteByte('\n')
+				}
+			case "noBreakHyphen":
+				dp.text.WriteByte('-')
+			case "hyperlink":
+				dp.flush()
+
+				linkURL, linkStart = r.rels[attr(t, "id")], len(dp.runs)
+			case "fldSimple":
+				dp.flush()
+
+				linkURL, linkStart = fieldURL(attr(t, "instr")), len(dp.runs)
+			case "fldChar":
+				switch attr(t, "fldCharType") {
+				case "begin":
+					fieldDepth++
+					if fieldDepth == 1 {
+						fieldInstr.Reset()
+					}
+				case "separate":
+					if fieldDepth == 1 {
+						dp.flush()
+
+						fieldStart = len(dp.runs)
+					}
+				case "end":
+					if fieldDepth == 1 && fieldStart >= 0 {
+						dp.link(r.doc, fieldStart, fieldURL(fieldInstr.String()))
+					}
+
+					fieldDepth = max(0, fieldDepth-1)
+					if fieldDepth == 0 {
+						fieldStart = -1
+					}
+				}
+			case "p":
+				// A text box paragraph, read as a block of its own
+				depth--
+
+				r.readParagraph(dec, blocks)
+			case "tbl":
+				depth--
+
+				r.readTable(dec, blocks)
+			case "Fallback", "moveFrom", "footnoteReference", "commentReference":
+				depth--
+
+				_ = dec.Skip()
+			}
+		case xml.CharData:
+			switch {
+			case inText && (fieldDepth == 0 || fieldStart >= 0):
+				dp.text.Write(t)
+			case inInstr && fieldDepth > 0 && fieldStart < 0:
+				fieldInstr.Write(t)
+			}
+		case xml.EndElement:
+			depth--
+
+			switch t.Name.Local {
+			case "pPr":
+				inPPr = false
+			case "r":
+				dp.flush()
+
+				inRun = false
+			case "t":
+				inText = false
+			case "instrText":
+				inInstr = false
+			case "hyperlink", "fldSimple":
+				if linkStart >= 0 {
+					dp.link(r.doc, linkStart, linkURL)
+				}
+
+				linkURL, linkStart = "", -1
+			}
+		}
+	}
+
+	dp.flush()
+
+	heading, styleList, styleBold := r.styleInfo(style)
+	if heading == 0 {
+		heading = outline
+	}
+
+	p.heading = heading
+	p.list = numbered || styleList
+	p.bold = len(dp.runs) > 0
+
+	var text strings.Builder
+
+	for _, run := range dp.runs {
+		text.WriteString(run.text)
+
+		if strings.TrimSpace(run.text) == "" {
+			continue
+		}
+
+		if run.bold < 0 || run.bold == 0 && !styleBold {
+			p.bold = false
+		}
+	}
+
+	p.text = text.String()
+
+	if b, ok := p.block(); ok {
+		*blocks = append(*blocks, b)
+	}
+}
+
+// attr returns the value of an attribute by local name.
+func attr(start xml.StartElement, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+
+	return ""
 }
 
 // extractTextFromRawXML extracts text from XML using simple pattern matching.
 func extractTextFromRawXML(xmlContent string) string {
 	var result strings.Builder
 
-	// Extract text within <w:t> tags
-	parts := strings.Split(xmlContent, "<w:t")
-	for i := 1; i < len(parts); i++ {
-		// Find the content between > and </w:t>
-		start := strings.Index(parts[i], ">")
-		end := strings.Index(parts[i], "</w:t>")
-
-		if start != -1 && end != -1 && start < end {
-			text := parts[i][start+1 : end]
-			result.WriteString(text)
+	// Extract text within <w:t> tags, one paragraph per line
+	for _, para := range strings.Split(xmlContent, "</w:p>") {
+		parts := strings.Split(para, "<w:t")
+		for i := 1; i < len(parts); i++ {
+			// Find the content between > and </w:t>
+			start := strings.Index(parts[i], ">")
+			end := strings.Index(parts[i], "</w:t>")
+
+			if start != -1 && end != -1 && start < end {
+				text := parts[i][start+1 : end]
+				result.WriteString(text)
+			}
 		}
+
+		result.WriteRune('\n')
 	}
 
 	// Replace XML entities
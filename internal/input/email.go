@@ -0,0 +1,306 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// maxEmailSize bounds uploaded email messages.
+	maxEmailSize = 25 * 1024 * 1024
+	// maxEmailDepth bounds the nesting of multipart and forwarded messages.
+	maxEmailDepth = 10
+)
+
+var (
+	// plainLink matches links in plain text bodies.
+	plainLink = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	// chromeLink matches the account and tracking links of job alert emails.
+	chromeLink = regexp.MustCompile(`(?i)/(unsubscribe|preferences|settings|privacy|terms|help|feedback|` +
+		`manage|login|signin|sign-in|alerts?)(/|$)|\.(png|gif|jpe?g)$`)
+)
+
+// EmailMessage is the content of an email, such as a job alert.
+type EmailMessage struct {
+	MessageID string
+	Subject   string
+	From      string
+	Date      *time.Time
+	Text      string   // Plain text body, or the HTML body rendered as text
+	Links     []string // http(s) links of the body, de-duplicated, in order
+}
+
+// ParseEmail reads an RFC 5322 message (an .eml file), decoding MIME parts,
+// transfer encodings and charsets. Forwarded messages are read too.
+func ParseEmail(data []byte) (*EmailMessage, error) {
+	if len(data) > maxEmailSize {
+		return nil, fmt.Errorf("message too large: %d bytes (max: %d)", len(data), maxEmailSize)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email message: %w", err)
+	}
+
+	decoder := &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+	out := &EmailMessage{
+		MessageID: strings.Trim(msg.Header.Get("Message-Id"), "<> "),
+		Subject:   decodeHeader(decoder, msg.Header.Get("Subject")),
+		From:      decodeHeader(decoder, msg.Header.Get("From")),
+	}
+
+	if date, err := msg.Header.Date(); err == nil {
+		out.Date = &date
+	}
+
+	bodies := &emailBodies{}
+	if err := bodies.read(msg.Header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+
+	out.Text = strings.TrimSpace(bodies.plain)
+	if out.Text == "" {
+		out.Text = bodies.htmlText
+	}
+
+	links := bodies.htmlLinks
+	if len(links) == 0 {
+		links = plainLink.FindAllString(bodies.plain, -1)
+	}
+
+	seen := map[string]bool{}
+
+	for _, link := range links {
+		link = cleanLink(link)
+		if link != "" && !seen[link] {
+			seen[link] = true
+			out.Links = append(out.Links, link)
+		}
+	}
+
+	if out.Text == "" && len(out.Links) == 0 {
+		return nil, errors.New("email has no text content")
+	}
+
+	return out, nil
+}
+
+// decodeHeader decodes RFC 2047 encoded words.
+func decodeHeader(decoder *mime.WordDecoder, s string) string {
+	decoded, err := decoder.DecodeHeader(s)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+
+	return strings.TrimSpace(decoded)
+}
+
+// emailBodies collects the text bodies of a message.
+type emailBodies struct {
+	plain     string
+	htmlText  string
+	htmlLinks []string
+}
+
+// read walks one MIME entity. The first text/plain and text/html parts that
+// are not attachments are the bodies.
+func (b *emailBodies) read(header map[string][]string, body io.Reader, depth int) error {
+	if depth > maxEmailDepth {
+		return errors.New("email is nested too deeply")
+	}
+
+	get := func(key string) string {
+		if v := header[key]; len(v) > 0 {
+			return v[0]
+		}
+
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if disposition, _, _ := mime.ParseMediaType(get("Content-Disposition")); disposition == "attachment" &&
+		mediaType != "message/rfc822" {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		r := multipart.NewReader(body, params["boundary"])
+
+		for {
+			part, err := r.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("invalid MIME part: %w", err)
+			}
+
+			if err := b.read(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	case mediaType == "message/rfc822":
+		msg, err := mail.ReadMessage(decodeTransfer(get("Content-Transfer-Encoding"), body))
+		if err != nil {
+			return nil
+		}
+
+		return b.read(msg.Header, msg.Body, depth+1)
+	case mediaType == "text/plain" && b.plain == "", mediaType == "text/html" && b.htmlText == "":
+		r, err := charset.NewReaderLabel(charsetLabel(params), decodeTransfer(get("Content-Transfer-Encoding"), body))
+		if err != nil {
+			r = decodeTransfer(get("Content-Transfer-Encoding"), body)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, maxEmailSize))
+		if err != nil {
+			return fmt.Errorf("failed to decode email body: %w", err)
+		}
+
+		if mediaType == "text/plain" {
+			b.plain = string(data)
+
+			return nil
+		}
+
+		doc, err := html.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+
+		b.htmlLinks = anchorLinks(doc)
+		b.htmlText = htmlText(doc)
+	}
+
+	return nil
+}
+
+// charsetLabel returns the charset of a text part, defaulting to UTF-8, a
+// superset of the US-ASCII that MIME assumes.
+func charsetLabel(params map[string]string) string {
+	if label := params["charset"]; label != "" {
+		return label
+	}
+
+	return "utf-8"
+}
+
+// decodeTransfer undoes a Content-Transfer-Encoding.
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &lineStripper{r: r})
+	default:
+		return r
+	}
+}
+
+// lineStripper drops the line breaks of base64 bodies.
+type lineStripper struct {
+	r io.Reader
+}
+
+func (l *lineStripper) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	kept := 0
+
+	for _, c := range p[:n] {
+		if c != '\r' && c != '\n' && c != ' ' && c != '\t' {
+			p[kept] = c
+			kept++
+		}
+	}
+
+	return kept, err
+}
+
+// anchorLinks returns the targets of the links of an HTML document.
+func anchorLinks(doc *html.Node) []string {
+	var links []string
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			if href := strings.TrimSpace(htmlAttr(n, "href")); href != "" {
+				links = append(links, href)
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	return links
+}
+
+// cleanLink returns an http(s) link without its fragment and utm_ tracking
+// parameters, or "" for other links.
+func cleanLink(link string) string {
+	u, err := url.Parse(strings.TrimRight(link, ".,;:!?"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+
+	u.Fragment = ""
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// PostingLinks returns the links of an email that likely lead to job
+// postings: links to applicant tracking systems and job boards, leaving out
+// account, unsubscribe and image links.
+func (m *EmailMessage) PostingLinks() []string {
+	var links []string
+
+	for _, link := range m.Links {
+		u, err := url.Parse(link)
+		if err != nil || chromeLink.MatchString(u.Path) {
+			continue
+		}
+
+		if _, _, ok := atsAdapterFor(u); ok || IsValidJobURL(link) {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
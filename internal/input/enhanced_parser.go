@@ -5,7 +5,13 @@ package input
 
 import (
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/skills"
 )
 
 // StructuredCVContent represents parsed CV content with structure.
@@ -20,6 +26,12 @@ type StructuredCVContent struct {
 	Skills         []string
 	Education      []Education
 	Certifications []string
+	Links          []string // Hyperlinks of the source document, e.g. LinkedIn or GitHub
+	Language       string   // ISO 639-1 code of the language the CV is written in
+	// ExperienceMonths is the tenure of the dated experience entries, and
+	// SkillMonths that of the entries mentioning each skill.
+	ExperienceMonths int
+	SkillMonths      map[string]int
 }
 
 // StructuredJobDescription represents parsed job description with structure.
@@ -28,11 +40,17 @@ type StructuredJobDescription struct {
 	Title            string
 	Company          string
 	Location         string
+	Salary           string
+	EmploymentType   string
+	Source           string // How a fetched posting was read, e.g. "json-ld" or "greenhouse"
 	Description      string
 	Requirements     []string
 	Responsibilities []string
 	PreferredSkills  []string
 	BenefitsKeywords []string
+	Language         string   // ISO 639-1 code of the language the posting is written in
+	RequiredYears    int      // Years of experience asked for, as in "5+ years"; zero when not stated
+	Skills           []string // Canonical names of the skills the posting mentions
 }
 
 // EnhancedParser provides structured parsing of CVs and job descriptions.
@@ -43,7 +61,8 @@ func NewEnhancedParser() *EnhancedParser {
 	return &EnhancedParser{}
 }
 
-// ParseCV extracts structured information from CV text.
+// ParseCV extracts structured information from CV text. Section headings
+// are recognized in the language the CV is written in.
 func (ep *EnhancedParser) ParseCV(content string) *StructuredCVContent {
 	cv := &StructuredCVContent{
 		RawText:        content,
@@ -51,6 +70,7 @@ func (ep *EnhancedParser) ParseCV(content string) *StructuredCVContent {
 		Skills:         []string{},
 		Education:      []Education{},
 		Certifications: []string{},
+		Language:       i18n.Detect(content),
 	}
 
 	// Extract contact information
@@ -67,20 +87,317 @@ func (ep *EnhancedParser) ParseCV(content string) *StructuredCVContent {
 	cv.Summary = extractSummary(content)
 
 	// Extract sections
-	cv.Experience = ep.extractExperienceSection(content)
-	cv.Skills = ep.extractSkillsSection(content)
-	cv.Education = ep.extractEducationSection(content)
-	cv.Certifications = ep.extractCertificationsSection(content)
+	cv.Experience = ep.extractExperienceSection(content, cv.Language)
+	cv.Skills = ep.extractSkillsSection(content, cv.Language)
+	cv.Education = ep.extractEducationSection(content, cv.Language)
+	cv.Certifications = ep.extractCertificationsSection(content, cv.Language)
 
 	// If no title found, use the first job title from experience
 	if cv.Title == "" && len(cv.Experience) > 0 {
 		cv.Title = cv.Experience[0].Title
 	}
 
+	cv.normalize(time.Now())
+
+	return cv
+}
+
+// normalize gives skills their canonical names, reads the periods of the
+// experience and education entries, orders experience most recent first and
+// computes the tenures.
+func (cv *StructuredCVContent) normalize(now time.Time) {
+	cv.Skills = skills.Default().Normalize(cv.Skills)
+	normalizePeriods(cv.Experience, cv.Education)
+
+	cv.ExperienceMonths = ExperienceMonths(cv.Experience, now)
+	cv.SkillMonths = SkillTenure(cv.Experience, cv.Skills, now)
+}
+
+// ParseDocument extracts structured information from a word-processor
+// document. Sections come from its headings, entries from subheadings and
+// list items, and skills from lists and tables; anything the structure does
+// not reveal falls back to ParseCV on the document text.
+func (ep *EnhancedParser) ParseDocument(doc *Document) *StructuredCVContent {
+	cv := ep.ParseCV(doc.Text())
+
+	for _, link := range doc.Links {
+		if !slices.Contains(cv.Links, link.URL) {
+			cv.Links = append(cv.Links, link.URL)
+		}
+	}
+
+	sections := doc.Sections()
+	if sections == nil {
+		return cv
+	}
+
+	// The name and title lead the document, before the first section
+	var intro []string
+
+	for _, b := range sections[0].Blocks {
+		if b.Kind != BlockTable && !isContactLine(b.Text) {
+			intro = append(intro, strings.TrimSpace(strings.Split(b.Text, "\n")[0]))
+		}
+	}
+
+	if len(intro) == 0 {
+		for _, line := range doc.Header {
+			if !isContactLine(line) {
+				intro = append(intro, line)
+			}
+		}
+	}
+
+	if len(intro) > 0 && len(intro[0]) < 80 {
+		cv.Name = intro[0]
+	}
+
+	if len(intro) > 1 && len(intro[1]) < 100 {
+		cv.Title = intro[1]
+	}
+
+	for _, section := range sections[1:] {
+		switch sectionKind(section.Title) {
+		case sectionSummary:
+			if summary := blockText(section.Blocks); summary != "" {
+				cv.Summary = summary
+			}
+		case sectionExperience:
+			var experience []Experience
+
+			for _, e := range documentEntries(section.Blocks) {
+				exp := Experience{Title: e.title, Duration: e.duration, Description: strings.Join(e.details, " ")}
+				if len(e.lines) > 0 {
+					exp.Company = e.lines[0]
+				}
+
+				if len(e.details) == 0 && len(e.lines) > 1 {
+					exp.Description = strings.Join(e.lines[1:], " ")
+				}
+
+				experience = append(experience, exp)
+			}
+
+			if len(experience) > 0 {
+				cv.Experience = experience
+			}
+		case sectionEducation:
+			var education []Education
+
+			for _, e := range documentEntries(section.Blocks) {
+				all := strings.Join(append([]string{e.title}, append(e.lines, e.details...)...), "\n")
+				education = append(education, Education{
+					School:   e.title,
+					Degree:   extractDegreeFromEntry(all),
+					Field:    extractFieldFromEntry(strings.Join(append(e.lines, e.details...), "\n")),
+					Duration: e.duration,
+				})
+			}
+
+			if len(education) > 0 {
+				cv.Education = education
+			}
+		case sectionSkills:
+			if skills := documentSkills(section.Blocks); len(skills) > 0 {
+				cv.Skills = skills
+			}
+		case sectionCertifications:
+			var certs []string
+
+			for _, b := range section.Blocks {
+				certs = append(certs, blockLines(b)...)
+			}
+
+			if len(certs) > 0 {
+				cv.Certifications = certs
+			}
+		}
+	}
+
+	if cv.Title == "" && len(cv.Experience) > 0 {
+		cv.Title = cv.Experience[0].Title
+	}
+
+	cv.normalize(time.Now())
+
 	return cv
 }
 
-// ParseJobDescription extracts structured information from job description text.
+// documentEntry is one job or school of a document section: a title line,
+// further header lines such as the company, and the detail list items.
+type documentEntry struct {
+	title    string
+	duration string
+	lines    []string
+	details  []string
+}
+
+// documentEntries splits a section into entries. A subheading or paragraph
+// after list items starts a new entry; tab-separated parts of the title line,
+// such as right-aligned dates, are split off.
+func documentEntries(blocks []Block) []documentEntry {
+	var (
+		entries []documentEntry
+		current *documentEntry
+	)
+
+	for _, b := range blocks {
+		switch b.Kind {
+		case BlockListItem:
+			if current == nil {
+				entries = append(entries, documentEntry{})
+				current = &entries[len(entries)-1]
+			}
+
+			current.details = append(current.details, b.Text)
+		default:
+			for _, line := range blockLines(b) {
+				if current == nil || len(current.details) > 0 || b.Kind == BlockHeading && current.title != "" {
+					entries = append(entries, documentEntry{})
+					current = &entries[len(entries)-1]
+				}
+
+				if current.title == "" {
+					current.title = line
+				} else {
+					current.lines = append(current.lines, line)
+				}
+			}
+		}
+	}
+
+	for i := range entries {
+		e := &entries[i]
+
+		if title, rest, ok := strings.Cut(e.title, "\t"); ok {
+			e.title = strings.TrimSpace(title)
+			e.lines = append([]string{strings.TrimSpace(rest)}, e.lines...)
+		}
+
+		if e.title == "" && len(e.details) > 0 {
+			e.title, e.details = e.details[0], e.details[1:]
+		}
+
+		// A short line with a date is the duration; otherwise take the date
+		// from the first line that has one
+		var lines []string
+
+		for _, line := range e.lines {
+			line = strings.TrimSpace(strings.ReplaceAll(line, "\t", " "))
+
+			switch {
+			case line == "":
+			case e.duration == "" && len(line) <= 40 && isDateLine(line):
+				e.duration = line
+			default:
+				lines = append(lines, line)
+			}
+		}
+
+		if e.duration == "" {
+			for _, line := range append([]string{e.title}, lines...) {
+				if _, e.duration, _ = FindDateRange(line); e.duration != "" {
+					break
+				}
+			}
+		}
+
+		e.lines = lines
+	}
+
+	return entries
+}
+
+// isDateLine reports whether a line has a date or date range.
+func isDateLine(line string) bool {
+	_, ok := ParseDateRange(line)
+
+	return ok
+}
+
+// documentSkills collects skills from list items, paragraphs and table cells,
+// dropping category labels such as "Languages:".
+func documentSkills(blocks []Block) []string {
+	var skills []string
+
+	add := func(text string) {
+		if _, after, ok := strings.Cut(text, ":"); ok {
+			text = after
+		}
+
+		for _, skill := range strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ';' || r == '|' || r == '•' || r == '\n' || r == '\t'
+		}) {
+			skill = strings.TrimSpace(skill)
+			if skill != "" && len(skill) < 100 && !slices.Contains(skills, skill) {
+				skills = append(skills, skill)
+			}
+		}
+	}
+
+	for _, b := range blocks {
+		if b.Kind == BlockTable {
+			for _, row := range b.Rows {
+				for _, cell := range row {
+					add(cell)
+				}
+			}
+
+			continue
+		}
+
+		add(b.Text)
+	}
+
+	return skills
+}
+
+// blockLines returns the lines of a block, one per row for tables.
+func blockLines(b Block) []string {
+	var lines []string
+
+	if b.Kind == BlockTable {
+		for _, row := range b.Rows {
+			if line := strings.TrimSpace(strings.Join(row, " ")); line != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		return lines
+	}
+
+	for _, line := range strings.Split(b.Text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// blockText joins the text of blocks into one paragraph.
+func blockText(blocks []Block) string {
+	var parts []string
+
+	for _, b := range blocks {
+		parts = append(parts, blockLines(b)...)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// isContactLine reports whether a line holds contact details rather than a
+// name or title.
+func isContactLine(line string) bool {
+	lower := strings.ToLower(line)
+
+	return extractEmail(line) != "" || extractPhone(line) != "" ||
+		strings.Contains(lower, "http") || strings.Contains(lower, "www.") || strings.Contains(lower, "linkedin.com")
+}
+
+// ParseJobDescription extracts structured information from job description
+// text. Section headings are recognized in the language the posting is
+// written in.
 func (ep *EnhancedParser) ParseJobDescription(content string) *StructuredJobDescription {
 	job := &StructuredJobDescription{
 		RawText:          content,
@@ -88,6 +405,7 @@ func (ep *EnhancedParser) ParseJobDescription(content string) *StructuredJobDesc
 		Responsibilities: []string{},
 		PreferredSkills:  []string{},
 		BenefitsKeywords: []string{},
+		Language:         i18n.Detect(content),
 	}
 
 	// Extract job title
@@ -100,19 +418,35 @@ func (ep *EnhancedParser) ParseJobDescription(content string) *StructuredJobDesc
 	job.Location = ep.extractLocation(content)
 
 	// Extract job description sections
-	job.Description = ep.extractDescription(content)
-	job.Requirements = ep.extractRequirements(content)
-	job.Responsibilities = ep.extractResponsibilities(content)
-	job.PreferredSkills = ep.extractPreferredSkills(content)
-	job.BenefitsKeywords = ep.extractBenefitsKeywords(content)
+	job.Description = ep.extractDescription(content, job.Language)
+	job.Requirements = ep.extractRequirements(content, job.Language)
+	job.Responsibilities = ep.extractResponsibilities(content, job.Language)
+	job.PreferredSkills = ep.extractPreferredSkills(content, job.Language)
+	job.BenefitsKeywords = ep.extractBenefitsKeywords(content, job.Language)
+	job.RequiredYears = ParseRequiredYears(content)
+
+	for _, skill := range skills.Default().Find(content) {
+		job.Skills = append(job.Skills, skill.Name)
+	}
 
 	return job
 }
 
+var (
+	// emailRegex matches email addresses, including internationalized ones
+	// such as "jürgen@müller.de".
+	emailRegex = regexp.MustCompile(`[\p{L}\p{N}._%+\-]+@[\p{L}\p{N}.\-]+\.\p{L}{2,}`)
+	// phoneRegex matches North American style phone numbers.
+	phoneRegex = regexp.MustCompile(`(?:\+\d{1,3}[-.\s]?)?\(?[0-9]{3}\)?[-.\s]?[0-9]{3}[-.\s]?[0-9]{4}`)
+	// intlPhoneRegex matches phone numbers with a country code, such as
+	// "+49 (0)30 1234-5678" or "0033 1 23 45 67 89".
+	intlPhoneRegex = regexp.MustCompile(`(?:^|[^\d+])((?:\+|00)[1-9][\d \t().\-/]{6,}\d)`)
+	// nationalPhoneRegex matches other phone numbers, such as "030 12345678".
+	nationalPhoneRegex = regexp.MustCompile(`(?:^|[^\d])(0\d[\d \t().\-/]{5,}\d)`)
+)
+
 // extractEmail extracts email address from text.
 func extractEmail(content string) string {
-	emailRegex := regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
-
 	matches := emailRegex.FindAllString(content, -1)
 	if len(matches) > 0 {
 		return matches[0]
@@ -121,29 +455,87 @@ func extractEmail(content string) string {
 	return ""
 }
 
-// extractPhone extracts phone number from text.
+// extractPhone extracts phone number from text. Numbers with a country code
+// come first, then North American numbers, then other national numbers.
 func extractPhone(content string) string {
-	phoneRegex := regexp.MustCompile(`(?:\+\d{1,3}[-.\s]?)?\(?[0-9]{3}\)?[-.\s]?[0-9]{3}[-.\s]?[0-9]{4}`)
+	for _, m := range intlPhoneRegex.FindAllStringSubmatch(content, -1) {
+		if isPhoneNumber(m[1]) {
+			return m[1]
+		}
+	}
 
 	matches := phoneRegex.FindAllString(content, -1)
 	if len(matches) > 0 {
 		return matches[0]
 	}
 
+	for _, m := range nationalPhoneRegex.FindAllStringSubmatch(content, -1) {
+		if isPhoneNumber(m[1]) {
+			return m[1]
+		}
+	}
+
 	return ""
 }
 
+// isPhoneNumber reports whether a match has as many digits as a phone
+// number, as opposed to a date range such as "2019 - 2021".
+func isPhoneNumber(s string) bool {
+	digits := 0
+
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+
+	return digits >= 8 && digits <= 15
+}
+
+// sectionNames appends the keywords of the given sections in a language
+// other than English to English section names.
+func sectionNames(lang string, names []string, sections ...i18n.Section) []string {
+	if lang == i18n.English {
+		return names
+	}
+
+	names = slices.Clone(names)
+	for _, section := range sections {
+		names = append(names, i18n.Keywords(lang, section)...)
+	}
+
+	return names
+}
+
+// findCVSection returns the index after the heading of a CV section. Heading
+// lines come first; otherwise the first mention of one of the section names
+// or the section keywords of lang is taken.
+func findCVSection(content, lang string, section i18n.Section, names []string) int {
+	offset := 0
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		offset += len(line)
+
+		if i18n.HeadingLine(line) == section {
+			return offset
+		}
+	}
+
+	return findSectionStart(content, sectionNames(lang, names, section))
+}
+
 // extractExperienceSection extracts work experience from CV.
-func (ep *EnhancedParser) extractExperienceSection(content string) []Experience {
+func (ep *EnhancedParser) extractExperienceSection(content, lang string) []Experience {
 	var experiences []Experience
 
 	// Find experience section
-	expStart := findSectionStart(content, []string{"experience", "work experience", "employment"})
+	expStart := findCVSection(content, lang, i18n.SectionExperience, []string{"experience", "work experience", "employment"})
 	if expStart == -1 {
 		return experiences
 	}
 
-	expEnd := findSectionEnd(content, expStart, []string{"education", "skills", "certification", "projects"})
+	expEnd := findSectionEnd(content, expStart, sectionNames(lang, []string{"education", "skills", "certification", "projects"},
+		i18n.SectionEducation, i18n.SectionSkills, i18n.SectionCertifications))
 	if expEnd == -1 {
 		expEnd = len(content)
 	}
@@ -168,15 +560,16 @@ func (ep *EnhancedParser) extractExperienceSection(content string) []Experience
 }
 
 // extractEducationSection extracts education from CV.
-func (ep *EnhancedParser) extractEducationSection(content string) []Education {
+func (ep *EnhancedParser) extractEducationSection(content, lang string) []Education {
 	var educations []Education
 
-	eduStart := findSectionStart(content, []string{"education", "academic"})
+	eduStart := findCVSection(content, lang, i18n.SectionEducation, []string{"education", "academic"})
 	if eduStart == -1 {
 		return educations
 	}
 
-	eduEnd := findSectionEnd(content, eduStart, []string{"experience", "skills", "certification"})
+	eduEnd := findSectionEnd(content, eduStart, sectionNames(lang, []string{"experience", "skills", "certification"},
+		i18n.SectionExperience, i18n.SectionSkills, i18n.SectionCertifications))
 	if eduEnd == -1 {
 		eduEnd = len(content)
 	}
@@ -200,15 +593,16 @@ func (ep *EnhancedParser) extractEducationSection(content string) []Education {
 }
 
 // extractSkillsSection extracts skills from CV.
-func (ep *EnhancedParser) extractSkillsSection(content string) []string {
+func (ep *EnhancedParser) extractSkillsSection(content, lang string) []string {
 	var skills []string
 
-	skillStart := findSectionStart(content, []string{"skills", "technical skills", "competencies"})
+	skillStart := findCVSection(content, lang, i18n.SectionSkills, []string{"skills", "technical skills", "competencies"})
 	if skillStart == -1 {
 		return skills
 	}
 
-	skillEnd := findSectionEnd(content, skillStart, []string{"experience", "education", "projects"})
+	skillEnd := findSectionEnd(content, skillStart, sectionNames(lang, []string{"experience", "education", "projects"},
+		i18n.SectionExperience, i18n.SectionEducation))
 	if skillEnd == -1 {
 		skillEnd = len(content)
 	}
@@ -234,15 +628,15 @@ func (ep *EnhancedParser) extractSkillsSection(content string) []string {
 }
 
 // extractCertificationsSection extracts certifications from CV.
-func (ep *EnhancedParser) extractCertificationsSection(content string) []string {
+func (ep *EnhancedParser) extractCertificationsSection(content, lang string) []string {
 	var certs []string
 
-	certStart := findSectionStart(content, []string{"certification", "certifications", "licenses"})
+	certStart := findCVSection(content, lang, i18n.SectionCertifications, []string{"certification", "certifications", "licenses"})
 	if certStart == -1 {
 		return certs
 	}
 
-	certEnd := findSectionEnd(content, certStart, []string{"skills", "projects"})
+	certEnd := findSectionEnd(content, certStart, sectionNames(lang, []string{"skills", "projects"}, i18n.SectionSkills))
 	if certEnd == -1 {
 		certEnd = len(content)
 	}
@@ -320,11 +714,12 @@ func (ep *EnhancedParser) extractLocation(content string) string {
 }
 
 // extractDescription extracts job description overview.
-func (ep *EnhancedParser) extractDescription(content string) string {
+func (ep *EnhancedParser) extractDescription(content, lang string) string {
 	// Get first few paragraphs before Requirements section
 	descStart := 0
 
-	descEnd := findSectionStart(content, []string{"requirements", "responsibilities", "qualifications"})
+	descEnd := findSectionStart(content, sectionNames(lang, []string{"requirements", "responsibilities", "qualifications"},
+		i18n.SectionRequirements, i18n.SectionResponsibilities))
 	if descEnd == -1 {
 		descEnd = minInt(len(content), 500)
 	}
@@ -340,37 +735,52 @@ func (ep *EnhancedParser) extractDescription(content string) string {
 }
 
 // extractRequirements extracts job requirements.
-func (ep *EnhancedParser) extractRequirements(content string) []string {
-	return ep.extractListSection(content, []string{"requirements", "must have", "required"})
+func (ep *EnhancedParser) extractRequirements(content, lang string) []string {
+	return ep.extractListSection(content, lang, i18n.SectionRequirements, []string{"requirements", "must have", "required"})
 }
 
 // extractResponsibilities extracts job responsibilities.
-func (ep *EnhancedParser) extractResponsibilities(content string) []string {
-	return ep.extractListSection(content, []string{"responsibilities", "your role", "what you'll do"})
+func (ep *EnhancedParser) extractResponsibilities(content, lang string) []string {
+	return ep.extractListSection(content, lang, i18n.SectionResponsibilities, []string{"responsibilities", "your role", "what you'll do"})
 }
 
 // extractPreferredSkills extracts preferred/nice-to-have skills.
-func (ep *EnhancedParser) extractPreferredSkills(content string) []string {
-	return ep.extractListSection(content, []string{"preferred", "nice to have", "bonus"})
+func (ep *EnhancedParser) extractPreferredSkills(content, lang string) []string {
+	return ep.extractListSection(content, lang, i18n.SectionPreferred, []string{"preferred", "nice to have", "bonus"})
 }
 
 // extractBenefitsKeywords extracts benefits mentioned.
-func (ep *EnhancedParser) extractBenefitsKeywords(content string) []string {
-	return ep.extractListSection(content, []string{"benefits", "perks", "compensation"})
+func (ep *EnhancedParser) extractBenefitsKeywords(content, lang string) []string {
+	return ep.extractListSection(content, lang, i18n.SectionBenefits, []string{"benefits", "perks", "compensation"})
 }
 
 // extractListSection extracts items from a bulleted/numbered list section.
-func (ep *EnhancedParser) extractListSection(content string, sectionNames []string) []string {
+// A heading line naming the section in any language starts it, up to the
+// next heading line; otherwise the section starts at one of names or a
+// keyword of the section in lang, and in languages other than English ends
+// at the heading of another section.
+func (ep *EnhancedParser) extractListSection(content, lang string, section i18n.Section, names []string) []string {
 	var items []string
 
-	start := findSectionStart(content, sectionNames)
+	start, end := findJobSection(content, section)
 	if start == -1 {
-		return items
-	}
+		start = findSectionStart(content, sectionNames(lang, names, section))
+		if start == -1 {
+			return items
+		}
+
+		var others []i18n.Section
 
-	end := findSectionEnd(content, start, []string{"education", "company", "location", "salary"})
-	if end == -1 {
-		end = len(content)
+		for _, other := range []i18n.Section{i18n.SectionRequirements, i18n.SectionResponsibilities, i18n.SectionPreferred, i18n.SectionBenefits} {
+			if other != section {
+				others = append(others, other)
+			}
+		}
+
+		end = findSectionEnd(content, start, sectionNames(lang, []string{"education", "company", "location", "salary"}, others...))
+		if end == -1 {
+			end = len(content)
+		}
 	}
 
 	sectionContent := content[start:end]
@@ -386,6 +796,36 @@ func (ep *EnhancedParser) extractListSection(content string, sectionNames []stri
 	return items
 }
 
+// findJobSection returns the bounds of the lines under the heading line of
+// a job posting section, up to the next heading line, or -1 and -1 when no
+// heading line names the section. Short lines ending with a colon, such as
+// "About us:", are headings too.
+func findJobSection(content string, section i18n.Section) (int, int) {
+	start, offset := -1, 0
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		kind := i18n.JobHeadingLine(line)
+		trimmed := strings.TrimSpace(line)
+		heading := kind != i18n.SectionUnknown ||
+			strings.HasSuffix(trimmed, ":") && len(strings.Fields(trimmed)) <= 4 && !strings.HasPrefix(trimmed, "-")
+
+		switch {
+		case start != -1 && heading:
+			return start, offset
+		case start == -1 && kind == section:
+			start = offset + len(line)
+		}
+
+		offset += len(line)
+	}
+
+	if start == -1 {
+		return -1, -1
+	}
+
+	return start, len(content)
+}
+
 // Helper functions
 
 func findSectionStart(content string, sectionNames []string) int {
@@ -393,24 +833,33 @@ func findSectionStart(content string, sectionNames []string) int {
 	for _, name := range sectionNames {
 		idx := strings.Index(lower, strings.ToLower(name))
 		if idx != -1 {
-			// Return index after the section name
-			return idx + len(name)
+			// Return index after the section name and its colon, as in "Profil recherché :"
+			end := idx + len(name)
+			if rest := strings.TrimLeft(content[end:], " \t"); strings.HasPrefix(rest, ":") {
+				end = len(content) - len(rest) + 1
+			}
+
+			return end
 		}
 	}
 
 	return -1
 }
 
+// findSectionEnd returns the index of the first of the next section names
+// after start.
 func findSectionEnd(content string, start int, nextSectionNames []string) int {
 	lower := strings.ToLower(content[start:])
+	end := -1
+
 	for _, name := range nextSectionNames {
 		idx := strings.Index(lower, strings.ToLower(name))
-		if idx != -1 {
-			return start + idx
+		if idx != -1 && (end == -1 || start+idx < end) {
+			end = start + idx
 		}
 	}
 
-	return -1
+	return end
 }
 
 func parseBulletedList(content string) []string {
@@ -469,22 +918,9 @@ func extractCompanyFromEntry(entry string) string {
 }
 
 func extractDurationFromEntry(entry string) string {
-	re := regexp.MustCompile(`(20[0-9]{2}|jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec|present)`)
+	_, duration, _ := FindDateRange(entry)
 
-	matches := re.FindStringSubmatch(strings.ToLower(entry))
-	if len(matches) > 0 {
-		// Find the full duration string
-		lower := strings.ToLower(entry)
-
-		idx := strings.Index(lower, strings.ToLower(matches[0]))
-		if idx != -1 {
-			end := minInt(idx+30, len(entry))
-
-			return strings.TrimSpace(entry[idx:end])
-		}
-	}
-
-	return ""
+	return duration
 }
 
 func extractDescriptionFromEntry(entry string) string {
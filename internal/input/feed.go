@@ -0,0 +1,181 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// feedTypes are the content types accepted for feeds. Many servers label
+// feeds as generic XML or even plain text.
+var feedTypes = []string{
+	"application/rss+xml", "application/atom+xml", "application/rdf+xml",
+	"application/xml", "text/xml", "text/plain",
+}
+
+// feedDateLayouts are the date formats of RSS and Atom feeds in the wild.
+var feedDateLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700", "2006-01-02T15:04:05", "2006-01-02",
+}
+
+// Feed is an RSS or Atom feed of job postings.
+type Feed struct {
+	Title string
+	Items []FeedItem
+}
+
+// FeedItem is one entry of a feed.
+type FeedItem struct {
+	ID        string // guid or Atom id, or the link when there is none
+	Title     string
+	Link      string
+	Content   string // HTML or plain text
+	Published *time.Time
+}
+
+// rssItem is an item of RSS 0.9x, 1.0 and 2.0 feeds.
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// atomEntry is an entry of an Atom feed.
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Content   atomText `xml:"content"`
+	Summary   atomText `xml:"summary"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+}
+
+// atomText is an Atom text construct; the XHTML form holds markup rather
+// than escaped text.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+
+	return t.Text
+}
+
+// ParseFeed reads an RSS (0.9x, 1.0 or 2.0) or Atom feed.
+func ParseFeed(data []byte) (*Feed, error) {
+	var doc struct {
+		XMLName xml.Name
+		Title   string `xml:"title"`
+		Channel struct {
+			Title string    `xml:"title"`
+			Items []rssItem `xml:"item"`
+		} `xml:"channel"`
+		Items   []rssItem   `xml:"item"`
+		Entries []atomEntry `xml:"entry"`
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	feed := &Feed{Items: []FeedItem{}}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		feed.Title = strings.TrimSpace(doc.Channel.Title)
+
+		// RSS 1.0 puts its items next to the channel
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			feed.Items = append(feed.Items, FeedItem{
+				ID:        firstNonEmpty(item.GUID, item.Link),
+				Title:     strings.TrimSpace(item.Title),
+				Link:      strings.TrimSpace(item.Link),
+				Content:   firstNonEmpty(item.Encoded, item.Description),
+				Published: parseFeedDate(firstNonEmpty(item.PubDate, item.Date)),
+			})
+		}
+	case "feed":
+		feed.Title = strings.TrimSpace(doc.Title)
+
+		for _, entry := range doc.Entries {
+			var link string
+
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = strings.TrimSpace(l.Href)
+
+					break
+				}
+			}
+
+			feed.Items = append(feed.Items, FeedItem{
+				ID:        firstNonEmpty(entry.ID, link),
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      link,
+				Content:   firstNonEmpty(entry.Content.String(), entry.Summary.String()),
+				Published: parseFeedDate(firstNonEmpty(entry.Published, entry.Updated)),
+			})
+		}
+	default:
+		return nil, errors.New("invalid feed: not an RSS or Atom document")
+	}
+
+	return feed, nil
+}
+
+// firstNonEmpty returns the first value that is not blank, trimmed.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// parseFeedDate parses a feed date, or returns nil.
+func parseFeedDate(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			t = t.UTC()
+
+			return &t
+		}
+	}
+
+	return nil
+}
@@ -4,140 +4,133 @@
 package input
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/egress"
+	"golang.org/x/net/html"
+)
+
+// pageTypes and apiTypes are the content types accepted for job pages and
+// API responses.
+var (
+	pageTypes = []string{"text/html", "application/xhtml+xml", "text/plain"}
+	apiTypes  = []string{"application/json"}
 )
 
-// Fetcher handles fetching content from URLs.
+// Fetcher handles fetching content from URLs under an egress policy.
 type Fetcher struct {
-	httpClient *http.Client
-	timeout    time.Duration
+	client  *egress.Client
+	timeout time.Duration
 }
 
-// NewFetcher creates a new URL fetcher.
+// NewFetcher creates a new URL fetcher with the default egress policy.
 func NewFetcher(timeout time.Duration) *Fetcher {
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
 	return &Fetcher{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		client:  egress.NewClient(egress.DefaultPolicy(), timeout),
 		timeout: timeout,
 	}
 }
 
-// FetchJobDescription fetches job description from a URL.
+// SetPolicy sets the egress policy URLs are fetched under.
+func (f *Fetcher) SetPolicy(policy *egress.Policy) {
+	f.client = egress.NewClient(policy, f.timeout)
+}
+
+// FetchJobDescription fetches the text of a job posting from a URL.
 func (f *Fetcher) FetchJobDescription(url string) (string, error) {
-	if url == "" {
-		return "", errors.New("URL cannot be empty")
+	job, err := f.FetchJob(context.Background(), url)
+	if err != nil {
+		return "", err
 	}
 
-	// Validate URL format
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		url = "https://" + url
-	}
+	return job.RawText, nil
+}
 
-	// Fetch the page
-	resp, err := f.httpClient.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+// FetchJob fetches a job posting and reads it the most reliable way
+// available: schema.org JobPosting data embedded in the page (JSON-LD or
+// microdata), then the public API of the applicant tracking system hosting
+// it (Greenhouse, Lever, Workday, Ashby or SmartRecruiters), then the main
+// content of the page with navigation, banners and footers left out.
+func (f *Fetcher) FetchJob(ctx context.Context, rawURL string) (*StructuredJobDescription, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, errors.New("URL cannot be empty")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch URL: status code %d", resp.StatusCode)
+	// Bare host names are taken as https; any other scheme is rejected
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
 	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	// Extract text from HTML
-	text := extractTextFromHTML(string(body))
+	var job *jobData
 
-	if text == "" {
-		return "", errors.New("no meaningful content extracted from URL")
+	page, pageErr := f.client.Get(ctx, rawURL, pageTypes...)
+	if pageErr == nil {
+		job = extractJobFromPage(page)
 	}
 
-	return text, nil
-}
-
-// extractTextFromHTML is a simple HTML to text converter.
-func extractTextFromHTML(html string) string {
-	// Remove script and style elements
-	html = removeHTMLTags("script", html)
-	html = removeHTMLTags("style", html)
-
-	// Remove HTML comments
-	html = strings.NewReplacer(
-		"<!--", "",
-		"-->", "",
-	).Replace(html)
-
-	// Remove all HTML tags
-	inTag := false
-
-	var result strings.Builder
-
-	for _, r := range html {
-		if r == '<' {
-			inTag = true
-		} else if r == '>' {
-			inTag = false
-
-			result.WriteRune(' ')
-		} else if !inTag {
-			result.WriteRune(r)
+	// Pages of applicant tracking systems are often rendered by JavaScript,
+	// leaving little but chrome to read
+	if job == nil || job.source == JobSourceReadability {
+		if atsJob := f.fetchATS(ctx, u); atsJob != nil {
+			job = atsJob
 		}
 	}
 
-	text := result.String()
-
-	// Clean up whitespace
-	lines := strings.Split(text, "\n")
-
-	var cleanedLines []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			cleanedLines = append(cleanedLines, line)
+	if job == nil {
+		if pageErr != nil {
+			return nil, pageErr
 		}
+
+		return nil, errors.New("no meaningful content extracted from URL")
 	}
 
-	return strings.Join(cleanedLines, "\n")
+	return structuredJob(job), nil
 }
 
-// removeHTMLTags removes specific HTML tags and their content.
-func removeHTMLTags(tagName, html string) string {
-	opening := "<" + tagName
-	closing := "</" + tagName + ">"
+// fetchATS reads a posting through the API of the applicant tracking system
+// hosting it, or returns nil.
+func (f *Fetcher) fetchATS(ctx context.Context, u *url.URL) *jobData {
+	adapter, api, ok := atsAdapterFor(u)
+	if !ok {
+		return nil
+	}
 
-	for {
-		startIdx := strings.Index(strings.ToLower(html), strings.ToLower(opening))
-		if startIdx == -1 {
-			break
-		}
+	body, err := f.client.GetAPI(ctx, api, apiTypes...)
+	if err != nil {
+		return nil
+	}
 
-		endIdx := strings.Index(strings.ToLower(html), strings.ToLower(closing))
-		if endIdx == -1 {
-			html = html[:startIdx]
+	job, err := adapter.parse(body, u)
+	if err != nil || (job.title == "" && job.description == "") {
+		return nil
+	}
 
-			break
-		}
+	return job
+}
 
-		html = html[:startIdx] + html[endIdx+len(closing):]
+// extractTextFromHTML renders an HTML page as plain text.
+func extractTextFromHTML(page string) string {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return ""
 	}
 
-	return html
+	return htmlText(doc)
 }
 
 // IsValidJobURL checks if a URL is likely a job listing.
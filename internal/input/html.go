@@ -0,0 +1,422 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlSkipped lists elements whose content is never visible text.
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Head:     true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Canvas:   true,
+	atom.Select:   true,
+	atom.Button:   true,
+}
+
+// htmlBlocks lists elements that start a new line.
+var htmlBlocks = map[atom.Atom]bool{
+	atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Nav: true,
+	atom.Tr: true, atom.Dd: true, atom.Dt: true, atom.Dl: true,
+	atom.Form: true, atom.Fieldset: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Address: true, atom.Hr: true,
+}
+
+// htmlParagraphs lists elements set apart by blank lines.
+var htmlParagraphs = map[atom.Atom]bool{
+	atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Ul: true, atom.Ol: true, atom.Table: true,
+	atom.Blockquote: true, atom.Pre: true,
+}
+
+// textWriter renders HTML as plain text, one line per block and list item.
+type textWriter struct {
+	lines []string
+	line  strings.Builder
+	space bool
+}
+
+// text writes character data, collapsing white space.
+func (w *textWriter) text(s string) {
+	if s == "" {
+		return
+	}
+
+	if r, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(r) {
+		w.space = true
+	}
+
+	for _, field := range strings.Fields(s) {
+		if w.space && w.line.Len() > 0 {
+			w.line.WriteByte(' ')
+		}
+
+		w.line.WriteString(field)
+		w.space = true
+	}
+
+	if r, _ := utf8.DecodeLastRuneInString(s); !unicode.IsSpace(r) {
+		w.space = false
+	}
+}
+
+// newline ends the current line.
+func (w *textWriter) newline() {
+	if w.line.Len() > 0 {
+		w.lines = append(w.lines, w.line.String())
+		w.line.Reset()
+	}
+
+	w.space = false
+}
+
+// blank ends the current line and leaves an empty one.
+func (w *textWriter) blank() {
+	w.newline()
+
+	if n := len(w.lines); n > 0 && w.lines[n-1] != "" {
+		w.lines = append(w.lines, "")
+	}
+}
+
+// walk renders a node and its children.
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+
+		return
+	case html.ElementNode:
+		if htmlSkipped[n.DataAtom] || isHidden(n) {
+			return
+		}
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+
+	a := n.DataAtom
+
+	switch {
+	case a == atom.Br:
+		w.newline()
+
+		return
+	case htmlParagraphs[a]:
+		w.blank()
+	case a == atom.Li:
+		w.newline()
+		w.line.WriteString("•")
+		w.space = true
+	case htmlBlocks[a]:
+		w.newline()
+	case a == atom.Td || a == atom.Th:
+		w.space = true
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+
+	switch {
+	case htmlParagraphs[a]:
+		w.blank()
+	case a == atom.Li || htmlBlocks[a]:
+		w.newline()
+	}
+}
+
+// String returns the rendered text.
+func (w *textWriter) String() string {
+	w.newline()
+
+	return strings.TrimSpace(strings.Join(w.lines, "\n"))
+}
+
+// htmlText renders a node as plain text, keeping headings, paragraphs and
+// list items on lines of their own.
+func htmlText(n *html.Node) string {
+	w := &textWriter{}
+	w.walk(n)
+
+	return w.String()
+}
+
+// htmlFragmentText renders an HTML fragment, such as the description of a job
+// posting, as plain text. Fragments that were entity-escaped once more, as
+// some job APIs return them, are unescaped first.
+func htmlFragmentText(s string) string {
+	if strings.Contains(s, "&lt;") && !strings.Contains(s, "<") {
+		s = html.UnescapeString(s)
+	}
+
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+
+	return htmlText(doc)
+}
+
+// htmlAttr returns an attribute of an element.
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// isHidden reports whether an element is hidden from readers.
+func isHidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if a.Val == "true" {
+				return true
+			}
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(a.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var (
+	// unlikelyContent matches class and id names of page chrome.
+	unlikelyContent = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|consent|cookie|disqus|footer|gdpr|` +
+		`header|menu|modal|nav|newsletter|pager|pagination|popup|related|remark|rss|share|shoutbox|sidebar|` +
+		`skyscraper|social|sponsor|subscribe|toolbar|widget`)
+	// maybeContent matches class and id names that rescue an element from
+	// unlikelyContent.
+	maybeContent = regexp.MustCompile(`(?i)and|article|body|column|content|description|job|main|posting`)
+	// positiveContent and negativeContent weigh candidate elements.
+	positiveContent = regexp.MustCompile(`(?i)article|body|content|description|entry|job|main|post|posting|story|text`)
+	negativeContent = regexp.MustCompile(`(?i)-ad-|aside|banner|combx|comment|contact|footer|footnote|masthead|media|` +
+		`meta|outbrain|promo|related|scroll|share|shopping|sidebar|sponsor|tags|tool|widget`)
+)
+
+// chromeRoles are ARIA landmarks that never hold the main content.
+var chromeRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"dialog": true, "alertdialog": true, "search": true, "menu": true, "menubar": true,
+}
+
+// mainContent finds the element holding the main content of a page, in the
+// manner of readability: page chrome is pruned, paragraphs add a score
+// based on their length and commas to their parent and grandparent, class
+// and id names weigh the candidates, and link-heavy candidates are
+// penalized. The page is modified.
+func mainContent(doc *html.Node) *html.Node {
+	pruneChrome(doc)
+
+	scores := map[*html.Node]float64{}
+
+	var candidates []*html.Node
+
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+
+		scores[n] += score
+	}
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+
+		if n.Type != html.ElementNode || !isScorable(n) {
+			return
+		}
+
+		text := htmlText(n)
+		if utf8.RuneCountInString(text) < 25 {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(utf8.RuneCountInString(text))/100, 3)
+		addScore(n.Parent, score)
+
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	}
+	visit(doc)
+
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+
+	for _, n := range candidates {
+		score := scores[n] * (1 - linkDensity(n))
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+
+	if best == nil {
+		return findElement(doc, atom.Body)
+	}
+
+	return best
+}
+
+// pruneChrome removes navigation, banners, footers and other page chrome.
+func pruneChrome(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		if c.Type == html.ElementNode && isChrome(c) {
+			n.RemoveChild(c)
+		} else {
+			pruneChrome(c)
+		}
+
+		c = next
+	}
+}
+
+// isChrome reports whether an element is page chrome rather than content.
+func isChrome(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Main, atom.Article:
+		return false
+	case atom.Nav, atom.Header, atom.Footer, atom.Aside, atom.Form, atom.Dialog:
+		return true
+	}
+
+	if htmlSkipped[n.DataAtom] || isHidden(n) || chromeRoles[htmlAttr(n, "role")] {
+		return true
+	}
+
+	names := htmlAttr(n, "class") + " " + htmlAttr(n, "id")
+
+	return unlikelyContent.MatchString(names) && !maybeContent.MatchString(names)
+}
+
+// isScorable reports whether an element holds a paragraph of text: a
+// paragraph-like element, or a div without block children.
+func isScorable(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Li, atom.Dd, atom.Blockquote:
+		return true
+	case atom.Div, atom.Section:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (htmlBlocks[c.DataAtom] || htmlParagraphs[c.DataAtom] || c.DataAtom == atom.Li) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// initialScore weighs a candidate by its tag and class and id names.
+func initialScore(n *html.Node) float64 {
+	var score float64
+
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score = 10
+	case atom.Div, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	for _, name := range []string{htmlAttr(n, "class"), htmlAttr(n, "id")} {
+		if name == "" {
+			continue
+		}
+
+		if negativeContent.MatchString(name) {
+			score -= 25
+		}
+
+		if positiveContent.MatchString(name) {
+			score += 25
+		}
+	}
+
+	return score
+}
+
+// linkDensity returns the share of an element's text that is link text.
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(htmlText(n))
+	if total == 0 {
+		return 0
+	}
+
+	links := 0
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			links += utf8.RuneCountInString(htmlText(n))
+
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+
+	return float64(links) / float64(total)
+}
+
+// findElement returns the first element of a kind, depth first.
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
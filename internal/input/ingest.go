@@ -0,0 +1,144 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// JobSourceEmail and JobSourceFeed mark postings read from the body of an
+	// email and from the content of a feed item.
+	JobSourceEmail = "email"
+	JobSourceFeed  = "feed"
+
+	// maxEmailPostings bounds how many posting links of one email are fetched.
+	maxEmailPostings = 20
+	// minFeedContent is the length below which the content of a feed item is
+	// taken for a teaser, and the posting it links to is fetched instead.
+	minFeedContent = 400
+)
+
+// IngestedJob is a job posting read from an email or a feed.
+type IngestedJob struct {
+	// ExternalID identifies the posting across emails and feeds: its link
+	// when it has one, so the same posting is only saved once.
+	ExternalID string
+	URL        string
+	PostedAt   *time.Time
+	Job        *StructuredJobDescription
+}
+
+// JobsFromEmail reads the job postings of an email. Job alerts link to their
+// postings, which are fetched; an email without posting links, such as a
+// forwarded job description, is one posting itself. It returns a message for
+// each posting that could not be fetched.
+func (f *Fetcher) JobsFromEmail(ctx context.Context, msg *EmailMessage) ([]IngestedJob, []string) {
+	var (
+		jobs []IngestedJob
+		errs []string
+	)
+
+	links := msg.PostingLinks()
+	if len(links) > maxEmailPostings {
+		errs = append(errs, fmt.Sprintf("only the first %d of %d posting links were read", maxEmailPostings, len(links)))
+		links = links[:maxEmailPostings]
+	}
+
+	for _, link := range links {
+		if ctx.Err() != nil {
+			errs = append(errs, "interrupted")
+
+			break
+		}
+
+		job, err := f.FetchJob(ctx, link)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", link, err))
+
+			continue
+		}
+
+		jobs = append(jobs, IngestedJob{ExternalID: link, URL: link, PostedAt: msg.Date, Job: job})
+	}
+
+	if len(links) == 0 && msg.Text != "" {
+		id := msg.MessageID
+		if id == "" {
+			id = contentID(msg.Subject, msg.Text)
+		}
+
+		job := structuredJob(&jobData{source: JobSourceEmail, title: msg.Subject, description: msg.Text})
+		jobs = append(jobs, IngestedJob{ExternalID: "email:" + id, PostedAt: msg.Date, Job: job})
+	}
+
+	return jobs, errs
+}
+
+// FetchFeed fetches and parses an RSS or Atom feed.
+func (f *Fetcher) FetchFeed(ctx context.Context, feedURL string) (*Feed, error) {
+	data, err := f.client.Get(ctx, feedURL, feedTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFeed(data)
+}
+
+// FeedItemID returns the external ID an item of a feed is saved under, so
+// known items can be skipped before they are read.
+func FeedItemID(feedURL string, item FeedItem) string {
+	if link := cleanLink(item.Link); link != "" {
+		return link
+	}
+
+	id := item.ID
+	if id == "" {
+		id = contentID(item.Title, item.Content)
+	}
+
+	return "feed:" + feedURL + "#" + id
+}
+
+// JobFromFeedItem reads the posting of a feed item. Items carrying only a
+// teaser are completed by fetching the posting they link to.
+func (f *Fetcher) JobFromFeedItem(ctx context.Context, feedURL string, item FeedItem) (IngestedJob, error) {
+	out := IngestedJob{ExternalID: FeedItemID(feedURL, item), URL: cleanLink(item.Link), PostedAt: item.Published}
+	text := htmlFragmentText(item.Content)
+
+	if utf8.RuneCountInString(text) < minFeedContent && out.URL != "" {
+		job, err := f.FetchJob(ctx, out.URL)
+		if err == nil {
+			out.Job = job
+
+			return out, nil
+		}
+
+		if text == "" {
+			return out, err
+		}
+	}
+
+	if text == "" && item.Title == "" {
+		return out, errors.New("feed item has no content")
+	}
+
+	out.Job = structuredJob(&jobData{source: JobSourceFeed, title: item.Title, description: text})
+
+	return out, nil
+}
+
+// contentID derives an ID from content that has none.
+func contentID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+
+	return hex.EncodeToString(sum[:12])
+}
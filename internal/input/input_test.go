@@ -4,8 +4,27 @@
 package input
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/docx"
+	"github.com/sammyoina/vibe-cv/internal/egress"
+	"github.com/sammyoina/vibe-cv/internal/ocr"
+	"github.com/sammyoina/vibe-cv/internal/types"
 )
 
 func TestFetcher_ExtractHTML(t *testing.T) {
@@ -135,6 +154,94 @@ func TestLinkedInParser_ParseProfile(t *testing.T) {
 	}
 }
 
+func TestLinkedInParser_ParseExport(t *testing.T) {
+	files := map[string]string{
+		"Basic_LinkedInDataExport/Profile.csv": "\xef\xbb\xbfFirst Name,Last Name,Maiden Name,Address,Birth Date,Headline,Summary,Industry,Zip Code,Geo Location,Twitter Handles,Websites,Instant Messengers\n" +
+			"Zoë,O'Connor-Nakamura,,,,Staff Engineer at Initech,\"Builds platforms, mentors teams.\",Software Development,,\"Dublin, Ireland\",,[PORTFOLIO:https://zoe.example],\n",
+		"Positions.csv": "Company Name,Title,Description,Location,Started On,Finished On\n" +
+			"Initech,Staff Engineer,\"Led the platform team.\nCut deploy times by 80%.\",\"Dublin, Ireland\",Mar 2021,\n" +
+			"Globex,Software Engineer,,,Jan 2016,Feb 2021\n",
+		"Education.csv": "School Name,Start Date,End Date,Notes,Degree Name,Activities\n" +
+			"Trinity College Dublin,2011,2015,,BSc Computer Science,Robotics society\n",
+		"Skills.csv":         "Name\nGo\nKubernetes\n\n",
+		"Certifications.csv": "Name,Url,Authority,Started On,Finished On,License Number\nCKA,https://cncf.example/cka,CNCF,Jun 2022,Jun 2025,LF-123\n",
+		"Messages.csv":       "FROM,TO,CONTENT\nsomeone,me,hello\n",
+	}
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := NewLinkedInParser().ParseExport(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseExport() error = %v", err)
+	}
+
+	if profile.Name != "Zoë O'Connor-Nakamura" || profile.Title != "Staff Engineer at Initech" ||
+		profile.Location != "Dublin, Ireland" || !slices.Equal(profile.Websites, []string{"https://zoe.example"}) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	want := []Experience{
+		{Title: "Staff Engineer", Company: "Initech", Location: "Dublin, Ireland", Duration: "Mar 2021 - Present", StartDate: "Mar 2021",
+			Description: "Led the platform team.\nCut deploy times by 80%.",
+			Period:      &DateRange{Start: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), Current: true}},
+		{Title: "Software Engineer", Company: "Globex", Duration: "Jan 2016 - Feb 2021", StartDate: "Jan 2016", EndDate: "Feb 2021",
+			Period: &DateRange{Start: time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	if !reflect.DeepEqual(profile.Experience, want) {
+		t.Errorf("Experience = %+v, want %+v", profile.Experience, want)
+	}
+
+	if len(profile.Education) != 1 || profile.Education[0].Degree != "BSc Computer Science" ||
+		profile.Education[0].Duration != "2011 - 2015" || profile.Education[0].Activities != "Robotics society" {
+		t.Errorf("unexpected education: %+v", profile.Education)
+	}
+
+	if !slices.Equal(profile.Skills, []string{"Go", "Kubernetes"}) {
+		t.Errorf("unexpected skills: %q", profile.Skills)
+	}
+
+	if len(profile.Certifications) != 1 || profile.Certifications[0].LicenseNumber != "LF-123" {
+		t.Errorf("unexpected certifications: %+v", profile.Certifications)
+	}
+
+	text := profile.ToText()
+	for _, part := range []string{"Name: Zoë O'Connor-Nakamura", "Staff Engineer at Initech\n  Mar 2021 - Present, Dublin, Ireland",
+		"  Cut deploy times by 80%.", "- CKA, CNCF"} {
+		if !strings.Contains(text, part) {
+			t.Errorf("expected %q in text:\n%s", part, text)
+		}
+	}
+
+	if _, err := NewLinkedInParser().ParseExport([]byte("not a zip")); err == nil {
+		t.Error("expected an error for a file that is not a ZIP archive")
+	}
+
+	buf.Reset()
+	zw = zip.NewWriter(&buf)
+	_, _ = zw.Create("notes.txt")
+	_ = zw.Close()
+
+	if _, err := NewLinkedInParser().ParseExport(buf.Bytes()); !errors.Is(err, ErrNotLinkedInExport) {
+		t.Errorf("expected ErrNotLinkedInExport, got %v", err)
+	}
+}
+
 func TestEnhancedParser_ParseCV(t *testing.T) {
 	cvText := `
 	John Doe
@@ -184,6 +291,8 @@ func TestEnhancedParser_ParseCV(t *testing.T) {
 
 	if len(cv.Experience) == 0 {
 		t.Error("Expected experience to be extracted")
+	} else if p := cv.Experience[0].Period; p == nil || !p.Current || p.Start.Year() != 2020 || cv.ExperienceMonths == 0 {
+		t.Errorf("Expected an ongoing period from 2020, got %+v (%d months)", p, cv.ExperienceMonths)
 	}
 
 	if len(cv.Education) == 0 {
@@ -191,6 +300,106 @@ func TestEnhancedParser_ParseCV(t *testing.T) {
 	}
 }
 
+func TestEnhancedParser_ParseCV_German(t *testing.T) {
+	cvText := `
+	Jürgen Müller-Lüdenscheidt
+	jürgen.müller@beispiel.de
+	+49 (0)30 1234-5678
+
+	Zusammenfassung:
+	Softwareentwickler mit zehn Jahren Erfahrung in der Entwicklung von verteilten Systemen für den Handel.
+
+	Berufserfahrung
+	Senior Softwareentwickler
+	Beispiel GmbH
+	2019 - heute
+	Entwicklung der Zahlungsplattform mit Go und Kubernetes
+
+	Kenntnisse
+	Go, Python, Kubernetes, PostgreSQL
+
+	Ausbildung
+	Technische Universität München
+	Master Informatik
+	2012 - 2014
+	`
+
+	parser := NewEnhancedParser()
+	cv := parser.ParseCV(cvText)
+
+	if cv.Language != "de" {
+		t.Errorf("Language = %q, want de", cv.Language)
+	}
+
+	if cv.Name != "Jürgen Müller-Lüdenscheidt" {
+		t.Errorf("Name = %q, want Jürgen Müller-Lüdenscheidt", cv.Name)
+	}
+
+	if cv.Email != "jürgen.müller@beispiel.de" {
+		t.Errorf("Email = %q, want jürgen.müller@beispiel.de", cv.Email)
+	}
+
+	if cv.Phone != "+49 (0)30 1234-5678" {
+		t.Errorf("Phone = %q, want +49 (0)30 1234-5678", cv.Phone)
+	}
+
+	if !strings.HasPrefix(cv.Summary, "Softwareentwickler mit zehn Jahren") {
+		t.Errorf("Summary = %q, want the Zusammenfassung", cv.Summary)
+	}
+
+	// Entries without bullets are read as one, up to the next section
+	if len(cv.Experience) != 1 || !strings.HasPrefix(cv.Experience[0].Title, "Senior Softwareentwickler") ||
+		strings.Contains(cv.Experience[0].Title, "Kenntnisse") {
+		t.Errorf("Experience = %+v, want the Berufserfahrung entry", cv.Experience)
+	}
+
+	if !slices.Equal(cv.Skills, []string{"Go", "Python", "Kubernetes", "PostgreSQL"}) {
+		t.Errorf("Skills = %v, want the Kenntnisse", cv.Skills)
+	}
+
+	if len(cv.Education) != 1 || !strings.HasPrefix(cv.Education[0].School, "Technische Universität München") {
+		t.Errorf("Education = %+v, want the Ausbildung entries", cv.Education)
+	}
+}
+
+func TestEnhancedParser_ParseJobDescription_French(t *testing.T) {
+	jobText := `
+	Développeur Go confirmé
+
+	Nous recherchons un développeur pour rejoindre notre équipe à Paris.
+
+	Vos missions :
+	- Concevoir les services de paiement
+	- Participer aux revues de code
+
+	Profil recherché :
+	- 5 ans d'expérience avec Go
+	- Maîtrise de Kubernetes
+
+	Avantages :
+	- Télétravail partiel
+	`
+
+	parser := NewEnhancedParser()
+	job := parser.ParseJobDescription(jobText)
+
+	if job.Language != "fr" {
+		t.Errorf("Language = %q, want fr", job.Language)
+	}
+
+	if job.Title != "Développeur Go confirmé" {
+		t.Errorf("Title = %q, want Développeur Go confirmé", job.Title)
+	}
+
+	if len(job.Responsibilities) == 0 || job.Responsibilities[0] != "Concevoir les services de paiement" {
+		t.Errorf("Responsibilities = %v, want the missions", job.Responsibilities)
+	}
+
+	if len(job.Requirements) == 0 || job.Requirements[0] != "5 ans d'expérience avec Go" {
+		t.Errorf("Requirements = %v, want the profil recherché", job.Requirements)
+	}
+}
+
 func TestEnhancedParser_ParseJobDescription(t *testing.T) {
 	jobText := `
 	Senior Go Engineer
@@ -250,6 +459,16 @@ func TestEnhancedParser_ParseJobDescription(t *testing.T) {
 	if len(job.BenefitsKeywords) == 0 {
 		t.Error("Expected benefits to be extracted")
 	}
+
+	for _, skill := range []string{"Go", "Kubernetes", "Microservices", "gRPC", "Docker"} {
+		if !slices.Contains(job.Skills, skill) {
+			t.Errorf("Expected skill %q in %q", skill, job.Skills)
+		}
+	}
+
+	if job.RequiredYears != 5 {
+		t.Errorf("RequiredYears = %d, want 5", job.RequiredYears)
+	}
 }
 
 func TestIsValidJobURL(t *testing.T) {
@@ -271,3 +490,984 @@ func TestIsValidJobURL(t *testing.T) {
 		}
 	}
 }
+
+func TestResolver_Resolve(t *testing.T) {
+	var docxFile bytes.Buffer
+	if err := docx.Write(&docxFile, "Jane Doe\nSenior Go Engineer\nSKILLS:\nGo, Postgres"); err != nil {
+		t.Fatalf("failed to build DOCX: %v", err)
+	}
+
+	resolver := NewResolver(NewFetcher(0))
+	req := &types.CustomizeCVRequest{
+		CVFile:         base64.StdEncoding.EncodeToString(docxFile.Bytes()),
+		JobDescription: "Backend engineer with Go experience",
+		InputSources: []types.InputSource{
+			{Type: "pdf", Content: "not base64!"},
+			{Type: "text", Content: "Prefers remote roles"},
+		},
+	}
+
+	resolved, err := resolver.Resolve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(resolved.CVText, "Senior Go Engineer") {
+		t.Errorf("expected CV text from DOCX, got: %s", resolved.CVText)
+	}
+
+	if resolved.JobDescription != req.JobDescription {
+		t.Errorf("expected job description %q, got %q", req.JobDescription, resolved.JobDescription)
+	}
+
+	if len(resolved.Context) != 1 || resolved.Context[0] != "Prefers remote roles" {
+		t.Errorf("expected text source as context, got %v", resolved.Context)
+	}
+
+	statuses := map[string]string{}
+	for _, src := range resolved.Sources {
+		statuses[src.Source] = src.Status
+	}
+
+	want := map[string]string{
+		"cv_file":          "resolved",
+		"job_description":  "resolved",
+		"input_sources[0]": "failed",
+		"input_sources[1]": "resolved",
+	}
+	for source, status := range want {
+		if statuses[source] != status {
+			t.Errorf("expected %s to be %s, got %q", source, status, statuses[source])
+		}
+	}
+}
+
+func TestResolver_MissingJobDescription(t *testing.T) {
+	resolver := NewResolver(NewFetcher(0))
+
+	_, err := resolver.Resolve(context.Background(), &types.CustomizeCVRequest{CV: "Jane Doe"})
+	if !errors.Is(err, ErrNoJobDescription) {
+		t.Errorf("expected ErrNoJobDescription, got %v", err)
+	}
+}
+
+// buildZip packs named parts into a ZIP file.
+func buildZip(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close ZIP: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestDOCXParser_Structure(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://github.com/janedoe" TargetMode="External"/>
+<Relationship Id="rId6" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+</Relationships>`,
+		"word/styles.xml": `<w:styles ` + wordNS + `>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:pPr><w:outlineLvl w:val="0"/></w:pPr></w:style>
+</w:styles>`,
+		"word/header1.xml": `<w:hdr ` + wordNS + `><w:p><w:r><w:t>jane@example.com | +1 555 123 4567</w:t></w:r></w:p></w:hdr>`,
+		"word/document.xml": `<w:document ` + wordNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>Jane Doe</w:t></w:r></w:p>
+<w:p><w:r><w:t>Senior Go Engineer</w:t></w:r></w:p>
+<w:p><w:hyperlink r:id="rId5"><w:r><w:t>GitHub</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Experience</w:t></w:r></w:p>
+<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Backend Engineer</w:t></w:r><w:r><w:tab/><w:t>Jan 2020 – Present</w:t></w:r></w:p>
+<w:p><w:r><w:t>Acme Corp</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Built payment APIs</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Cut latency </w:t></w:r><w:r><w:t>by 40%</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Skills</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Go</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>PostgreSQL</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>Kubernetes</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr></w:tbl>
+<w:sectPr><w:headerReference w:type="default" r:id="rId6"/></w:sectPr>
+</w:body></w:document>`,
+	})
+
+	doc, err := NewDOCXParser(0).ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	text := doc.Text()
+	for _, want := range []string{
+		"jane@example.com | +1 555 123 4567\n\nJane Doe",
+		"GitHub (https://github.com/janedoe)",
+		"\nExperience\n",
+		"• Built payment APIs\n  • Cut latency by 40%",
+		"Go | PostgreSQL\nKubernetes",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	cv := NewEnhancedParser().ParseDocument(doc)
+
+	if cv.Name != "Jane Doe" || cv.Title != "Senior Go Engineer" || cv.Email != "jane@example.com" {
+		t.Errorf("unexpected contact details: name %q, title %q, email %q", cv.Name, cv.Title, cv.Email)
+	}
+
+	if len(cv.Experience) != 1 {
+		t.Fatalf("expected 1 experience entry, got %+v", cv.Experience)
+	}
+
+	exp := cv.Experience[0]
+	if exp.Title != "Backend Engineer" || exp.Company != "Acme Corp" || exp.Duration != "Jan 2020 – Present" {
+		t.Errorf("unexpected experience entry: %+v", exp)
+	}
+
+	if exp.Description != "Built payment APIs Cut latency by 40%" {
+		t.Errorf("unexpected experience description: %q", exp.Description)
+	}
+
+	if strings.Join(cv.Skills, ",") != "Go,PostgreSQL,Kubernetes" {
+		t.Errorf("unexpected skills: %v", cv.Skills)
+	}
+
+	if len(cv.Links) != 1 || cv.Links[0] != "https://github.com/janedoe" {
+		t.Errorf("unexpected links: %v", cv.Links)
+	}
+}
+
+func TestODTParser_Structure(t *testing.T) {
+	const ns = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+		`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+		`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+		`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" ` +
+		`xmlns:xlink="http://www.w3.org/1999/xlink"`
+
+	data := buildZip(t, map[string]string{
+		"mimetype": "application/vnd.oasis.opendocument.text",
+		"styles.xml": `<office:document-styles ` + ns + `><office:master-styles><style:master-page style:name="Standard">
+<style:footer><text:p>jane@example.com</text:p></style:footer></style:master-page></office:master-styles></office:document-styles>`,
+		"content.xml": `<office:document-content ` + ns + `>
+<office:automatic-styles><style:style style:name="T1" style:family="text"><style:text-properties fo:font-weight="bold"/></style:style></office:automatic-styles>
+<office:body><office:text>
+<text:p>Jane   Doe</text:p>
+<text:h text:outline-level="1">Skills</text:h>
+<text:list><text:list-item><text:p>Go<text:s text:c="2"/>and Rust</text:p>
+<text:list><text:list-item><text:p>Concurrency</text:p></text:list-item></text:list></text:list-item></text:list>
+<table:table><table:table-row><table:table-cell><text:p>Docker</text:p></table:table-cell><table:table-cell><text:p>AWS</text:p></table:table-cell></table:table-row></table:table>
+<text:p><text:span text:style-name="T1">Projects</text:span></text:p>
+<text:p>See <text:a xlink:href="https://example.com/jane">my portfolio</text:a></text:p>
+</office:text></office:body></office:document-content>`,
+	})
+
+	if detectFileType(base64.StdEncoding.EncodeToString(data), "") != SourceODT {
+		t.Errorf("expected ODT content to be detected")
+	}
+
+	doc, err := NewODTParser(0).ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	want := "Jane Doe\n\nSkills\n• Go and Rust\n  • Concurrency\nDocker | AWS\n\nProjects\n" +
+		"See my portfolio (https://example.com/jane)\n\njane@example.com"
+	if text := doc.Text(); text != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", text, want)
+	}
+}
+
+func TestRTFParser_Structure(t *testing.T) {
+	data := `{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0 Arial;}}{\stylesheet{\s0 Normal;}{\s1\b heading 1;}}
+{\header\pard\plain jane@example.com\par}
+\pard\plain Ren\'e9e Doe\par
+\pard\s1\b Experience\b0\par
+\pard\plain Go Engineer\par
+\pard\ls1\ilvl0 {\listtext\'b7\tab}Shipped \u8220?fast\u8221? APIs\par
+\pard\s1 Skills\par
+\trowd\cellx2000\cellx4000\pard\intbl Go\cell Rust\cell\row
+\pard\plain {\field{\*\fldinst HYPERLINK "https://example.com/renee"}{\fldrslt Portfolio}}\par
+}`
+
+	if detectFileType(base64.StdEncoding.EncodeToString([]byte(data)), "") != SourceRTF {
+		t.Errorf("expected RTF content to be detected")
+	}
+
+	doc, err := NewRTFParser(0).ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	want := "jane@example.com\nRenée Doe\n\nExperience\nGo Engineer\n• Shipped “fast” APIs\n\nSkills\nGo | Rust\n" +
+		"Portfolio (https://example.com/renee)"
+	if text := doc.Text(); text != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", text, want)
+	}
+}
+
+func TestRTFParser_HeaderInParagraph(t *testing.T) {
+	// A header group in the middle of a body paragraph: the paragraph is set
+	// aside while the header is read, then continued
+	data := `{\rtf1\ansi\pard\plain Jane {\header\pard\plain jane@example.com\par}Doe, Go Engineer\par
+\pard\plain Berlin {\footer\pard Page 1\par}Germany\par
+}`
+
+	doc, err := NewRTFParser(0).ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	if want := "jane@example.com\nJane Doe, Go Engineer\nBerlin Germany\n\nPage 1"; doc.Text() != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", doc.Text(), want)
+	}
+}
+
+// buildDOC builds a minimal Word 97 document whose single piece holds text
+// as 8-bit characters.
+func buildDOC(text []byte) []byte {
+	le := binary.LittleEndian
+
+	word := make([]byte, 4096)
+	le.PutUint16(word[0:], 0xA5EC)
+	le.PutUint16(word[2:], 0xC1)
+	le.PutUint16(word[32:], 14) // csw
+	le.PutUint16(word[62:], 22) // cslw
+	le.PutUint32(word[76:], uint32(len(text)))
+	le.PutUint16(word[152:], 93) // cbRgFcLcb
+	le.PutUint32(word[154+33*8:], 0)
+	le.PutUint32(word[154+33*8+4:], 21)
+	copy(word[1024:], text)
+
+	table := make([]byte, 4096)
+	table[0] = 2
+	le.PutUint32(table[1:], 16)
+	le.PutUint32(table[9:], uint32(len(text)))
+	le.PutUint32(table[15:], (1024*2)|0x40000000)
+
+	// Compound file: FAT in sector 0, directory in sector 1, then the streams
+	out := make([]byte, 512*19)
+	copy(out, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	le.PutUint16(out[0x1A:], 3)
+	le.PutUint16(out[0x1C:], 0xFFFE)
+	le.PutUint16(out[0x1E:], 9)
+	le.PutUint16(out[0x20:], 6)
+	le.PutUint32(out[0x2C:], 1)
+	le.PutUint32(out[0x30:], 1)
+	le.PutUint32(out[0x38:], 4096)
+	le.PutUint32(out[0x3C:], 0xFFFFFFFE)
+	le.PutUint32(out[0x44:], 0xFFFFFFFE)
+
+	for i := range 109 {
+		le.PutUint32(out[0x4C+4*i:], 0xFFFFFFFF)
+	}
+
+	le.PutUint32(out[0x4C:], 0)
+
+	fat := out[512:1024]
+	for i := range 128 {
+		le.PutUint32(fat[4*i:], 0xFFFFFFFF)
+	}
+
+	le.PutUint32(fat[0:], 0xFFFFFFFD)
+	le.PutUint32(fat[4:], 0xFFFFFFFE)
+
+	for i := 2; i < 18; i++ {
+		next := uint32(i + 1)
+		if i == 9 || i == 17 {
+			next = 0xFFFFFFFE
+		}
+
+		le.PutUint32(fat[4*i:], next)
+	}
+
+	dir := out[1024:1536]
+	entry := func(i int, name string, typ byte, start, size uint32) {
+		e := dir[128*i:]
+		for j, c := range name {
+			le.PutUint16(e[2*j:], uint16(c))
+		}
+
+		le.PutUint16(e[0x40:], uint16(2*len(name)+2))
+		e[0x42] = typ
+		le.PutUint32(e[0x74:], start)
+		le.PutUint32(e[0x78:], size)
+	}
+
+	entry(0, "Root Entry", 5, 0xFFFFFFFE, 0)
+	entry(1, "WordDocument", 2, 2, 4096)
+	entry(2, "0Table", 2, 10, 4096)
+
+	copy(out[512*3:], word)
+	copy(out[512*11:], table)
+
+	return out
+}
+
+func TestDOCParser_Structure(t *testing.T) {
+	text := "JANE DOE\rVisit \x13 HYPERLINK \"https://example.com\" \x14my site\x15\r" +
+		"EXPERIENCE\rGo Engineer\tJan 2020 - Present\rCaf\xe9 Corp\r" +
+		"SKILLS\rGo\x07Postgres\x07\x07Kafka\x07Redis\x07\x07\r"
+	data := buildDOC([]byte(text))
+
+	if detectFileType(base64.StdEncoding.EncodeToString(data), "") != SourceDOC {
+		t.Errorf("expected DOC content to be detected")
+	}
+
+	doc, err := NewDOCParser(0).ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	want := "JANE DOE\nVisit my site (https://example.com)\n\nEXPERIENCE\nGo Engineer\tJan 2020 - Present\nCafé Corp\n\n" +
+		"SKILLS\nGo | Postgres\nKafka | Redis"
+	if got := doc.Text(); got != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", got, want)
+	}
+
+	cv := NewEnhancedParser().ParseDocument(doc)
+	if strings.Join(cv.Skills, ",") != "Go,PostgreSQL,Kafka,Redis" {
+		t.Errorf("unexpected skills: %v", cv.Skills)
+	}
+
+	if len(cv.Experience) != 1 || cv.Experience[0].Company != "Café Corp" {
+		t.Errorf("unexpected experience: %+v", cv.Experience)
+	}
+}
+
+// fakeOCR returns a fixed result for every image.
+type fakeOCR struct {
+	result *ocr.Result
+	images []ocr.Image
+}
+
+func (f *fakeOCR) Name() string { return "fake" }
+
+func (f *fakeOCR) Recognize(_ context.Context, img ocr.Image) (*ocr.Result, error) {
+	f.images = append(f.images, img)
+
+	return f.result, nil
+}
+
+// scannedPDF builds a one-page PDF whose only content is a grayscale image.
+func scannedPDF() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im0 5 0 R >> >> /Contents 4 0 R >>",
+		"<< /Length 29 >>\nstream\nq 612 0 0 792 0 0 cm /Im0 Do Q\nendstream",
+		"<< /Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 2 >>\nstream\n\x00\xff\nendstream",
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestPDFParser_OCR(t *testing.T) {
+	data := scannedPDF()
+	parser := NewPDFParser(0)
+
+	if _, err := parser.ParseBytes(data); err == nil || !strings.Contains(err.Error(), "OCR is not configured") {
+		t.Errorf("expected an error about missing OCR, got %v", err)
+	}
+
+	engine := &fakeOCR{result: &ocr.Result{
+		Lines: []ocr.Line{
+			{Text: "Jane Doe", Confidence: 95, Words: make([]ocr.Word, 2)},
+			{Text: "Kubemetes", Confidence: 40, Words: make([]ocr.Word, 1), Box: image.Rect(10, 20, 110, 50), Paragraph: 1},
+		},
+		Confidence: 76.666,
+	}}
+	parser.SetOCR(engine)
+
+	text, report, err := parser.Parse(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if text != "Jane Doe\n\nKubemetes" {
+		t.Errorf("unexpected text: %q", text)
+	}
+
+	if len(engine.images) != 1 || engine.images[0].DPI != 300 {
+		t.Fatalf("expected one page rendered at 300 DPI, got %+v", engine.images)
+	}
+
+	if report == nil || report.Engine != "fake" || len(report.Pages) != 1 || report.Confidence != 76.7 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	want := types.OCRRegion{Page: 1, Text: "Kubemetes", Confidence: 40, Box: [4]int{10, 20, 100, 30}}
+	if len(report.Review) != 1 || report.Review[0] != want {
+		t.Errorf("unexpected review regions: %+v", report.Review)
+	}
+}
+
+func TestFetcher_FetchJob(t *testing.T) {
+	pages := map[string]string{
+		"/json-ld": `<html><head><title>Careers</title>
+<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "WebSite"}, {
+  "@type": "JobPosting", "title": "Senior Go Engineer",
+  "hiringOrganization": {"@type": "Organization", "name": "Acme"},
+  "jobLocation": {"@type": "Place", "address": {"addressLocality": "Berlin", "addressCountry": "DE"}},
+  "employmentType": "FULL_TIME",
+  "baseSalary": {"@type": "MonetaryAmount", "currency": "EUR", "value": {"minValue": 80000, "maxValue": 95000, "unitText": "YEAR"}},
+  "description": "&lt;p&gt;Build APIs.&lt;/p&gt;&lt;ul&gt;&lt;li&gt;5+ years of Go&lt;/li&gt;&lt;/ul&gt;"}]}</script>
+</head><body><nav>Home Jobs About</nav><div class="cookie-banner">We use cookies</div></body></html>`,
+		"/readability": `<html><head><title>Data Engineer - Globex</title></head><body>
+<nav><a href="/">Home</a> <a href="/jobs">All jobs</a></nav>
+<div id="cookie-consent">Accept all cookies to continue browsing this website today.</div>
+<div class="job-description">
+<h1>Data Engineer</h1>
+<p>Globex is hiring a data engineer to own our pipelines, warehouses, and reporting tools.</p>
+<p>You will work with Python, SQL, and Airflow, and partner with analysts across the company.</p>
+</div>
+<footer>Copyright Globex, all rights reserved, privacy policy, terms of use.</footer>
+</body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(0)
+
+	if _, err := fetcher.FetchJob(context.Background(), server.URL+"/json-ld"); !errors.Is(err, egress.ErrBlocked) {
+		t.Fatalf("expected the default policy to block a loopback server, got %v", err)
+	}
+
+	policy := egress.DefaultPolicy()
+	policy.AllowPrivate = true
+	policy.HostInterval = 0
+	fetcher.SetPolicy(policy)
+
+	job, err := fetcher.FetchJob(context.Background(), server.URL+"/json-ld")
+	if err != nil {
+		t.Fatalf("FetchJob() error = %v", err)
+	}
+
+	if job.Source != JobSourceJSONLD || job.Title != "Senior Go Engineer" || job.Company != "Acme" ||
+		job.Location != "Berlin, DE" || job.EmploymentType != "Full time" {
+		t.Errorf("unexpected posting: %+v", job)
+	}
+
+	if job.Salary != "EUR 80,000–95,000 per year" {
+		t.Errorf("unexpected salary: %q", job.Salary)
+	}
+
+	if !strings.Contains(job.RawText, "• 5+ years of Go") || strings.Contains(job.RawText, "cookies") {
+		t.Errorf("unexpected text: %q", job.RawText)
+	}
+
+	job, err = fetcher.FetchJob(context.Background(), server.URL+"/readability")
+	if err != nil {
+		t.Fatalf("FetchJob() error = %v", err)
+	}
+
+	if job.Source != JobSourceReadability || !strings.Contains(job.RawText, "Airflow") {
+		t.Errorf("unexpected posting: %+v", job)
+	}
+
+	for _, chrome := range []string{"All jobs", "cookies", "Copyright"} {
+		if strings.Contains(job.RawText, chrome) {
+			t.Errorf("expected %q to be left out, got %q", chrome, job.RawText)
+		}
+	}
+
+	if _, err := fetcher.FetchJob(context.Background(), server.URL+"/missing"); err == nil {
+		t.Error("expected an error for a missing page")
+	}
+}
+
+func TestATSAdapters(t *testing.T) {
+	tests := []struct {
+		url     string
+		adapter string
+		api     string
+		body    string
+		check   func(*jobData) bool
+	}{
+		{
+			url:     "https://boards.greenhouse.io/acme/jobs/4012345",
+			adapter: "greenhouse",
+			api:     "https://boards-api.greenhouse.io/v1/boards/acme/jobs/4012345?pay_transparency=true",
+			body: `{"title": "Backend Engineer", "company_name": "Acme", "location": {"name": "Remote"},
+				"content": "&lt;h3&gt;Requirements&lt;/h3&gt;&lt;ul&gt;&lt;li&gt;Go&lt;/li&gt;&lt;/ul&gt;",
+				"pay_input_ranges": [{"min_cents": 12000000, "max_cents": 15000000, "currency_type": "USD"}]}`,
+			check: func(j *jobData) bool {
+				return j.company == "Acme" && j.salary == "USD 120,000–150,000 per year" &&
+					strings.Contains(j.description, "• Go")
+			},
+		},
+		{
+			url:     "https://jobs.lever.co/globex/9f1c-22ab",
+			adapter: "lever",
+			api:     "https://api.lever.co/v0/postings/globex/9f1c-22ab",
+			body: `{"text": "SRE", "categories": {"location": "London", "commitment": "Full-time"},
+				"workplaceType": "remote", "description": "<p>Keep things running.</p>",
+				"lists": [{"text": "What you bring", "content": "<li>Kubernetes</li><li>Terraform</li>"}]}`,
+			check: func(j *jobData) bool {
+				return j.company == "Globex" && j.location == "London (Remote)" &&
+					slices.Equal(j.requirements, []string{"Kubernetes", "Terraform"})
+			},
+		},
+		{
+			url:     "https://initech.wd5.myworkdayjobs.com/en-US/External/job/Austin-TX/Analyst_R123",
+			adapter: "workday",
+			api:     "https://initech.wd5.myworkdayjobs.com/wday/cxs/initech/External/job/Austin-TX/Analyst_R123",
+			body: `{"jobPostingInfo": {"title": "Analyst", "jobDescription": "<p>Analyze TPS reports.</p>",
+				"location": "Austin, TX", "timeType": "Full time"}, "hiringOrganization": {"name": "Initech"}}`,
+			check: func(j *jobData) bool {
+				return j.company == "Initech" && j.location == "Austin, TX" && j.description == "Analyze TPS reports."
+			},
+		},
+		{
+			url:     "https://jobs.ashbyhq.com/hooli/6d2e",
+			adapter: "ashby",
+			api:     "https://api.ashbyhq.com/posting-api/job-board/hooli?includeCompensation=true",
+			body: `{"jobs": [{"id": "0000", "title": "Other"}, {"id": "6d2e", "title": "Designer", "location": "NYC",
+				"descriptionPlain": "Design things.", "compensation": {"compensationTierSummary": "$100K – $120K"}}]}`,
+			check: func(j *jobData) bool {
+				return j.title == "Designer" && j.salary == "$100K – $120K" && j.description == "Design things."
+			},
+		},
+		{
+			url:     "https://jobs.smartrecruiters.com/Umbrella/743999-lab-technician",
+			adapter: "smartrecruiters",
+			api:     "https://api.smartrecruiters.com/v1/companies/Umbrella/postings/743999",
+			body: `{"name": "Lab Technician", "company": {"name": "Umbrella"}, "location": {"city": "Raccoon City", "country": "us"},
+				"jobAd": {"sections": {"jobDescription": {"title": "Job Description", "text": "<p>Run assays.</p>"},
+				"qualifications": {"title": "Qualifications", "text": "<ul><li>BSc Biology</li></ul>"}}}}`,
+			check: func(j *jobData) bool {
+				return j.location == "Raccoon City, US" && slices.Equal(j.requirements, []string{"BSc Biology"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+
+		adapter, api, ok := atsAdapterFor(u)
+		if !ok || adapter.name != tt.adapter || api != tt.api {
+			t.Errorf("%s: expected %s at %s, got %v %q", tt.url, tt.adapter, tt.api, ok, api)
+
+			continue
+		}
+
+		job, err := adapter.parse([]byte(tt.body), u)
+		if err != nil {
+			t.Errorf("%s: parse error = %v", tt.adapter, err)
+
+			continue
+		}
+
+		if !tt.check(job) {
+			t.Errorf("%s: unexpected posting: %+v", tt.adapter, job)
+		}
+	}
+
+	u, _ := url.Parse("https://example.com/careers/123")
+	if _, _, ok := atsAdapterFor(u); ok {
+		t.Error("expected no adapter for an unknown host")
+	}
+}
+
+func TestParseEmail(t *testing.T) {
+	html := `<html><body><p>3 new jobs for you</p>
+<a href="https://jobs.example.com/jobs/1?utm_source=alert&amp;ref=mail#apply">Go Engineer</a>
+<a href="https://boards.greenhouse.io/acme/jobs/42">Platform Engineer</a>
+<a href="https://jobs.example.com/jobs/alerts/manage">Manage alerts</a>
+<a href="https://jobs.example.com/unsubscribe?id=7">Unsubscribe</a>
+<a href="https://jobs.example.com/jobs/1?ref=mail&amp;utm_medium=email">Go Engineer again</a>
+</body></html>`
+
+	alert := "From: Job Alerts <alerts@example.com>\r\n" +
+		"To: me@example.com\r\n" +
+		"Subject: =?UTF-8?B?TmV3IGpvYnMg4oCTIEdv?=\r\n" +
+		"Message-ID: <alert-1@example.com>\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+		"\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"3 new jobs near Caf=E9 Street, see the links in this=\r\n" +
+		" email.\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		wrapBase64(html) +
+		"--b1--\r\n"
+
+	msg, err := ParseEmail([]byte(alert))
+	if err != nil {
+		t.Fatalf("ParseEmail() error = %v", err)
+	}
+
+	if msg.Subject != "New jobs – Go" || msg.MessageID != "alert-1@example.com" || msg.Date == nil {
+		t.Errorf("unexpected headers: %+v", msg)
+	}
+
+	if !strings.Contains(msg.Text, "Café Street, see the links in this email.") {
+		t.Errorf("unexpected text: %q", msg.Text)
+	}
+
+	want := []string{"https://jobs.example.com/jobs/1?ref=mail", "https://boards.greenhouse.io/acme/jobs/42"}
+	if links := msg.PostingLinks(); !slices.Equal(links, want) {
+		t.Errorf("PostingLinks() = %q, want %q", links, want)
+	}
+
+	forwarded := "From: me@example.com\r\n" +
+		"Subject: Fwd: Backend Engineer\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"Content-Disposition: attachment; filename=\"posting.eml\"\r\n" +
+		"\r\n" +
+		"From: recruiter@example.com\r\n" +
+		"Subject: Backend Engineer\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"We are hiring a backend engineer with Go and PostgreSQL experience.\r\n" +
+		"--outer\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Disposition: attachment; filename=\"logo.png\"\r\n" +
+		"\r\n" +
+		"not a text part\r\n" +
+		"--outer--\r\n"
+
+	msg, err = ParseEmail([]byte(forwarded))
+	if err != nil {
+		t.Fatalf("ParseEmail() error = %v", err)
+	}
+
+	if msg.Text != "We are hiring a backend engineer with Go and PostgreSQL experience." || len(msg.PostingLinks()) != 0 {
+		t.Errorf("unexpected forwarded message: %+v", msg)
+	}
+
+	if _, err := ParseEmail([]byte("not an email")); err == nil {
+		t.Error("expected an error for a message without headers")
+	}
+}
+
+// wrapBase64 encodes s as a base64 body with 76 character lines.
+func wrapBase64(s string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(s))
+
+	var b strings.Builder
+
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+
+	b.WriteString(encoded + "\r\n")
+
+	return b.String()
+}
+
+func TestParseFeed(t *testing.T) {
+	rss := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel><title>Acme Jobs</title>
+<item>
+  <title>Go Engineer</title>
+  <link>https://acme.example/jobs/1</link>
+  <guid>job-1</guid>
+  <description>Short teaser</description>
+  <content:encoded><![CDATA[<p>Build services in Go &amp; Postgres.</p>]]></content:encoded>
+  <pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>
+</item>
+<item><title>Designer</title><link>https://acme.example/jobs/2</link><description>Design &nbsp;things</description></item>
+</channel></rss>`
+
+	feed, err := ParseFeed([]byte(rss))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	if feed.Title != "Acme Jobs" || len(feed.Items) != 2 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	item := feed.Items[0]
+	if item.ID != "job-1" || item.Link != "https://acme.example/jobs/1" || item.Published == nil ||
+		item.Content != "<p>Build services in Go &amp; Postgres.</p>" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if feed.Items[1].ID != "https://acme.example/jobs/2" {
+		t.Errorf("expected the link to stand in for a missing guid, got %q", feed.Items[1].ID)
+	}
+
+	atomFeed := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Globex Careers</title>
+<entry>
+  <id>urn:uuid:1</id>
+  <title>Data Engineer</title>
+  <link rel="self" href="https://globex.example/feed/1"/>
+  <link rel="alternate" href="https://globex.example/jobs/data"/>
+  <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Own our <b>pipelines</b>.</p></div></content>
+  <updated>2006-01-02T15:04:05Z</updated>
+</entry>
+</feed>`
+
+	feed, err = ParseFeed([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	if feed.Title != "Globex Careers" || len(feed.Items) != 1 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	item = feed.Items[0]
+	if item.ID != "urn:uuid:1" || item.Link != "https://globex.example/jobs/data" || item.Published == nil ||
+		htmlFragmentText(item.Content) != "Own our pipelines." {
+		t.Errorf("unexpected entry: %+v", item)
+	}
+
+	if _, err := ParseFeed([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("expected an error for a document that is not a feed")
+	}
+}
+
+func TestFetcher_Ingest(t *testing.T) {
+	posting := `<html><head><script type="application/ld+json">{"@type": "JobPosting",
+  "title": "%s", "hiringOrganization": {"name": "Acme"},
+  "description": "Build reliable services in Go, operate Postgres and mentor engineers."}</script></head><body></body></html>`
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jobs/1":
+			fmt.Fprintf(w, posting, "Go Engineer")
+		case "/jobs/2":
+			fmt.Fprintf(w, posting, "SRE")
+		case "/feed":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<rss version="2.0"><channel><title>Acme</title>
+<item><title>SRE</title><link>%s/jobs/2?utm_source=rss</link><description>Keep things up.</description></item>
+<item><title>Writer</title><guid>w-1</guid><description>%s</description></item>
+</channel></rss>`, server.URL, strings.Repeat("Write docs for developers. ", 20))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	policy := egress.DefaultPolicy()
+	policy.AllowPrivate = true
+	policy.HostInterval = 0
+
+	fetcher := NewFetcher(0)
+	fetcher.SetPolicy(policy)
+
+	msg := &EmailMessage{
+		MessageID: "alert-2@example.com",
+		Subject:   "New jobs",
+		Links:     []string{server.URL + "/jobs/1", server.URL + "/jobs/404", server.URL + "/unsubscribe"},
+	}
+
+	jobs, errs := fetcher.JobsFromEmail(context.Background(), msg)
+	if len(jobs) != 1 || jobs[0].Job.Title != "Go Engineer" || jobs[0].ExternalID != server.URL+"/jobs/1" {
+		t.Errorf("unexpected jobs: %+v", jobs)
+	}
+
+	if len(errs) != 1 || !strings.Contains(errs[0], "/jobs/404") {
+		t.Errorf("expected one fetch error, got %q", errs)
+	}
+
+	msg = &EmailMessage{MessageID: "fwd-1@example.com", Subject: "Backend Engineer", Text: "We are hiring a backend engineer."}
+
+	jobs, _ = fetcher.JobsFromEmail(context.Background(), msg)
+	if len(jobs) != 1 || jobs[0].ExternalID != "email:fwd-1@example.com" || jobs[0].Job.Source != JobSourceEmail ||
+		jobs[0].Job.Title != "Backend Engineer" {
+		t.Errorf("unexpected jobs: %+v", jobs)
+	}
+
+	feed, err := fetcher.FetchFeed(context.Background(), server.URL+"/feed")
+	if err != nil {
+		t.Fatalf("FetchFeed() error = %v", err)
+	}
+
+	// The teaser of the first item is completed from the posting it links to
+	job, err := fetcher.JobFromFeedItem(context.Background(), server.URL+"/feed", feed.Items[0])
+	if err != nil {
+		t.Fatalf("JobFromFeedItem() error = %v", err)
+	}
+
+	if job.ExternalID != server.URL+"/jobs/2" || job.Job.Source != JobSourceJSONLD || job.Job.Title != "SRE" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	job, err = fetcher.JobFromFeedItem(context.Background(), server.URL+"/feed", feed.Items[1])
+	if err != nil {
+		t.Fatalf("JobFromFeedItem() error = %v", err)
+	}
+
+	if job.ExternalID != "feed:"+server.URL+"/feed#w-1" || job.Job.Source != JobSourceFeed || job.Job.Title != "Writer" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		in       string
+		start    time.Time
+		end      time.Time
+		current  bool
+		duration string
+	}{
+		{"Jan 2020 – Present", month(2020, time.January), time.Time{}, true, "Jan 2020 – Present"},
+		{"2019–21", month(2019, time.January), month(2021, time.December), false, "2019–21"},
+		{"03/2018 - 11/2020", month(2018, time.March), month(2020, time.November), false, "03/2018 - 11/2020"},
+		{"Acme Corp, Sept. 2015 to Mar 2017, Berlin", month(2015, time.September), month(2017, time.March), false, "Sept. 2015 to Mar 2017"},
+		{"seit März 2021", month(2021, time.March), time.Time{}, true, "seit März 2021"},
+		{"enero de 2018 - diciembre de 2020", month(2018, time.January), month(2020, time.December), false, "enero de 2018 - diciembre de 2020"},
+		{"février 2016 à août 2019", month(2016, time.February), month(2019, time.August), false, "février 2016 à août 2019"},
+		{"2018-03 – heute", month(2018, time.March), time.Time{}, true, "2018-03 – heute"},
+		{"2012", month(2012, time.January), month(2012, time.December), false, "2012"},
+	}
+
+	for _, tt := range tests {
+		r, duration, ok := FindDateRange(tt.in)
+		if !ok {
+			t.Errorf("FindDateRange(%q) found no range", tt.in)
+
+			continue
+		}
+
+		if !r.Start.Equal(tt.start) || !r.End.Equal(tt.end) || r.Current != tt.current {
+			t.Errorf("FindDateRange(%q) = %v - %v (current %t), want %v - %v (current %t)",
+				tt.in, r.Start, r.End, r.Current, tt.start, tt.end, tt.current)
+		}
+
+		if duration != tt.duration {
+			t.Errorf("FindDateRange(%q) text = %q, want %q", tt.in, duration, tt.duration)
+		}
+	}
+
+	for _, in := range []string{"", "Senior Engineer", "2021 - 2019"} {
+		if r, ok := ParseDateRange(in); ok {
+			t.Errorf("ParseDateRange(%q) = %+v, want no range", in, r)
+		}
+	}
+}
+
+func TestExperienceTenure(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	var experience []Experience
+
+	for _, e := range [][3]string{
+		{"Intern", "Python scripting", "2012"},
+		{"Staff Engineer", "Go and Kubernetes platform", "Jan 2020 - Present"},
+		{"Engineer", "Go microservices, C++ tooling", "03/2016 - 06/2020"},
+		{"Freelancer", "Consulting", "sometime"},
+	} {
+		period, _ := ParseDateRange(e[2])
+		experience = append(experience, Experience{Title: e[0], Description: e[1], Duration: e[2], Period: period})
+	}
+
+	// 2012, then March 2016 through June 2024 with the overlap counted once
+	if got, want := ExperienceMonths(experience, now), 12+100; got != want {
+		t.Errorf("ExperienceMonths = %d, want %d", got, want)
+	}
+
+	gaps := ExperienceGaps(experience, now, 6)
+	if len(gaps) != 1 || gaps[0].Months != 38 || !gaps[0].Start.Equal(time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ExperienceGaps = %+v, want one gap of 38 months from January 2013", gaps)
+	}
+
+	tenure := SkillTenure(experience, []string{"Go", "C++", "Kubernetes", "Rust"}, now)
+	if tenure["Go"] != 100 || tenure["C++"] != 52 || tenure["Kubernetes"] != 54 {
+		t.Errorf("SkillTenure = %v", tenure)
+	}
+
+	if _, ok := tenure["Rust"]; ok {
+		t.Error("Expected no tenure for an unmentioned skill")
+	}
+
+	SortExperience(experience)
+
+	var titles []string
+	for _, exp := range experience {
+		titles = append(titles, exp.Title)
+	}
+
+	if want := []string{"Staff Engineer", "Engineer", "Intern", "Freelancer"}; !slices.Equal(titles, want) {
+		t.Errorf("SortExperience order = %v, want %v", titles, want)
+	}
+}
+
+func TestParseRequiredYears(t *testing.T) {
+	tests := map[string]int{
+		"5+ years of experience with Go":                     5,
+		"3-5 years in backend development, 2 yrs of Rust":    3,
+		"Mindestens 4 Jahre Berufserfahrung":                 4,
+		"Au moins 7 ans d'expérience":                        7,
+		"Experiencia de 3 a 5 años":                          3,
+		"A strong engineer, founded in 2015 with 200 people": 0,
+	}
+
+	for in, want := range tests {
+		if got := ParseRequiredYears(in); got != want {
+			t.Errorf("ParseRequiredYears(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Job extraction methods, reported in StructuredJobDescription.Source.
+const (
+	JobSourceJSONLD      = "json-ld"
+	JobSourceMicrodata   = "microdata"
+	JobSourceReadability = "readability"
+)
+
+// jobData is a job posting read from structured data, an ATS API or the
+// main content of a page.
+type jobData struct {
+	source           string
+	title            string
+	company          string
+	location         string
+	salary           string
+	employmentType   string
+	description      string // Plain text
+	requirements     []string
+	responsibilities []string
+}
+
+// extractJobFromPage reads a job posting from a page: schema.org JobPosting
+// data (JSON-LD, then microdata) when the page has it, otherwise the main
+// content of the page. Structured data without a description borrows the
+// main content.
+func extractJobFromPage(page []byte) *jobData {
+	doc, err := html.Parse(strings.NewReader(string(page)))
+	if err != nil {
+		return nil
+	}
+
+	job := jsonLDPosting(doc)
+	if job == nil {
+		job = microdataPosting(doc)
+	}
+
+	title, site := pageTitle(doc)
+	text := htmlText(mainContent(doc))
+
+	if job == nil {
+		if text == "" {
+			return nil
+		}
+
+		job = &jobData{source: JobSourceReadability, title: title, company: site}
+	}
+
+	if job.description == "" {
+		job.description = text
+	}
+
+	return job
+}
+
+// pageTitle returns the title and site name a page announces, preferring
+// the Open Graph tags over the first heading and the title element.
+func pageTitle(doc *html.Node) (title, site string) {
+	var visit func(n *html.Node)
+
+	var h1, titleTag string
+
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Meta:
+				switch htmlAttr(n, "property") {
+				case "og:title":
+					title = strings.TrimSpace(htmlAttr(n, "content"))
+				case "og:site_name":
+					site = strings.TrimSpace(htmlAttr(n, "content"))
+				}
+			case atom.Title:
+				if titleTag == "" {
+					titleTag = htmlText(n)
+				}
+			case atom.H1:
+				if h1 == "" {
+					h1 = htmlText(n)
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	for _, candidate := range []string{title, h1, titleTag} {
+		if candidate != "" {
+			return candidate, site
+		}
+	}
+
+	return "", site
+}
+
+// jsonLDPosting reads the first schema.org JobPosting of a page's JSON-LD
+// scripts.
+func jsonLDPosting(doc *html.Node) *jobData {
+	var found map[string]any
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		if found != nil {
+			return
+		}
+
+		if n.Type == html.ElementNode && n.DataAtom == atom.Script &&
+			strings.Contains(strings.ToLower(htmlAttr(n, "type")), "ld+json") && n.FirstChild != nil {
+			// Raw line breaks inside strings are common and invalid JSON
+			data := strings.NewReplacer("\n", " ", "\r", " ", "\t", " ").Replace(n.FirstChild.Data)
+
+			var v any
+			if json.Unmarshal([]byte(data), &v) == nil {
+				found = findJobPosting(v, 0)
+			}
+
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	if found == nil {
+		return nil
+	}
+
+	return postingData(found, JobSourceJSONLD)
+}
+
+// findJobPosting searches JSON-LD, including @graph lists, for a JobPosting.
+func findJobPosting(v any, depth int) map[string]any {
+	if depth > 8 {
+		return nil
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if isJobPostingType(v["@type"]) {
+			return v
+		}
+
+		for _, key := range []string{"@graph", "mainEntity", "itemListElement", "item"} {
+			if found := findJobPosting(v[key], depth+1); found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if found := findJobPosting(item, depth+1); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+// isJobPostingType reports whether a @type or itemtype names JobPosting.
+func isJobPostingType(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == "JobPosting" || strings.HasSuffix(v, "schema.org/JobPosting")
+	case []any:
+		return slices.ContainsFunc(v, isJobPostingType)
+	}
+
+	return false
+}
+
+// microdataPosting reads the first schema.org JobPosting item of a page.
+func microdataPosting(doc *html.Node) *jobData {
+	var found *html.Node
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		if found != nil {
+			return
+		}
+
+		if n.Type == html.ElementNode && hasAttr(n, "itemscope") {
+			for _, t := range strings.Fields(htmlAttr(n, "itemtype")) {
+				if isJobPostingType(t) {
+					found = n
+
+					return
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+
+	if found == nil {
+		return nil
+	}
+
+	return postingData(microdataItem(found), JobSourceMicrodata)
+}
+
+// hasAttr reports whether an element has an attribute.
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// microdataItem reads the properties of a microdata item into the shape of
+// JSON-LD, with nested items as maps. The first value of each property wins.
+func microdataItem(item *html.Node) map[string]any {
+	props := map[string]any{}
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			names := strings.Fields(htmlAttr(c, "itemprop"))
+			nested := hasAttr(c, "itemscope")
+
+			if len(names) > 0 {
+				var value any
+				if nested {
+					value = microdataItem(c)
+				} else {
+					value = microdataValue(c)
+				}
+
+				for _, name := range names {
+					if _, ok := props[name]; !ok {
+						props[name] = value
+					}
+				}
+			}
+
+			if !nested {
+				visit(c)
+			}
+		}
+	}
+	visit(item)
+
+	return props
+}
+
+// microdataValue returns the value of a microdata property element.
+func microdataValue(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Meta:
+		return htmlAttr(n, "content")
+	case atom.A, atom.Link, atom.Area:
+		return htmlAttr(n, "href")
+	case atom.Img, atom.Audio, atom.Video, atom.Source:
+		return htmlAttr(n, "src")
+	case atom.Time:
+		if v := htmlAttr(n, "datetime"); v != "" {
+			return v
+		}
+	case atom.Data, atom.Meter:
+		return htmlAttr(n, "value")
+	}
+
+	if v := htmlAttr(n, "content"); v != "" {
+		return v
+	}
+
+	return htmlText(n)
+}
+
+// postingData maps schema.org JobPosting properties onto a job.
+func postingData(p map[string]any, source string) *jobData {
+	job := &jobData{
+		source:           source,
+		title:            ldText(p["title"]),
+		company:          ldText(p["hiringOrganization"]),
+		location:         ldLocation(p),
+		salary:           ldSalary(p["baseSalary"]),
+		employmentType:   humanizeEnum(ldText(p["employmentType"])),
+		description:      ldDescription(p["description"]),
+		responsibilities: ldLines(p["responsibilities"]),
+	}
+
+	if job.title == "" {
+		job.title = ldText(p["name"])
+	}
+
+	if job.salary == "" {
+		job.salary = ldSalary(p["estimatedSalary"])
+	}
+
+	for _, key := range []string{"qualifications", "skills", "experienceRequirements", "educationRequirements"} {
+		job.requirements = append(job.requirements, ldLines(p[key])...)
+	}
+
+	return job
+}
+
+// ldText returns the text of a JSON-LD value: strings as they are, the
+// name of things and lists joined by commas.
+func ldText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		for _, key := range []string{"name", "@value", "description", "value"} {
+			if s := ldText(v[key]); s != "" {
+				return s
+			}
+		}
+	case []any:
+		var parts []string
+
+		for _, item := range v {
+			if s := ldText(item); s != "" && !slices.Contains(parts, s) {
+				parts = append(parts, s)
+			}
+		}
+
+		return strings.Join(parts, ", ")
+	}
+
+	return ""
+}
+
+// ldLines returns a JSON-LD text or list as separate lines, such as the
+// items of a qualifications list.
+func ldLines(v any) []string {
+	var lines []string
+
+	if list, ok := v.([]any); ok {
+		for _, item := range list {
+			lines = append(lines, textLines(ldDescription(item))...)
+		}
+
+		return lines
+	}
+
+	return textLines(ldDescription(v))
+}
+
+// ldDescription returns a text property as plain text. Descriptions are
+// HTML in most postings, and plain text with line breaks in the rest.
+func ldDescription(v any) string {
+	s := ldText(v)
+	if strings.Contains(s, "<") {
+		return htmlFragmentText(s)
+	}
+
+	return s
+}
+
+// ldLocation returns the job locations of a posting, with remote work.
+func ldLocation(p map[string]any) string {
+	places, ok := p["jobLocation"].([]any)
+	if !ok {
+		places = []any{p["jobLocation"]}
+	}
+
+	var locations []string
+
+	for _, place := range places {
+		var parts []string
+
+		switch address := place.(type) {
+		case map[string]any:
+			addr := address["address"]
+			if m, ok := addr.(map[string]any); ok {
+				for _, key := range []string{"addressLocality", "addressRegion", "addressCountry"} {
+					if s := ldText(m[key]); s != "" && !slices.Contains(parts, s) {
+						parts = append(parts, s)
+					}
+				}
+			} else if s := ldText(addr); s != "" {
+				parts = append(parts, s)
+			} else if s := ldText(address["name"]); s != "" {
+				parts = append(parts, s)
+			}
+		case string:
+			parts = append(parts, strings.TrimSpace(address))
+		}
+
+		if loc := strings.Join(parts, ", "); loc != "" && !slices.Contains(locations, loc) {
+			locations = append(locations, loc)
+		}
+	}
+
+	location := strings.Join(locations, "; ")
+
+	if strings.EqualFold(ldText(p["jobLocationType"]), "TELECOMMUTE") {
+		remote := "Remote"
+		if req := ldText(p["applicantLocationRequirements"]); req != "" {
+			remote += " (" + req + ")"
+		}
+
+		if location == "" {
+			return remote
+		}
+
+		return location + " or " + remote
+	}
+
+	return location
+}
+
+// ldSalary formats a MonetaryAmount, e.g. "USD 120,000–150,000 per year".
+func ldSalary(v any) string {
+	switch v := v.(type) {
+	case []any:
+		if len(v) > 0 {
+			return ldSalary(v[0])
+		}
+
+		return ""
+	case map[string]any:
+		currency := ldText(v["currency"])
+		unit := ldText(v["unitText"])
+		value := v["value"]
+
+		if m, ok := value.(map[string]any); ok {
+			if u := ldText(m["unitText"]); u != "" {
+				unit = u
+			}
+
+			if c := ldText(m["currency"]); c != "" && currency == "" {
+				currency = c
+			}
+
+			if minValue, ok := ldNumber(m["minValue"]); ok {
+				maxValue, hasMax := ldNumber(m["maxValue"])
+				if !hasMax || maxValue == minValue {
+					return formatSalary(currency, unit, minValue)
+				}
+
+				return formatSalary(currency, unit, minValue, maxValue)
+			}
+
+			value = m["value"]
+		}
+
+		if n, ok := ldNumber(value); ok {
+			return formatSalary(currency, unit, n)
+		}
+	}
+
+	return ""
+}
+
+// ldNumber reads a number that may be written as a string.
+func ldNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+// formatSalary formats an amount or range with its currency and period.
+func formatSalary(currency, unit string, amounts ...float64) string {
+	parts := make([]string, len(amounts))
+	for i, a := range amounts {
+		parts[i] = formatAmount(a)
+	}
+
+	s := strings.Join(parts, "–")
+	if currency != "" {
+		s = strings.ToUpper(currency) + " " + s
+	}
+
+	switch strings.ToUpper(unit) {
+	case "HOUR":
+		s += " per hour"
+	case "DAY":
+		s += " per day"
+	case "WEEK":
+		s += " per week"
+	case "MONTH":
+		s += " per month"
+	case "YEAR":
+		s += " per year"
+	}
+
+	return s
+}
+
+// formatAmount writes a number with thousands separators.
+func formatAmount(a float64) string {
+	digits := strconv.FormatFloat(math.Round(math.Abs(a)), 'f', 0, 64)
+
+	var b strings.Builder
+
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteRune(d)
+	}
+
+	if a < 0 {
+		return "-" + b.String()
+	}
+
+	return b.String()
+}
+
+// humanizeEnum turns schema.org enumerations such as "FULL_TIME" into
+// "Full time".
+func humanizeEnum(s string) string {
+	if s == "" || strings.ToUpper(s) != s {
+		return s
+	}
+
+	s = strings.ToLower(strings.ReplaceAll(s, "_", " "))
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// structuredJob turns an extracted posting into a StructuredJobDescription.
+// Its raw text leads with the posting's facts so the LLM sees them; fields
+// read from structured data win over those parsed from the text.
+func structuredJob(job *jobData) *StructuredJobDescription {
+	var b strings.Builder
+
+	if job.title != "" {
+		b.WriteString(job.title + "\n")
+	}
+
+	for _, fact := range [][2]string{
+		{"Company", job.company},
+		{"Location", job.location},
+		{"Salary", job.salary},
+		{"Employment type", job.employmentType},
+	} {
+		if fact[1] != "" {
+			fmt.Fprintf(&b, "%s: %s\n", fact[0], fact[1])
+		}
+	}
+
+	b.WriteString("\n" + job.description)
+
+	for _, list := range []struct {
+		heading string
+		items   []string
+	}{{"Responsibilities", job.responsibilities}, {"Requirements", job.requirements}} {
+		var missing []string
+
+		for _, item := range list.items {
+			if !strings.Contains(job.description, item) {
+				missing = append(missing, item)
+			}
+		}
+
+		if len(missing) > 0 {
+			b.WriteString("\n\n" + list.heading + ":\n• " + strings.Join(missing, "\n• "))
+		}
+	}
+
+	out := NewEnhancedParser().ParseJobDescription(strings.TrimSpace(b.String()))
+	out.Source = job.source
+	out.Salary = job.salary
+	out.EmploymentType = job.employmentType
+
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{{&out.Title, job.title}, {&out.Company, job.company}, {&out.Location, job.location}} {
+		if field.src != "" {
+			*field.dst = field.src
+		}
+	}
+
+	for _, item := range job.requirements {
+		if !slices.Contains(out.Requirements, item) {
+			out.Requirements = append(out.Requirements, item)
+		}
+	}
+
+	for _, item := range job.responsibilities {
+		if !slices.Contains(out.Responsibilities, item) {
+			out.Responsibilities = append(out.Responsibilities, item)
+		}
+	}
+
+	return out
+}
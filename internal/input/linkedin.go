@@ -6,34 +6,62 @@ package input
 import (
 	"errors"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
 )
 
 // LinkedInProfile represents a parsed LinkedIn profile.
 type LinkedInProfile struct {
-	Name       string
-	Title      string
-	Summary    string
-	Experience []Experience
-	Skills     []string
-	Education  []Education
-	RawProfile string
+	Name           string
+	Title          string
+	Summary        string
+	Location       string
+	Industry       string
+	Websites       []string
+	Experience     []Experience
+	Skills         []string
+	Education      []Education
+	Certifications []Certification
+	RawProfile     string
 }
 
-// Experience represents a work experience entry.
+// Experience represents a work experience entry. StartDate and EndDate are
+// only known for data exports; an empty EndDate is a current position.
+// Period is the normalized Duration, nil when it has no recognizable dates.
 type Experience struct {
 	Title       string
 	Company     string
+	Location    string
 	Duration    string
+	StartDate   string
+	EndDate     string
 	Description string
+	Period      *DateRange
 }
 
 // Education represents an education entry.
 type Education struct {
-	School   string
-	Degree   string
-	Field    string
-	Duration string
+	School     string
+	Degree     string
+	Field      string
+	Duration   string
+	StartDate  string
+	EndDate    string
+	Notes      string
+	Activities string
+	Period     *DateRange
+}
+
+// Certification represents a license or certification.
+type Certification struct {
+	Name          string
+	Authority     string
+	LicenseNumber string
+	URL           string
+	StartDate     string
+	EndDate       string
 }
 
 // LinkedInParser handles LinkedIn profile parsing.
@@ -76,6 +104,8 @@ func (lp *LinkedInParser) ParseProfile(content string) (*LinkedInProfile, error)
 	// Extract education
 	profile.Education = extractEducation(content)
 
+	normalizePeriods(profile.Experience, profile.Education)
+
 	return profile, nil
 }
 
@@ -91,6 +121,18 @@ func (p *LinkedInProfile) ToText() string {
 		sb.WriteString("Current Title: " + p.Title + "\n")
 	}
 
+	if p.Location != "" {
+		sb.WriteString("Location: " + p.Location + "\n")
+	}
+
+	if p.Industry != "" {
+		sb.WriteString("Industry: " + p.Industry + "\n")
+	}
+
+	for _, site := range p.Websites {
+		sb.WriteString("Website: " + site + "\n")
+	}
+
 	if p.Summary != "" {
 		sb.WriteString("\nSummary:\n" + p.Summary + "\n")
 	}
@@ -109,12 +151,16 @@ func (p *LinkedInProfile) ToText() string {
 				sb.WriteString("\n")
 			}
 
-			if exp.Duration != "" {
+			if exp.Duration != "" && exp.Location != "" {
+				sb.WriteString("  " + exp.Duration + ", " + exp.Location + "\n")
+			} else if exp.Duration != "" {
 				sb.WriteString("  " + exp.Duration + "\n")
+			} else if exp.Location != "" {
+				sb.WriteString("  " + exp.Location + "\n")
 			}
 
 			if exp.Description != "" {
-				sb.WriteString("  " + exp.Description + "\n")
+				sb.WriteString("  " + strings.ReplaceAll(exp.Description, "\n", "\n  ") + "\n")
 			}
 		}
 	}
@@ -144,24 +190,61 @@ func (p *LinkedInProfile) ToText() string {
 			if edu.Duration != "" {
 				sb.WriteString("  " + edu.Duration + "\n")
 			}
+
+			if edu.Activities != "" {
+				sb.WriteString("  Activities: " + edu.Activities + "\n")
+			}
+
+			if edu.Notes != "" {
+				sb.WriteString("  " + edu.Notes + "\n")
+			}
+		}
+	}
+
+	if len(p.Certifications) > 0 {
+		sb.WriteString("\nCertifications:\n")
+
+		for _, cert := range p.Certifications {
+			sb.WriteString("- " + cert.Name)
+
+			if cert.Authority != "" {
+				sb.WriteString(", " + cert.Authority)
+			}
+
+			sb.WriteString("\n")
+
+			if cert.StartDate != "" {
+				sb.WriteString("  " + dateRange(cert.StartDate, cert.EndDate, "") + "\n")
+			}
+
+			if cert.LicenseNumber != "" {
+				sb.WriteString("  License: " + cert.LicenseNumber + "\n")
+			}
+
+			if cert.URL != "" {
+				sb.WriteString("  " + cert.URL + "\n")
+			}
 		}
 	}
 
 	return sb.String()
 }
 
-// extractName extracts the profile name.
-func extractName(content string) string {
-	// Look for common LinkedIn name patterns
-	patterns := []string{
-		`(?i)(?:name|profile)[\s:]*([A-Z][a-z]+\s+[A-Z][a-z]+)`,
-		`^\s*([A-Z][a-z]+\s+[A-Z][a-z]+)`,
-		`(?m)^\s*([A-Z][a-z]+\s+[A-Z][a-z]+)\s*$`,
-	}
+// personName matches a personal name in any script with Latin-style casing,
+// such as "José María García" or "Anna van der Berg": capitalized words,
+// possibly hyphenated, and the lower-case particles of surnames.
+const personName = `\p{Lu}\p{Ll}+(?:-\p{Lu}\p{Ll}+)?(?:[ \t]+(?:(?:van|von|der|den|de|del|della|di|da|do|dos|das|du|le|la|y|zu|ten|ter)[ \t]+){0,2}\p{Lu}[\p{Ll}'’]+(?:-\p{Lu}\p{Ll}+)?){1,3}`
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
+// namePatterns match the profile name, after a label or on a line of its own.
+var namePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i:name|nom|nombre|nome|naam|profile)[\s:]*(` + personName + `)`),
+	regexp.MustCompile(`^\s*(` + personName + `)`),
+	regexp.MustCompile(`(?m)^\s*(` + personName + `)\s*$`),
+}
 
+// extractName extracts the profile name.
+func extractName(content string) string {
+	for _, re := range namePatterns {
 		matches := re.FindStringSubmatch(content)
 		if len(matches) > 1 {
 			return strings.TrimSpace(matches[1])
@@ -209,6 +292,8 @@ func extractSummary(content string) string {
 	patterns := []string{
 		`(?i)(?:summary|about)[\s:]*([^\n]+(?:\n[^\n]+){0,10})`,
 		`(?i)(?:about me)[\s:]*([^\n]+(?:\n[^\n]+){0,10})`,
+		// Headings of other languages, such as "Zusammenfassung" or "Perfil"
+		`(?im)^\s*(?:zusammenfassung|kurzprofil|profil|über mich|à propos|resumen|perfil|sobre mí|resumo|sobre mim|samenvatting|profiel|over mij)(?:[ \t:–-]*\n|[ \t:–-]+)([^\n]+(?:\n[^\n]+){0,10})`,
 	}
 
 	for _, pattern := range patterns {
@@ -241,7 +326,7 @@ func extractExperience(content string) []Experience {
 		line := strings.TrimSpace(lines[i])
 
 		// Check for experience section start
-		if strings.Contains(strings.ToLower(line), "experience") {
+		if strings.Contains(strings.ToLower(line), "experience") || isHeading(line, i18n.SectionExperience) {
 			inExpSection = true
 
 			continue
@@ -299,7 +384,7 @@ func extractSkills(content string) []string {
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		if strings.Contains(strings.ToLower(trimmed), "skills") {
+		if strings.Contains(strings.ToLower(trimmed), "skills") || isHeading(trimmed, i18n.SectionSkills) {
 			inSkillsSection = true
 
 			continue
@@ -309,7 +394,8 @@ func extractSkills(content string) []string {
 			// Stop at next section
 			if strings.Contains(strings.ToLower(trimmed), "experience") ||
 				strings.Contains(strings.ToLower(trimmed), "education") ||
-				strings.Contains(strings.ToLower(trimmed), "summary") {
+				strings.Contains(strings.ToLower(trimmed), "summary") ||
+				isHeading(trimmed, i18n.SectionExperience, i18n.SectionEducation, i18n.SectionSummary) {
 				break
 			}
 
@@ -341,7 +427,7 @@ func extractEducation(content string) []Education {
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		if strings.Contains(strings.ToLower(trimmed), "education") {
+		if strings.Contains(strings.ToLower(trimmed), "education") || isHeading(trimmed, i18n.SectionEducation) {
 			inEduSection = true
 
 			continue
@@ -353,7 +439,8 @@ func extractEducation(content string) []Education {
 
 		// Stop at next section
 		if (strings.Contains(strings.ToLower(trimmed), "skills") ||
-			strings.Contains(strings.ToLower(trimmed), "experience")) &&
+			strings.Contains(strings.ToLower(trimmed), "experience") ||
+			isHeading(trimmed, i18n.SectionSkills, i18n.SectionExperience)) &&
 			currentEdu.School != "" {
 			educations = append(educations, currentEdu)
 
@@ -397,13 +484,26 @@ func extractEducation(content string) []Education {
 
 // Helper functions to identify content types
 
+// isHeading reports whether a line is the heading of one of the given
+// sections, in any of the supported languages.
+func isHeading(line string, sections ...i18n.Section) bool {
+	section := i18n.HeadingLine(line)
+
+	return section != i18n.SectionUnknown && slices.Contains(sections, section)
+}
+
 func isLikelyJobTitle(s string) bool {
-	// Common job title keywords
+	// Common job title keywords, in English and the other supported languages
 	keywords := []string{
 		"engineer", "developer", "manager", "architect", "analyst",
 		"consultant", "designer", "scientist", "director", "senior",
 		"junior", "lead", "associate", "specialist", "coordinator",
 		"officer", "executive", "administrator", "technician",
+		"entwickler", "ingenieur", "berater", "leiter", "referent", "sachbearbeiter",
+		"développeur", "ingénieur", "chef de projet", "responsable", "chargé",
+		"desarrollador", "ingeniero", "jefe", "gerente", "analista", "consultor",
+		"desenvolvedor", "engenheiro", "gestor", "coordenador",
+		"ontwikkelaar", "medewerker", "adviseur", "beheerder",
 	}
 
 	lower := strings.ToLower(s)
@@ -422,8 +522,11 @@ func isLikelyCompanyName(s string) bool {
 }
 
 func isLikelyDuration(s string) bool {
+	if _, ok := ParseDateRange(s); ok {
+		return true
+	}
+
 	patterns := []string{
-		"20[0-9]{2}", // Years
 		"jan", "feb", "mar", "apr", "may", "jun",
 		"jul", "aug", "sep", "oct", "nov", "dec",
 		"present", "current", "-",
@@ -448,6 +551,11 @@ func isLikelySchoolName(s string) bool {
 	keywords := []string{
 		"university", "college", "institute", "school", "academy",
 		"polytechnic", "technical", "state", "central",
+		"universität", "hochschule", "fachhochschule", "gymnasium",
+		"université", "école", "lycée",
+		"universidad", "instituto", "escuela", "colegio",
+		"universidade", "faculdade", "escola",
+		"universiteit", "hogeschool",
 	}
 
 	lower := strings.ToLower(s)
@@ -465,6 +573,8 @@ func isLikelyDegree(s string) bool {
 		"bachelor", "master", "phd", "diploma", "certificate",
 		"associate", "b.a", "b.s", "m.a", "m.s", "m.b.a",
 		"b.tech", "m.tech",
+		"diplom", "promotion", "abitur", "licence", "doctorat", "licenciatura",
+		"grado", "doctorado", "graduação", "mestrado", "doutorado", "doctoraal",
 	}
 
 	lower := strings.ToLower(s)
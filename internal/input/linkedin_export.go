@@ -0,0 +1,245 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxExportFileSize bounds the decompressed size of each CSV file read from
+// a LinkedIn data export.
+const maxExportFileSize = 10 * 1024 * 1024
+
+// ErrNotLinkedInExport is returned for archives without any of the files of
+// a LinkedIn data export.
+var ErrNotLinkedInExport = errors.New("not a LinkedIn data export: no Profile.csv, Positions.csv, Education.csv, Skills.csv or Certifications.csv")
+
+// exportTable is a CSV file of a LinkedIn data export, its rows keyed by
+// lower-case column name.
+type exportTable []map[string]string
+
+// ParseExport reads the archive of LinkedIn's "Download your data" export.
+// Profile.csv, Positions.csv, Education.csv, Skills.csv and
+// Certifications.csv are read; any of them may be missing, as in partial
+// exports.
+func (lp *LinkedInParser) ParseExport(data []byte) (*LinkedInProfile, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %w", err)
+	}
+
+	tables := map[string]exportTable{}
+
+	for _, f := range archive.File {
+		name := strings.ToLower(path.Base(f.Name))
+
+		switch name {
+		case "profile.csv", "positions.csv", "education.csv", "skills.csv", "certifications.csv":
+		default:
+			continue
+		}
+
+		table, err := readExportTable(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Base(f.Name), err)
+		}
+
+		tables[name] = table
+	}
+
+	if len(tables) == 0 {
+		return nil, ErrNotLinkedInExport
+	}
+
+	profile := &LinkedInProfile{
+		Experience:     []Experience{},
+		Skills:         []string{},
+		Education:      []Education{},
+		Certifications: []Certification{},
+	}
+
+	if rows := tables["profile.csv"]; len(rows) > 0 {
+		row := rows[0]
+		profile.Name = strings.TrimSpace(row["first name"] + " " + row["last name"])
+		profile.Title = row["headline"]
+		profile.Summary = row["summary"]
+		profile.Location = row["geo location"]
+		profile.Industry = row["industry"]
+		profile.Websites = exportWebsites(row["websites"])
+	}
+
+	for _, row := range tables["positions.csv"] {
+		profile.Experience = append(profile.Experience, Experience{
+			Title:       row["title"],
+			Company:     row["company name"],
+			Location:    row["location"],
+			Duration:    dateRange(row["started on"], row["finished on"], "Present"),
+			StartDate:   row["started on"],
+			EndDate:     row["finished on"],
+			Description: row["description"],
+		})
+	}
+
+	for _, row := range tables["education.csv"] {
+		profile.Education = append(profile.Education, Education{
+			School:     row["school name"],
+			Degree:     row["degree name"],
+			Duration:   dateRange(row["start date"], row["end date"], ""),
+			StartDate:  row["start date"],
+			EndDate:    row["end date"],
+			Notes:      row["notes"],
+			Activities: row["activities"],
+		})
+	}
+
+	for _, row := range tables["skills.csv"] {
+		if row["name"] != "" {
+			profile.Skills = append(profile.Skills, row["name"])
+		}
+	}
+
+	for _, row := range tables["certifications.csv"] {
+		profile.Certifications = append(profile.Certifications, Certification{
+			Name:          row["name"],
+			Authority:     row["authority"],
+			LicenseNumber: row["license number"],
+			URL:           row["url"],
+			StartDate:     row["started on"],
+			EndDate:       row["finished on"],
+		})
+	}
+
+	normalizePeriods(profile.Experience, profile.Education)
+
+	return profile, nil
+}
+
+// readExportTable reads a CSV file of an export. Some exports start with a
+// few lines of notes before the header row, which are skipped.
+func readExportTable(f *zip.File) (exportTable, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxExportFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxExportFileSize {
+		return nil, fmt.Errorf("file too large (max: %d bytes)", maxExportFileSize)
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	// The header is the first row naming a column of a known table
+	header := -1
+
+	for i, record := range records {
+		for _, field := range record {
+			switch strings.ToLower(strings.TrimSpace(field)) {
+			case "first name", "company name", "school name", "name":
+				header = i
+			}
+		}
+
+		if header >= 0 {
+			break
+		}
+	}
+
+	if header < 0 {
+		return exportTable{}, nil
+	}
+
+	columns := make([]string, len(records[header]))
+	for i, field := range records[header] {
+		columns[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+
+	table := exportTable{}
+
+	for _, record := range records[header+1:] {
+		row := map[string]string{}
+		empty := true
+
+		for i, field := range record {
+			if i >= len(columns) {
+				break
+			}
+
+			field = strings.TrimSpace(strings.ReplaceAll(field, "\r\n", "\n"))
+			if field != "" {
+				empty = false
+			}
+
+			row[columns[i]] = field
+		}
+
+		if !empty {
+			table = append(table, row)
+		}
+	}
+
+	return table, nil
+}
+
+// exportWebsites reads the Websites column of Profile.csv, a bracketed list
+// such as "[PORTFOLIO:https://example.com,BLOG:https://blog.example.com]".
+func exportWebsites(s string) []string {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]"))
+	if s == "" {
+		return nil
+	}
+
+	var sites []string
+
+	for _, site := range strings.Split(s, ",") {
+		site = strings.TrimSpace(site)
+
+		// Drop the site type prefix, keeping the URL scheme
+		if kind, rest, ok := strings.Cut(site, ":"); ok && !strings.HasPrefix(rest, "//") && !strings.Contains(kind, ".") {
+			site = strings.TrimSpace(rest)
+		}
+
+		if site != "" {
+			sites = append(sites, site)
+		}
+	}
+
+	return sites
+}
+
+// dateRange formats a start and end date. An open-ended range ends with
+// ongoing, or shows the start date alone when ongoing is empty.
+func dateRange(start, end, ongoing string) string {
+	switch {
+	case start == "" && end == "":
+		return ""
+	case start == "":
+		return end
+	case end != "":
+		return start + " - " + end
+	case ongoing != "":
+		return start + " - " + ongoing
+	default:
+		return start
+	}
+}
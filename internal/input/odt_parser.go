@@ -0,0 +1,454 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ODTParser handles OpenDocument text (.odt) file parsing.
+type ODTParser struct {
+	maxFileSize int64
+}
+
+// NewODTParser creates a new ODT parser.
+func NewODTParser(maxFileSize int64) *ODTParser {
+	if maxFileSize == 0 {
+		maxFileSize = 50 * 1024 * 1024 // 50MB default
+	}
+
+	return &ODTParser{
+		maxFileSize: maxFileSize,
+	}
+}
+
+// ParseFile extracts text from an ODT file.
+func (p *ODTParser) ParseFile(filePath string) (string, error) {
+	content, err := readLimitedFile(filePath, p.maxFileSize)
+	if err != nil {
+		return "", err
+	}
+
+	return p.ParseBytes(content)
+}
+
+// ParseBytes extracts text from ODT bytes.
+func (p *ODTParser) ParseBytes(data []byte) (string, error) {
+	doc, err := p.ParseDocument(data)
+	if err != nil {
+		return "", err
+	}
+
+	return doc.Text(), nil
+}
+
+// ParseDocument reads the structure of an ODT file: headings, lists, tables,
+// frames, hyperlinks, and the headers and footers of the page styles.
+func (p *ODTParser) ParseDocument(data []byte) (*Document, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty ODT data")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid ODT file: %w", err)
+	}
+
+	pkg := &docxPackage{files: map[string]*zip.File{}, maxSize: p.maxFileSize}
+	for _, f := range reader.File {
+		pkg.files[f.Name] = f
+	}
+
+	content, err := pkg.read("content.xml")
+	if err != nil {
+		return nil, errors.New("content.xml not found in ODT file")
+	}
+
+	doc := &Document{}
+	r := &odtReader{doc: doc, styles: map[string]*odtStyle{}}
+
+	styles, stylesErr := pkg.read("styles.xml")
+	if stylesErr == nil {
+		r.readStyles(styles)
+	}
+
+	r.readStyles(content)
+
+	if err := r.read(content, &doc.Blocks); err != nil && len(doc.Blocks) == 0 {
+		return nil, fmt.Errorf("failed to parse content.xml: %w", err)
+	}
+
+	if stylesErr == nil {
+		r.readHeaderFooter(styles)
+	}
+
+	if len(doc.Blocks) == 0 && len(doc.Header) == 0 && len(doc.Footer) == 0 {
+		return nil, errors.New("no text content found in ODT")
+	}
+
+	return doc, nil
+}
+
+// odtStyle is a paragraph or text style.
+type odtStyle struct {
+	parent  string
+	bold    int // 1 bold, -1 not bold, 0 inherited
+	heading int
+}
+
+// odtReader reads the OpenDocument XML of one document.
+type odtReader struct {
+	doc    *Document
+	styles map[string]*odtStyle
+}
+
+// readStyles reads the named and automatic styles of a part.
+func (r *odtReader) readStyles(content []byte) {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+
+	var current *odtStyle
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "style":
+				if t.Name.Space != odtStyleNS {
+					continue
+				}
+
+				name := attrNS(t, odtStyleNS, "name")
+				current = &odtStyle{parent: attrNS(t, odtStyleNS, "parent-style-name")}
+				r.styles[name] = current
+
+				if level := attrNS(t, odtStyleNS, "default-outline-level"); level != "" {
+					current.heading, _ = strconv.Atoi(level)
+				} else if m := docxHeadingStyle.FindStringSubmatch(strings.ReplaceAll(name, "_20_", " ")); m != nil {
+					current.heading, _ = strconv.Atoi(m[1])
+				}
+			case "text-properties":
+				if current != nil {
+					switch attrNS(t, odtFoNS, "font-weight") {
+					case "":
+					case "bold", "600", "700", "800", "900":
+						current.bold = 1
+					default:
+						current.bold = -1
+					}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "style" && t.Name.Space == odtStyleNS {
+				current = nil
+			}
+		}
+	}
+}
+
+// styleInfo resolves the heading level and boldness of a style, following
+// its parents. Boldness is 1 for bold, -1 for not bold and 0 when no style
+// sets it.
+func (r *odtReader) styleInfo(name string) (heading, bold int) {
+	for range 16 {
+		s, ok := r.styles[name]
+		if !ok {
+			break
+		}
+
+		if heading == 0 {
+			heading = s.heading
+		}
+
+		if bold == 0 {
+			bold = s.bold
+		}
+
+		name = s.parent
+	}
+
+	return heading, bold
+}
+
+// OpenDocument namespaces.
+const (
+	odtTextNS  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odtTableNS = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odtStyleNS = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	odtFoNS    = "urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"
+	odtXLinkNS = "http://www.w3.org/1999/xlink"
+)
+
+// attrNS returns the value of a namespaced attribute.
+func attrNS(start xml.StartElement, space, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// read reads the blocks of the document body.
+func (r *odtReader) read(content []byte, blocks *[]Block) error {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "body" {
+			r.readBlocks(dec, blocks, -1)
+
+			return nil
+		}
+	}
+}
+
+// readHeaderFooter reads the headers and footers of the master pages.
+func (r *odtReader) readHeaderFooter(styles []byte) {
+	dec := xml.NewDecoder(bytes.NewReader(styles))
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Space != odtStyleNS {
+			continue
+		}
+
+		var blocks []Block
+
+		switch start.Name.Local {
+		case "header", "header-left", "header-first":
+			r.readBlocks(dec, &blocks, -1)
+			r.doc.Header = addLines(r.doc.Header, (&Document{Blocks: blocks}).Text())
+		case "footer", "footer-left", "footer-first":
+			r.readBlocks(dec, &blocks, -1)
+			r.doc.Footer = addLines(r.doc.Footer, (&Document{Blocks: blocks}).Text())
+		}
+	}
+}
+
+// readBlocks reads block content up to the end of the enclosing element.
+// listLevel is the nesting level of the enclosing list, -1 outside lists.
+func (r *odtReader) readBlocks(dec *xml.Decoder, blocks *[]Block, listLevel int) {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == odtTextNS && (t.Name.Local == "p" || t.Name.Local == "h"):
+				r.readParagraph(dec, t, blocks, listLevel)
+			case t.Name.Space == odtTextNS && t.Name.Local == "list":
+				r.readBlocks(dec, blocks, listLevel+1)
+			case t.Name.Space == odtTableNS && t.Name.Local == "table":
+				r.readTable(dec, blocks)
+			case t.Name.Space == odtTextNS && isODTSkipped(t.Name.Local):
+				_ = dec.Skip()
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// isODTSkipped reports whether a text element holds no body text.
+func isODTSkipped(local string) bool {
+	switch local {
+	case "note", "tracked-changes", "sequence-decls", "variable-decls", "user-field-decls", "table-of-content-source":
+		return true
+	}
+
+	return false
+}
+
+// readTable reads a table, unpacking layout tables into their blocks.
+func (r *odtReader) readTable(dec *xml.Decoder, blocks *[]Block) {
+	var rows [][][]Block
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == odtTableNS && t.Name.Local == "table-row":
+				rows = append(rows, nil)
+				depth++
+			case t.Name.Space == odtTableNS && t.Name.Local == "table-cell":
+				if len(rows) == 0 {
+					rows = append(rows, nil)
+				}
+
+				var cell []Block
+
+				r.readBlocks(dec, &cell, -1)
+				rows[len(rows)-1] = append(rows[len(rows)-1], cell)
+			case t.Name.Space == odtTableNS && t.Name.Local == "covered-table-cell":
+				_ = dec.Skip()
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	*blocks = append(*blocks, tableBlocks(rows)...)
+}
+
+// readParagraph reads a text:p or text:h element. Paragraphs of frames
+// anchored in it are added first.
+func (r *odtReader) readParagraph(dec *xml.Decoder, start xml.StartElement, blocks *[]Block, listLevel int) {
+	heading, styleBold := r.styleInfo(attrNS(start, odtTextNS, "style-name"))
+
+	p := paragraph{heading: heading, list: listLevel >= 0, level: max(listLevel, 0), bold: true}
+
+	if start.Name.Local == "h" {
+		p.heading = 1
+		if level, err := strconv.Atoi(attrNS(start, odtTextNS, "outline-level")); err == nil && level > 0 {
+			p.heading = level
+		}
+	}
+
+	var (
+		text      strings.Builder
+		boldStack = []bool{styleBold > 0}
+		hasText   bool
+		linkURL   string
+		linkStart = -1
+	)
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odtTextNS {
+				// Frames and text boxes hold paragraphs; annotations are comments
+				if t.Name.Local == "annotation" {
+					_ = dec.Skip()
+				} else {
+					depth++
+				}
+
+				continue
+			}
+
+			switch t.Name.Local {
+			case "p", "h":
+				r.readParagraph(dec, t, blocks, -1)
+			case "list":
+				r.readBlocks(dec, blocks, 0)
+			case "s":
+				n, err := strconv.Atoi(attrNS(t, odtTextNS, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+
+				text.WriteString(strings.Repeat(" ", min(n, 100)))
+				_ = dec.Skip()
+			case "tab":
+				text.WriteByte('\t')
+				_ = dec.Skip()
+			case "line-break":
+				text.WriteByte('\n')
+				_ = dec.Skip()
+			case "span":
+				bold := boldStack[len(boldStack)-1]
+				if _, b := r.styleInfo(attrNS(t, odtTextNS, "style-name")); b != 0 {
+					bold = b > 0
+				}
+
+				boldStack = append(boldStack, bold)
+				depth++
+			case "a":
+				linkURL, linkStart = attrNS(t, odtXLinkNS, "href"), text.Len()
+				depth++
+			default:
+				if isODTSkipped(t.Name.Local) || t.Name.Local == "bookmark-ref" {
+					_ = dec.Skip()
+
+					continue
+				}
+
+				depth++
+			}
+		case xml.CharData:
+			// Line breaks and tabs in the markup are plain white space
+			text.WriteString(strings.Map(func(r rune) rune {
+				if r == '\n' || r == '\r' || r == '\t' {
+					return ' '
+				}
+
+				return r
+			}, string(t)))
+
+			if strings.TrimSpace(string(t)) != "" {
+				hasText = true
+
+				if !boldStack[len(boldStack)-1] {
+					p.bold = false
+				}
+			}
+		case xml.EndElement:
+			depth--
+
+			switch {
+			case t.Name.Space == odtTextNS && t.Name.Local == "span" && len(boldStack) > 1:
+				boldStack = boldStack[:len(boldStack)-1]
+			case t.Name.Space == odtTextNS && t.Name.Local == "a" && linkStart >= 0:
+				all := text.String()
+				linked := r.doc.addLink(all[linkStart:], linkURL)
+
+				text.Reset()
+				text.WriteString(all[:linkStart])
+				text.WriteString(linked)
+
+				linkURL, linkStart = "", -1
+			}
+		}
+	}
+
+	p.text = text.String()
+	p.bold = p.bold && hasText
+
+	if b, ok := p.block(); ok {
+		*blocks = append(*blocks, b)
+	}
+}
@@ -4,17 +4,27 @@
 package input
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"slices"
 	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/ocr"
+	"github.com/sammyoina/vibe-cv/internal/pdf"
+	"github.com/sammyoina/vibe-cv/internal/types"
 )
 
+// ocrDPI is the resolution scanned pages are rendered at for OCR.
+const ocrDPI = 300
+
 // PDFParser handles PDF file parsing.
 type PDFParser struct {
 	maxFileSize int64
+	ocr         ocr.Engine
 }
 
 // NewPDFParser creates a new PDF parser.
@@ -28,6 +38,12 @@ func NewPDFParser(maxFileSize int64) *PDFParser {
 	}
 }
 
+// SetOCR sets the engine that recognizes the text of scanned pages. Without
+// one, scanned pages yield no text.
+func (p *PDFParser) SetOCR(engine ocr.Engine) {
+	p.ocr = engine
+}
+
 // ParseFile extracts text from a PDF file.
 func (p *PDFParser) ParseFile(filePath string) (string, error) {
 	// Check file size
@@ -56,93 +72,144 @@ func (p *PDFParser) ParseFile(filePath string) (string, error) {
 	return p.ParseBytes(content)
 }
 
-// ParseBytes extracts text from PDF bytes
-// Note: For full PDF parsing, consider using:
-// - github.com/unidoc/unipdf
-// - github.com/pdfcpu/pdfcpu.
+// ParseBytes extracts text from PDF bytes. Text comes back in reading order,
+// one line per line of the page and with a blank line between paragraphs,
+// columns and pages, so section headings survive for the CV parsers.
 func (p *PDFParser) ParseBytes(data []byte) (string, error) {
+	text, _, err := p.Parse(context.Background(), data)
+
+	return text, err
+}
+
+// Parse extracts text like ParseBytes. Scanned pages are rendered and passed
+// to the OCR engine, if one is set; the report then describes the recognized
+// text, and is nil when no page needed OCR.
+func (p *PDFParser) Parse(ctx context.Context, data []byte) (string, *types.OCRReport, error) {
 	if len(data) == 0 {
-		return "", errors.New("empty PDF data")
+		return "", nil, errors.New("empty PDF data")
 	}
 
-	// Check PDF signature
-	if !bytes.HasPrefix(data, []byte("%PDF")) {
-		return "", errors.New("not a valid PDF file")
+	if int64(len(data)) > p.maxFileSize {
+		return "", nil, fmt.Errorf("file size exceeds maximum allowed (%d bytes)", p.maxFileSize)
 	}
 
-	// For MVP Phase 2, extract basic text content
-	// This is a simplified implementation
-	text := extractTextFromPDF(data)
+	doc, err := pdf.Open(data)
+	if errors.Is(err, pdf.ErrInvalid) {
+		return "", nil, errors.New("not a valid PDF file")
+	}
 
-	if text == "" {
-		return "", errors.New("no text content found in PDF")
+	if errors.Is(err, pdf.ErrEncrypted) {
+		return "", nil, err
 	}
 
-	return text, nil
-}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
 
-// extractTextFromPDF performs basic text extraction from PDF
-// This is a simplified version - for production use a dedicated library.
-func extractTextFromPDF(data []byte) string {
-	// Try to extract text between BT (begin text) and ET (end text) operators
-	var result strings.Builder
+	var (
+		pages   []string
+		results []pageOCR
+		ocrErr  error
+	)
 
-	inText := false
-	i := 0
+	scanned := 0
 
-	for i < len(data) {
-		// Look for "BT" operator
-		if i+1 < len(data) && data[i] == 'B' && data[i+1] == 'T' {
-			inText = true
-			i += 2
+	for i := range doc.NumPages() {
+		if !doc.IsScanned(i) {
+			pages = append(pages, doc.PageText(i))
 
 			continue
 		}
 
-		// Look for "ET" operator
-		if i+1 < len(data) && data[i] == 'E' && data[i+1] == 'T' {
-			inText = false
+		scanned++
+
+		if p.ocr == nil {
+			pages = append(pages, doc.PageText(i))
+
+			continue
+		}
 
-			result.WriteRune('\n')
+		result, err := p.recognize(ctx, doc, i)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", nil, ctx.Err()
+			}
 
-			i += 2
+			ocrErr = err
+			pages = append(pages, doc.PageText(i))
 
 			continue
 		}
 
-		if inText {
-			// Look for text strings in parentheses or angle brackets
-			if data[i] == '(' {
-				j := i + 1
-				for j < len(data) && data[j] != ')' {
-					if data[j] >= 32 && data[j] < 127 {
-						result.WriteByte(data[j])
-					}
+		results = append(results, pageOCR{page: i + 1, result: result})
+		pages = append(pages, result.Text())
+	}
 
-					j++
-				}
+	text := strings.TrimSpace(strings.Join(slices.DeleteFunc(pages, func(page string) bool {
+		return strings.TrimSpace(page) == ""
+	}), "\n\n"))
+
+	switch {
+	case text != "":
+		return text, ocrReport(p.ocr, results), nil
+	case scanned > 0 && p.ocr == nil:
+		return "", nil, errors.New("no text content found in PDF: the document is scanned and OCR is not configured")
+	case ocrErr != nil:
+		return "", nil, fmt.Errorf("no text content found in PDF: OCR failed: %w", ocrErr)
+	}
 
-				i = j
+	return "", nil, errors.New("no text content found in PDF")
+}
 
-				continue
-			}
-		}
+// pageOCR is the text recognized on one page, counting from 1.
+type pageOCR struct {
+	page   int
+	result *ocr.Result
+}
 
-		i++
+// recognize renders a scanned page and runs OCR on it.
+func (p *PDFParser) recognize(ctx context.Context, doc *pdf.Document, page int) (*ocr.Result, error) {
+	img, err := doc.RenderPage(page, ocrDPI)
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %w", page+1, err)
 	}
 
-	// Clean up whitespace
-	text := result.String()
-	lines := strings.Split(text, "\n")
+	result, err := p.ocr.Recognize(ctx, ocr.Image{Data: img.Data, DPI: img.DPI})
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %w", page+1, err)
+	}
 
-	var cleanedLines []string
+	return result, nil
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			cleanedLines = append(cleanedLines, line)
+// ocrReport summarizes the recognized pages, flagging the lines whose
+// confidence is below ocr.ReviewThreshold.
+func ocrReport(engine ocr.Engine, results []pageOCR) *types.OCRReport {
+	if len(results) == 0 {
+		return nil
+	}
+
+	report := &types.OCRReport{Engine: engine.Name(), Pages: []int{}}
+	total, words := 0.0, 0
+
+	for _, r := range results {
+		report.Pages = append(report.Pages, r.page)
+		total += r.result.Confidence * float64(r.result.Words())
+		words += r.result.Words()
+
+		for _, line := range r.result.LowConfidence(ocr.ReviewThreshold) {
+			report.Review = append(report.Review, types.OCRRegion{
+				Page:       r.page,
+				Text:       line.Text,
+				Confidence: math.Round(line.Confidence*10) / 10,
+				Box:        [4]int{line.Box.Min.X, line.Box.Min.Y, line.Box.Dx(), line.Box.Dy()},
+			})
 		}
 	}
 
-	return strings.Join(cleanedLines, "\n")
+	if words > 0 {
+		report.Confidence = math.Round(total/float64(words)*10) / 10
+	}
+
+	return report
 }
@@ -0,0 +1,478 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/ocr"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+var (
+	// ErrNoCV is returned when no input source resolved to CV text.
+	ErrNoCV = errors.New("no CV could be resolved from the request")
+	// ErrNoJobDescription is returned when no input source resolved to a job description.
+	ErrNoJobDescription = errors.New("no job description could be resolved from the request")
+)
+
+// Input source types.
+const (
+	SourceText     = "text"
+	SourceURL      = "url"
+	SourcePDF      = "pdf"
+	SourceDOCX     = "docx"
+	SourceDOC      = "doc"
+	SourceODT      = "odt"
+	SourceRTF      = "rtf"
+	SourceLinkedIn = "linkedin"
+)
+
+// ResolvedInput is the normalized input of a customization request.
+type ResolvedInput struct {
+	CVText         string
+	JobDescription string
+	Context        []string
+	CV             *StructuredCVContent
+	Job            *StructuredJobDescription
+	UsedLinkedIn   bool
+	Sources        []types.InputSourceResult
+}
+
+// Resolver turns every input source of a customization request (raw text,
+// base64 PDF, DOCX, DOC, ODT and RTF files, job URLs, LinkedIn profile text
+// and URL context items) into normalized text and structure.
+type Resolver struct {
+	fetcher  *Fetcher
+	pdf      *PDFParser
+	docx     *DOCXParser
+	doc      *DOCParser
+	odt      *ODTParser
+	rtf      *RTFParser
+	linkedin *LinkedInParser
+	parser   *EnhancedParser
+}
+
+// NewResolver creates a new input resolver.
+func NewResolver(fetcher *Fetcher) *Resolver {
+	return &Resolver{
+		fetcher:  fetcher,
+		pdf:      NewPDFParser(0),
+		docx:     NewDOCXParser(0),
+		doc:      NewDOCParser(0),
+		odt:      NewODTParser(0),
+		rtf:      NewRTFParser(0),
+		linkedin: NewLinkedInParser(),
+		parser:   NewEnhancedParser(),
+	}
+}
+
+// SetOCR sets the engine that recognizes the text of scanned PDF pages.
+func (r *Resolver) SetOCR(engine ocr.Engine) {
+	r.pdf.SetOCR(engine)
+}
+
+// resolvedSource is the text of one successfully resolved source, with the
+// document structure of word-processor files and the structure of fetched
+// job postings.
+type resolvedSource struct {
+	label string
+	role  string
+	text  string
+	doc   *Document
+	job   *StructuredJobDescription
+}
+
+// Resolve resolves all sources of a request. The first CV and the first job
+// description, in request order, become the primary inputs; any further CV or
+// job material is passed to the LLM as labelled context. The outcome of every
+// source is reported in Sources. It returns ErrNoCV or ErrNoJobDescription,
+// along with the partial result, when a required input could not be resolved.
+func (r *Resolver) Resolve(ctx context.Context, req *types.CustomizeCVRequest) (*ResolvedInput, error) {
+	out := &ResolvedInput{Context: []string{}, Sources: []types.InputSourceResult{}}
+
+	var resolved []resolvedSource
+
+	add := func(source, sourceType, role string, resolve func() (string, error)) {
+		result := types.InputSourceResult{Source: source, Type: sourceType, Role: role}
+
+		text, err := resolve()
+		if err == nil {
+			text = strings.TrimSpace(text)
+			if text == "" {
+				err = errors.New("source is empty")
+			}
+		}
+
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		} else {
+			result.Status = "resolved"
+			result.Chars = len(text)
+			result.Language = i18n.Detect(text)
+			resolved = append(resolved, resolvedSource{label: source, role: role, text: text})
+
+			if sourceType == SourceLinkedIn {
+				out.UsedLinkedIn = true
+			}
+		}
+
+		out.Sources = append(out.Sources, result)
+	}
+
+	addFile := func(source, sourceType, role, encoded string) {
+		var parsed *parsedFile
+
+		add(source, sourceType, role, func() (string, error) {
+			var err error
+
+			parsed, err = r.parseFile(ctx, encoded, sourceType)
+			if err != nil {
+				return "", err
+			}
+
+			return parsed.text, nil
+		})
+
+		if parsed == nil {
+			return
+		}
+
+		out.Sources[len(out.Sources)-1].OCR = parsed.ocr
+
+		if n := len(resolved); parsed.doc != nil && n > 0 && resolved[n-1].label == source {
+			resolved[n-1].doc = parsed.doc
+		}
+	}
+
+	addURL := func(source, role, url string) {
+		var job *StructuredJobDescription
+
+		add(source, SourceURL, role, func() (string, error) {
+			var err error
+
+			job, err = r.fetch(ctx, url)
+			if err != nil {
+				return "", err
+			}
+
+			return job.RawText, nil
+		})
+
+		if n := len(resolved); job != nil && n > 0 && resolved[n-1].label == source {
+			resolved[n-1].job = job
+		}
+	}
+
+	if req.CV != "" {
+		add("cv", SourceText, types.RoleCV, func() (string, error) { return req.CV, nil })
+	}
+
+	if req.CVFile != "" {
+		addFile("cv_file", detectFileType(req.CVFile, ""), types.RoleCV, req.CVFile)
+	}
+
+	if req.LinkedInProfile != "" {
+		add("linkedin_profile", SourceLinkedIn, types.RoleCV, func() (string, error) {
+			return r.parseLinkedIn(req.LinkedInProfile)
+		})
+	}
+
+	if req.JobDescription != "" {
+		add("job_description", SourceText, types.RoleJobDescription, func() (string, error) { return req.JobDescription, nil })
+	}
+
+	if req.JobDescriptionURL != "" {
+		addURL("job_description_url", types.RoleJobDescription, req.JobDescriptionURL)
+	}
+
+	for i, src := range req.InputSources {
+		label := fmt.Sprintf("input_sources[%d]", i)
+		sourceType := strings.ToLower(src.Type)
+
+		switch {
+		case sourceType == "file" || (sourceType == "" && src.FileName != ""):
+			sourceType = detectFileType(src.Content, src.FileName)
+		case sourceType == "" && src.URL != "":
+			sourceType = SourceURL
+		case sourceType == "":
+			sourceType = SourceText
+		}
+
+		role := src.Role
+		if role == "" {
+			role = defaultRole(sourceType)
+		}
+
+		if role != types.RoleCV && role != types.RoleJobDescription && role != types.RoleContext {
+			out.Sources = append(out.Sources, types.InputSourceResult{
+				Source: label, Type: sourceType, Role: role, Status: "failed",
+				Error: fmt.Sprintf("unsupported role %q", role),
+			})
+
+			continue
+		}
+
+		if isFileType(sourceType) {
+			addFile(label, sourceType, role, src.Content)
+
+			continue
+		}
+
+		if sourceType == SourceURL {
+			url := src.URL
+			if url == "" {
+				url = src.Content
+			}
+
+			addURL(label, role, url)
+
+			continue
+		}
+
+		add(label, sourceType, role, func() (string, error) {
+			return r.resolveSource(ctx, src, sourceType)
+		})
+	}
+
+	for i, item := range req.AdditionalContext {
+		label := fmt.Sprintf("additional_context[%d]", i)
+
+		switch item.Type {
+		case "url":
+			addURL(label, types.RoleContext, item.Content)
+		case "text", "":
+			add(label, SourceText, types.RoleContext, func() (string, error) { return item.Content, nil })
+		default:
+			out.Sources = append(out.Sources, types.InputSourceResult{
+				Source: label, Type: item.Type, Role: types.RoleContext, Status: "failed",
+				Error: fmt.Sprintf("unsupported context type %q", item.Type),
+			})
+		}
+	}
+
+	var (
+		cvDoc *Document
+		job   *StructuredJobDescription
+	)
+
+	for _, src := range resolved {
+		switch {
+		case src.role == types.RoleCV && out.CVText == "":
+			out.CVText = src.text
+			cvDoc = src.doc
+		case src.role == types.RoleJobDescription && out.JobDescription == "":
+			out.JobDescription = src.text
+			job = src.job
+		case src.role == types.RoleCV:
+			out.Context = append(out.Context, fmt.Sprintf("Additional candidate information (%s):\n%s", src.label, src.text))
+		case src.role == types.RoleJobDescription:
+			out.Context = append(out.Context, fmt.Sprintf("Additional job information (%s):\n%s", src.label, src.text))
+		default:
+			out.Context = append(out.Context, src.text)
+		}
+	}
+
+	if out.CVText == "" {
+		return out, ErrNoCV
+	}
+
+	if out.JobDescription == "" {
+		return out, ErrNoJobDescription
+	}
+
+	if cvDoc != nil {
+		out.CV = r.parser.ParseDocument(cvDoc)
+	} else {
+		out.CV = r.parser.ParseCV(out.CVText)
+	}
+
+	if job != nil {
+		out.Job = job
+	} else {
+		out.Job = r.parser.ParseJobDescription(out.JobDescription)
+	}
+
+	return out, nil
+}
+
+// resolveSource resolves one entry of input_sources.
+func (r *Resolver) resolveSource(ctx context.Context, src types.InputSource, sourceType string) (string, error) {
+	switch sourceType {
+	case SourceText:
+		return src.Content, nil
+	case SourceLinkedIn:
+		return r.parseLinkedIn(src.Content)
+	default:
+		return "", fmt.Errorf("unsupported source type %q", src.Type)
+	}
+}
+
+// fetch fetches a job posting, giving up when ctx is done.
+func (r *Resolver) fetch(ctx context.Context, url string) (*StructuredJobDescription, error) {
+	return r.fetcher.FetchJob(ctx, url)
+}
+
+// parsedFile is the text of an uploaded file, with the document structure
+// of word-processor formats and the OCR report of scanned PDFs.
+type parsedFile struct {
+	text string
+	doc  *Document
+	ocr  *types.OCRReport
+}
+
+// parseFile decodes a base64 file and extracts its text.
+func (r *Resolver) parseFile(ctx context.Context, encoded, sourceType string) (*parsedFile, error) {
+	data, err := decodeBase64File(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *Document
+
+	switch sourceType {
+	case SourcePDF:
+		text, report, err := r.pdf.Parse(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &parsedFile{text: text, ocr: report}, nil
+	case SourceDOCX:
+		doc, err = r.docx.ParseDocument(data)
+	case SourceDOC:
+		doc, err = r.doc.ParseDocument(data)
+	case SourceODT:
+		doc, err = r.odt.ParseDocument(data)
+	case SourceRTF:
+		doc, err = r.rtf.ParseDocument(data)
+	default:
+		return nil, errors.New("unsupported file type: expected PDF, DOCX, DOC, ODT or RTF")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsedFile{text: doc.Text(), doc: doc}, nil
+}
+
+// isFileType reports whether a source type is a base64 file.
+func isFileType(sourceType string) bool {
+	switch sourceType {
+	case SourcePDF, SourceDOCX, SourceDOC, SourceODT, SourceRTF:
+		return true
+	}
+
+	return false
+}
+
+// parseLinkedIn converts LinkedIn profile text to CV text.
+func (r *Resolver) parseLinkedIn(content string) (string, error) {
+	profile, err := r.linkedin.ParseProfile(content)
+	if err != nil {
+		return "", err
+	}
+
+	text := ConvertToCV(profile)
+	if strings.TrimSpace(text) == "" {
+		// Nothing recognized; the raw profile is still useful to the LLM
+		return content, nil
+	}
+
+	return text, nil
+}
+
+// decodeBase64File decodes standard or URL-safe base64, with or without a
+// data URL prefix.
+func decodeBase64File(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if strings.HasPrefix(encoded, "data:") {
+		if _, payload, ok := strings.Cut(encoded, ","); ok {
+			encoded = payload
+		}
+	}
+
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(encoded); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, errors.New("file content is not valid base64")
+}
+
+// detectFileType guesses a file type from its content, falling back to the
+// file name extension.
+func detectFileType(encoded, fileName string) string {
+	if data, err := decodeBase64File(encoded); err == nil {
+		switch {
+		case bytes.HasPrefix(data, []byte("%PDF")):
+			return SourcePDF
+		case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+			return detectZipType(data)
+		case bytes.HasPrefix(data, cfbMagic):
+			return SourceDOC
+		case bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(`{\rtf`)):
+			return SourceRTF
+		}
+	}
+
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), ".")); ext {
+	case SourcePDF, SourceDOCX, SourceDOC, SourceODT, SourceRTF:
+		return ext
+	}
+
+	return "file"
+}
+
+// detectZipType tells OpenDocument text from DOCX, which are both ZIP files.
+func detectZipType(data []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return SourceDOCX
+	}
+
+	for _, f := range reader.File {
+		if f.Name != "mimetype" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			break
+		}
+
+		mimetype, _ := io.ReadAll(io.LimitReader(rc, 64))
+		rc.Close()
+
+		if strings.HasPrefix(string(mimetype), "application/vnd.oasis.opendocument.text") {
+			return SourceODT
+		}
+	}
+
+	return SourceDOCX
+}
+
+// defaultRole returns the role of an input source that does not set one.
+func defaultRole(sourceType string) string {
+	switch sourceType {
+	case SourcePDF, SourceDOCX, SourceDOC, SourceODT, SourceRTF, SourceLinkedIn:
+		return types.RoleCV
+	case SourceURL:
+		return types.RoleJobDescription
+	default:
+		return types.RoleContext
+	}
+}
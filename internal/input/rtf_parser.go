@@ -0,0 +1,631 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxRTFDepth bounds the group nesting of an RTF document.
+const maxRTFDepth = 512
+
+// RTFParser handles Rich Text Format (.rtf) file parsing.
+type RTFParser struct {
+	maxFileSize int64
+}
+
+// NewRTFParser creates a new RTF parser.
+func NewRTFParser(maxFileSize int64) *RTFParser {
+	if maxFileSize == 0 {
+		maxFileSize = 50 * 1024 * 1024 // 50MB default
+	}
+
+	return &RTFParser{
+		maxFileSize: maxFileSize,
+	}
+}
+
+// ParseFile extracts text from an RTF file.
+func (p *RTFParser) ParseFile(filePath string) (string, error) {
+	content, err := readLimitedFile(filePath, p.maxFileSize)
+	if err != nil {
+		return "", err
+	}
+
+	return p.ParseBytes(content)
+}
+
+// ParseBytes extracts text from RTF bytes.
+func (p *RTFParser) ParseBytes(data []byte) (string, error) {
+	doc, err := p.ParseDocument(data)
+	if err != nil {
+		return "", err
+	}
+
+	return doc.Text(), nil
+}
+
+// ParseDocument reads the structure of an RTF file: headings from styles and
+// outline levels, lists, tables, hyperlink fields, and headers and footers.
+func (p *RTFParser) ParseDocument(data []byte) (*Document, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty RTF data")
+	}
+
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(`{\rtf`)) {
+		return nil, errors.New("not a valid RTF file")
+	}
+
+	if int64(len(data)) > p.maxFileSize {
+		return nil, errors.New("file size exceeds maximum allowed")
+	}
+
+	r := &rtfReader{
+		data:    data,
+		doc:     &Document{},
+		charset: charmap.Windows1252,
+		styles:  map[int]string{},
+		st:      rtfState{uc: 1},
+		para:    &rtfParagraph{},
+	}
+	r.para.reset()
+	r.run()
+
+	if len(r.doc.Blocks) == 0 && len(r.doc.Header) == 0 && len(r.doc.Footer) == 0 {
+		return nil, errors.New("no text content found in RTF")
+	}
+
+	return r.doc, nil
+}
+
+// RTF destinations with special handling.
+const (
+	rtfBody = iota
+	rtfSkip
+	rtfStylesheet
+	rtfFieldInstr
+	rtfHeader
+	rtfFooter
+)
+
+// rtfState is the group-scoped state of the reader.
+type rtfState struct {
+	dest  int
+	uc    int // Fallback characters after \u
+	bold  bool
+	field *rtfField
+}
+
+// rtfField is a field, of which hyperlinks are kept.
+type rtfField struct {
+	instr strings.Builder
+	start int // Offset of the field result in the paragraph text, -1 before it
+}
+
+// rtfParagraph is the paragraph being read.
+type rtfParagraph struct {
+	text    strings.Builder
+	style   int
+	outline int
+	list    bool
+	level   int
+	inTable bool
+	bold    bool
+	hasText bool
+	blocks  *[]Block
+	cell    []Block
+	row     [][]Block
+	rows    [][][]Block
+}
+
+// reset resets the paragraph properties, as \pard does.
+func (p *rtfParagraph) reset() {
+	p.style, p.outline, p.list, p.level, p.inTable = 0, 0, false, 0, false
+}
+
+// rtfReader reads RTF control words and text.
+type rtfReader struct {
+	data    []byte
+	pos     int
+	doc     *Document
+	charset *charmap.Charmap
+	styles  map[int]string
+
+	st    rtfState
+	stack []rtfState
+
+	// Paragraphs are held by pointer, as their text builder must not be
+	// copied once written to
+	para      *rtfParagraph
+	saved     []*rtfParagraph // Body paragraph while reading headers and footers
+	skipChars int             // Pending \u fallback characters
+
+	styleDepth int // Group depth of the stylesheet
+	styleNum   int
+	styleName  strings.Builder
+}
+
+// rtfSkipped are destinations without body text.
+var rtfSkipped = map[string]bool{
+	"fonttbl": true, "colortbl": true, "info": true, "pict": true, "object": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"listtable": true, "listoverridetable": true, "rsidtbl": true, "generator": true,
+	"xmlnstbl": true, "footnote": true, "annotation": true, "atnid": true, "atnauthor": true,
+	"nonshppict": true, "shp": true, "bkmkstart": true, "bkmkend": true, "pn": true,
+	"xe": true, "tc": true, "txe": true, "filetbl": true, "revtbl": true, "userprops": true,
+	"wgrffmtfilter": true, "pgdsctbl": true, "mmathPr": true, "fldtype": true,
+}
+
+func (r *rtfReader) run() {
+	var blocks []Block
+
+	r.para.blocks = &blocks
+
+	for r.pos < len(r.data) {
+		c := r.data[r.pos]
+
+		switch c {
+		case '{':
+			r.pos++
+
+			if len(r.stack) >= maxRTFDepth {
+				r.skipGroup()
+
+				continue
+			}
+
+			r.stack = append(r.stack, r.st)
+
+			if r.st.dest == rtfStylesheet && len(r.stack) == r.styleDepth+1 {
+				r.styleNum = 0
+				r.styleName.Reset()
+			}
+		case '}':
+			r.pos++
+			r.endGroup()
+		case '\\':
+			r.pos++
+			r.controlWord()
+		case '\r', '\n':
+			r.pos++
+		default:
+			r.pos++
+			r.writeByte(c)
+		}
+	}
+
+	r.endParagraph()
+	r.flushTable()
+
+	r.doc.Blocks = blocks
+}
+
+// skipGroup skips to the end of the current group.
+func (r *rtfReader) skipGroup() {
+	for depth := 1; r.pos < len(r.data) && depth > 0; r.pos++ {
+		switch r.data[r.pos] {
+		case '\\':
+			r.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+	}
+}
+
+// endGroup restores the state of the enclosing group.
+func (r *rtfReader) endGroup() {
+	if len(r.stack) == 0 {
+		return
+	}
+
+	prev := r.st
+	r.st = r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+
+	switch {
+	case prev.dest == rtfStylesheet && len(r.stack) == r.styleDepth:
+		if name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(r.styleName.String()), ";")); name != "" && r.styleNum >= 0 {
+			r.styles[r.styleNum] = name
+		}
+
+		r.styleName.Reset()
+	case (prev.dest == rtfHeader || prev.dest == rtfFooter) && r.st.dest != prev.dest:
+		r.endParagraph()
+		r.flushTable()
+
+		text := (&Document{Blocks: *r.para.blocks}).Text()
+		if prev.dest == rtfHeader {
+			r.doc.Header = addLines(r.doc.Header, text)
+		} else {
+			r.doc.Footer = addLines(r.doc.Footer, text)
+		}
+
+		if n := len(r.saved); n > 0 {
+			r.para = r.saved[n-1]
+			r.saved = r.saved[:n-1]
+		}
+	case prev.field != nil && prev.field != r.st.field:
+		r.endField(prev.field)
+	}
+}
+
+// endField replaces the result of a HYPERLINK field with its link text.
+func (r *rtfReader) endField(f *rtfField) {
+	url := fieldURL(f.instr.String())
+	text := r.para.text.String()
+
+	if url == "" || f.start < 0 || f.start > len(text) {
+		return
+	}
+
+	linked := r.doc.addLink(text[f.start:], url)
+
+	r.para.text.Reset()
+	r.para.text.WriteString(text[:f.start])
+	r.para.text.WriteString(linked)
+}
+
+// controlWord reads and applies the control word or symbol after a
+// backslash.
+func (r *rtfReader) controlWord() {
+	if r.pos >= len(r.data) {
+		return
+	}
+
+	c := r.data[r.pos]
+
+	if !isASCIILetter(c) {
+		r.pos++
+		r.controlSymbol(c)
+
+		return
+	}
+
+	start := r.pos
+	for r.pos < len(r.data) && isASCIILetter(r.data[r.pos]) {
+		r.pos++
+	}
+
+	word := string(r.data[start:r.pos])
+
+	param, hasParam := 0, false
+	numStart := r.pos
+
+	if r.pos < len(r.data) && r.data[r.pos] == '-' {
+		r.pos++
+	}
+
+	for r.pos < len(r.data) && r.data[r.pos] >= '0' && r.data[r.pos] <= '9' {
+		r.pos++
+	}
+
+	if r.pos > numStart && r.data[r.pos-1] != '-' {
+		param, _ = strconv.Atoi(string(r.data[numStart:r.pos]))
+		hasParam = true
+	} else {
+		r.pos = numStart
+	}
+
+	if r.pos < len(r.data) && r.data[r.pos] == ' ' {
+		r.pos++
+	}
+
+	r.apply(word, param, hasParam)
+}
+
+// controlSymbol applies a control symbol such as \' or \~.
+func (r *rtfReader) controlSymbol(c byte) {
+	switch c {
+	case '\'':
+		if r.pos+2 <= len(r.data) {
+			if b, err := strconv.ParseUint(string(r.data[r.pos:r.pos+2]), 16, 8); err == nil {
+				r.pos += 2
+				r.writeByte(byte(b))
+			}
+		}
+	case '*':
+		// An optional destination: skipped unless the next word is known
+		if r.pos < len(r.data) && r.data[r.pos] == '\\' {
+			save := r.pos
+			r.pos++
+
+			start := r.pos
+			for r.pos < len(r.data) && isASCIILetter(r.data[r.pos]) {
+				r.pos++
+			}
+
+			word := string(r.data[start:r.pos])
+			r.pos = save
+
+			if word == "fldinst" {
+				return
+			}
+		}
+
+		r.st.dest = rtfSkip
+	case '~':
+		r.writeText(" ")
+	case '_':
+		r.writeText("-")
+	case '\\', '{', '}':
+		r.writeText(string(c))
+	case '\r', '\n':
+		r.endParagraph()
+	}
+}
+
+// apply applies a control word.
+func (r *rtfReader) apply(word string, param int, hasParam bool) {
+	if rtfSkipped[word] {
+		r.st.dest = rtfSkip
+
+		return
+	}
+
+	if r.st.dest == rtfSkip {
+		if word == "bin" && hasParam {
+			r.pos = min(len(r.data), r.pos+max(param, 0))
+		}
+
+		return
+	}
+
+	switch word {
+	case "ansicpg":
+		r.charset = codePage(param, r.charset)
+	case "mac":
+		r.charset = charmap.Macintosh
+	case "uc":
+		r.st.uc = max(param, 0)
+	case "u":
+		if param < 0 {
+			param += 65536
+		}
+
+		r.writeText(string(rune(param)))
+		r.skipChars = r.st.uc
+	case "bin":
+		r.pos = min(len(r.data), r.pos+max(param, 0))
+	case "stylesheet":
+		r.st.dest = rtfStylesheet
+		r.styleDepth = len(r.stack)
+	case "s":
+		if r.st.dest == rtfStylesheet {
+			r.styleNum = param
+		} else {
+			r.para.style = param
+		}
+	case "cs", "ds", "ts":
+		if r.st.dest == rtfStylesheet {
+			r.styleNum = -1
+		}
+	case "header", "headerl", "headerr", "headerf", "footer", "footerl", "footerr", "footerf":
+		r.saved = append(r.saved, r.para)
+		r.para = &rtfParagraph{blocks: &[]Block{}}
+
+		r.st.dest = rtfHeader
+		if strings.HasPrefix(word, "footer") {
+			r.st.dest = rtfFooter
+		}
+	case "field":
+		r.st.field = &rtfField{start: -1}
+	case "fldinst":
+		r.st.dest = rtfFieldInstr
+	case "fldrslt":
+		if r.st.field != nil {
+			r.st.field.start = r.para.text.Len()
+		}
+	case "pntext", "listtext":
+		r.para.list = true
+		r.st.dest = rtfSkip
+	case "pard":
+		r.para.reset()
+	case "outlinelevel":
+		r.para.outline = param + 1
+	case "ls":
+		r.para.list = true
+	case "ilvl":
+		r.para.level = param
+	case "intbl":
+		r.para.inTable = true
+	case "b":
+		r.st.bold = !hasParam || param != 0
+	case "plain":
+		r.st.bold = false
+	case "par", "sect", "page":
+		r.endParagraph()
+	case "line":
+		r.writeText("\n")
+	case "tab":
+		r.writeText("\t")
+	case "cell", "nestcell":
+		r.endParagraph()
+		r.para.row = append(r.para.row, r.para.cell)
+		r.para.cell = nil
+	case "row", "nestrow":
+		r.endParagraph()
+
+		if len(r.para.cell) > 0 {
+			r.para.row = append(r.para.row, r.para.cell)
+			r.para.cell = nil
+		}
+
+		r.para.rows = append(r.para.rows, r.para.row)
+		r.para.row = nil
+	case "emdash":
+		r.writeText("—")
+	case "endash":
+		r.writeText("–")
+	case "bullet":
+		r.writeText("•")
+	case "lquote":
+		r.writeText("‘")
+	case "rquote":
+		r.writeText("’")
+	case "ldblquote":
+		r.writeText("“")
+	case "rdblquote":
+		r.writeText("”")
+	case "emspace", "enspace", "qmspace":
+		r.writeText(" ")
+	}
+}
+
+// writeByte writes a byte of text in the document code page.
+func (r *rtfReader) writeByte(b byte) {
+	if r.skipChars > 0 {
+		r.skipChars--
+
+		return
+	}
+
+	if b < 0x80 {
+		r.writeText(string(rune(b)))
+
+		return
+	}
+
+	r.writeText(string(r.charset.DecodeByte(b)))
+}
+
+// writeText writes text to the current destination.
+func (r *rtfReader) writeText(s string) {
+	switch r.st.dest {
+	case rtfSkip:
+		return
+	case rtfStylesheet:
+		r.styleName.WriteString(s)
+
+		return
+	case rtfFieldInstr:
+		if r.st.field != nil {
+			r.st.field.instr.WriteString(s)
+		}
+
+		return
+	}
+
+	r.skipChars = 0
+	r.para.text.WriteString(s)
+
+	if strings.TrimSpace(s) != "" {
+		if !r.para.hasText {
+			r.para.bold = true
+		}
+
+		r.para.hasText = true
+		r.para.bold = r.para.bold && r.st.bold
+	}
+}
+
+// endParagraph ends the current paragraph, adding it to the open table cell
+// or the document.
+func (r *rtfReader) endParagraph() {
+	heading, styleList := 0, false
+
+	if name, ok := r.styles[r.para.style]; ok {
+		if m := docxHeadingStyle.FindStringSubmatch(name); m != nil {
+			heading, _ = strconv.Atoi(m[1])
+		} else if strings.EqualFold(name, "title") {
+			heading = 1
+		}
+
+		styleList = strings.HasPrefix(strings.ToLower(name), "list ")
+	}
+
+	if heading == 0 {
+		heading = r.para.outline
+	}
+
+	p := paragraph{
+		text:    r.para.text.String(),
+		heading: heading,
+		list:    r.para.list || styleList,
+		level:   r.para.level,
+		bold:    r.para.bold && r.para.hasText,
+	}
+
+	r.para.text.Reset()
+	r.para.hasText, r.para.bold = false, false
+
+	if r.st.field != nil && r.st.field.start >= 0 {
+		r.st.field.start = 0
+	}
+
+	b, ok := p.block()
+
+	if r.para.inTable {
+		if ok {
+			r.para.cell = append(r.para.cell, b)
+		}
+
+		return
+	}
+
+	r.flushTable()
+
+	if ok {
+		*r.para.blocks = append(*r.para.blocks, b)
+	}
+}
+
+// flushTable adds the table read so far to the document.
+func (r *rtfReader) flushTable() {
+	if len(r.para.row) > 0 || len(r.para.cell) > 0 {
+		if len(r.para.cell) > 0 {
+			r.para.row = append(r.para.row, r.para.cell)
+		}
+
+		r.para.rows = append(r.para.rows, r.para.row)
+		r.para.row, r.para.cell = nil, nil
+	}
+
+	if len(r.para.rows) > 0 {
+		*r.para.blocks = append(*r.para.blocks, tableBlocks(r.para.rows)...)
+		r.para.rows = nil
+	}
+}
+
+// codePage returns the charmap of a Windows code page, or fallback.
+func codePage(cp int, fallback *charmap.Charmap) *charmap.Charmap {
+	switch cp {
+	case 437:
+		return charmap.CodePage437
+	case 850:
+		return charmap.CodePage850
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	case 1253:
+		return charmap.Windows1253
+	case 1254:
+		return charmap.Windows1254
+	case 1255:
+		return charmap.Windows1255
+	case 1256:
+		return charmap.Windows1256
+	case 1257:
+		return charmap.Windows1257
+	case 1258:
+		return charmap.Windows1258
+	case 10000:
+		return charmap.Macintosh
+	}
+
+	return fallback
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,281 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package interview prepares candidates for interviews. A pack lists likely
+// questions for every requirement of a job, outlines STAR answers from the
+// passages of the CV that cover each requirement, and gives talking points
+// for the skills the CV is missing. The LLM writes the questions, outlines
+// and talking points; when its answer cannot be read, they are filled in
+// from templates, so a pack is always grounded in the CV.
+package interview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/agent"
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// maxRequirements is how many requirements a pack prepares for.
+const maxRequirements = 12
+
+// STAR outlines an answer as situation, task, action and result.
+type STAR struct {
+	Situation string `json:"situation"`
+	Task      string `json:"task"`
+	Action    string `json:"action"`
+	Result    string `json:"result"`
+}
+
+// RequirementPrep prepares one requirement of the job.
+type RequirementPrep struct {
+	Requirement string   `json:"requirement"`
+	Kind        string   `json:"kind"` // "required" or "preferred"
+	Covered     bool     `json:"covered"`
+	Evidence    string   `json:"evidence,omitempty"` // Passage of the CV the answer draws on
+	Questions   []string `json:"questions"`
+	Answer      *STAR    `json:"star_answer,omitempty"` // Only for covered requirements
+}
+
+// SkillPrep prepares a skill the CV is missing.
+type SkillPrep struct {
+	Skill         string   `json:"skill"`
+	TalkingPoints []string `json:"talking_points"`
+}
+
+// Pack is the interview preparation for a CV and a job.
+type Pack struct {
+	Language      string            `json:"language"`
+	Requirements  []RequirementPrep `json:"requirements"`
+	MissingSkills []SkillPrep       `json:"missing_skills"`
+	GeneratedBy   string            `json:"generated_by"` // LLM provider, or "template" when its answer could not be read
+}
+
+// Generator prepares interview packs with an LLM provider.
+type Generator struct {
+	provider llm.Provider
+}
+
+// NewGenerator creates an interview pack generator.
+func NewGenerator(provider llm.Provider) *Generator {
+	return &Generator{provider: provider}
+}
+
+// packJSON is the pack the LLM is asked for. Some models nest it in the
+// customized CV object instead of encoding it as a string.
+type packJSON struct {
+	Requirements []struct {
+		Requirement string   `json:"requirement"`
+		Questions   []string `json:"questions"`
+		Answer      *STAR    `json:"star_answer"`
+	} `json:"requirements"`
+	MissingSkills []SkillPrep `json:"missing_skills"`
+	CustomizedCV  *packJSON   `json:"customized_cv"`
+}
+
+// Generate prepares a pack for a CV and a job from the requirements of their
+// match and the skills the analyzer agent finds missing. The language is an
+// ISO 639-1 code; when empty it is detected from the CV.
+func (g *Generator) Generate(ctx context.Context, cv, jobDescription string, details *types.MatchDetails, language string) (*Pack, error) {
+	if strings.TrimSpace(cv) == "" {
+		return nil, fmt.Errorf("CV has no text to prepare an interview from")
+	}
+
+	if language == "" {
+		language = i18n.Detect(cv)
+	}
+
+	pack := &Pack{Language: language, Requirements: []RequirementPrep{}, MissingSkills: []SkillPrep{}}
+
+	if details != nil {
+		for _, r := range details.Requirements[:min(maxRequirements, len(details.Requirements))] {
+			pack.Requirements = append(pack.Requirements, RequirementPrep{
+				Requirement: r.Requirement,
+				Kind:        r.Kind,
+				Covered:     r.Covered,
+				Evidence:    r.Evidence,
+			})
+		}
+	}
+
+	for _, skill := range agent.AnalyzeSkills(cv, jobDescription).Missing {
+		pack.MissingSkills = append(pack.MissingSkills, SkillPrep{Skill: skill})
+	}
+
+	resp, err := g.provider.Customize(llm.WithLanguage(ctx, language), cv, jobDescription, instructions(pack))
+	if err != nil {
+		return nil, err
+	}
+
+	pack.GeneratedBy = g.provider.GetName()
+	if !pack.merge(resp.ModifiedCV) {
+		pack.GeneratedBy = "template"
+	}
+
+	pack.fill()
+
+	return pack, nil
+}
+
+// instructions asks the LLM for the questions, answers and talking points of
+// a pack.
+func instructions(pack *Pack) []string {
+	lines := []string{
+		"Do not return a CV. Instead, prepare the candidate for an interview for this job, and return as \"customized_cv\" " +
+			"a JSON object, encoded as a string, of the form " +
+			`{"requirements": [{"requirement": "...", "questions": ["..."], "star_answer": {"situation": "...", "task": "...", "action": "...", "result": "..."}}], ` +
+			`"missing_skills": [{"skill": "...", "talking_points": ["..."]}]}.`,
+		"Give 2 or 3 likely interview questions for each requirement below. For requirements with CV evidence, outline a STAR answer " +
+			"using only what the CV states; never invent employers, projects or figures. Leave out star_answer for the others.",
+	}
+
+	for _, r := range pack.Requirements {
+		if r.Covered {
+			lines = append(lines, fmt.Sprintf("- Requirement: %s (CV evidence: %s)", r.Requirement, r.Evidence))
+		} else {
+			lines = append(lines, fmt.Sprintf("- Requirement: %s (not shown in the CV)", r.Requirement))
+		}
+	}
+
+	if len(pack.MissingSkills) > 0 {
+		skills := make([]string, 0, len(pack.MissingSkills))
+		for _, s := range pack.MissingSkills {
+			skills = append(skills, s.Skill)
+		}
+
+		lines = append(lines, "The CV does not show these skills. For each, give 2 or 3 honest talking points: related experience from the CV, "+
+			"and how the candidate would learn it: "+strings.Join(skills, ", "))
+	}
+
+	return lines
+}
+
+// merge reads the LLM's answer into a pack, matching requirements and skills
+// by name. It returns false if the answer is not a pack.
+func (p *Pack) merge(content string) bool {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start == -1 || end < start {
+		return false
+	}
+
+	var answer packJSON
+	if err := json.Unmarshal([]byte(content[start:end+1]), &answer); err != nil {
+		return false
+	}
+
+	if answer.CustomizedCV != nil {
+		answer = *answer.CustomizedCV
+	}
+
+	if len(answer.Requirements) == 0 && len(answer.MissingSkills) == 0 {
+		return false
+	}
+
+	for _, a := range answer.Requirements {
+		for i := range p.Requirements {
+			r := &p.Requirements[i]
+			if !strings.EqualFold(strings.TrimSpace(a.Requirement), r.Requirement) {
+				continue
+			}
+
+			r.Questions = a.Questions
+			if r.Covered && a.Answer != nil {
+				r.Answer = a.Answer
+			}
+		}
+	}
+
+	for _, a := range answer.MissingSkills {
+		for i := range p.MissingSkills {
+			if strings.EqualFold(strings.TrimSpace(a.Skill), p.MissingSkills[i].Skill) {
+				p.MissingSkills[i].TalkingPoints = a.TalkingPoints
+			}
+		}
+	}
+
+	return true
+}
+
+// fill completes what the LLM left out from templates built on the CV's
+// evidence. The templates are in English.
+func (p *Pack) fill() {
+	for i := range p.Requirements {
+		r := &p.Requirements[i]
+
+		if len(r.Questions) == 0 {
+			r.Questions = []string{
+				fmt.Sprintf("The role asks for %q. Where have you done this, and what did you do yourself?", r.Requirement),
+				fmt.Sprintf("What was the hardest problem you solved around %q, and how did you approach it?", r.Requirement),
+			}
+		}
+
+		if r.Covered && r.Answer == nil {
+			r.Answer = &STAR{
+				Situation: fmt.Sprintf("Set the context of: %s", r.Evidence),
+				Task:      fmt.Sprintf("Explain what you were responsible for that shows %q.", r.Requirement),
+				Action:    "Walk through the steps you took yourself, and the tools and decisions involved.",
+				Result:    "Close with the outcome, with the figures the CV gives where it has them.",
+			}
+		}
+	}
+
+	for i := range p.MissingSkills {
+		s := &p.MissingSkills[i]
+
+		if len(s.TalkingPoints) == 0 {
+			s.TalkingPoints = []string{
+				fmt.Sprintf("Acknowledge that you have not used %s in production.", s.Skill),
+				fmt.Sprintf("Point to the closest experience on your CV and what carries over to %s.", s.Skill),
+				fmt.Sprintf("Say how you are learning %s, such as a course or side project.", s.Skill),
+			}
+		}
+	}
+}
+
+// Markdown renders a pack as a Markdown document.
+func (p *Pack) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Interview Preparation\n")
+
+	if len(p.Requirements) > 0 {
+		b.WriteString("\n## Requirements\n")
+	}
+
+	for _, r := range p.Requirements {
+		status := "shown in your CV"
+		if !r.Covered {
+			status = "not shown in your CV"
+		}
+
+		fmt.Fprintf(&b, "\n### %s\n\n*%s, %s*\n\n**Likely questions**\n\n", r.Requirement, r.Kind, status)
+
+		for _, q := range r.Questions {
+			fmt.Fprintf(&b, "- %s\n", q)
+		}
+
+		if r.Answer != nil {
+			fmt.Fprintf(&b, "\n**STAR answer**\n\n- **Situation:** %s\n- **Task:** %s\n- **Action:** %s\n- **Result:** %s\n",
+				r.Answer.Situation, r.Answer.Task, r.Answer.Action, r.Answer.Result)
+		}
+	}
+
+	if len(p.MissingSkills) > 0 {
+		b.WriteString("\n## Missing Skills\n")
+	}
+
+	for _, s := range p.MissingSkills {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Skill)
+
+		for _, point := range s.TalkingPoints {
+			fmt.Fprintf(&b, "- %s\n", point)
+		}
+	}
+
+	return b.String()
+}
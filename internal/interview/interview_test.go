@@ -0,0 +1,113 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package interview
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// fakeProvider returns a fixed answer.
+type fakeProvider struct {
+	answer  string
+	context []string
+}
+
+func (p *fakeProvider) Customize(_ context.Context, _, _ string, additionalContext []string) (*llm.CustomizationResponse, error) {
+	p.context = additionalContext
+
+	return &llm.CustomizationResponse{ModifiedCV: p.answer}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "fake"
+}
+
+const (
+	testCV  = "Jane Doe\n\nExperience\n- Built Golang microservices handling 2M requests per day\n\nSkills: Go, Docker"
+	testJob = "Backend Engineer\n\nRequirements:\n- Strong Go programming skills\n- Experience with Kafka event streaming"
+)
+
+var testDetails = &types.MatchDetails{Requirements: []types.RequirementMatch{
+	{Requirement: "Strong Go programming skills", Kind: "required", Covered: true, Evidence: "Built Golang microservices handling 2M requests per day"},
+	{Requirement: "Experience with Kafka event streaming", Kind: "required"},
+}}
+
+func TestGenerate(t *testing.T) {
+	// The pack nested in the customized CV object, as some models answer
+	provider := &fakeProvider{answer: `{"customized_cv": {
+		"requirements": [
+			{"requirement": "strong go programming skills", "questions": ["How do you structure a Go service?"],
+			 "star_answer": {"situation": "A service handling 2M requests per day", "task": "Build it", "action": "Wrote it in Go", "result": "It scaled"}},
+			{"requirement": "Experience with Kafka event streaming", "questions": ["Have you used Kafka?"],
+			 "star_answer": {"situation": "Invented", "task": "", "action": "", "result": ""}}
+		],
+		"missing_skills": [{"skill": "Kafka", "talking_points": ["I have built event-driven services without Kafka."]}]
+	}}`}
+
+	pack, err := NewGenerator(provider).Generate(context.Background(), testCV, testJob, testDetails, "")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if pack.GeneratedBy != "fake" || pack.Language != "en" {
+		t.Errorf("generated by %q in %q", pack.GeneratedBy, pack.Language)
+	}
+
+	if len(pack.Requirements) != 2 {
+		t.Fatalf("requirements = %+v", pack.Requirements)
+	}
+
+	if got := pack.Requirements[0]; got.Questions[0] != "How do you structure a Go service?" || got.Answer == nil || got.Answer.Result != "It scaled" {
+		t.Errorf("covered requirement = %+v", got)
+	}
+
+	// No STAR answer is outlined for what the CV does not show
+	if got := pack.Requirements[1]; got.Answer != nil || len(got.Questions) != 1 {
+		t.Errorf("uncovered requirement = %+v", got)
+	}
+
+	if len(pack.MissingSkills) != 1 || pack.MissingSkills[0].Skill != "Kafka" || len(pack.MissingSkills[0].TalkingPoints) != 1 {
+		t.Errorf("missing skills = %+v", pack.MissingSkills)
+	}
+
+	if prompt := strings.Join(provider.context, "\n"); !strings.Contains(prompt, "CV evidence: Built Golang microservices") || !strings.Contains(prompt, "Kafka") {
+		t.Errorf("prompt lacks the evidence or missing skills:\n%s", prompt)
+	}
+
+	markdown := pack.Markdown()
+	for _, want := range []string{"# Interview Preparation", "### Strong Go programming skills", "- **Result:** It scaled", "## Missing Skills", "### Kafka"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown() is missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestGenerate_Template(t *testing.T) {
+	pack, err := NewGenerator(&fakeProvider{answer: "Sorry, I cannot help with that."}).Generate(context.Background(), testCV, testJob, testDetails, "")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if pack.GeneratedBy != "template" {
+		t.Errorf("generated by %q, want template", pack.GeneratedBy)
+	}
+
+	covered := pack.Requirements[0]
+	if len(covered.Questions) == 0 || covered.Answer == nil || !strings.Contains(covered.Answer.Situation, "2M requests per day") {
+		t.Errorf("covered requirement = %+v", covered)
+	}
+
+	if pack.Requirements[1].Answer != nil {
+		t.Errorf("uncovered requirement has an answer: %+v", pack.Requirements[1].Answer)
+	}
+
+	if len(pack.MissingSkills) != 1 || len(pack.MissingSkills[0].TalkingPoints) == 0 {
+		t.Errorf("missing skills = %+v", pack.MissingSkills)
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
 )
 
 // LaTeXGenerator generates LaTeX content and compiles to PDF.
@@ -24,16 +28,47 @@ func NewLaTeXGenerator(outputDir, laTeXPath string) *LaTeXGenerator {
 	}
 }
 
-// GeneratePDF generates a PDF from CV content.
-func (lg *LaTeXGenerator) GeneratePDF(cvContent string, filename string) (string, error) {
+// GeneratePDF generates a PDF from CV content. The language, an ISO 639-1
+// code, selects the hyphenation patterns and headings; when empty it is
+// detected from the content.
+func (lg *LaTeXGenerator) GeneratePDF(cvContent, filename, language string) (string, error) {
+	// Generate LaTeX template in the language of the CV
+	if language == "" {
+		language = i18n.Detect(cvContent)
+	}
+
+	return lg.compile(generateLaTeXTemplate(cvContent, language), filename)
+}
+
+// GenerateCoverLetterPDF generates a PDF from the text of a cover letter,
+// like GeneratePDF does from a CV.
+func (lg *LaTeXGenerator) GenerateCoverLetterPDF(letter, filename, language string) (string, error) {
+	if language == "" {
+		language = i18n.Detect(letter)
+	}
+
+	return lg.compile(generateCoverLetterTemplate(letter, language), filename)
+}
+
+// GenerateMarkdownPDF generates a PDF from a Markdown document, such as an
+// interview preparation pack. Headings, bullet lists, bold and italic text
+// are rendered; other Markdown is kept as text.
+func (lg *LaTeXGenerator) GenerateMarkdownPDF(markdown, filename, language string) (string, error) {
+	if language == "" {
+		language = i18n.Detect(markdown)
+	}
+
+	return lg.compile(generateMarkdownTemplate(markdown, language), filename)
+}
+
+// compile writes a LaTeX document to the output directory and compiles it,
+// returning the path of the PDF.
+func (lg *LaTeXGenerator) compile(latexContent, filename string) (string, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(lg.outputDir, 0o755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Generate LaTeX template
-	latexContent := generateLaTeXTemplate(cvContent)
-
 	// Write LaTeX file
 	texFile := filepath.Join(lg.outputDir, filename+".tex")
 
@@ -65,10 +100,29 @@ func (lg *LaTeXGenerator) GeneratePDF(cvContent string, filename string) (string
 	return pdfFile, nil
 }
 
-// generateLaTeXTemplate generates a basic LaTeX template for CV.
-func generateLaTeXTemplate(cvContent string) string {
+// RenderPDF compiles a CV in a temporary directory and returns the PDF.
+func (lg *LaTeXGenerator) RenderPDF(cvContent, language string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "vibe-cv-render-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pdfFile, err := NewLaTeXGenerator(dir, lg.laTeXPath).GeneratePDF(cvContent, "cv", language)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(pdfFile)
+}
+
+// generateLaTeXTemplate generates a basic LaTeX template for CV, hyphenated
+// with the babel patterns of its language.
+func generateLaTeXTemplate(cvContent, language string) string {
 	template := `\documentclass[11pt,a4paper]{article}
 \usepackage[utf8]{inputenc}
+\usepackage[T1]{fontenc}
+\usepackage[` + i18n.BabelName(language) + `]{babel}
 \usepackage[margin=0.5in]{geometry}
 \usepackage{hyperref}
 \usepackage{xcolor}
@@ -87,10 +141,128 @@ func generateLaTeXTemplate(cvContent string) string {
 
 \begin{document}
 
-\section*{Professional Summary}
+\section*{` + i18n.Title(language, i18n.SectionSummary) + `}
 ` + cvContent + `
 
 \end{document}`
 
 	return template
 }
+
+// generateCoverLetterTemplate generates a LaTeX letter, dated in its
+// language and hyphenated with its babel patterns. The paragraphs of the
+// letter are kept, with the line breaks within them, as in a sign-off.
+func generateCoverLetterTemplate(letter, language string) string {
+	var paragraphs []string
+
+	for _, paragraph := range blankLines.Split(strings.TrimSpace(letter), -1) {
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		for i, line := range lines {
+			lines[i] = latexSpecial.Replace(strings.TrimSpace(line))
+		}
+
+		paragraphs = append(paragraphs, strings.Join(lines, "\\\\\n"))
+	}
+
+	template := `\documentclass[11pt,a4paper]{article}
+\usepackage[utf8]{inputenc}
+\usepackage[T1]{fontenc}
+\usepackage[` + i18n.BabelName(language) + `]{babel}
+\usepackage[margin=1in]{geometry}
+\usepackage{hyperref}
+
+\pagestyle{empty}
+
+\setlength{\parindent}{0pt}
+\setlength{\parskip}{1em}
+
+\begin{document}
+
+\begin{flushright}
+\today
+\end{flushright}
+
+` + strings.Join(paragraphs, "\n\n") + `
+
+\end{document}`
+
+	return template
+}
+
+var (
+	// latexSpecial replaces the characters LaTeX reserves.
+	latexSpecial = strings.NewReplacer(
+		`\`, `\textbackslash{}`, "&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`,
+		"_", `\_`, "{", `\{`, "}", `\}`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`,
+	)
+	// blankLines matches the blank lines between paragraphs.
+	blankLines = regexp.MustCompile(`\n\s*\n`)
+	// markdownBold and markdownItalic match emphasized Markdown text.
+	markdownBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalic = regexp.MustCompile(`\*([^*]+)\*`)
+	// markdownHeadings are the LaTeX commands of Markdown heading levels.
+	markdownHeadings = []string{`\section*`, `\subsection*`, `\subsubsection*`}
+)
+
+// generateMarkdownTemplate generates a LaTeX document from Markdown.
+func generateMarkdownTemplate(markdown, language string) string {
+	var b strings.Builder
+
+	inList := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		item, isItem := strings.CutPrefix(trimmed, "- ")
+		if isItem != inList {
+			if isItem {
+				b.WriteString("\\begin{itemize}\n")
+			} else {
+				b.WriteString("\\end{itemize}\n")
+			}
+
+			inList = isItem
+		}
+
+		level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+
+		switch {
+		case isItem:
+			b.WriteString("\\item " + markdownInline(item) + "\n")
+		case level > 0 && level <= len(markdownHeadings) && strings.HasPrefix(trimmed[level:], " "):
+			b.WriteString(markdownHeadings[level-1] + "{" + markdownInline(strings.TrimSpace(trimmed[level:])) + "}\n")
+		default:
+			b.WriteString(markdownInline(trimmed) + "\n")
+		}
+	}
+
+	if inList {
+		b.WriteString("\\end{itemize}\n")
+	}
+
+	return `\documentclass[11pt,a4paper]{article}
+\usepackage[utf8]{inputenc}
+\usepackage[T1]{fontenc}
+\usepackage[` + i18n.BabelName(language) + `]{babel}
+\usepackage[margin=0.75in]{geometry}
+\usepackage{hyperref}
+
+\pagestyle{plain}
+
+\setlength{\parindent}{0pt}
+\setlength{\parskip}{0.5em}
+
+\begin{document}
+
+` + b.String() + `
+\end{document}`
+}
+
+// markdownInline escapes a line of Markdown and renders its bold and
+// italic text.
+func markdownInline(text string) string {
+	text = latexSpecial.Replace(text)
+	text = markdownBold.ReplaceAllString(text, `\textbf{$1}`)
+
+	return markdownItalic.ReplaceAllString(text, `\textit{$1}`)
+}
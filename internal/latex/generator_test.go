@@ -0,0 +1,34 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package latex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateCoverLetterTemplate(t *testing.T) {
+	letter := "Dear Ms Smith,\n\nI led R&D for C# and C++ tools, with 20% growth, $2M saved and\nthe my_team\\ops rota.\n\nKind regards,\nJane Doe"
+
+	tex := generateCoverLetterTemplate(letter, "en")
+
+	for _, want := range []string{
+		`R\&D`, `C\#`, `20\% growth`, `\$2M`, `my\_team\textbackslash{}ops`,
+		// Paragraphs stay apart, and lines within them break
+		"Dear Ms Smith,\n\nI led",
+		"saved and\\\\\nthe",
+		"Kind regards,\\\\\nJane Doe",
+	} {
+		if !strings.Contains(tex, want) {
+			t.Errorf("template is missing %q:\n%s", want, tex)
+		}
+	}
+
+	body := tex[strings.Index(tex, `\end{flushright}`):]
+	for _, raw := range []string{"R&D", " 20% ", "C# "} {
+		if strings.Contains(body, raw) {
+			t.Errorf("template keeps %q unescaped", raw)
+		}
+	}
+}
@@ -34,7 +34,7 @@ func (p *AnthropicProvider) Customize(ctx context.Context, cv, jobDescription st
 	reqBody := map[string]any{
 		"model":      p.model,
 		"max_tokens": 2000,
-		"system":     systemPrompt,
+		"system":     systemPromptFor(ctx),
 		"messages": []map[string]string{
 			{
 				"role":    "user",
@@ -42,7 +42,7 @@ func (p *GeminiProvider) Customize(ctx context.Context, cv, jobDescription strin
 	// Add system instruction as first message
 	contents = append(contents, &genai.Content{
 		Role:  "user",
-		Parts: []*genai.Part{genai.NewPartFromText(systemPrompt)},
+		Parts: []*genai.Part{genai.NewPartFromText(systemPromptFor(ctx))},
 	})
 
 	// Add the actual prompt
@@ -57,7 +57,7 @@ func (p *GeminiProvider) Customize(ctx context.Context, cv, jobDescription strin
 
 	config := &genai.GenerateContentConfig{
 		SystemInstruction: &genai.Content{
-			Parts: []*genai.Part{genai.NewPartFromText(systemPrompt)},
+			Parts: []*genai.Part{genai.NewPartFromText(systemPromptFor(ctx))},
 		},
 		Temperature: &temp,
 		TopP:        &topP,
@@ -0,0 +1,48 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+)
+
+type languageKey struct{}
+
+// WithLanguage returns a context asking providers to write customized CVs in
+// a language, given as an ISO 639-1 code. English is the default.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// LanguageFrom returns the language set on a context with WithLanguage.
+func LanguageFrom(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && lang != "" {
+		return lang
+	}
+
+	return i18n.English
+}
+
+// systemPromptFor returns the system prompt for the language of a context.
+// CVs not written in English are customized in their own language, with its
+// section headings and conventions.
+func systemPromptFor(ctx context.Context) string {
+	lang := LanguageFrom(ctx)
+	if lang == i18n.English {
+		return systemPrompt
+	}
+
+	name := i18n.Name(lang)
+
+	return systemPrompt + fmt.Sprintf(`
+
+The CV is written in %[1]s. Write the customized CV and the modifications in %[1]s:
+- Do not translate the CV into English, even where the job description is in another language
+- Use the section headings usual in %[1]s CVs, such as "%[2]s" for the summary
+- Keep the date, phone number and address conventions of the original CV
+- Use the %[1]s terms for keywords of the job description that have one, and keep technical terms and product names as they are`, name, i18n.Title(lang, i18n.SectionSummary))
+}
@@ -36,7 +36,7 @@ func (p *OpenAIProvider) Customize(ctx context.Context, cv, jobDescription strin
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
-				Content: systemPrompt,
+				Content: systemPromptFor(ctx),
 			},
 			{
 				Role:    openai.ChatMessageRoleUser,
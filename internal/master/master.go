@@ -0,0 +1,554 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package master builds one master CV for a family of similar jobs. The
+// keywords of every job are extracted by the ATS analyzer and merged through
+// the skills taxonomy, weighted by how many of the jobs ask for them. Bullets
+// and skill lists of the CV are then reordered so that what most jobs ask for
+// comes first, and experience bullets no job asks for are dropped. Finally
+// the LLM works in the keywords the CV still misses where its experience
+// supports them. Every step is checked with an ATS analysis for each job: a
+// step that would lower the score of any job is left out.
+package master
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/ats"
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/llm"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+)
+
+// Actions decided for a line of the CV.
+const (
+	ActionKeep    = "keep"
+	ActionReorder = "reorder"
+	ActionDrop    = "drop"
+)
+
+const (
+	// minBullets is how many bullets of an experience list are kept, even
+	// when no job asks for them.
+	minBullets = 2
+	// minListItems is how many comma-separated items make a line a list of
+	// skills.
+	minListItems = 3
+	// maxListItem is the longest item of a list of skills, in characters.
+	maxListItem = 40
+)
+
+// bullet matches the marker of a bullet line.
+var bullet = regexp.MustCompile(`^\s*[-*•·▪–]\s+`)
+
+// Job is one of the jobs the master CV is built for.
+type Job struct {
+	SavedJobID  int    `json:"saved_job_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"-"`
+}
+
+// Keyword is a keyword of the jobs with how many of them ask for it.
+type Keyword struct {
+	Keyword string  `json:"keyword"`
+	Jobs    int     `json:"jobs"`
+	Weight  float64 `json:"weight"` // Share of the jobs asking for it
+}
+
+// Decision is what was decided for a bullet or skill list of the CV.
+type Decision struct {
+	Action    string   `json:"action"`
+	Line      string   `json:"line"`                // As in the base CV
+	Reordered string   `json:"reordered,omitempty"` // A skill list with its items reordered
+	Keywords  []string `json:"keywords"`            // Keywords of the jobs it matches
+	Relevance float64  `json:"relevance"`           // Sum of the weights of its keywords
+}
+
+// JobDelta is how the master CV compares with the base CV for one job.
+type JobDelta struct {
+	Job
+	ScoreBefore    float64    `json:"score_before"` // ATS overall scores
+	ScoreAfter     float64    `json:"score_after"`
+	CoverageBefore float64    `json:"coverage_before"` // Share of the job's keywords matched
+	CoverageAfter  float64    `json:"coverage_after"`
+	Change         ats.Change `json:"change"`
+	Missing        []string   `json:"missing_keywords"` // What a variant for this job alone could still add
+}
+
+// Result is a master CV with how it was built.
+type Result struct {
+	CV             string     `json:"master_cv"`
+	Profile        string     `json:"ats_profile"`
+	Keywords       []Keyword  `json:"keywords"`        // Most asked for first
+	Decisions      []Decision `json:"decisions"`       // In the order of the base CV
+	CoverageBefore float64    `json:"coverage_before"` // Averages over the jobs
+	CoverageAfter  float64    `json:"coverage_after"`
+	Jobs           []JobDelta `json:"jobs"`
+	GeneratedBy    string     `json:"generated_by"` // LLM provider, or "plan" when its rewrite was not kept
+}
+
+// Optimizer builds master CVs.
+type Optimizer struct {
+	analyzer *ats.Analyzer
+	provider llm.Provider
+}
+
+// NewOptimizer creates a master CV optimizer.
+func NewOptimizer(provider llm.Provider) *Optimizer {
+	return &Optimizer{analyzer: ats.NewAnalyzer(provider), provider: provider}
+}
+
+// JobDescription joins the descriptions of jobs into one text, as the job
+// description of a master CV version.
+func JobDescription(jobs []Job) string {
+	descriptions := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		descriptions = append(descriptions, strings.TrimSpace(job.Description))
+	}
+
+	return strings.Join(descriptions, "\n\n---\n\n")
+}
+
+// evaluation is the ATS analyses of a CV for every job.
+type evaluation struct {
+	cv       string
+	analyses []*ats.ATSAnalysisResult
+}
+
+// coverage returns the average share of the jobs' keywords the CV matches.
+func (e *evaluation) coverage() float64 {
+	total := 0.0
+	for _, a := range e.analyses {
+		total += keywordCoverage(a)
+	}
+
+	return total / float64(len(e.analyses))
+}
+
+// lowers reports whether a CV scores lower than a base one for any job.
+func (e *evaluation) lowers(base *evaluation) bool {
+	for i, a := range e.analyses {
+		if a.OverallScore < base.analyses[i].OverallScore {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keywordCoverage is the share of a job's keywords an analysis matched.
+func keywordCoverage(a *ats.ATSAnalysisResult) float64 {
+	total := len(a.KeywordMatches.Matched) + len(a.KeywordMatches.Missing)
+	if total == 0 {
+		return 0
+	}
+
+	return float64(len(a.KeywordMatches.Matched)) / float64(total)
+}
+
+// Optimize builds a master CV from a base CV for jobs, analyzed as the ATS
+// of profile would; nil is the generic profile.
+func (o *Optimizer) Optimize(ctx context.Context, cv string, jobs []Job, profile *ats.Profile) (*Result, error) {
+	if strings.TrimSpace(cv) == "" {
+		return nil, fmt.Errorf("CV has no text to optimize")
+	}
+
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("at least one job is required")
+	}
+
+	if profile == nil {
+		profile, _ = ats.LookupProfile(ats.GenericProfile)
+	}
+
+	jobKeywords := make([][]string, len(jobs))
+	for i, job := range jobs {
+		keywords, err := o.keywords(ctx, job.Description)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract keywords of job %d: %w", i+1, err)
+		}
+
+		jobKeywords[i] = keywords
+	}
+
+	evaluate := func(text string) (*evaluation, error) {
+		e := &evaluation{cv: text}
+
+		for i, job := range jobs {
+			a, err := o.analyzer.AnalyzeCVWith(ctx, text, job.Description, ats.Options{Profile: profile, Keywords: jobKeywords[i]})
+			if err != nil {
+				return nil, err
+			}
+
+			e.analyses = append(e.analyses, a)
+		}
+
+		return e, nil
+	}
+
+	base, err := evaluate(cv)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Profile: profile.Name, Keywords: merge(jobKeywords), GeneratedBy: "plan"}
+
+	// Drop nothing when dropping would cost any job
+	planned, decisions := o.plan(cv, result.Keywords, true)
+
+	best, err := evaluate(planned)
+	if err != nil {
+		return nil, err
+	}
+
+	if best.lowers(base) {
+		planned, decisions = o.plan(cv, result.Keywords, false)
+
+		if best, err = evaluate(planned); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Decisions = decisions
+
+	if missing := missingKeywords(best, result.Keywords); len(missing) > 0 {
+		// Keep the rewrite only when it matches more without costing any job;
+		// when it fails, the plan is a master CV on its own
+		rewritten, err := o.rewrite(ctx, best.cv, JobDescription(jobs), missing)
+		if err != nil {
+			fmt.Printf("Master CV rewrite failed, keeping the plan: %v\n", err)
+		} else if candidate, err := evaluate(rewritten); err == nil && candidate.coverage() > best.coverage() && !candidate.lowers(base) {
+			best = candidate
+			result.GeneratedBy = o.provider.GetName()
+		}
+	}
+
+	result.CV = best.cv
+	result.CoverageBefore = base.coverage()
+	result.CoverageAfter = best.coverage()
+
+	for i, job := range jobs {
+		before, after := base.analyses[i], best.analyses[i]
+		result.Jobs = append(result.Jobs, JobDelta{
+			Job:            job,
+			ScoreBefore:    before.OverallScore,
+			ScoreAfter:     after.OverallScore,
+			CoverageBefore: keywordCoverage(before),
+			CoverageAfter:  keywordCoverage(after),
+			Change:         ats.Compare(before, after),
+			Missing:        after.KeywordMatches.Missing,
+		})
+	}
+
+	return result, nil
+}
+
+// keywords returns the keywords of a job: those the ATS analyzer extracts
+// and the skills of the taxonomy it names, each once.
+func (o *Optimizer) keywords(ctx context.Context, jobDescription string) ([]string, error) {
+	extracted, err := o.analyzer.ExtractKeywords(ctx, jobDescription)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomy := skills.Default()
+	keywords := []string{}
+	seen := make(map[string]bool)
+
+	for _, skill := range taxonomy.Find(jobDescription) {
+		extracted = append(extracted, strings.ToLower(skill.Name))
+	}
+
+	for _, keyword := range extracted {
+		if k := key(keyword); !seen[k] {
+			seen[k] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	return keywords, nil
+}
+
+// key identifies a keyword across jobs, so that "k8s" and "kubernetes" are
+// one keyword.
+func key(keyword string) string {
+	return strings.ToLower(skills.Default().Canonical(keyword))
+}
+
+// merge weighs the keywords of the jobs by how many of them ask for each,
+// most asked for first.
+func merge(jobKeywords [][]string) []Keyword {
+	var merged []Keyword
+
+	index := make(map[string]int)
+
+	for _, keywords := range jobKeywords {
+		for _, keyword := range keywords {
+			i, ok := index[key(keyword)]
+			if !ok {
+				i = len(merged)
+				index[key(keyword)] = i
+				merged = append(merged, Keyword{Keyword: keyword})
+			}
+
+			merged[i].Jobs++
+		}
+	}
+
+	for i := range merged {
+		merged[i].Weight = float64(merged[i].Jobs) / float64(len(jobKeywords))
+	}
+
+	slices.SortStableFunc(merged, func(a, b Keyword) int {
+		return b.Jobs - a.Jobs
+	})
+
+	return merged
+}
+
+// relevance returns the keywords a text matches and the sum of their
+// weights.
+func (o *Optimizer) relevance(text string, keywords []Keyword) ([]string, float64) {
+	names := make([]string, len(keywords))
+	for i, k := range keywords {
+		names[i] = k.Keyword
+	}
+
+	_, matched := o.analyzer.CalculateKeywordMatch(text, names)
+
+	found := []string{}
+	total := 0.0
+
+	for _, k := range keywords {
+		if matched[k.Keyword] {
+			found = append(found, k.Keyword)
+			total += k.Weight
+		}
+	}
+
+	return found, total
+}
+
+// line is a line of the CV being planned.
+type line struct {
+	text      string
+	decision  int // Index of its decision; -1 for lines kept as they are
+	relevance float64
+	drop      bool
+}
+
+// plan reorders the bullets of every list of the CV and the items of every
+// skill list by relevance, and drops the experience bullets no job asks for
+// when drop is set, keeping at least minBullets of each list. Other lines
+// are kept as they are.
+func (o *Optimizer) plan(cv string, keywords []Keyword, drop bool) (string, []Decision) {
+	decisions := []Decision{}
+	lines := []line{}
+	section := i18n.SectionUnknown
+
+	for _, text := range strings.Split(cv, "\n") {
+		l := line{text: text, decision: -1}
+		isBullet := bullet.MatchString(text)
+
+		if !isBullet && strings.TrimSpace(text) != "" {
+			if s := i18n.HeadingLine(text); s != i18n.SectionUnknown {
+				section = s
+			}
+		}
+
+		if reordered, ok := o.reorderList(text, keywords); ok || isBullet {
+			d := Decision{Action: ActionKeep, Line: text}
+			d.Keywords, d.Relevance = o.relevance(text, keywords)
+
+			if ok && reordered != text {
+				d.Action = ActionReorder
+				d.Reordered = reordered
+				l.text = reordered
+			}
+
+			// Only experience bullets are dropped; education and the like
+			// stay whatever the jobs ask for
+			l.drop = drop && isBullet && section == i18n.SectionExperience && d.Relevance == 0
+			l.relevance = d.Relevance
+			l.decision = len(decisions)
+			decisions = append(decisions, d)
+		}
+
+		lines = append(lines, l)
+	}
+
+	var out []string
+
+	for start := 0; start < len(lines); {
+		if !bullet.MatchString(lines[start].text) {
+			out = append(out, lines[start].text)
+			start++
+
+			continue
+		}
+
+		end := start
+		for end < len(lines) && bullet.MatchString(lines[end].text) {
+			end++
+		}
+
+		out = append(out, arrange(lines[start:end], decisions)...)
+		start = end
+	}
+
+	return strings.Join(out, "\n"), decisions
+}
+
+// arrange orders a list of bullets by relevance, most relevant first, and
+// drops those marked for dropping beyond the first minBullets kept.
+func arrange(list []line, decisions []Decision) []string {
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case list[a].relevance > list[b].relevance:
+			return -1
+		case list[a].relevance < list[b].relevance:
+			return 1
+		}
+
+		return 0
+	})
+
+	kept := []int{}
+
+	for _, i := range order {
+		if list[i].drop && len(kept) >= minBullets {
+			decisions[list[i].decision].Action = ActionDrop
+
+			continue
+		}
+
+		kept = append(kept, i)
+	}
+
+	// A bullet moved when its place among the kept ones changed, not when
+	// bullets above it were dropped
+	original := slices.Clone(kept)
+	slices.Sort(original)
+
+	out := make([]string, 0, len(kept))
+
+	for position, i := range kept {
+		if d := &decisions[list[i].decision]; d.Action == ActionKeep && original[position] != i {
+			d.Action = ActionReorder
+		}
+
+		out = append(out, list[i].text)
+	}
+
+	return out
+}
+
+// reorderList reorders the items of a list of skills, such as "Skills: Go,
+// Docker, SQL", most relevant first. It reports false for lines that are not
+// lists of skills: at least minListItems short items, half of which the
+// taxonomy knows.
+func (o *Optimizer) reorderList(text string, keywords []Keyword) (string, bool) {
+	prefix := bullet.FindString(text)
+	rest := text[len(prefix):]
+
+	if i := strings.Index(rest, ":"); i >= 0 && !strings.Contains(rest[:i], ",") {
+		prefix += rest[:i+1] + " "
+		rest = rest[i+1:]
+	}
+
+	items := strings.Split(rest, ",")
+	if len(items) < minListItems {
+		return "", false
+	}
+
+	known := 0
+	relevance := make(map[string]float64)
+
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+		if items[i] == "" || len(items[i]) > maxListItem {
+			return "", false
+		}
+
+		if _, ok := skills.Default().Resolve(items[i]); ok {
+			known++
+		}
+
+		_, relevance[items[i]] = o.relevance(items[i], keywords)
+	}
+
+	if known*2 < len(items) {
+		return "", false
+	}
+
+	ordered := slices.Clone(items)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		switch {
+		case relevance[a] > relevance[b]:
+			return -1
+		case relevance[a] < relevance[b]:
+			return 1
+		}
+
+		return 0
+	})
+
+	if slices.Equal(ordered, items) {
+		return text, true
+	}
+
+	return prefix + strings.Join(ordered, ", "), true
+}
+
+// missingKeywords returns the keywords of the jobs a CV does not match for
+// any job that asks for them, most asked for first.
+func missingKeywords(e *evaluation, keywords []Keyword) []Keyword {
+	missing := make(map[string]bool)
+
+	for _, a := range e.analyses {
+		for _, keyword := range a.KeywordMatches.Missing {
+			missing[key(keyword)] = true
+		}
+	}
+
+	var out []Keyword
+
+	for _, k := range keywords {
+		if missing[key(k.Keyword)] {
+			out = append(out, k)
+		}
+	}
+
+	return out
+}
+
+// rewrite asks the LLM to work the missing keywords into the planned CV
+// where its experience supports them, without undoing the plan.
+func (o *Optimizer) rewrite(ctx context.Context, cv, jobDescription string, missing []Keyword) (string, error) {
+	instructions := []string{
+		"This CV is a master version for several similar jobs, whose descriptions are separated by \"---\". " +
+			"Its content is already ordered by how many of the jobs ask for it: keep that order, and do not add back removed content.",
+		"Work in the following keywords, most asked for first, only where the CV's experience supports them; never invent experience. " +
+			"Leave out the keywords the CV gives no ground for.",
+	}
+
+	for _, k := range missing {
+		instructions = append(instructions, fmt.Sprintf("- %s (%d of the jobs)", k.Keyword, k.Jobs))
+	}
+
+	resp, err := o.provider.Customize(llm.WithLanguage(ctx, i18n.Detect(cv)), cv, jobDescription, instructions)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ModifiedCV, nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package master
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sammyoina/vibe-cv/internal/llm"
+)
+
+// fakeProvider extracts no keywords beyond the taxonomy's, and rewrites a
+// CV by appending a line.
+type fakeProvider struct {
+	appended string
+	context  []string
+	err      error // Returned by rewrites
+}
+
+func (p *fakeProvider) Customize(_ context.Context, cv, _ string, additionalContext []string) (*llm.CustomizationResponse, error) {
+	if cv == "" {
+		return &llm.CustomizationResponse{}, nil
+	}
+
+	p.context = additionalContext
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return &llm.CustomizationResponse{ModifiedCV: cv + p.appended}, nil
+}
+
+func (p *fakeProvider) GetName() string {
+	return "fake"
+}
+
+const testCV = `Jane Doe
+Backend Engineer
+
+Work Experience:
+Senior Backend Engineer
+Acme Corp
+2020 - Present
+- Organized the yearly office party
+- Built Go microservices on Kubernetes
+- Ran the team book club
+- Tuned PostgreSQL queries for reporting
+
+Backend Engineer
+Beta Ltd
+2017 - 2019
+- Operated Kubernetes clusters
+- Deployed Docker images built with Go tooling
+
+Skills:
+Excel, Docker, Go, Kubernetes
+
+Education:
+BS in Computer Science
+University of State
+2012 - 2016`
+
+var testJobs = []Job{
+	{Title: "Platform Engineer", Description: "Requirements:\n- Go\n- Kubernetes\n- Docker"},
+	{Title: "Backend Engineer", Description: "Requirements:\n- Go\n- PostgreSQL\n- Kafka"},
+}
+
+func TestOptimize(t *testing.T) {
+	provider := &fakeProvider{appended: "\n- Streamed order events through Kafka"}
+
+	result, err := NewOptimizer(provider).Optimize(context.Background(), testCV, testJobs, nil)
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+
+	if k := result.Keywords[0]; k.Keyword != "go" || k.Jobs != 2 || k.Weight != 1 {
+		t.Errorf("first keyword = %+v, want go for both jobs", k)
+	}
+
+	// What both jobs ask for comes first, in bullets and in the skill list
+	for _, order := range [][]string{
+		{"Built Go microservices on Kubernetes", "Tuned PostgreSQL queries"},
+		{"Deployed Docker images", "Operated Kubernetes clusters"},
+		{"Go, Docker, Kubernetes, Excel"},
+	} {
+		if i := strings.Index(result.CV, order[0]); i == -1 || (len(order) > 1 && strings.Index(result.CV, order[1]) < i) {
+			t.Errorf("master CV does not put %q first:\n%s", order[0], result.CV)
+		}
+	}
+
+	actions := make(map[string]string)
+	for _, d := range result.Decisions {
+		actions[d.Line] = d.Action
+	}
+
+	if got := actions["- Deployed Docker images built with Go tooling"]; got != ActionReorder {
+		t.Errorf("moved bullet action = %q, want reorder", got)
+	}
+
+	// A bullet that only rises as others are dropped keeps its place
+	if got := actions["- Built Go microservices on Kubernetes"]; got != ActionKeep {
+		t.Errorf("relevant bullet action = %q, want keep", got)
+	}
+
+	if got := actions["- Ran the team book club"]; got != ActionDrop || strings.Contains(result.CV, "book club") {
+		t.Errorf("irrelevant bullet action = %q, want drop", got)
+	}
+
+	if strings.Contains(result.CV, "office party") {
+		t.Errorf("master CV keeps an irrelevant bullet:\n%s", result.CV)
+	}
+
+	// The rewrite is kept as it adds Kafka without costing either job
+	if result.GeneratedBy != "fake" || result.CoverageAfter <= result.CoverageBefore {
+		t.Errorf("generated by %q, coverage %.2f -> %.2f", result.GeneratedBy, result.CoverageBefore, result.CoverageAfter)
+	}
+
+	if prompt := strings.Join(provider.context, "\n"); !strings.Contains(prompt, "- kafka (1 of the jobs)") {
+		t.Errorf("rewrite prompt lacks the missing keyword:\n%s", prompt)
+	}
+
+	if len(result.Jobs) != 2 {
+		t.Fatalf("jobs = %+v", result.Jobs)
+	}
+
+	if delta := result.Jobs[1]; len(delta.Change.KeywordsGained) != 1 || delta.Change.KeywordsGained[0] != "kafka" || len(delta.Missing) != 0 {
+		t.Errorf("second job delta = %+v", delta)
+	}
+
+	for _, delta := range result.Jobs {
+		if delta.ScoreAfter < delta.ScoreBefore {
+			t.Errorf("%s scores %.3f -> %.3f", delta.Title, delta.ScoreBefore, delta.ScoreAfter)
+		}
+	}
+}
+
+func TestOptimize_RewriteRejected(t *testing.T) {
+	// A rewrite that matches nothing more is not kept
+	result, err := NewOptimizer(&fakeProvider{appended: "\n- Enjoyed hiking"}).Optimize(context.Background(), testCV, testJobs, nil)
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+
+	if result.GeneratedBy != "plan" || strings.Contains(result.CV, "hiking") {
+		t.Errorf("generated by %q:\n%s", result.GeneratedBy, result.CV)
+	}
+
+	if result.CoverageAfter != result.CoverageBefore {
+		t.Errorf("coverage %.2f -> %.2f, want unchanged", result.CoverageBefore, result.CoverageAfter)
+	}
+}
+
+func TestOptimize_RewriteFailed(t *testing.T) {
+	// The plan is kept when the LLM fails to rewrite it
+	provider := &fakeProvider{err: errors.New("rate limited")}
+
+	result, err := NewOptimizer(provider).Optimize(context.Background(), testCV, testJobs, nil)
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+
+	if result.GeneratedBy != "plan" || len(result.Decisions) == 0 || strings.Contains(result.CV, "book club") {
+		t.Errorf("generated by %q, %d decisions:\n%s", result.GeneratedBy, len(result.Decisions), result.CV)
+	}
+
+	if provider.context == nil {
+		t.Error("Expected a rewrite to be attempted")
+	}
+}
+
+func TestOptimize_Errors(t *testing.T) {
+	optimizer := NewOptimizer(&fakeProvider{})
+
+	if _, err := optimizer.Optimize(context.Background(), " ", testJobs, nil); err == nil {
+		t.Error("Expected an error for an empty CV")
+	}
+
+	if _, err := optimizer.Optimize(context.Background(), testCV, nil, nil); err == nil {
+		t.Error("Expected an error without jobs")
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// Embedding backends.
+const (
+	BackendLocal  = "local"
+	BackendOpenAI = "openai"
+	BackendGemini = "gemini"
+	BackendOllama = "ollama"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// close their meanings are.
+type Embedder interface {
+	// Name identifies the backend and model in reports, e.g. "local".
+	Name() string
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Range returns the similarity at or below which two texts are unrelated
+	// and the one at or above which they say the same, for this model.
+	Range() (low, high float64)
+}
+
+// NewEmbedder creates the embedding backend with a name. The model defaults
+// per backend; the URL is the base URL of an Ollama server.
+func NewEmbedder(ctx context.Context, backend, apiKey, model, url string) (Embedder, error) {
+	switch backend {
+	case "", BackendLocal:
+		return NewLocalEmbedder(), nil
+	case BackendOpenAI:
+		if apiKey == "" {
+			return nil, errors.New("OpenAI API key is required for embeddings")
+		}
+
+		return NewOpenAIEmbedder(apiKey, model), nil
+	case BackendGemini:
+		if apiKey == "" {
+			return nil, errors.New("gemini API key is required for embeddings")
+		}
+
+		return NewGeminiEmbedder(ctx, apiKey, model)
+	case BackendOllama:
+		return NewOllamaEmbedder(url, model), nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding backend: %s", backend)
+	}
+}
+
+// localDims is the number of dimensions of local embeddings.
+const localDims = 1024
+
+// Feature weights of local embeddings. Skills weigh most, so that "K8s" and
+// "Kubernetes" embed close; character trigrams catch other word forms.
+const (
+	weightSkill    = 3.0
+	weightAncestor = 1.0
+	weightWord     = 1.0
+	weightBigram   = 0.5
+	weightTrigram  = 0.15
+)
+
+// stopWords are common English words left out of local embeddings.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"our": true, "the": true, "to": true, "we": true, "will": true, "with": true, "you": true,
+	"your": true, "experience": true, "years": true, "year": true, "strong": true, "ability": true,
+	"knowledge": true, "working": true, "skills": true, "plus": true,
+}
+
+// LocalEmbedder embeds texts without a model or network: words, word pairs,
+// character trigrams and the skills of the taxonomy mentioned in a text are
+// hashed into a fixed number of dimensions. It is deterministic, so scores
+// are reproducible across runs and machines.
+type LocalEmbedder struct {
+	taxonomy *skills.Taxonomy
+}
+
+// NewLocalEmbedder creates a local embedder using the default skills taxonomy.
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{taxonomy: skills.Default()}
+}
+
+// Name returns the backend name.
+func (e *LocalEmbedder) Name() string {
+	return BackendLocal
+}
+
+// Range returns the similarity range of local embeddings.
+func (e *LocalEmbedder) Range() (float64, float64) {
+	return 0.1, 0.5
+}
+
+// Embed embeds each text.
+func (e *LocalEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+
+	return vectors, nil
+}
+
+// embed embeds one text as a unit vector, or a zero vector if it has no
+// features.
+func (e *LocalEmbedder) embed(text string) []float32 {
+	v := make([]float64, localDims)
+
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+
+		// The top bit picks the sign, so that collisions cancel out on average
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+
+		v[sum%localDims] += weight
+	}
+
+	for _, s := range e.taxonomy.Find(text) {
+		add("skill:"+s.ID, weightSkill)
+
+		for _, ancestor := range e.taxonomy.Ancestors(s.ID) {
+			add("skill:"+ancestor, weightAncestor)
+		}
+	}
+
+	words := strings.FieldsFunc(i18n.Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	var kept []string
+
+	for _, word := range words {
+		if !stopWords[word] {
+			kept = append(kept, stem(word))
+		}
+	}
+
+	for i, word := range kept {
+		add("w:"+word, weightWord)
+
+		if i > 0 {
+			add("b:"+kept[i-1]+" "+word, weightBigram)
+		}
+
+		padded := []rune("^" + word + "$")
+		for j := 0; j+3 <= len(padded); j++ {
+			add("t:"+string(padded[j:j+3]), weightTrigram)
+		}
+	}
+
+	return normalize(v)
+}
+
+// stem strips common English suffixes of longer words, so that "designing"
+// and "designed" share the features of "design".
+func stem(word string) string {
+	if len(word) <= 4 {
+		return word
+	}
+
+	for _, suffix := range []string{"ations", "ation", "ments", "ment", "ings", "ing", "ies", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+
+	return word
+}
+
+// normalize scales a vector to unit length.
+func normalize(v []float64) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = float32(x / norm)
+	}
+
+	return out
+}
+
+// OpenAIEmbedder embeds texts with the OpenAI embeddings API.
+type OpenAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIEmbedder creates an OpenAI embedder; the model defaults to
+// text-embedding-3-small.
+func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
+	if model == "" {
+		model = string(openai.SmallEmbedding3)
+	}
+
+	return &OpenAIEmbedder{client: openai.NewClient(apiKey), model: model}
+}
+
+// Name returns the backend and model.
+func (e *OpenAIEmbedder) Name() string {
+	return BackendOpenAI + "/" + e.model
+}
+
+// Range returns the similarity range of OpenAI embeddings.
+func (e *OpenAIEmbedder) Range() (float64, float64) {
+	return 0.25, 0.65
+}
+
+// Embed embeds the texts in one request.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed with OpenAI: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+
+	return checkVectors(vectors)
+}
+
+// GeminiEmbedder embeds texts with the Gemini embeddings API.
+type GeminiEmbedder struct {
+	client *genai.Client
+	model  string
+}
+
+// NewGeminiEmbedder creates a Gemini embedder; the model defaults to
+// gemini-embedding-001.
+func NewGeminiEmbedder(ctx context.Context, apiKey, model string) (*GeminiEmbedder, error) {
+	if model == "" {
+		model = "gemini-embedding-001"
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	return &GeminiEmbedder{client: client, model: model}, nil
+}
+
+// Name returns the backend and model.
+func (e *GeminiEmbedder) Name() string {
+	return BackendGemini + "/" + e.model
+}
+
+// Range returns the similarity range of Gemini embeddings.
+func (e *GeminiEmbedder) Range() (float64, float64) {
+	return 0.45, 0.8
+}
+
+// Embed embeds the texts in one request.
+func (e *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+
+	resp, err := e.client.Models.EmbedContent(ctx, e.model, contents, &genai.EmbedContentConfig{TaskType: "SEMANTIC_SIMILARITY"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed with Gemini: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, emb := range resp.Embeddings {
+		if i < len(vectors) && emb != nil {
+			vectors[i] = emb.Values
+		}
+	}
+
+	return checkVectors(vectors)
+}
+
+// OllamaEmbedder embeds texts with a model served by a local Ollama server,
+// such as nomic-embed-text.
+type OllamaEmbedder struct {
+	client *http.Client
+	url    string
+	model  string
+}
+
+// NewOllamaEmbedder creates an Ollama embedder. The URL defaults to
+// http://localhost:11434 and the model to nomic-embed-text.
+func NewOllamaEmbedder(url, model string) *OllamaEmbedder {
+	if url == "" {
+		url = "http://localhost:11434"
+	}
+
+	if model == "" {
+		model = "nomic-embed-text"
+	}
+
+	return &OllamaEmbedder{
+		client: &http.Client{Timeout: 60 * time.Second},
+		url:    strings.TrimSuffix(url, "/"),
+		model:  model,
+	}
+}
+
+// Name returns the backend and model.
+func (e *OllamaEmbedder) Name() string {
+	return BackendOllama + "/" + e.model
+}
+
+// Range returns the similarity range of local sentence embedding models.
+func (e *OllamaEmbedder) Range() (float64, float64) {
+	return 0.4, 0.75
+}
+
+// Embed embeds the texts in one request to the /api/embed endpoint.
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed with Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return nil, fmt.Errorf("failed to embed with Ollama: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama embeddings: %w", err)
+	}
+
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d texts", len(out.Embeddings), len(texts))
+	}
+
+	return checkVectors(out.Embeddings)
+}
+
+// checkVectors reports an error if a backend left a text without a vector.
+func checkVectors(vectors [][]float32) ([][]float32, error) {
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no embedding returned for text %d", i)
+		}
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,242 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package match scores how well a CV matches a job: requirements of the job
+// and passages of the CV are embedded through a pluggable backend, and each
+// requirement is covered by its closest passage. The score is the weighted
+// coverage of the requirements, so it is reproducible and explainable.
+package match
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/sammyoina/vibe-cv/internal/input"
+	"github.com/sammyoina/vibe-cv/internal/skills"
+	"github.com/sammyoina/vibe-cv/internal/types"
+)
+
+// Requirement kinds.
+const (
+	KindRequired  = "required"
+	KindPreferred = "preferred"
+)
+
+// Limits on what is embedded for one score.
+const (
+	maxRequirements  = 40
+	maxPassages      = 300
+	maxPassageLength = 400
+)
+
+// kindWeights weighs preferred requirements at half of required ones.
+var kindWeights = map[string]float64{KindRequired: 1, KindPreferred: 0.5}
+
+// ErrNoCV is returned when a CV has no text to match.
+var ErrNoCV = errors.New("CV has no text to match")
+
+// Result is the match of a CV with a job.
+type Result struct {
+	Score   float64 // Weighted coverage of the requirements, 0 to 1
+	Details types.MatchDetails
+}
+
+// Scorer scores CVs against jobs with an embedding backend.
+type Scorer struct {
+	embedder Embedder
+	parser   *input.EnhancedParser
+}
+
+// NewScorer creates a scorer with an embedding backend.
+func NewScorer(embedder Embedder) *Scorer {
+	return &Scorer{embedder: embedder, parser: input.NewEnhancedParser()}
+}
+
+// Name returns the name of the scorer's embedding backend.
+func (s *Scorer) Name() string {
+	return s.embedder.Name()
+}
+
+// Score matches a CV with a job description.
+func (s *Scorer) Score(ctx context.Context, cv, jobDescription string) (*Result, error) {
+	passages := cvPassages(cv)
+	if len(passages) == 0 {
+		return nil, ErrNoCV
+	}
+
+	reqs := s.requirements(jobDescription)
+
+	texts := make([]string, 0, len(reqs)+len(passages))
+	for _, r := range reqs {
+		texts = append(texts, r.Requirement)
+	}
+
+	texts = append(texts, passages...)
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	low, high := s.embedder.Range()
+
+	var total, weights float64
+
+	for i := range reqs {
+		best, bestSim := -1, math.Inf(-1)
+
+		for j := range passages {
+			if sim := cosine(vectors[i], vectors[len(reqs)+j]); sim > bestSim {
+				best, bestSim = j, sim
+			}
+		}
+
+		r := &reqs[i]
+		r.Similarity = round(bestSim, 3)
+		r.Coverage = round(math.Min(1, math.Max(0, (bestSim-low)/(high-low))), 3)
+		r.Covered = r.Coverage >= 0.5
+
+		if r.Coverage > 0 {
+			r.Evidence = passages[best]
+		}
+
+		total += kindWeights[r.Kind] * r.Coverage
+		weights += kindWeights[r.Kind]
+	}
+
+	result := &Result{Details: types.MatchDetails{Embedder: s.embedder.Name(), Requirements: reqs}}
+	if weights > 0 {
+		result.Score = round(total/weights, 2)
+	}
+
+	return result, nil
+}
+
+// requirements returns the requirements of a job: its requirement and
+// preferred skill lists, or else the lines that mention a skill, or else the
+// whole description.
+func (s *Scorer) requirements(jobDescription string) []types.RequirementMatch {
+	var reqs []types.RequirementMatch
+
+	add := func(text, kind string) {
+		text = trimItem(text)
+		if text == "" || len(reqs) >= maxRequirements || slices.ContainsFunc(reqs, func(r types.RequirementMatch) bool {
+			return strings.EqualFold(r.Requirement, text)
+		}) {
+			return
+		}
+
+		reqs = append(reqs, types.RequirementMatch{Requirement: text, Kind: kind})
+	}
+
+	job := s.parser.ParseJobDescription(jobDescription)
+	for _, r := range job.Requirements {
+		add(r, KindRequired)
+	}
+
+	for _, r := range job.PreferredSkills {
+		add(r, KindPreferred)
+	}
+
+	if len(reqs) == 0 {
+		taxonomy := skills.Default()
+
+		for _, line := range strings.Split(jobDescription, "\n") {
+			if len(taxonomy.Find(line)) > 0 {
+				add(line, KindRequired)
+			}
+		}
+	}
+
+	if len(reqs) == 0 {
+		add(strings.Join(strings.Fields(jobDescription), " "), KindRequired)
+	}
+
+	return reqs
+}
+
+// cvPassages splits a CV into the passages requirements are matched with:
+// its lines, with long lines split into sentences.
+func cvPassages(cv string) []string {
+	var passages []string
+
+	for _, line := range strings.Split(cv, "\n") {
+		line = trimItem(line)
+		if len([]rune(line)) < 3 {
+			continue
+		}
+
+		if len(line) <= maxPassageLength {
+			passages = append(passages, line)
+		} else {
+			passages = append(passages, sentences(line)...)
+		}
+
+		if len(passages) >= maxPassages {
+			return passages[:maxPassages]
+		}
+	}
+
+	return passages
+}
+
+// sentences splits a text after sentence ends.
+func sentences(text string) []string {
+	var out []string
+
+	start := 0
+
+	for i := 0; i < len(text); i++ {
+		if strings.ContainsRune(".!?;", rune(text[i])) && (i+1 == len(text) || text[i+1] == ' ') {
+			if s := strings.TrimSpace(text[start : i+1]); len(s) >= 3 {
+				out = append(out, s)
+			}
+
+			start = i + 1
+		}
+	}
+
+	if s := strings.TrimSpace(text[start:]); len(s) >= 3 {
+		out = append(out, s)
+	}
+
+	return out
+}
+
+// trimItem trims spaces and list markers around a line.
+func trimItem(s string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(s), "-*•·–"))
+}
+
+// cosine returns the cosine similarity of two vectors, or zero if either is
+// zero or their lengths differ.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, na, nb float64
+
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return dot / math.Sqrt(na*nb)
+}
+
+// round rounds x to a number of decimals, so that scores do not depend on
+// the last bits of floating point sums.
+func round(x float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+
+	return math.Round(x*p) / p
+}
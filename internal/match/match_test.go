@@ -0,0 +1,132 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testCV = `Jane Doe
+Senior Backend Engineer
+
+Experience
+- Built Golang microservices handling 2M requests per day
+- Operated K8s clusters on AWS with Terraform
+- Designed Postgres schemas and tuned slow queries
+- Mentored four junior engineers
+
+Skills: Go, Kubernetes, PostgreSQL, Docker, Terraform`
+
+const testJob = `Backend Engineer
+
+Requirements:
+- Strong Go programming skills
+- Experience running Kubernetes in production
+- SQL database design
+- Experience with Kafka event streaming
+
+Preferred Skills:
+- Mentoring junior developers`
+
+func TestScorer_Local(t *testing.T) {
+	scorer := NewScorer(NewLocalEmbedder())
+
+	result, err := scorer.Score(context.Background(), testCV, testJob)
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+
+	covered := make(map[string]bool)
+	for _, r := range result.Details.Requirements {
+		covered[r.Requirement] = r.Covered
+
+		if r.Covered && r.Evidence == "" {
+			t.Errorf("Expected evidence for covered requirement %q", r.Requirement)
+		}
+	}
+
+	for req, want := range map[string]bool{
+		"Strong Go programming skills":                true,
+		"Experience running Kubernetes in production": true,
+		"SQL database design":                         true,
+		"Experience with Kafka event streaming":       false,
+		"Mentoring junior developers":                 true,
+	} {
+		if got, ok := covered[req]; !ok || got != want {
+			t.Errorf("Requirement %q covered = %t (found %t), want %t", req, got, ok, want)
+		}
+	}
+
+	if result.Score <= 0.5 || result.Score >= 1 {
+		t.Errorf("Score = %v, want between 0.5 and 1", result.Score)
+	}
+
+	if result.Details.Embedder != BackendLocal {
+		t.Errorf("Embedder = %q, want %q", result.Details.Embedder, BackendLocal)
+	}
+
+	// Scores are reproducible
+	again, _ := scorer.Score(context.Background(), testCV, testJob)
+	if again.Score != result.Score {
+		t.Errorf("Score changed between runs: %v, then %v", result.Score, again.Score)
+	}
+
+	unrelated, _ := scorer.Score(context.Background(), "Pastry chef\n- Baked sourdough bread daily", testJob)
+	if unrelated.Score >= result.Score/2 {
+		t.Errorf("Unrelated CV scored %v, want well below %v", unrelated.Score, result.Score)
+	}
+
+	if _, err := scorer.Score(context.Background(), "  \n", testJob); err != ErrNoCV {
+		t.Errorf("Expected ErrNoCV for an empty CV, got %v", err)
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+
+		if r.URL.Path != "/api/embed" || json.NewDecoder(r.Body).Decode(&req) != nil || req.Model != "nomic-embed-text" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+
+			return
+		}
+
+		embeddings := make([][]float32, len(req.Input))
+		for i, text := range req.Input {
+			embeddings[i] = []float32{float32(len(text)), 1}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(context.Background(), BackendOllama, "", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"ab", "abcd"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if len(vectors) != 2 || vectors[1][0] != 4 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+
+	if embedder.Name() != "ollama/nomic-embed-text" {
+		t.Errorf("Name() = %q", embedder.Name())
+	}
+
+	if _, err := NewEmbedder(context.Background(), "word2vec", "", "", ""); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ocr recognizes text in images, such as the pages of scanned CVs,
+// through pluggable OCR engines.
+package ocr
+
+import (
+	"context"
+	"errors"
+	"image"
+)
+
+// ReviewThreshold is the confidence, from 0 to 100, below which recognized
+// text should be checked by the user.
+const ReviewThreshold = 60
+
+// ErrUnavailable is returned when an OCR engine is not installed.
+var ErrUnavailable = errors.New("OCR engine is not available")
+
+// Engine recognizes text in an image.
+type Engine interface {
+	// Name identifies the engine in reports, e.g. "tesseract".
+	Name() string
+	// Recognize returns the text found in an image file.
+	Recognize(ctx context.Context, img Image) (*Result, error)
+}
+
+// Image is an image file to recognize.
+type Image struct {
+	Data []byte // PNG, JPEG, TIFF or JPEG 2000 file
+	DPI  int    // Resolution, or 0 if unknown
+}
+
+// Word is one recognized word.
+type Word struct {
+	Text       string
+	Confidence float64
+	Box        image.Rectangle
+}
+
+// Line is a line of recognized words.
+type Line struct {
+	Text       string
+	Confidence float64 // Mean confidence of the words
+	Box        image.Rectangle
+	Words      []Word
+	Paragraph  int // Lines of the same paragraph share a number
+}
+
+// Result is the text recognized in one image.
+type Result struct {
+	Lines      []Line
+	Confidence float64 // Mean confidence of all words, 0 if there are none
+}
+
+// Text returns the recognized text, one line per line of the image with a
+// blank line between paragraphs.
+func (r *Result) Text() string {
+	var out []byte
+
+	for i, line := range r.Lines {
+		if i > 0 {
+			out = append(out, '\n')
+			if line.Paragraph != r.Lines[i-1].Paragraph {
+				out = append(out, '\n')
+			}
+		}
+
+		out = append(out, line.Text...)
+	}
+
+	return string(out)
+}
+
+// Words returns the number of recognized words.
+func (r *Result) Words() int {
+	n := 0
+	for _, line := range r.Lines {
+		n += len(line.Words)
+	}
+
+	return n
+}
+
+// LowConfidence returns the lines whose confidence is below threshold.
+func (r *Result) LowConfidence(threshold float64) []Line {
+	var lines []Line
+
+	for _, line := range r.Lines {
+		if line.Confidence < threshold {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// newResult joins the words of each line and computes confidences.
+func newResult(lines []Line) *Result {
+	r := &Result{Lines: []Line{}}
+
+	total, words := 0.0, 0
+
+	for _, line := range lines {
+		if len(line.Words) == 0 {
+			continue
+		}
+
+		sum := 0.0
+		text := make([]byte, 0, 64)
+
+		for i, w := range line.Words {
+			if i > 0 {
+				text = append(text, ' ')
+			}
+
+			text = append(text, w.Text...)
+			sum += w.Confidence
+			line.Box = line.Box.Union(w.Box)
+		}
+
+		line.Text = string(text)
+		line.Confidence = sum / float64(len(line.Words))
+		r.Lines = append(r.Lines, line)
+
+		total += sum
+		words += len(line.Words)
+	}
+
+	if words > 0 {
+		r.Confidence = total / float64(words)
+	}
+
+	return r
+}
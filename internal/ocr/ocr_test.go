@@ -0,0 +1,82 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ocr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t2550\t3300\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t100\t100\t400\t40\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t100\t100\t180\t40\t96.5\tJane\n" +
+	"5\t1\t1\t1\t1\t2\t300\t100\t200\t40\t93.5\tDoe\n" +
+	"5\t1\t1\t1\t2\t1\t100\t150\t300\t30\t91\tEngineer\n" +
+	"5\t1\t2\t1\t1\t1\t100\t300\t250\t30\t41\tKubemetes\n" +
+	"5\t1\t2\t1\t1\t2\t360\t300\t80\t30\t-1\t \n"
+
+func TestParseTSV(t *testing.T) {
+	result, err := parseTSV(strings.NewReader(sampleTSV))
+	if err != nil {
+		t.Fatalf("parseTSV() error = %v", err)
+	}
+
+	if got := result.Text(); got != "Jane Doe\nEngineer\n\nKubemetes" {
+		t.Errorf("unexpected text: %q", got)
+	}
+
+	if result.Words() != 4 || result.Confidence != 80.5 {
+		t.Errorf("expected 4 words at 80.5%%, got %d at %v", result.Words(), result.Confidence)
+	}
+
+	if box := result.Lines[0].Box; box.Min.X != 100 || box.Max.X != 500 || box.Dy() != 40 {
+		t.Errorf("unexpected line box: %v", box)
+	}
+
+	low := result.LowConfidence(ReviewThreshold)
+	if len(low) != 1 || low[0].Text != "Kubemetes" {
+		t.Errorf("expected one low-confidence line, got %+v", low)
+	}
+}
+
+func TestTesseract_Recognize(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "output.tsv")
+	args := filepath.Join(dir, "args")
+
+	if err := os.WriteFile(out, []byte(sampleTSV), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	script := filepath.Join(dir, "tesseract")
+	body := "#!/bin/sh\necho \"$@\" > " + args + "\ncat > /dev/null\ncat " + out + "\n"
+
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewTesseract(script, "eng", "deu").Recognize(context.Background(), Image{Data: []byte("png"), DPI: 300})
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+
+	if len(result.Lines) != 3 {
+		t.Errorf("expected 3 lines, got %+v", result.Lines)
+	}
+
+	got, _ := os.ReadFile(args)
+	if strings.TrimSpace(string(got)) != "stdin stdout -l eng+deu --psm 3 --dpi 300 tsv" {
+		t.Errorf("unexpected arguments: %s", got)
+	}
+
+	_, err = NewTesseract(filepath.Join(dir, "missing")).Recognize(context.Background(), Image{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Tesseract runs the local tesseract command line tool.
+type Tesseract struct {
+	path      string
+	languages string
+}
+
+// NewTesseract creates a Tesseract engine. path defaults to "tesseract" on
+// the PATH and languages to English; several languages are joined as
+// tesseract expects ("eng+deu").
+func NewTesseract(path string, languages ...string) *Tesseract {
+	if path == "" {
+		path = "tesseract"
+	}
+
+	if len(languages) == 0 {
+		languages = []string{"eng"}
+	}
+
+	return &Tesseract{
+		path:      path,
+		languages: strings.Join(languages, "+"),
+	}
+}
+
+// Name returns "tesseract".
+func (t *Tesseract) Name() string {
+	return "tesseract"
+}
+
+// Recognize runs tesseract on an image and reads its TSV output, which
+// carries the position and confidence of every word.
+func (t *Tesseract) Recognize(ctx context.Context, img Image) (*Result, error) {
+	if _, err := exec.LookPath(t.path); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	args := []string{"stdin", "stdout", "-l", t.languages, "--psm", "3"}
+	if img.DPI > 0 {
+		args = append(args, "--dpi", strconv.Itoa(img.DPI))
+	}
+
+	args = append(args, "tsv")
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, t.path, args...)
+	cmd.Stdin = bytes.NewReader(img.Data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+
+		return nil, fmt.Errorf("tesseract failed: %w: %s", err, msg)
+	}
+
+	return parseTSV(&stdout)
+}
+
+// parseTSV reads tesseract TSV output. Rows hold the level, page, block,
+// paragraph, line and word numbers, the box and the confidence of an
+// element, followed by its text; only word rows (level 5) carry text.
+func parseTSV(r io.Reader) (*Result, error) {
+	type lineKey struct{ page, block, par, line int }
+
+	var (
+		lines         []Line
+		current, para lineKey
+	)
+
+	paragraph := -1
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for header := true; scanner.Scan(); header = false {
+		fields := strings.Split(scanner.Text(), "\t")
+		if header || len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+
+		var n [10]int
+
+		for i := range 10 {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tesseract output: %w", err)
+			}
+
+			n[i] = v
+		}
+
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tesseract output: %w", err)
+		}
+
+		text := strings.TrimSpace(strings.Join(fields[11:], "\t"))
+		if text == "" || conf < 0 {
+			continue
+		}
+
+		key := lineKey{n[1], n[2], n[3], n[4]}
+		if len(lines) == 0 || key != current {
+			if p := (lineKey{n[1], n[2], n[3], 0}); len(lines) == 0 || p != para {
+				paragraph++
+				para = p
+			}
+
+			lines = append(lines, Line{Paragraph: paragraph})
+			current = key
+		}
+
+		last := &lines[len(lines)-1]
+		last.Words = append(last.Words, Word{
+			Text:       text,
+			Confidence: conf,
+			Box:        image.Rect(n[6], n[7], n[6]+n[8], n[7]+n[9]),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return newResult(lines), nil
+}
@@ -0,0 +1,533 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"math"
+)
+
+const (
+	// maxOps bounds the operators executed per page.
+	maxOps = 2_000_000
+	// maxFormDepth bounds the nesting of form XObjects.
+	maxFormDepth = 12
+)
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translate(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+// textChar is one shown glyph in user space.
+type textChar struct {
+	text   string
+	x, y   float64 // start of the glyph on the baseline
+	ex, ey float64 // end of the glyph on the baseline
+	dx, dy float64 // unit baseline direction
+	size   float64 // rendered font size
+}
+
+// gstate is the part of the graphics state that affects text placement.
+type gstate struct {
+	ctm                  matrix
+	font                 *font
+	size                 float64
+	tc, tw, th, tl, rise float64
+}
+
+// interpreter executes content streams and collects the glyphs they show
+// and the images they paint.
+type interpreter struct {
+	d      *Document
+	chars  []textChar
+	images []placedImage
+	gs     gstate
+	stack  []gstate
+	tm     matrix
+	tlm    matrix
+	ops    int
+	forms  map[int]bool
+}
+
+func newInterpreter(d *Document) *interpreter {
+	return &interpreter{
+		d:     d,
+		gs:    gstate{ctm: identity, th: 1, font: fallbackFont},
+		tm:    identity,
+		tlm:   identity,
+		forms: map[int]bool{},
+	}
+}
+
+// run executes a content stream with the given resources.
+func (in *interpreter) run(content []byte, resources dict, depth int) {
+	l := newLexer(content, 0)
+	l.refs = false
+
+	var operands []object
+
+	for in.ops < maxOps {
+		obj, err := l.readObject()
+		if errors.Is(err, errUnexpectedEOF) {
+			return
+		}
+
+		if err != nil {
+			// Skip the malformed token and carry on, as viewers do
+			operands = operands[:0]
+
+			continue
+		}
+
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+
+			continue
+		}
+
+		in.ops++
+
+		if op == "BI" {
+			if img := in.readInlineImage(l, resources); img != nil {
+				in.images = append(in.images, placedImage{s: img, ctm: in.gs.ctm})
+			}
+		} else {
+			in.exec(op, operands, resources, depth)
+		}
+
+		operands = operands[:0]
+	}
+}
+
+// nums returns the trailing n operands as numbers.
+func nums(operands []object, n int) ([]float64, bool) {
+	if len(operands) < n {
+		return nil, false
+	}
+
+	out := make([]float64, n)
+
+	for i, o := range operands[len(operands)-n:] {
+		switch v := o.(type) {
+		case int64:
+			out[i] = float64(v)
+		case float64:
+			out[i] = v
+		default:
+			return nil, false
+		}
+	}
+
+	return out, true
+}
+
+func (in *interpreter) exec(op keyword, operands []object, resources dict, depth int) {
+	switch op {
+	case "q":
+		if len(in.stack) < 256 {
+			in.stack = append(in.stack, in.gs)
+		}
+	case "Q":
+		if n := len(in.stack); n > 0 {
+			in.gs = in.stack[n-1]
+			in.stack = in.stack[:n-1]
+		}
+	case "cm":
+		if v, ok := nums(operands, 6); ok {
+			in.gs.ctm = matrix(v).mul(in.gs.ctm)
+		}
+	case "BT":
+		in.tm, in.tlm = identity, identity
+	case "Tf":
+		if len(operands) >= 2 {
+			if fn, ok := operands[len(operands)-2].(name); ok {
+				in.gs.font = in.font(resources, fn)
+			}
+
+			if v, ok := nums(operands, 1); ok {
+				in.gs.size = v[0]
+			}
+		}
+	case "Tc", "Tw", "Tz", "TL", "Ts":
+		v, ok := nums(operands, 1)
+		if !ok {
+			return
+		}
+
+		switch op {
+		case "Tc":
+			in.gs.tc = v[0]
+		case "Tw":
+			in.gs.tw = v[0]
+		case "Tz":
+			in.gs.th = v[0] / 100
+		case "TL":
+			in.gs.tl = v[0]
+		case "Ts":
+			in.gs.rise = v[0]
+		}
+	case "Td", "TD":
+		if v, ok := nums(operands, 2); ok {
+			if op == "TD" {
+				in.gs.tl = -v[1]
+			}
+
+			in.tlm = translate(v[0], v[1]).mul(in.tlm)
+			in.tm = in.tlm
+		}
+	case "Tm":
+		if v, ok := nums(operands, 6); ok {
+			in.tlm = matrix(v)
+			in.tm = in.tlm
+		}
+	case "T*":
+		in.nextLine()
+	case "Tj":
+		if s, ok := lastString(operands); ok {
+			in.show(s)
+		}
+	case "'":
+		in.nextLine()
+
+		if s, ok := lastString(operands); ok {
+			in.show(s)
+		}
+	case "\"":
+		if len(operands) >= 3 {
+			if v, ok := nums(operands[:len(operands)-1], 2); ok {
+				in.gs.tw, in.gs.tc = v[0], v[1]
+			}
+		}
+
+		in.nextLine()
+
+		if s, ok := lastString(operands); ok {
+			in.show(s)
+		}
+	case "TJ":
+		if len(operands) == 0 {
+			return
+		}
+
+		arr, ok := operands[len(operands)-1].(array)
+		if !ok {
+			return
+		}
+
+		for _, item := range arr {
+			switch v := item.(type) {
+			case string:
+				in.show(v)
+			case int64, float64:
+				n, _ := nums(array{v}, 1)
+				tx := -n[0] / 1000 * in.gs.size * in.gs.th
+				in.tm = translate(tx, 0).mul(in.tm)
+			}
+		}
+	case "Do":
+		if len(operands) > 0 {
+			if xn, ok := operands[len(operands)-1].(name); ok {
+				in.xobject(resources, xn, depth)
+			}
+		}
+	}
+}
+
+func lastString(operands []object) (string, bool) {
+	if len(operands) == 0 {
+		return "", false
+	}
+
+	s, ok := operands[len(operands)-1].(string)
+
+	return s, ok
+}
+
+func (in *interpreter) nextLine() {
+	in.tlm = translate(0, -in.gs.tl).mul(in.tlm)
+	in.tm = in.tlm
+}
+
+func (in *interpreter) font(resources dict, fn name) *font {
+	fonts := in.d.resolveDict(resources["Font"])
+	if fonts == nil {
+		return fallbackFont
+	}
+
+	obj, ok := fonts[fn]
+	if !ok {
+		return fallbackFont
+	}
+
+	return in.d.loadFont(obj)
+}
+
+// show places the glyphs of a string and advances the text matrix.
+func (in *interpreter) show(s string) {
+	gs := &in.gs
+	f := gs.font
+
+	for _, g := range f.decode(s) {
+		trm := matrix{gs.size * gs.th, 0, 0, gs.size, 0, gs.rise}.mul(in.tm).mul(gs.ctm)
+
+		tx := g.width*gs.size + gs.tc
+		if g.space {
+			tx += gs.tw
+		}
+
+		in.tm = translate(tx*gs.th, 0).mul(in.tm)
+
+		if g.text == "" {
+			continue
+		}
+
+		end := matrix{gs.size * gs.th, 0, 0, gs.size, 0, gs.rise}.mul(in.tm).mul(gs.ctm)
+
+		dx, dy := trm[0], trm[1]
+		if n := math.Hypot(dx, dy); n > 0 {
+			dx, dy = dx/n, dy/n
+		} else {
+			dx, dy = 1, 0
+		}
+
+		in.chars = append(in.chars, textChar{
+			text: g.text,
+			x:    trm[4],
+			y:    trm[5],
+			ex:   end[4],
+			ey:   end[5],
+			dx:   dx,
+			dy:   dy,
+			size: math.Hypot(trm[2], trm[3]),
+		})
+	}
+}
+
+// xobject executes a form XObject or records a painted image.
+func (in *interpreter) xobject(resources dict, xn name, depth int) {
+	if depth >= maxFormDepth {
+		return
+	}
+
+	xobjects := in.d.resolveDict(resources["XObject"])
+	if xobjects == nil {
+		return
+	}
+
+	ref, isRef := xobjects[xn].(objref)
+	if isRef {
+		if in.forms[ref.num] {
+			return
+		}
+
+		in.forms[ref.num] = true
+		defer delete(in.forms, ref.num)
+	}
+
+	s, ok := in.d.resolve(xobjects[xn]).(*stream)
+	if ok && s.hdr["Subtype"] == name("Image") {
+		in.images = append(in.images, placedImage{s: s, ctm: in.gs.ctm})
+
+		return
+	}
+
+	if !ok || s.hdr["Subtype"] != name("Form") {
+		return
+	}
+
+	data, err := in.d.decodeStream(s)
+	if err != nil {
+		return
+	}
+
+	formResources := in.d.resolveDict(s.hdr["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	saved, savedTm, savedTlm := in.gs, in.tm, in.tlm
+
+	if m := in.d.resolveArray(s.hdr["Matrix"]); len(m) == 6 {
+		if v, ok := nums(m, 6); ok {
+			in.gs.ctm = matrix(v).mul(in.gs.ctm)
+		}
+	}
+
+	in.run(data, formResources, depth+1)
+
+	in.gs, in.tm, in.tlm = saved, savedTm, savedTlm
+}
+
+// inlineKeys expands the abbreviated keys of inline image dictionaries.
+var inlineKeys = map[name]name{
+	"BPC": "BitsPerComponent",
+	"CS":  "ColorSpace",
+	"D":   "Decode",
+	"DP":  "DecodeParms",
+	"F":   "Filter",
+	"H":   "Height",
+	"IM":  "ImageMask",
+	"W":   "Width",
+}
+
+// inlineColorSpaces expands the abbreviated colour spaces of inline images.
+var inlineColorSpaces = map[name]name{
+	"G":    "DeviceGray",
+	"RGB":  "DeviceRGB",
+	"CMYK": "DeviceCMYK",
+	"I":    "Indexed",
+}
+
+// readInlineImage reads an inline image ("BI ... ID data EI") as a stream.
+// It returns nil, having moved past the image, when the image is malformed.
+func (in *interpreter) readInlineImage(l *lexer, resources dict) *stream {
+	hdr := dict{}
+
+	var key name
+
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return nil
+		}
+
+		if kw, ok := obj.(keyword); ok && kw == "ID" {
+			break
+		}
+
+		if k, ok := obj.(name); ok && key == "" {
+			key = k
+
+			continue
+		}
+
+		if full, ok := inlineKeys[key]; ok {
+			key = full
+		}
+
+		hdr[key] = obj
+		key = ""
+	}
+
+	switch cs := hdr["ColorSpace"].(type) {
+	case name:
+		if full, ok := inlineColorSpaces[cs]; ok {
+			hdr["ColorSpace"] = full
+		} else if named, ok := in.d.resolveDict(resources["ColorSpace"])[cs]; ok {
+			hdr["ColorSpace"] = named
+		}
+	case array:
+		expanded := make(array, len(cs))
+		for i, v := range cs {
+			expanded[i] = v
+			if n, ok := v.(name); ok && inlineColorSpaces[n] != "" {
+				expanded[i] = inlineColorSpaces[n]
+			}
+		}
+
+		hdr["ColorSpace"] = expanded
+	}
+
+	l.pos++ // single whitespace after ID
+	start := l.pos
+
+	for l.pos < len(l.data) {
+		i := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.data)
+
+			return nil
+		}
+
+		at := l.pos + i
+		l.pos = at + 2
+
+		if at > start && isSpace(l.data[at-1]) && (l.pos >= len(l.data) || isSpace(l.data[l.pos])) {
+			return &stream{hdr: hdr, data: l.data[start : at-1]}
+		}
+	}
+
+	return nil
+}
+
+// runPage runs the content of a page and its annotation appearances.
+func (d *Document) runPage(page dict) *interpreter {
+	in := newInterpreter(d)
+	resources := d.resolveDict(page["Resources"])
+
+	var content []byte
+
+	contents := d.resolve(page["Contents"])
+	if arr, ok := contents.(array); ok {
+		for _, c := range arr {
+			if data, err := d.streamData(c); err == nil {
+				content = append(content, data...)
+				content = append(content, '\n')
+			}
+		}
+	} else if data, err := d.streamData(contents); err == nil {
+		content = data
+	}
+
+	in.run(content, resources, 0)
+
+	// Filled-in form fields and text boxes live in annotation appearances
+	for _, a := range d.resolveArray(page["Annots"]) {
+		annot := d.resolveDict(a)
+		if st := annot["Subtype"]; st != name("Widget") && st != name("FreeText") {
+			continue
+		}
+
+		ap, ok := d.resolve(d.resolveDict(annot["AP"])["N"]).(*stream)
+		if !ok {
+			continue
+		}
+
+		rect, ok1 := nums(d.resolveArray(annot["Rect"]), 4)
+		bbox, ok2 := nums(d.resolveArray(ap.hdr["BBox"]), 4)
+
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		data, err := d.decodeStream(ap)
+		if err != nil {
+			continue
+		}
+
+		apResources := d.resolveDict(ap.hdr["Resources"])
+		if apResources == nil {
+			apResources = resources
+		}
+
+		in.gs = gstate{
+			ctm:  translate(math.Min(rect[0], rect[2])-bbox[0], math.Min(rect[1], rect[3])-bbox[1]),
+			th:   1,
+			font: fallbackFont,
+		}
+		in.stack = in.stack[:0]
+		in.run(data, apResources, 1)
+	}
+
+	return in
+}
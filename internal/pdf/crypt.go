@@ -0,0 +1,366 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// passwordPad pads passwords for the RC4-based security handler revisions.
+var passwordPad = []byte{
+	0x28, 0xbf, 0x4e, 0x5e, 0x4e, 0x75, 0x8a, 0x41, 0x64, 0x00, 0x4e, 0x56, 0xff, 0xfa, 0x01, 0x08,
+	0x2e, 0x2e, 0x00, 0xb6, 0xd0, 0x68, 0x3e, 0x80, 0x2f, 0x0c, 0xa9, 0xfe, 0x64, 0x53, 0x69, 0x7a,
+}
+
+// Crypt filter methods.
+const (
+	cryptNone = iota
+	cryptRC4
+	cryptAESV2
+	cryptAESV3
+)
+
+// decrypter implements the standard security handler for documents that
+// open without a user password, which covers "owner password only" PDFs
+// that restrict printing or copying but can be read by anyone.
+type decrypter struct {
+	key        []byte
+	stmMethod  int
+	strMethod  int
+	encryptObj int
+}
+
+func newDecrypter(d *Document, enc dict) (*decrypter, error) {
+	if f, _ := d.resolve(enc["Filter"]).(name); f != "Standard" {
+		return nil, fmt.Errorf("unsupported security handler %q", f)
+	}
+
+	v := d.intOr(enc["V"], 0)
+	r := d.intOr(enc["R"], 0)
+
+	o, _ := d.resolve(enc["O"]).(string)
+	u, _ := d.resolve(enc["U"]).(string)
+
+	c := &decrypter{encryptObj: -1, stmMethod: cryptRC4, strMethod: cryptRC4}
+
+	if v >= 4 {
+		cf := d.resolveDict(enc["CF"])
+		c.stmMethod = cryptFilterMethod(d, cf, enc["StmF"])
+		c.strMethod = cryptFilterMethod(d, cf, enc["StrF"])
+	}
+
+	var err error
+
+	switch {
+	case r >= 5:
+		ue, _ := d.resolve(enc["UE"]).(string)
+		c.key, err = aes256Key([]byte(u), []byte(ue), r)
+	case r >= 2:
+		c.key, err = rc4Key(d, enc, []byte(o), []byte(u), r)
+	default:
+		err = fmt.Errorf("unsupported security handler revision %d", r)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func cryptFilterMethod(d *Document, cf dict, filterName object) int {
+	fn, _ := d.resolve(filterName).(name)
+	if fn == "" || fn == "Identity" {
+		return cryptNone
+	}
+
+	switch m, _ := d.resolve(d.resolveDict(cf[fn])["CFM"]).(name); m {
+	case "V2":
+		return cryptRC4
+	case "AESV2":
+		return cryptAESV2
+	case "AESV3":
+		return cryptAESV3
+	default:
+		return cryptNone
+	}
+}
+
+// rc4Key computes the file key of revisions 2-4 for the empty user password
+// and checks it against /U.
+func rc4Key(d *Document, enc dict, o, u []byte, r int) ([]byte, error) {
+	length := d.intOr(enc["Length"], 40) / 8
+	if r == 2 {
+		length = 5
+	}
+
+	if length < 5 || length > 16 {
+		length = 16
+	}
+
+	var id []byte
+	if ids := d.resolveArray(d.trailer["ID"]); len(ids) > 0 {
+		s, _ := d.resolve(ids[0]).(string)
+		id = []byte(s)
+	}
+
+	h := md5.New()
+	h.Write(passwordPad)
+	h.Write(o)
+
+	p := make([]byte, 4)
+	binary.LittleEndian.PutUint32(p, uint32(int32(d.intOr(enc["P"], 0))))
+	h.Write(p)
+	h.Write(id)
+
+	if meta, ok := d.resolve(enc["EncryptMetadata"]).(bool); r >= 4 && ok && !meta {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+
+	key := h.Sum(nil)
+
+	if r >= 3 {
+		for range 50 {
+			sum := md5.Sum(key[:length])
+			key = sum[:]
+		}
+	}
+
+	key = key[:length]
+
+	// Check the key by re-deriving /U
+	var check []byte
+
+	if r == 2 {
+		check = rc4Crypt(key, passwordPad)
+	} else {
+		h := md5.New()
+		h.Write(passwordPad)
+		h.Write(id)
+		check = rc4Crypt(key, h.Sum(nil))
+
+		for i := 1; i <= 19; i++ {
+			k := make([]byte, len(key))
+			for j := range key {
+				k[j] = key[j] ^ byte(i)
+			}
+
+			check = rc4Crypt(k, check)
+		}
+
+		u = u[:min(16, len(u))]
+		check = check[:16]
+	}
+
+	if !bytes.Equal(check, u[:min(len(check), len(u))]) {
+		return nil, ErrEncrypted
+	}
+
+	return key, nil
+}
+
+// aes256Key computes the file key of revisions 5 and 6 for the empty user
+// password.
+func aes256Key(u, ue []byte, r int) ([]byte, error) {
+	if len(u) < 48 || len(ue) < 32 {
+		return nil, errors.New("invalid encryption dictionary")
+	}
+
+	validationSalt, keySalt := u[32:40], u[40:48]
+
+	if !bytes.Equal(hashR6(validationSalt, r), u[:32]) {
+		return nil, ErrEncrypted
+	}
+
+	block, err := aes.NewCipher(hashR6(keySalt, r))
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(key, ue[:32])
+
+	return key, nil
+}
+
+// hashR6 is the password hash of revision 6 (and the plain SHA-256 of
+// revision 5) for the empty password and the given salt.
+func hashR6(salt []byte, r int) []byte {
+	sum := sha256.Sum256(salt)
+	k := sum[:]
+
+	if r == 5 {
+		return k
+	}
+
+	for i := 0; ; i++ {
+		k1 := bytes.Repeat(k, 64)
+
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		mod := 0
+		for _, b := range e[:16] {
+			mod += int(b)
+		}
+
+		switch mod % 3 {
+		case 0:
+			s := sha256.Sum256(e)
+			k = s[:]
+		case 1:
+			s := sha512.Sum384(e)
+			k = s[:]
+		default:
+			s := sha512.Sum512(e)
+			k = s[:]
+		}
+
+		if i >= 63 && int(e[len(e)-1]) <= i-31 {
+			break
+		}
+	}
+
+	return k[:32]
+}
+
+func rc4Crypt(key, data []byte) []byte {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+
+	out := make([]byte, len(data))
+	c.XORKeyStream(out, data)
+
+	return out
+}
+
+// objectKey derives the key of one object.
+func (c *decrypter) objectKey(ref objref, method int) []byte {
+	if method == cryptAESV3 {
+		return c.key
+	}
+
+	buf := make([]byte, 0, len(c.key)+9)
+	buf = append(buf, c.key...)
+	buf = append(buf, byte(ref.num), byte(ref.num>>8), byte(ref.num>>16), byte(ref.gen), byte(ref.gen>>8))
+
+	if method == cryptAESV2 {
+		buf = append(buf, "sAlT"...)
+	}
+
+	sum := md5.Sum(buf)
+
+	return sum[:min(len(c.key)+5, 16)]
+}
+
+func (c *decrypter) decrypt(data []byte, ref objref, method int) []byte {
+	switch method {
+	case cryptRC4:
+		return rc4Crypt(c.objectKey(ref, method), data)
+	case cryptAESV2, cryptAESV3:
+		if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+			return nil
+		}
+
+		block, err := aes.NewCipher(c.objectKey(ref, method))
+		if err != nil {
+			return nil
+		}
+
+		out := make([]byte, len(data)-aes.BlockSize)
+		cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(out, data[aes.BlockSize:])
+
+		// Strip PKCS#5 padding
+		if pad := int(out[len(out)-1]); pad > 0 && pad <= aes.BlockSize && pad <= len(out) {
+			out = out[:len(out)-pad]
+		}
+
+		return out
+	default:
+		return data
+	}
+}
+
+// decryptObject decrypts the strings and stream data of an indirect object.
+func (d *Document) decryptObject(obj object, ref objref) object {
+	if d.crypt == nil || ref.num == d.crypt.encryptObj {
+		return obj
+	}
+
+	var walk func(o object, depth int) object
+
+	walk = func(o object, depth int) object {
+		if depth > maxNesting {
+			return o
+		}
+
+		switch v := o.(type) {
+		case string:
+			return string(d.crypt.decrypt([]byte(v), ref, d.crypt.strMethod))
+		case array:
+			out := make(array, len(v))
+			for i, e := range v {
+				out[i] = walk(e, depth+1)
+			}
+
+			return out
+		case dict:
+			out := make(dict, len(v))
+			for k, e := range v {
+				out[k] = walk(e, depth+1)
+			}
+
+			return out
+		case *stream:
+			hdr, _ := walk(v.hdr, depth+1).(dict)
+			s := &stream{hdr: hdr, data: v.data, ref: v.ref}
+
+			if hdr["Type"] != name("XRef") && !d.identityCrypt(hdr) {
+				s.data = d.crypt.decrypt(v.data, ref, d.crypt.stmMethod)
+			}
+
+			return s
+		}
+
+		return o
+	}
+
+	return walk(obj, 0)
+}
+
+// identityCrypt reports whether a stream opts out of encryption with the
+// Identity crypt filter.
+func (d *Document) identityCrypt(hdr dict) bool {
+	filters := d.resolveArray(hdr["Filter"])
+	if f, ok := d.resolve(hdr["Filter"]).(name); ok {
+		filters = array{f}
+	}
+
+	for _, f := range filters {
+		if d.resolve(f) == name("Crypt") {
+			parm := d.resolveDict(hdr["DecodeParms"])
+			if parms := d.resolveArray(hdr["DecodeParms"]); len(parms) > 0 {
+				parm = d.resolveDict(parms[len(parms)-1])
+			}
+
+			n, _ := d.resolve(parm["Name"]).(name)
+
+			return n == "" || n == "Identity"
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,757 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrInvalid is returned for data that is not a PDF document.
+	ErrInvalid = errors.New("not a valid PDF file")
+	// ErrEncrypted is returned for documents that need a password to open.
+	ErrEncrypted = errors.New("PDF is password protected")
+)
+
+// maxPages bounds the page tree walk.
+const maxPages = 2000
+
+// xrefEntry locates an object, either at a file offset or inside an object stream.
+type xrefEntry struct {
+	offset   int
+	inStream bool
+	stream   int // object stream number
+}
+
+// objectStream is the decoded data of an object stream.
+type objectStream struct {
+	data    []byte
+	offsets map[int]int // object number -> offset in data
+}
+
+// Document is a parsed PDF document.
+type Document struct {
+	data    []byte
+	xref    map[int]xrefEntry
+	trailer dict
+	cache   map[int]object
+	loading map[int]bool
+	objstm  map[int]*objectStream
+	crypt   *decrypter
+	scanned bool
+	pages   []dict
+	fonts   map[int]*font
+}
+
+// Open parses a PDF document.
+func Open(data []byte) (*Document, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, ErrInvalid
+	}
+
+	d := &Document{
+		data:    data,
+		xref:    map[int]xrefEntry{},
+		trailer: dict{},
+		cache:   map[int]object{},
+		loading: map[int]bool{},
+		objstm:  map[int]*objectStream{},
+		fonts:   map[int]*font{},
+	}
+
+	if err := d.readXrefChain(); err != nil || d.trailer["Root"] == nil {
+		// Broken or missing cross-reference data; rebuild it from the objects
+		d.scan()
+	}
+
+	if err := d.initCrypt(); err != nil {
+		return nil, err
+	}
+
+	if d.scanned {
+		d.scanObjectStreams()
+	}
+
+	err := d.loadPages()
+	if err != nil && !d.scanned {
+		// The cross-reference data may point to the wrong objects; try again
+		// from a scan of the file
+		d.scan()
+		d.scanObjectStreams()
+
+		err = d.loadPages()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// initCrypt sets up decryption for encrypted documents.
+func (d *Document) initCrypt() error {
+	enc := d.resolve(d.trailer["Encrypt"])
+	if enc == nil {
+		return nil
+	}
+
+	encDict, ok := enc.(dict)
+	if !ok {
+		return errors.New("invalid encryption dictionary")
+	}
+
+	crypt, err := newDecrypter(d, encDict)
+	if err != nil {
+		return err
+	}
+
+	if ref, ok := d.trailer["Encrypt"].(objref); ok {
+		crypt.encryptObj = ref.num
+	}
+
+	d.crypt = crypt
+
+	// Objects loaded so far were not decrypted
+	d.cache = map[int]object{}
+	d.objstm = map[int]*objectStream{}
+
+	return nil
+}
+
+// NumPages returns the number of pages of the document.
+func (d *Document) NumPages() int {
+	return len(d.pages)
+}
+
+// readXrefChain reads the cross-reference sections from startxref backwards
+// through the /Prev chain. Entries of newer sections take precedence.
+func (d *Document) readXrefChain() error {
+	tail := d.data[max(0, len(d.data)-2048):]
+
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return errors.New("startxref not found")
+	}
+
+	l := newLexer(tail, i+len("startxref"))
+
+	offset, ok := l.readInt()
+	if !ok {
+		return errors.New("invalid startxref")
+	}
+
+	seen := map[int]bool{}
+
+	for offset > 0 && !seen[offset] {
+		seen[offset] = true
+
+		trailer, err := d.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+
+		for k, v := range trailer {
+			if _, ok := d.trailer[k]; !ok {
+				d.trailer[k] = v
+			}
+		}
+
+		// Hybrid files keep compressed objects in an additional xref stream
+		if stm, ok := trailer["XRefStm"].(int64); ok && !seen[int(stm)] {
+			seen[int(stm)] = true
+			if _, err := d.readXrefSection(int(stm)); err != nil {
+				return err
+			}
+		}
+
+		prev, _ := trailer["Prev"].(int64)
+		offset = int(prev)
+	}
+
+	return nil
+}
+
+// readXrefSection reads a classic xref table or an xref stream at offset and
+// returns its trailer dictionary.
+func (d *Document) readXrefSection(offset int) (dict, error) {
+	if offset >= len(d.data) {
+		return nil, errors.New("xref offset out of range")
+	}
+
+	l := newLexer(d.data, offset)
+	l.skipSpace()
+
+	if bytes.HasPrefix(d.data[l.pos:], []byte("xref")) {
+		l.pos += len("xref")
+
+		return d.readXrefTable(l)
+	}
+
+	obj, _, err := d.readIndirect(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := obj.(*stream)
+	if !ok || s.hdr["Type"] != name("XRef") {
+		return nil, errors.New("xref stream expected")
+	}
+
+	return s.hdr, d.readXrefStream(s)
+}
+
+func (d *Document) readXrefTable(l *lexer) (dict, error) {
+	for {
+		l.skipSpace()
+
+		if bytes.HasPrefix(l.data[l.pos:], []byte("trailer")) {
+			l.pos += len("trailer")
+
+			obj, err := l.readObject()
+			if err != nil {
+				return nil, err
+			}
+
+			trailer, ok := obj.(dict)
+			if !ok {
+				return nil, errors.New("invalid trailer")
+			}
+
+			return trailer, nil
+		}
+
+		first, ok1 := l.readInt()
+		count, ok2 := l.readInt()
+
+		if !ok1 || !ok2 {
+			return nil, errors.New("invalid xref subsection")
+		}
+
+		for i := range count {
+			off, ok1 := l.readInt()
+			_, ok2 := l.readInt()
+
+			l.skipSpace()
+
+			if !ok1 || !ok2 || l.pos >= len(l.data) {
+				return nil, errors.New("invalid xref entry")
+			}
+
+			kind := l.data[l.pos]
+			l.pos++
+
+			num := first + i
+			if _, ok := d.xref[num]; !ok && kind == 'n' && off > 0 {
+				d.xref[num] = xrefEntry{offset: off}
+			}
+		}
+	}
+}
+
+func (d *Document) readXrefStream(s *stream) error {
+	data, err := d.decodeStream(s)
+	if err != nil {
+		return err
+	}
+
+	widths := d.resolveArray(s.hdr["W"])
+	if len(widths) != 3 {
+		return errors.New("invalid xref stream widths")
+	}
+
+	w := make([]int, 3)
+	for i := range w {
+		w[i] = d.intOr(widths[i], 0)
+		if w[i] < 0 || w[i] > 8 {
+			return errors.New("invalid xref stream widths")
+		}
+	}
+
+	size := d.intOr(s.hdr["Size"], 0)
+
+	index := d.resolveArray(s.hdr["Index"])
+	if len(index) == 0 {
+		index = array{int64(0), int64(size)}
+	}
+
+	entryLen := w[0] + w[1] + w[2]
+	if entryLen == 0 {
+		return errors.New("invalid xref stream widths")
+	}
+
+	field := func(b []byte) int {
+		v := 0
+		for _, c := range b {
+			v = v<<8 | int(c)
+		}
+
+		return v
+	}
+
+	pos := 0
+
+	for i := 0; i+1 < len(index); i += 2 {
+		first := d.intOr(index[i], 0)
+		count := d.intOr(index[i+1], 0)
+
+		for j := 0; j < count && pos+entryLen <= len(data); j++ {
+			entry := data[pos : pos+entryLen]
+			pos += entryLen
+
+			kind := 1
+			if w[0] > 0 {
+				kind = field(entry[:w[0]])
+			}
+
+			f2 := field(entry[w[0] : w[0]+w[1]])
+
+			num := first + j
+			if _, ok := d.xref[num]; ok {
+				continue
+			}
+
+			switch kind {
+			case 1:
+				d.xref[num] = xrefEntry{offset: f2}
+			case 2:
+				d.xref[num] = xrefEntry{inStream: true, stream: f2}
+			}
+		}
+	}
+
+	return nil
+}
+
+var objHeader = regexp.MustCompile(`(?:^|[\r\n\s])(\d+)\s+(\d+)\s+obj\b`)
+
+// scan rebuilds the cross-reference data by locating every "n g obj" in the
+// file. Later definitions win, matching incremental updates.
+func (d *Document) scan() {
+	d.scanned = true
+	d.xref = map[int]xrefEntry{}
+	d.cache = map[int]object{}
+	d.objstm = map[int]*objectStream{}
+
+	for _, m := range objHeader.FindAllSubmatchIndex(d.data, -1) {
+		num, err := strconv.Atoi(string(d.data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+
+		d.xref[num] = xrefEntry{offset: m[2]}
+	}
+
+	// Trailers of every revision, newest last
+	for i := 0; ; {
+		j := bytes.Index(d.data[i:], []byte("trailer"))
+		if j < 0 {
+			break
+		}
+
+		i += j + len("trailer")
+
+		if t, err := newLexer(d.data, i).readObject(); err == nil {
+			if trailer, ok := t.(dict); ok {
+				for k, v := range trailer {
+					d.trailer[k] = v
+				}
+			}
+		}
+	}
+
+	// Trailer keys of xref streams
+	for _, e := range d.xref {
+		if obj, _, err := d.readIndirect(e.offset); err == nil {
+			if s, ok := obj.(*stream); ok && s.hdr["Type"] == name("XRef") {
+				for _, k := range []name{"Root", "Encrypt", "ID", "Info"} {
+					if v, ok := s.hdr[k]; ok && d.trailer[k] == nil {
+						d.trailer[k] = v
+					}
+				}
+			}
+		}
+	}
+}
+
+// scanObjectStreams adds the objects of every object stream to a scanned
+// cross-reference. It runs after decryption is set up, as object streams
+// are encrypted as a whole.
+func (d *Document) scanObjectStreams() {
+	nums := make([]int, 0, len(d.xref))
+	for num, e := range d.xref {
+		if !e.inStream {
+			nums = append(nums, num)
+		}
+	}
+
+	for _, num := range nums {
+		if s, ok := d.object(num).(*stream); !ok || s.hdr["Type"] != name("ObjStm") {
+			continue
+		}
+
+		stm, err := d.objectStream(num)
+		if err != nil {
+			continue
+		}
+
+		for objNum := range stm.offsets {
+			if _, ok := d.xref[objNum]; !ok {
+				d.xref[objNum] = xrefEntry{inStream: true, stream: num}
+			}
+		}
+	}
+
+	if d.trailer["Root"] == nil {
+		// Last resort: any catalog object
+		for num := range d.xref {
+			if c, ok := d.object(num).(dict); ok && c["Type"] == name("Catalog") {
+				d.trailer["Root"] = objref{num: num}
+
+				break
+			}
+		}
+	}
+}
+
+// readIndirect reads the "n g obj ... endobj" object at offset. Strings and
+// streams are returned still encrypted.
+func (d *Document) readIndirect(offset int) (object, objref, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, objref{}, errors.New("object offset out of range")
+	}
+
+	l := newLexer(d.data, offset)
+
+	num, ok1 := l.readInt()
+	gen, ok2 := l.readInt()
+
+	if !ok1 || !ok2 {
+		return nil, objref{}, fmt.Errorf("no object at offset %d", offset)
+	}
+
+	if err := l.expectKeyword("obj"); err != nil {
+		return nil, objref{}, err
+	}
+
+	ref := objref{num: num, gen: gen}
+
+	obj, err := l.readObject()
+	if err != nil {
+		return nil, ref, err
+	}
+
+	hdr, ok := obj.(dict)
+	if !ok {
+		return obj, ref, nil
+	}
+
+	l.skipSpace()
+
+	if !bytes.HasPrefix(d.data[l.pos:], []byte("stream")) {
+		return obj, ref, nil
+	}
+
+	start := l.pos + len("stream")
+	if start < len(d.data) && d.data[start] == '\r' {
+		start++
+	}
+
+	if start < len(d.data) && d.data[start] == '\n' {
+		start++
+	}
+
+	end := -1
+
+	if n, ok := d.streamLength(hdr["Length"]); ok && start+n <= len(d.data) {
+		rest := bytes.TrimLeft(d.data[start+n:min(start+n+32, len(d.data))], "\r\n\t ")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			end = start + n
+		}
+	}
+
+	if end < 0 {
+		// Wrong or missing /Length; find the end marker instead
+		i := bytes.Index(d.data[start:], []byte("endstream"))
+		if i < 0 {
+			return nil, ref, errors.New("unterminated stream")
+		}
+
+		end = start + i
+		for end > start && (d.data[end-1] == '\n' || d.data[end-1] == '\r') {
+			end--
+		}
+	}
+
+	return &stream{hdr: hdr, data: d.data[start:end], ref: ref}, ref, nil
+}
+
+// streamLength resolves a stream /Length, which may be an indirect object.
+func (d *Document) streamLength(obj object) (int, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return int(v), v >= 0
+	case objref:
+		if d.loading[v.num] {
+			return 0, false
+		}
+
+		n, ok := d.resolve(v).(int64)
+
+		return int(n), ok && n >= 0
+	}
+
+	return 0, false
+}
+
+// object loads an indirect object by number.
+func (d *Document) object(num int) object {
+	if obj, ok := d.cache[num]; ok {
+		return obj
+	}
+
+	if d.loading[num] {
+		// Reference cycle
+		return nil
+	}
+
+	d.loading[num] = true
+	defer delete(d.loading, num)
+
+	e, ok := d.xref[num]
+	if !ok {
+		return nil
+	}
+
+	var obj object
+
+	if e.inStream {
+		obj = d.objectFromStream(e.stream, num)
+	} else {
+		o, ref, err := d.readIndirect(e.offset)
+		if err == nil && ref.num == num {
+			obj = d.decryptObject(o, ref)
+		} else if !d.scanned {
+			if off := d.findObject(num); off >= 0 {
+				if o, ref, err := d.readIndirect(off); err == nil {
+					obj = d.decryptObject(o, ref)
+				}
+			}
+		}
+	}
+
+	d.cache[num] = obj
+
+	return obj
+}
+
+// findObject searches the file for the definition of an object whose xref
+// offset turned out to be wrong.
+func (d *Document) findObject(num int) int {
+	re := regexp.MustCompile(`(?:^|[\r\n\s])(` + strconv.Itoa(num) + `)\s+\d+\s+obj\b`)
+
+	matches := re.FindAllSubmatchIndex(d.data, -1)
+	if len(matches) == 0 {
+		return -1
+	}
+
+	return matches[len(matches)-1][2]
+}
+
+// objectStream decodes an object stream and indexes its objects.
+func (d *Document) objectStream(stmNum int) (*objectStream, error) {
+	if stm, ok := d.objstm[stmNum]; ok {
+		return stm, nil
+	}
+
+	d.objstm[stmNum] = &objectStream{} // guards against self-reference
+
+	s, ok := d.object(stmNum).(*stream)
+	if !ok {
+		return nil, errors.New("object stream not found")
+	}
+
+	data, err := d.decodeStream(s)
+	if err != nil {
+		return nil, err
+	}
+
+	n := d.intOr(s.hdr["N"], 0)
+	first := d.intOr(s.hdr["First"], 0)
+
+	stm := &objectStream{data: data, offsets: map[int]int{}}
+	l := newLexer(data, 0)
+
+	for range n {
+		num, ok1 := l.readInt()
+		off, ok2 := l.readInt()
+
+		if !ok1 || !ok2 {
+			break
+		}
+
+		stm.offsets[num] = first + off
+	}
+
+	d.objstm[stmNum] = stm
+
+	return stm, nil
+}
+
+// objectFromStream reads an object stored in an object stream. Objects in
+// object streams are not encrypted individually.
+func (d *Document) objectFromStream(stmNum, num int) object {
+	stm, err := d.objectStream(stmNum)
+	if err != nil {
+		return nil
+	}
+
+	off, ok := stm.offsets[num]
+	if !ok || off >= len(stm.data) {
+		return nil
+	}
+
+	obj, err := newLexer(stm.data, off).readObject()
+	if err != nil {
+		return nil
+	}
+
+	return obj
+}
+
+// resolve follows indirect references.
+func (d *Document) resolve(obj object) object {
+	for range 32 {
+		ref, ok := obj.(objref)
+		if !ok {
+			return obj
+		}
+
+		obj = d.object(ref.num)
+	}
+
+	return nil
+}
+
+func (d *Document) resolveDict(obj object) dict {
+	switch v := d.resolve(obj).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.hdr
+	}
+
+	return nil
+}
+
+func (d *Document) resolveArray(obj object) array {
+	a, _ := d.resolve(obj).(array)
+
+	return a
+}
+
+func (d *Document) number(obj object) (float64, bool) {
+	switch v := d.resolve(obj).(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func (d *Document) intOr(obj object, def int) int {
+	if f, ok := d.number(obj); ok {
+		return int(f)
+	}
+
+	return def
+}
+
+// streamData returns the decoded data of a stream object.
+func (d *Document) streamData(obj object) ([]byte, error) {
+	s, ok := d.resolve(obj).(*stream)
+	if !ok {
+		return nil, errors.New("stream expected")
+	}
+
+	return d.decodeStream(s)
+}
+
+// loadPages walks the page tree, applying inherited attributes.
+func (d *Document) loadPages() error {
+	root := d.resolveDict(d.trailer["Root"])
+	if root == nil {
+		return errors.New("document catalog not found")
+	}
+
+	d.pages = nil
+
+	visited := map[int]bool{}
+
+	var walk func(node object, inherited dict, depth int)
+
+	walk = func(node object, inherited dict, depth int) {
+		if depth > 64 || len(d.pages) >= maxPages {
+			return
+		}
+
+		if ref, ok := node.(objref); ok {
+			if visited[ref.num] {
+				return
+			}
+
+			visited[ref.num] = true
+		}
+
+		n := d.resolveDict(node)
+		if n == nil {
+			return
+		}
+
+		attrs := dict{}
+		for k, v := range inherited {
+			attrs[k] = v
+		}
+
+		for _, k := range []name{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := n[k]; ok {
+				attrs[k] = v
+			}
+		}
+
+		kids := d.resolveArray(n["Kids"])
+		if n["Type"] == name("Pages") || (n["Type"] == nil && kids != nil) {
+			for _, kid := range kids {
+				walk(kid, attrs, depth+1)
+			}
+
+			return
+		}
+
+		page := dict{}
+		for k, v := range n {
+			page[k] = v
+		}
+
+		for k, v := range attrs {
+			page[k] = v
+		}
+
+		d.pages = append(d.pages, page)
+	}
+
+	walk(root["Pages"], dict{}, 0)
+
+	if len(d.pages) == 0 {
+		return errors.New("document has no pages")
+	}
+
+	return nil
+}
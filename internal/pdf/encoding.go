@@ -0,0 +1,231 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// encoding maps the single-byte codes of a simple font to text.
+type encoding [256]rune
+
+// winAnsiHigh is the 0x80-0x9F range of WinAnsiEncoding (Windows-1252);
+// the rest of the upper half is Latin-1.
+var winAnsiHigh = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// macRomanHigh is the upper half of MacRomanEncoding.
+var macRomanHigh = [128]rune{
+	'Ä', 'Å', 'Ç', 'É', 'Ñ', 'Ö', 'Ü', 'á', 'à', 'â', 'ä', 'ã', 'å', 'ç', 'é', 'è',
+	'ê', 'ë', 'í', 'ì', 'î', 'ï', 'ñ', 'ó', 'ò', 'ô', 'ö', 'õ', 'ú', 'ù', 'û', 'ü',
+	'†', '°', '¢', '£', '§', '•', '¶', 'ß', '®', '©', '™', '´', '¨', '≠', 'Æ', 'Ø',
+	'∞', '±', '≤', '≥', '¥', 'µ', '∂', '∑', '∏', 'π', '∫', 'ª', 'º', 'Ω', 'æ', 'ø',
+	'¿', '¡', '¬', '√', 'ƒ', '≈', '∆', '«', '»', '…', ' ', 'À', 'Ã', 'Õ', 'Œ', 'œ',
+	'–', '—', '“', '”', '‘', '’', '÷', '◊', 'ÿ', 'Ÿ', '⁄', '€', '‹', '›', 'ﬁ', 'ﬂ',
+	'‡', '·', '‚', '„', '‰', 'Â', 'Ê', 'Á', 'Ë', 'È', 'Í', 'Î', 'Ï', 'Ì', 'Ó', 'Ô',
+	'\uf8ff', 'Ò', 'Ú', 'Û', 'Ù', 'ı', 'ˆ', '˜', '¯', '˘', '˙', '˚', '¸', '˝', '˛', 'ˇ',
+}
+
+// standardHigh is the upper half of StandardEncoding, keyed by code.
+var standardHigh = map[byte]rune{
+	0xa1: '¡', 0xa2: '¢', 0xa3: '£', 0xa4: '⁄', 0xa5: '¥', 0xa6: 'ƒ', 0xa7: '§', 0xa8: '¤',
+	0xa9: '\'', 0xaa: '“', 0xab: '«', 0xac: '‹', 0xad: '›', 0xae: 'ﬁ', 0xaf: 'ﬂ',
+	0xb1: '–', 0xb2: '†', 0xb3: '‡', 0xb4: '·', 0xb6: '¶', 0xb7: '•', 0xb8: '‚', 0xb9: '„',
+	0xba: '”', 0xbb: '»', 0xbc: '…', 0xbd: '‰', 0xbf: '¿',
+	0xc1: '`', 0xc2: '´', 0xc3: 'ˆ', 0xc4: '˜', 0xc5: '¯', 0xc6: '˘', 0xc7: '˙', 0xc8: '¨',
+	0xca: '˚', 0xcb: '¸', 0xcd: '˝', 0xce: '˛', 0xcf: 'ˇ', 0xd0: '—',
+	0xe1: 'Æ', 0xe3: 'ª', 0xe8: 'Ł', 0xe9: 'Ø', 0xea: 'Œ', 0xeb: 'º',
+	0xf1: 'æ', 0xf5: 'ı', 0xf8: 'ł', 0xf9: 'ø', 0xfa: 'œ', 0xfb: 'ß',
+}
+
+var (
+	winAnsiEncoding  = buildWinAnsi()
+	macRomanEncoding = buildMacRoman()
+	standardEncoding = buildStandard()
+)
+
+func asciiBase() encoding {
+	var e encoding
+	for i := 32; i < 127; i++ {
+		e[i] = rune(i)
+	}
+
+	return e
+}
+
+func buildWinAnsi() encoding {
+	e := asciiBase()
+	for i, r := range winAnsiHigh {
+		e[0x80+i] = r
+	}
+
+	for i := 0xa0; i < 256; i++ {
+		e[i] = rune(i)
+	}
+
+	// Non-breaking and soft hyphens read better as plain characters
+	e[0xa0] = ' '
+	e[0xad] = '-'
+
+	return e
+}
+
+func buildMacRoman() encoding {
+	e := asciiBase()
+	for i, r := range macRomanHigh {
+		e[0x80+i] = r
+	}
+
+	e[0xca] = ' '
+
+	return e
+}
+
+func buildStandard() encoding {
+	e := asciiBase()
+	e['\''] = '’'
+	e['`'] = '‘'
+
+	for c, r := range standardHigh {
+		e[c] = r
+	}
+
+	return e
+}
+
+// baseEncoding returns a named base encoding.
+func baseEncoding(n name) (encoding, bool) {
+	switch n {
+	case "WinAnsiEncoding":
+		return winAnsiEncoding, true
+	case "MacRomanEncoding", "MacExpertEncoding":
+		return macRomanEncoding, true
+	case "StandardEncoding":
+		return standardEncoding, true
+	}
+
+	return encoding{}, false
+}
+
+// glyphNames maps the glyph names used in /Differences arrays to text. Names
+// not listed are resolved by glyphText from their structure.
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$",
+	"percent": "%", "ampersand": "&", "quotesingle": "'", "quoteright": "’", "parenleft": "(",
+	"parenright": ")", "asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-",
+	"period": ".", "slash": "/", "zero": "0", "one": "1", "two": "2", "three": "3",
+	"four": "4", "five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"colon": ":", "semicolon": ";", "less": "<", "equal": "=", "greater": ">",
+	"question": "?", "at": "@", "bracketleft": "[", "backslash": "\\", "bracketright": "]",
+	"asciicircum": "^", "underscore": "_", "grave": "`", "quoteleft": "‘", "braceleft": "{",
+	"bar": "|", "braceright": "}", "asciitilde": "~",
+
+	"exclamdown": "¡", "cent": "¢", "sterling": "£", "currency": "¤", "yen": "¥",
+	"brokenbar": "¦", "section": "§", "dieresis": "¨", "copyright": "©", "ordfeminine": "ª",
+	"guillemotleft": "«", "logicalnot": "¬", "registered": "®", "macron": "¯", "degree": "°",
+	"plusminus": "±", "twosuperior": "²", "threesuperior": "³", "acute": "´", "mu": "µ",
+	"paragraph": "¶", "periodcentered": "·", "cedilla": "¸", "onesuperior": "¹",
+	"ordmasculine": "º", "guillemotright": "»", "onequarter": "¼", "onehalf": "½",
+	"threequarters": "¾", "questiondown": "¿", "multiply": "×", "divide": "÷",
+	"nbspace": " ", "nonbreakingspace": " ", "sfthyphen": "-", "softhyphen": "-",
+
+	"Agrave": "À", "Aacute": "Á", "Acircumflex": "Â", "Atilde": "Ã", "Adieresis": "Ä",
+	"Aring": "Å", "AE": "Æ", "Ccedilla": "Ç", "Egrave": "È", "Eacute": "É",
+	"Ecircumflex": "Ê", "Edieresis": "Ë", "Igrave": "Ì", "Iacute": "Í", "Icircumflex": "Î",
+	"Idieresis": "Ï", "Eth": "Ð", "Ntilde": "Ñ", "Ograve": "Ò", "Oacute": "Ó",
+	"Ocircumflex": "Ô", "Otilde": "Õ", "Odieresis": "Ö", "Oslash": "Ø", "Ugrave": "Ù",
+	"Uacute": "Ú", "Ucircumflex": "Û", "Udieresis": "Ü", "Yacute": "Ý", "Thorn": "Þ",
+	"germandbls": "ß", "agrave": "à", "aacute": "á", "acircumflex": "â", "atilde": "ã",
+	"adieresis": "ä", "aring": "å", "ae": "æ", "ccedilla": "ç", "egrave": "è",
+	"eacute": "é", "ecircumflex": "ê", "edieresis": "ë", "igrave": "ì", "iacute": "í",
+	"icircumflex": "î", "idieresis": "ï", "eth": "ð", "ntilde": "ñ", "ograve": "ò",
+	"oacute": "ó", "ocircumflex": "ô", "otilde": "õ", "odieresis": "ö", "oslash": "ø",
+	"ugrave": "ù", "uacute": "ú", "ucircumflex": "û", "udieresis": "ü", "yacute": "ý",
+	"thorn": "þ", "ydieresis": "ÿ",
+
+	"Amacron": "Ā", "amacron": "ā", "Abreve": "Ă", "abreve": "ă", "Aogonek": "Ą", "aogonek": "ą",
+	"Cacute": "Ć", "cacute": "ć", "Ccaron": "Č", "ccaron": "č", "Dcaron": "Ď", "dcaron": "ď",
+	"Dcroat": "Đ", "dcroat": "đ", "Emacron": "Ē", "emacron": "ē", "Edotaccent": "Ė", "edotaccent": "ė",
+	"Eogonek": "Ę", "eogonek": "ę", "Ecaron": "Ě", "ecaron": "ě", "Gbreve": "Ğ", "gbreve": "ğ",
+	"Idotaccent": "İ", "dotlessi": "ı", "Lslash": "Ł", "lslash": "ł", "Nacute": "Ń", "nacute": "ń",
+	"Ncaron": "Ň", "ncaron": "ň", "Ohungarumlaut": "Ő", "ohungarumlaut": "ő", "OE": "Œ", "oe": "œ",
+	"Rcaron": "Ř", "rcaron": "ř", "Sacute": "Ś", "sacute": "ś", "Scedilla": "Ş", "scedilla": "ş",
+	"Scaron": "Š", "scaron": "š", "Tcaron": "Ť", "tcaron": "ť", "Uring": "Ů", "uring": "ů",
+	"Uhungarumlaut": "Ű", "uhungarumlaut": "ű", "Ydieresis": "Ÿ", "Zacute": "Ź", "zacute": "ź",
+	"Zdotaccent": "Ż", "zdotaccent": "ż", "Zcaron": "Ž", "zcaron": "ž", "florin": "ƒ",
+
+	"circumflex": "ˆ", "caron": "ˇ", "breve": "˘", "dotaccent": "˙", "ring": "˚",
+	"ogonek": "˛", "tilde": "˜", "hungarumlaut": "˝",
+
+	"endash": "–", "emdash": "—", "quotesinglbase": "‚", "quotedblleft": "“",
+	"quotedblright": "”", "quotedblbase": "„", "dagger": "†", "daggerdbl": "‡",
+	"bullet": "•", "ellipsis": "…", "perthousand": "‰", "guilsinglleft": "‹",
+	"guilsinglright": "›", "fraction": "⁄", "Euro": "€", "trademark": "™",
+	"minus": "−", "figuredash": "‒", "horizontalbar": "―", "bulletoperator": "∙",
+	"middot": "·", "periodcentered.cap": "·", "arrowright": "→", "arrowleft": "←",
+	"checkmark": "✓", "lozenge": "◊", "filledbox": "■", "H22073": "□", "H18543": "▪",
+	"H18551": "▫", "H18533": "●", "circle": "○", "triagrt": "►", "blackcircle": "●",
+	"notequal": "≠", "lessequal": "≤", "greaterequal": "≥", "infinity": "∞",
+	"approxequal": "≈", "partialdiff": "∂", "summation": "∑", "product": "∏", "pi": "π",
+	"integral": "∫", "Omega": "Ω", "Delta": "∆", "radical": "√", "apple": "\uf8ff",
+
+	"ff": "ff", "fi": "fi", "fl": "fl", "ffi": "ffi", "ffl": "ffl", "st": "st",
+}
+
+// glyphText maps a glyph name to text, following the Adobe glyph naming
+// conventions: "uniXXXX" sequences, "uXXXX[XX]", ligatures joined with
+// underscores and variant suffixes after a period.
+func glyphText(glyph string) string {
+	if t, ok := glyphNames[glyph]; ok {
+		return t
+	}
+
+	if i := strings.IndexByte(glyph, '.'); i > 0 {
+		glyph = glyph[:i]
+		if t, ok := glyphNames[glyph]; ok {
+			return t
+		}
+	}
+
+	if strings.Contains(glyph, "_") {
+		var sb strings.Builder
+		for _, part := range strings.Split(glyph, "_") {
+			sb.WriteString(glyphText(part))
+		}
+
+		return sb.String()
+	}
+
+	if strings.HasPrefix(glyph, "uni") && len(glyph) >= 7 && (len(glyph)-3)%4 == 0 {
+		var sb strings.Builder
+
+		for i := 3; i+4 <= len(glyph); i += 4 {
+			v, err := strconv.ParseUint(glyph[i:i+4], 16, 32)
+			if err != nil || (v >= 0xd800 && v <= 0xdfff) {
+				return ""
+			}
+
+			sb.WriteRune(rune(v))
+		}
+
+		return sb.String()
+	}
+
+	if strings.HasPrefix(glyph, "u") && len(glyph) >= 5 && len(glyph) <= 7 {
+		if v, err := strconv.ParseUint(glyph[1:], 16, 32); err == nil && utf8.ValidRune(rune(v)) {
+			return string(rune(v))
+		}
+	}
+
+	// Single letters are named after themselves
+	if len(glyph) == 1 && glyph[0] > ' ' && glyph[0] < 0x7f {
+		return glyph
+	}
+
+	return ""
+}
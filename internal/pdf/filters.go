@@ -0,0 +1,373 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxStreamSize bounds the decoded size of a single stream, protecting
+// against decompression bombs.
+const maxStreamSize = 64 << 20
+
+// errImageFilter is returned for image-only filters, whose data never holds text.
+var errImageFilter = errors.New("image stream")
+
+// decodeStream applies the filters of a stream to its (decrypted) data.
+func (d *Document) decodeStream(s *stream) ([]byte, error) {
+	data := s.data
+
+	filters := d.resolveArray(s.hdr["Filter"])
+	parms := d.resolveArray(s.hdr["DecodeParms"])
+
+	if len(filters) == 0 {
+		if f, ok := d.resolve(s.hdr["Filter"]).(name); ok {
+			filters = array{f}
+		}
+	}
+
+	if len(parms) == 0 {
+		if p, ok := d.resolve(s.hdr["DecodeParms"]).(dict); ok {
+			parms = array{p}
+		}
+	}
+
+	for i, f := range filters {
+		filter, _ := d.resolve(f).(name)
+
+		var parm dict
+		if i < len(parms) {
+			parm, _ = d.resolve(parms[i]).(dict)
+		}
+
+		var err error
+
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data)
+			if err == nil {
+				data, err = d.unpredict(data, parm)
+			}
+		case "LZWDecode", "LZW":
+			early := true
+			if v, ok := d.resolve(parm["EarlyChange"]).(int64); ok && v == 0 {
+				early = false
+			}
+
+			data, err = lzwDecode(data, early)
+			if err == nil {
+				data, err = d.unpredict(data, parm)
+			}
+		case "ASCIIHexDecode", "AHx":
+			data = asciiHexDecode(data)
+		case "ASCII85Decode", "A85":
+			data, err = ascii85Decode(data)
+		case "RunLengthDecode", "RL":
+			data = runLengthDecode(data)
+		case "Crypt":
+			// Identity crypt filter; decryption has already been applied
+		case "DCTDecode", "DCT", "JPXDecode", "CCITTFaxDecode", "CCF", "JBIG2Decode":
+			return data, errImageFilter
+		default:
+			return nil, fmt.Errorf("unsupported filter %q", filter)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filter, err)
+		}
+	}
+
+	return data, nil
+}
+
+// inflate decompresses zlib data. Many producers write truncated or slightly
+// corrupt streams, so whatever could be decompressed before an error is kept.
+func inflate(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err == nil {
+		r = zr
+	} else {
+		// Some writers omit the zlib header
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamSize+1))
+	if len(out) > maxStreamSize {
+		return nil, errors.New("stream too large")
+	}
+
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// unpredict reverses the PNG and TIFF predictors of Flate and LZW streams.
+func (d *Document) unpredict(data []byte, parm dict) ([]byte, error) {
+	predictor, _ := d.resolve(parm["Predictor"]).(int64)
+	if predictor <= 1 {
+		return data, nil
+	}
+
+	colors := d.intOr(parm["Colors"], 1)
+	bpc := d.intOr(parm["BitsPerComponent"], 8)
+	columns := d.intOr(parm["Columns"], 1)
+
+	if colors < 1 || bpc < 1 || columns < 1 || colors*bpc*columns > 1<<24 {
+		return nil, errors.New("invalid predictor parameters")
+	}
+
+	bpp := max(1, colors*bpc/8)
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	if predictor == 2 {
+		if bpc != 8 {
+			return data, nil
+		}
+
+		for row := 0; row+rowLen <= len(data); row += rowLen {
+			for i := bpp; i < rowLen; i++ {
+				data[row+i] += data[row+i-bpp]
+			}
+		}
+
+		return data, nil
+	}
+
+	// PNG predictors: every row starts with its filter type byte
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+
+	for pos := 0; pos < len(data); pos += rowLen + 1 {
+		ft := data[pos]
+
+		end := min(pos+1+rowLen, len(data))
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:end])
+
+		for i := range rowLen {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+
+			up := prev[i]
+
+			switch ft {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+
+		out = append(out, row[:end-pos-1]...)
+		prev = row
+	}
+
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// lzwDecode decodes LZW data with the variable code width used by PDF.
+// compress/lzw cannot be used as it does not support the early change.
+func lzwDecode(data []byte, early bool) ([]byte, error) {
+	const (
+		clearCode = 256
+		eodCode   = 257
+	)
+
+	var (
+		out   []byte
+		table [][]byte
+		prev  []byte
+		bits  uint32
+		nbits uint
+	)
+
+	reset := func() {
+		table = table[:0]
+		for i := range 256 {
+			table = append(table, []byte{byte(i)})
+		}
+
+		table = append(table, nil, nil)
+		prev = nil
+	}
+
+	reset()
+
+	width := uint(9)
+
+	for _, b := range data {
+		bits = bits<<8 | uint32(b)
+		nbits += 8
+
+		for nbits >= width {
+			code := int(bits >> (nbits - width) & (1<<width - 1))
+			nbits -= width
+
+			switch {
+			case code == clearCode:
+				reset()
+
+				width = 9
+
+				continue
+			case code == eodCode:
+				return out, nil
+			}
+
+			var entry []byte
+
+			switch {
+			case code < len(table) && table[code] != nil:
+				entry = table[code]
+			case code == len(table) && prev != nil:
+				entry = append(append([]byte{}, prev...), prev[0])
+			default:
+				return out, errors.New("invalid LZW code")
+			}
+
+			out = append(out, entry...)
+			if len(out) > maxStreamSize {
+				return nil, errors.New("stream too large")
+			}
+
+			if prev != nil {
+				table = append(table, append(append([]byte{}, prev...), entry[0]))
+			}
+
+			prev = entry
+
+			next := len(table)
+			if early {
+				next++
+			}
+
+			if next >= 1<<width && width < 12 {
+				width++
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func asciiHexDecode(data []byte) []byte {
+	out := make([]byte, 0, len(data)/2)
+
+	var hi byte
+
+	half := false
+
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+
+		v, ok := unhex(c)
+		if !ok {
+			continue
+		}
+
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+
+		half = !half
+	}
+
+	if half {
+		out = append(out, hi<<4)
+	}
+
+	return out
+}
+
+func ascii85Decode(data []byte) ([]byte, error) {
+	clean := make([]byte, 0, len(data))
+	for _, c := range data {
+		if !isSpace(c) {
+			clean = append(clean, c)
+		}
+	}
+
+	clean = bytes.TrimPrefix(clean, []byte("<~"))
+	if i := bytes.Index(clean, []byte("~>")); i >= 0 {
+		clean = clean[:i]
+	}
+
+	out := make([]byte, len(clean)*4/5+4*bytes.Count(clean, []byte("z"))+4)
+
+	n, _, err := ascii85.Decode(out, clean, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return out[:n], nil
+}
+
+func runLengthDecode(data []byte) []byte {
+	var out []byte
+
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+
+		switch {
+		case n == 128:
+			return out
+		case n < 128:
+			end := min(i+n+1, len(data))
+			out = append(out, data[i:end]...)
+			i = end
+		default:
+			if i < len(data) {
+				out = append(out, bytes.Repeat([]byte{data[i]}, 257-n)...)
+				i++
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,548 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+)
+
+// font decodes the strings shown with a font into text and glyph widths.
+type font struct {
+	toUnicode *cmap // ToUnicode map, if any
+	codes     *cmap // code space and CIDs of a composite font; nil for Identity
+	composite bool
+	ucs2      bool // composite codes are Unicode (UCS2/UTF16 CMaps)
+	enc       encoding
+	widths    map[int]float64 // glyph space widths by code (simple) or CID (composite)
+	defWidth  float64
+	scale     float64 // glyph space to text space
+}
+
+// glyph is one decoded character code.
+type glyph struct {
+	text  string
+	width float64 // horizontal displacement in text space at font size 1
+	space bool    // single-byte code 32, which word spacing applies to
+}
+
+// symbolBullets lists the bullet-like codes of common symbol fonts, whose
+// built-in encodings are not Latin text.
+var symbolBullets = map[string][]byte{
+	"Symbol":    {0xb7},
+	"Dingbats":  {0x6c, 0x6e, 0x75, 0x76},
+	"Wingdings": {0x6c, 0x6e, 0x71, 0x76, 0x77, 0x9f, 0xa7, 0xa8, 0xd8, 0xfc},
+}
+
+// fallbackFont is used when a font resource is missing.
+var fallbackFont = &font{enc: standardEncoding, defWidth: 500, scale: 0.001}
+
+// loadFont builds a font from its dictionary. Fonts are cached per object.
+func (d *Document) loadFont(obj object) *font {
+	ref, isRef := obj.(objref)
+	if isRef {
+		if f, ok := d.fonts[ref.num]; ok {
+			return f
+		}
+	}
+
+	fd := d.resolveDict(obj)
+	if fd == nil {
+		return fallbackFont
+	}
+
+	f := &font{scale: 0.001, widths: map[int]float64{}}
+
+	if tu := d.resolve(fd["ToUnicode"]); tu != nil {
+		if data, err := d.streamData(tu); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+
+	if fd["Subtype"] == name("Type0") {
+		d.loadCompositeFont(f, fd)
+	} else {
+		d.loadSimpleFont(f, fd)
+	}
+
+	if isRef {
+		d.fonts[ref.num] = f
+	}
+
+	return f
+}
+
+func (d *Document) loadSimpleFont(f *font, fd dict) {
+	baseFont, _ := d.resolve(fd["BaseFont"]).(name)
+
+	// Type 1 fonts default to their built-in encoding, which is usually
+	// StandardEncoding; TrueType fonts are almost always WinAnsi
+	f.enc = standardEncoding
+	if fd["Subtype"] == name("TrueType") {
+		f.enc = winAnsiEncoding
+	}
+
+	switch e := d.resolve(fd["Encoding"]).(type) {
+	case name:
+		if base, ok := baseEncoding(e); ok {
+			f.enc = base
+		}
+	case dict:
+		if bn, ok := d.resolve(e["BaseEncoding"]).(name); ok {
+			if base, ok := baseEncoding(bn); ok {
+				f.enc = base
+			}
+		}
+
+		code := 0
+
+		for _, item := range d.resolveArray(e["Differences"]) {
+			switch v := d.resolve(item).(type) {
+			case int64:
+				code = int(v)
+			case name:
+				if code >= 0 && code < 256 {
+					f.enc[code] = 0
+					if t := glyphText(string(v)); t != "" {
+						if r := []rune(t); len(r) == 1 {
+							f.enc[code] = r[0]
+						} else {
+							// Ligatures map to several characters; record them
+							// as ToUnicode entries
+							if f.toUnicode == nil {
+								f.toUnicode = newCMap()
+							}
+
+							if _, ok := f.toUnicode.chars[string([]byte{byte(code)})]; !ok {
+								f.toUnicode.chars[string([]byte{byte(code)})] = t
+							}
+						}
+					}
+				}
+
+				code++
+			}
+		}
+	}
+
+	// Bullets are the only glyphs of symbol fonts that matter in a CV
+	for family, codes := range symbolBullets {
+		if strings.Contains(string(baseFont), family) {
+			for _, c := range codes {
+				f.enc[c] = '•'
+			}
+		}
+	}
+
+	if fd["Subtype"] == name("Type3") {
+		if m := d.resolveArray(fd["FontMatrix"]); len(m) == 6 {
+			if s, ok := d.number(m[0]); ok && s != 0 {
+				f.scale = s
+			}
+		}
+	}
+
+	desc := d.resolveDict(fd["FontDescriptor"])
+
+	f.defWidth = 500
+	if strings.Contains(string(baseFont), "Courier") {
+		f.defWidth = 600
+	}
+
+	if mw, ok := d.number(desc["MissingWidth"]); ok && mw > 0 {
+		f.defWidth = mw
+	}
+
+	first := d.intOr(fd["FirstChar"], 0)
+	for i, w := range d.resolveArray(fd["Widths"]) {
+		if v, ok := d.number(w); ok {
+			f.widths[first+i] = v
+		}
+	}
+}
+
+func (d *Document) loadCompositeFont(f *font, fd dict) {
+	f.composite = true
+
+	switch e := d.resolve(fd["Encoding"]).(type) {
+	case name:
+		if strings.Contains(string(e), "UCS2") || strings.Contains(string(e), "UTF16") {
+			f.ucs2 = true
+		}
+	case *stream:
+		if data, err := d.decodeStream(e); err == nil {
+			f.codes = parseCMap(data)
+		}
+	}
+
+	descendants := d.resolveArray(fd["DescendantFonts"])
+	if len(descendants) == 0 {
+		f.defWidth = 1000
+
+		return
+	}
+
+	cid := d.resolveDict(descendants[0])
+
+	f.defWidth = 1000
+	if dw, ok := d.number(cid["DW"]); ok {
+		f.defWidth = dw
+	}
+
+	// /W is a list of "c [w1 w2 ...]" and "cfirst clast w" entries
+	w := d.resolveArray(cid["W"])
+	for i := 0; i < len(w); {
+		first, ok := d.number(w[i])
+		if !ok || i+1 >= len(w) {
+			break
+		}
+
+		if list := d.resolveArray(w[i+1]); list != nil {
+			for j, v := range list {
+				if width, ok := d.number(v); ok {
+					f.widths[int(first)+j] = width
+				}
+			}
+
+			i += 2
+
+			continue
+		}
+
+		if i+2 >= len(w) {
+			break
+		}
+
+		last, ok1 := d.number(w[i+1])
+		width, ok2 := d.number(w[i+2])
+
+		if ok1 && ok2 && last >= first && last-first < 1<<16 {
+			for c := int(first); c <= int(last); c++ {
+				f.widths[c] = width
+			}
+		}
+
+		i += 3
+	}
+}
+
+// decode splits a shown string into glyphs.
+func (f *font) decode(s string) []glyph {
+	glyphs := make([]glyph, 0, len(s))
+
+	for i := 0; i < len(s); {
+		n := 1
+
+		if f.composite {
+			n = 2
+			if f.codes != nil {
+				n = f.codes.codeLen(s[i:])
+			}
+		}
+
+		n = min(n, len(s)-i)
+		code := s[i : i+n]
+		i += n
+
+		value := 0
+		for j := range len(code) {
+			value = value<<8 | int(code[j])
+		}
+
+		var g glyph
+
+		text, mapped := "", false
+		if f.toUnicode != nil {
+			text, mapped = f.toUnicode.lookup(code)
+		}
+
+		var w float64
+
+		if f.composite {
+			cid := value
+			if f.codes != nil {
+				cid = f.codes.cid(code, value)
+			}
+
+			if !mapped && f.ucs2 {
+				text = decodeUTF16([]byte(code))
+			}
+
+			w = f.defWidth
+			if v, ok := f.widths[cid]; ok {
+				w = v
+			}
+		} else {
+			if !mapped && f.enc[value] != 0 {
+				text = string(f.enc[value])
+			}
+
+			w = f.defWidth
+			if v, ok := f.widths[value]; ok {
+				w = v
+			}
+		}
+
+		if n == 1 && value == ' ' {
+			g.space = true
+		}
+
+		g.text = text
+		g.width = w * f.scale
+
+		glyphs = append(glyphs, g)
+	}
+
+	return glyphs
+}
+
+// decodeUTF16 decodes big-endian UTF-16 text.
+func decodeUTF16(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// cmap is a parsed CMap: a ToUnicode map or the encoding of a composite font.
+type cmap struct {
+	space     []codeRange
+	chars     map[string]string
+	ranges    []bfRange
+	cidChars  map[string]int
+	cidRanges []cidRange
+}
+
+type codeRange struct {
+	lo, hi []byte
+}
+
+// bfRange maps a range of codes either to consecutive text starting at
+// text, or to the individual entries of texts.
+type bfRange struct {
+	lo, hi []byte
+	text   []byte
+	texts  []string
+}
+
+type cidRange struct {
+	lo, hi []byte
+	cid    int
+}
+
+// maxCMapTokens bounds the work spent on a single CMap.
+const maxCMapTokens = 1 << 20
+
+func newCMap() *cmap {
+	return &cmap{chars: map[string]string{}, cidChars: map[string]int{}}
+}
+
+// parseCMap parses the code space, bfchar/bfrange and cidchar/cidrange
+// sections of a CMap. Everything else is ignored.
+func parseCMap(data []byte) *cmap {
+	c := newCMap()
+	l := newLexer(data, 0)
+	l.refs = false
+
+	var operands []object
+
+	for range maxCMapTokens {
+		obj, err := l.readObject()
+		if err != nil {
+			break
+		}
+
+		kw, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+
+			continue
+		}
+
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].(string)
+				hi, ok2 := operands[i+1].(string)
+
+				if ok1 && ok2 && len(lo) == len(hi) && len(lo) > 0 && len(lo) <= 4 {
+					c.space = append(c.space, codeRange{lo: []byte(lo), hi: []byte(hi)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].(string)
+				if !ok {
+					continue
+				}
+
+				switch dst := operands[i+1].(type) {
+				case string:
+					c.chars[src] = decodeUTF16([]byte(dst))
+				case name:
+					c.chars[src] = glyphText(string(dst))
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(string)
+				hi, ok2 := operands[i+1].(string)
+
+				if !ok1 || !ok2 || len(lo) != len(hi) {
+					continue
+				}
+
+				r := bfRange{lo: []byte(lo), hi: []byte(hi)}
+
+				switch dst := operands[i+2].(type) {
+				case string:
+					r.text = []byte(dst)
+				case array:
+					for _, t := range dst {
+						s, _ := t.(string)
+						r.texts = append(r.texts, decodeUTF16([]byte(s)))
+					}
+				default:
+					continue
+				}
+
+				c.ranges = append(c.ranges, r)
+			}
+		case "endcidchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(string)
+				cid, ok2 := operands[i+1].(int64)
+
+				if ok1 && ok2 {
+					c.cidChars[src] = int(cid)
+				}
+			}
+		case "endcidrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(string)
+				hi, ok2 := operands[i+1].(string)
+				cid, ok3 := operands[i+2].(int64)
+
+				if ok1 && ok2 && ok3 && len(lo) == len(hi) {
+					c.cidRanges = append(c.cidRanges, cidRange{lo: []byte(lo), hi: []byte(hi), cid: int(cid)})
+				}
+			}
+		}
+
+		operands = operands[:0]
+	}
+
+	return c
+}
+
+// codeLen returns the length of the character code at the start of s.
+func (c *cmap) codeLen(s string) int {
+	shortest := 0
+
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		for _, r := range c.space {
+			if len(r.lo) != n {
+				continue
+			}
+
+			if shortest == 0 || n < shortest {
+				shortest = n
+			}
+
+			in := true
+			for i := range n {
+				if s[i] < r.lo[i] || s[i] > r.hi[i] {
+					in = false
+
+					break
+				}
+			}
+
+			if in {
+				return n
+			}
+		}
+	}
+
+	if shortest == 0 {
+		return 2
+	}
+
+	return shortest
+}
+
+// lookup maps a character code to text.
+func (c *cmap) lookup(code string) (string, bool) {
+	if t, ok := c.chars[code]; ok {
+		return t, true
+	}
+
+	b := []byte(code)
+
+	for _, r := range c.ranges {
+		if len(r.lo) != len(b) || bytes.Compare(b, r.lo) < 0 || bytes.Compare(b, r.hi) > 0 {
+			continue
+		}
+
+		offset := codeValue(b) - codeValue(r.lo)
+
+		if r.texts != nil {
+			if offset < len(r.texts) {
+				return r.texts[offset], true
+			}
+
+			return "", false
+		}
+
+		if len(r.text) == 0 {
+			return "", true
+		}
+
+		// Increment the last UTF-16 code unit of the destination
+		dst := append([]byte{}, r.text...)
+		if len(dst) >= 2 {
+			last := int(dst[len(dst)-2])<<8 | int(dst[len(dst)-1])
+			last += offset
+			dst[len(dst)-2], dst[len(dst)-1] = byte(last>>8), byte(last)
+		} else {
+			dst[0] += byte(offset)
+		}
+
+		return decodeUTF16(dst), true
+	}
+
+	return "", false
+}
+
+// cid maps a character code to a CID, defaulting to the code value.
+func (c *cmap) cid(code string, value int) int {
+	if cid, ok := c.cidChars[code]; ok {
+		return cid
+	}
+
+	b := []byte(code)
+	for _, r := range c.cidRanges {
+		if len(r.lo) == len(b) && bytes.Compare(b, r.lo) >= 0 && bytes.Compare(b, r.hi) <= 0 {
+			return r.cid + codeValue(b) - codeValue(r.lo)
+		}
+	}
+
+	return value
+}
+
+func codeValue(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+
+	return v
+}
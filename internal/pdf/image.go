@@ -0,0 +1,635 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+)
+
+const (
+	// maxRenderPixels bounds the size of a rendered page and of a decoded
+	// image.
+	maxRenderPixels = 40_000_000
+	// minScannedCoverage is the share of a page that images must cover for
+	// a page without text to count as scanned.
+	minScannedCoverage = 0.5
+	// maxScannedChars is the number of glyphs a scanned page may still show,
+	// such as a page number stamped by the scanner.
+	maxScannedChars = 16
+)
+
+var (
+	// ErrNoImages is returned when a page paints no image that can be rendered.
+	ErrNoImages = errors.New("page has no images that can be rendered")
+
+	errUnsupportedImage = errors.New("unsupported image encoding")
+)
+
+// Image is an image file holding a rendered page.
+type Image struct {
+	Format string // "png", "jpeg", "jp2" or "tiff"
+	Data   []byte
+	DPI    int
+}
+
+// placedImage is an image painted on a page, with the matrix that maps the
+// unit square onto user space.
+type placedImage struct {
+	s   *stream
+	ctm matrix
+}
+
+// IsScanned reports whether page i, counting from zero, shows (almost) no
+// text but is mostly covered by images, as the pages of a scanned document
+// are.
+func (d *Document) IsScanned(i int) bool {
+	if i < 0 || i >= len(d.pages) {
+		return false
+	}
+
+	page := d.pages[i]
+	in := d.runPage(page)
+
+	visible := 0
+
+	for _, c := range in.chars {
+		if strings.TrimSpace(c.text) != "" {
+			visible++
+		}
+	}
+
+	if visible > maxScannedChars || len(in.images) == 0 {
+		return false
+	}
+
+	box := d.pageBox(page)
+	area := (box[2] - box[0]) * (box[3] - box[1])
+
+	covered := 0.0
+
+	for _, img := range in.images {
+		x0, y0, x1, y1 := img.ctm.unitBounds()
+		w := math.Min(x1, box[2]) - math.Max(x0, box[0])
+		h := math.Min(y1, box[3]) - math.Max(y0, box[1])
+
+		if w > 0 && h > 0 {
+			covered += w * h
+		}
+	}
+
+	return area > 0 && covered/area >= minScannedCoverage
+}
+
+// RenderPage renders the images painted on page i, counting from zero, in
+// grayscale at the given resolution. Text and vector graphics are not drawn,
+// which suits OCR of scanned pages. A page made of one upright image covering
+// it is returned as the image's own JPEG, JPEG 2000 or CCITT (wrapped in
+// TIFF) data, which OCR engines read directly; anything else is rendered to
+// PNG.
+func (d *Document) RenderPage(i int, dpi float64) (*Image, error) {
+	if i < 0 || i >= len(d.pages) {
+		return nil, errors.New("page out of range")
+	}
+
+	page := d.pages[i]
+	box := d.pageBox(page)
+	rotate := (d.intOr(page["Rotate"], 0)%360 + 360) % 360 / 90 * 90
+	in := d.runPage(page)
+
+	if len(in.images) == 1 && rotate == 0 {
+		if img, ok := d.nativeImage(in.images[0], box); ok {
+			return img, nil
+		}
+	}
+
+	pw, ph := box[2]-box[0], box[3]-box[1]
+	if pw <= 0 || ph <= 0 {
+		return nil, errors.New("invalid page size")
+	}
+
+	scale := dpi / 72
+	if pw*ph*scale*scale > maxRenderPixels {
+		scale = math.Sqrt(maxRenderPixels / (pw * ph))
+	}
+
+	w, h := int(math.Ceil(pw*scale)), int(math.Ceil(ph*scale))
+
+	// dev maps user space onto the canvas, whose y axis points down
+	dev := matrix{scale, 0, 0, -scale, -box[0] * scale, box[3] * scale}
+
+	switch rotate {
+	case 90:
+		dev = dev.mul(matrix{0, 1, -1, 0, float64(h), 0})
+		w, h = h, w
+	case 180:
+		dev = dev.mul(matrix{-1, 0, 0, -1, float64(w), float64(h)})
+	case 270:
+		dev = dev.mul(matrix{0, -1, 1, 0, 0, float64(w)})
+		w, h = h, w
+	}
+
+	canvas := image.NewGray(image.Rect(0, 0, w, h))
+	for j := range canvas.Pix {
+		canvas.Pix[j] = 0xFF
+	}
+
+	drawn := false
+
+	for _, placed := range in.images {
+		img, mask, err := d.decodeImage(placed.s)
+		if err != nil {
+			continue
+		}
+
+		paint(canvas, img, mask, placed.ctm.mul(dev))
+
+		drawn = true
+	}
+
+	if !drawn {
+		return nil, ErrNoImages
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, err
+	}
+
+	return &Image{Format: "png", Data: buf.Bytes(), DPI: int(math.Round(scale * 72))}, nil
+}
+
+// pageBox returns the visible area of a page as [x0 y0 x1 y1].
+func (d *Document) pageBox(page dict) [4]float64 {
+	for _, key := range []name{"CropBox", "MediaBox"} {
+		if v, ok := nums(d.resolveArray(page[key]), 4); ok {
+			return [4]float64{math.Min(v[0], v[2]), math.Min(v[1], v[3]), math.Max(v[0], v[2]), math.Max(v[1], v[3])}
+		}
+	}
+
+	return [4]float64{0, 0, 612, 792}
+}
+
+// unitBounds returns the bounding box of the unit square under m.
+func (m matrix) unitBounds() (x0, y0, x1, y1 float64) {
+	x0, y0 = math.Inf(1), math.Inf(1)
+	x1, y1 = math.Inf(-1), math.Inf(-1)
+
+	for _, p := range [4][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		x := m[0]*p[0] + m[2]*p[1] + m[4]
+		y := m[1]*p[0] + m[3]*p[1] + m[5]
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+	}
+
+	return x0, y0, x1, y1
+}
+
+// paint draws an image onto the canvas with nearest-neighbour sampling; m
+// maps the unit square onto canvas pixels. Stencil masks only paint their
+// black pixels.
+func paint(canvas, img *image.Gray, mask bool, m matrix) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if math.Abs(det) < 1e-9 {
+		return
+	}
+
+	x0, y0, x1, y1 := m.unitBounds()
+	bounds := image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1))).
+		Intersect(canvas.Rect)
+
+	iw, ih := img.Rect.Dx(), img.Rect.Dy()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := float64(x)+0.5-m[4], float64(y)+0.5-m[5]
+			u := (m[3]*px - m[2]*py) / det
+			v := (m[0]*py - m[1]*px) / det
+
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+
+			// Row zero of an image is at the top of the unit square
+			c := img.GrayAt(img.Rect.Min.X+int(u*float64(iw)), img.Rect.Min.Y+int((1-v)*float64(ih))).Y
+			if mask && c != 0 {
+				continue
+			}
+
+			canvas.Pix[y*canvas.Stride+x] = c
+		}
+	}
+}
+
+// imageFilter returns the image codec of a stream, if any, with its
+// parameters.
+func (d *Document) imageFilter(s *stream) (name, dict) {
+	filters := d.resolveArray(s.hdr["Filter"])
+	if len(filters) == 0 {
+		filters = array{s.hdr["Filter"]}
+	}
+
+	parms := d.resolveArray(s.hdr["DecodeParms"])
+	if len(parms) == 0 {
+		parms = array{s.hdr["DecodeParms"]}
+	}
+
+	last := len(filters) - 1
+	f, _ := d.resolve(filters[last]).(name)
+
+	var parm dict
+	if last < len(parms) {
+		parm = d.resolveDict(parms[last])
+	}
+
+	return f, parm
+}
+
+// nativeImage returns the encoded data of an image that covers the page
+// upright, when it is in a format OCR engines read.
+func (d *Document) nativeImage(placed placedImage, box [4]float64) (*Image, bool) {
+	m := placed.ctm
+	if m[1] != 0 || m[2] != 0 || m[0] <= 0 || m[3] <= 0 {
+		return nil, false
+	}
+
+	x0, y0, x1, y1 := m.unitBounds()
+	cw := math.Min(x1, box[2]) - math.Max(x0, box[0])
+	ch := math.Min(y1, box[3]) - math.Max(y0, box[1])
+
+	if cw <= 0 || ch <= 0 || cw*ch < 0.9*(box[2]-box[0])*(box[3]-box[1]) {
+		return nil, false
+	}
+
+	s := placed.s
+	w, h := d.intOr(s.hdr["Width"], 0), d.intOr(s.hdr["Height"], 0)
+
+	data, err := d.decodeStream(s)
+	if !errors.Is(err, errImageFilter) || w <= 0 || h <= 0 {
+		return nil, false
+	}
+
+	dpi := int(math.Round(float64(w) / (m[0] / 72)))
+	filter, parm := d.imageFilter(s)
+
+	switch filter {
+	case "DCTDecode", "DCT":
+		return &Image{Format: "jpeg", Data: data, DPI: dpi}, true
+	case "JPXDecode":
+		return &Image{Format: "jp2", Data: data, DPI: dpi}, true
+	case "CCITTFaxDecode", "CCF":
+		return &Image{Format: "tiff", Data: d.ccittTIFF(s, data, parm, w, h, dpi), DPI: dpi}, true
+	}
+
+	return nil, false
+}
+
+// ccittTIFF wraps CCITT fax data in a single-strip TIFF file.
+func (d *Document) ccittTIFF(s *stream, data []byte, parm dict, w, h, dpi int) []byte {
+	k := d.intOr(parm["K"], 0)
+	h = d.intOr(parm["Rows"], h)
+
+	compression, options := uint32(3), uint32(0)
+	if k < 0 {
+		compression = 4
+	} else if k > 0 {
+		options = 1 // two-dimensional coding
+	}
+
+	if d.resolve(parm["EncodedByteAlign"]) == keyword("true") && compression == 3 {
+		options |= 4
+	}
+
+	// Fax codes describe white and black runs, so WhiteIsZero matches the
+	// default PDF rendering; an inverting Decode array flips it
+	photometric := uint32(0)
+	if dec, ok := nums(d.resolveArray(s.hdr["Decode"]), 2); ok && dec[0] > dec[1] {
+		photometric = 1
+	}
+
+	type entry struct {
+		tag, typ uint16
+		value    uint32
+	}
+
+	const short, long, rational = 3, 4, 5
+
+	entries := []entry{
+		{256, long, uint32(w)},
+		{257, long, uint32(h)},
+		{258, short, 1},
+		{259, short, compression},
+		{262, short, photometric},
+		{273, long, 0}, // strip offset, set below
+		{277, short, 1},
+		{278, long, uint32(h)},
+		{279, long, uint32(len(data))},
+		{282, rational, 0}, // resolutions, set below
+		{283, rational, 0},
+	}
+
+	// Tags must be sorted
+	if compression == 3 {
+		entries = append(entries, entry{292, long, options})
+	}
+
+	entries = append(entries, entry{296, short, 2})
+
+	le := binary.LittleEndian
+	ifdSize := 2 + 12*len(entries) + 4
+	resOffset := uint32(8 + ifdSize)
+	dataOffset := resOffset + 16
+
+	out := make([]byte, int(dataOffset)+len(data))
+	copy(out, "II*\x00")
+	le.PutUint32(out[4:], 8)
+	le.PutUint16(out[8:], uint16(len(entries)))
+
+	for i, e := range entries {
+		switch e.tag {
+		case 273:
+			e.value = dataOffset
+		case 282:
+			e.value = resOffset
+		case 283:
+			e.value = resOffset + 8
+		}
+
+		p := out[10+12*i:]
+		le.PutUint16(p, e.tag)
+		le.PutUint16(p[2:], e.typ)
+		le.PutUint32(p[4:], 1)
+
+		if e.typ == short {
+			le.PutUint16(p[8:], uint16(e.value))
+		} else {
+			le.PutUint32(p[8:], e.value)
+		}
+	}
+
+	for i := range 2 {
+		le.PutUint32(out[int(resOffset)+8*i:], uint32(max(dpi, 1)))
+		le.PutUint32(out[int(resOffset)+8*i+4:], 1)
+	}
+
+	copy(out[dataOffset:], data)
+
+	return out
+}
+
+// decodeImage decodes an image to grayscale. For stencil masks, mask is
+// true and black marks the painted pixels.
+func (d *Document) decodeImage(s *stream) (img *image.Gray, mask bool, err error) {
+	w, h := d.intOr(s.hdr["Width"], 0), d.intOr(s.hdr["Height"], 0)
+	if w <= 0 || h <= 0 || w*h > maxRenderPixels {
+		return nil, false, errors.New("invalid image size")
+	}
+
+	mask = d.resolve(s.hdr["ImageMask"]) == keyword("true")
+
+	data, err := d.decodeStream(s)
+	if errors.Is(err, errImageFilter) {
+		if filter, _ := d.imageFilter(s); filter != "DCTDecode" && filter != "DCT" {
+			return nil, false, errUnsupportedImage
+		}
+
+		decoded, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, false, err
+		}
+
+		gray := image.NewGray(decoded.Bounds())
+		draw.Draw(gray, gray.Rect, decoded, decoded.Bounds().Min, draw.Src)
+
+		return gray, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if mask {
+		return d.decodeMask(s, data, w, h), true, nil
+	}
+
+	cs, err := d.colorSpace(s.hdr["ColorSpace"], 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bpc := d.intOr(s.hdr["BitsPerComponent"], 8)
+	if bpc != 1 && bpc != 2 && bpc != 4 && bpc != 8 && bpc != 16 {
+		return nil, false, errors.New("invalid bits per component")
+	}
+
+	// Decode ranges map samples to component values; indexed images use
+	// the raw sample as the palette index
+	maxSample := float64(int(1)<<bpc - 1)
+	lo, hi := make([]float64, cs.n), make([]float64, cs.n)
+
+	for c := range cs.n {
+		lo[c], hi[c] = 0, 1
+		if cs.lookup != nil {
+			hi[c] = maxSample
+		}
+	}
+
+	if dec, ok := nums(d.resolveArray(s.hdr["Decode"]), 2*cs.n); ok {
+		for c := range cs.n {
+			lo[c], hi[c] = dec[2*c], dec[2*c+1]
+		}
+	}
+
+	img = image.NewGray(image.Rect(0, 0, w, h))
+	stride := (w*cs.n*bpc + 7) / 8
+	comps := make([]float64, cs.n)
+
+	for y := range h {
+		if (y+1)*stride > len(data) {
+			for x := range w {
+				img.Pix[y*img.Stride+x] = 0xFF
+			}
+
+			continue
+		}
+
+		row := data[y*stride : (y+1)*stride]
+
+		for x := range w {
+			for c := range cs.n {
+				v := float64(sample(row, x*cs.n+c, bpc)) / maxSample
+				comps[c] = lo[c] + v*(hi[c]-lo[c])
+			}
+
+			img.Pix[y*img.Stride+x] = cs.gray(comps)
+		}
+	}
+
+	return img, false, nil
+}
+
+// decodeMask decodes a stencil mask: with the default Decode array, zero
+// samples are painted.
+func (d *Document) decodeMask(s *stream, data []byte, w, h int) *image.Gray {
+	paintOne := false
+	if dec, ok := nums(d.resolveArray(s.hdr["Decode"]), 2); ok && dec[0] > dec[1] {
+		paintOne = true
+	}
+
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	stride := (w + 7) / 8
+
+	for y := range h {
+		for x := range w {
+			painted := false
+			if i := y*stride + x/8; i < len(data) {
+				painted = (data[i]>>(7-x%8)&1 == 1) == paintOne
+			}
+
+			if !painted {
+				img.Pix[y*img.Stride+x] = 0xFF
+			}
+		}
+	}
+
+	return img
+}
+
+// sample reads the i-th sample of a row.
+func sample(row []byte, i, bpc int) int {
+	switch bpc {
+	case 8:
+		return int(row[i])
+	case 16:
+		return int(row[2*i])<<8 | int(row[2*i+1])
+	}
+
+	bit := i * bpc
+
+	return int(row[bit/8]>>(8-bpc-bit%8)) & (1<<bpc - 1)
+}
+
+// colorSpace describes how the components of an image map to gray.
+type colorSpace struct {
+	kind   string // "gray", "rgb", "cmyk", "ink" or "indexed"
+	n      int
+	base   *colorSpace
+	lookup []byte
+}
+
+// colorSpace resolves an image colour space.
+func (d *Document) colorSpace(obj object, depth int) (*colorSpace, error) {
+	if depth > 4 {
+		return nil, errors.New("colour space nesting too deep")
+	}
+
+	obj = d.resolve(obj)
+
+	family, _ := obj.(name)
+	arr, _ := obj.(array)
+
+	if len(arr) > 0 {
+		family, _ = d.resolve(arr[0]).(name)
+	}
+
+	switch family {
+	case "DeviceGray", "CalGray", "G":
+		return &colorSpace{kind: "gray", n: 1}, nil
+	case "DeviceRGB", "CalRGB", "RGB":
+		return &colorSpace{kind: "rgb", n: 3}, nil
+	case "DeviceCMYK", "CMYK":
+		return &colorSpace{kind: "cmyk", n: 4}, nil
+	case "ICCBased":
+		if len(arr) < 2 {
+			break
+		}
+
+		if s, ok := d.resolve(arr[1]).(*stream); ok {
+			switch d.intOr(s.hdr["N"], 3) {
+			case 1:
+				return &colorSpace{kind: "gray", n: 1}, nil
+			case 4:
+				return &colorSpace{kind: "cmyk", n: 4}, nil
+			}
+		}
+
+		return &colorSpace{kind: "rgb", n: 3}, nil
+	case "Separation":
+		return &colorSpace{kind: "ink", n: 1}, nil
+	case "DeviceN":
+		if len(arr) > 1 {
+			if names := d.resolveArray(arr[1]); len(names) > 0 {
+				return &colorSpace{kind: "ink", n: len(names)}, nil
+			}
+		}
+	case "Indexed", "I":
+		if len(arr) < 4 {
+			break
+		}
+
+		base, err := d.colorSpace(arr[1], depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		var lookup []byte
+
+		switch v := d.resolve(arr[3]).(type) {
+		case string:
+			lookup = []byte(v)
+		case *stream:
+			if lookup, err = d.decodeStream(v); err != nil {
+				return nil, err
+			}
+		}
+
+		return &colorSpace{kind: "indexed", n: 1, base: base, lookup: lookup}, nil
+	}
+
+	return nil, errUnsupportedImage
+}
+
+// gray converts component values in [0, 1] (palette indexes for indexed
+// colour spaces) to a gray level.
+func (cs *colorSpace) gray(c []float64) uint8 {
+	var v float64
+
+	switch cs.kind {
+	case "gray":
+		v = c[0]
+	case "rgb":
+		v = 0.299*c[0] + 0.587*c[1] + 0.114*c[2]
+	case "cmyk":
+		k := 1 - c[3]
+		v = 0.299*(1-c[0])*k + 0.587*(1-c[1])*k + 0.114*(1-c[2])*k
+	case "ink":
+		v = 1 - c[0]
+		for _, ink := range c[1:] {
+			v = math.Min(v, 1-ink)
+		}
+	case "indexed":
+		n := cs.base.n
+		i := int(c[0]) * n
+
+		if i < 0 || i+n > len(cs.lookup) {
+			return 0xFF
+		}
+
+		comps := make([]float64, n)
+		for j := range n {
+			comps[j] = float64(cs.lookup[i+j]) / 255
+		}
+
+		return cs.base.gray(comps)
+	}
+
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
@@ -0,0 +1,533 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// fragmentGap splits a row into fragments at horizontal gaps wider than
+	// this many font sizes; narrower gaps are word spaces.
+	fragmentGap = 1.0
+	// wordGap is the gap, in font sizes, above which a space is inserted
+	// between glyphs that were positioned apart rather than separated by a
+	// space character.
+	wordGap = 0.15
+	// paragraphGap is the baseline distance, in font sizes, above which
+	// consecutive lines are separated by a blank line.
+	paragraphGap = 1.75
+	// minColumnRows is how many rows of text a column needs.
+	minColumnRows = 3
+	// maxCutDepth bounds the recursion of the layout analysis.
+	maxCutDepth = 64
+)
+
+// fragment is a horizontal run of glyphs on one baseline with no gap wider
+// than fragmentGap.
+type fragment struct {
+	chars  []textChar
+	x0, x1 float64
+	y      float64 // baseline
+	size   float64
+}
+
+func (f *fragment) top() float64    { return f.y + 0.75*f.size }
+func (f *fragment) bottom() float64 { return f.y - 0.25*f.size }
+
+// interval is a closed range on the x axis.
+type interval struct {
+	lo, hi float64
+}
+
+// layoutPage turns the glyphs of a page into text in reading order. Text is
+// grouped into rows and fragments, the page is recursively split at column
+// gutters and horizontal gaps (an XY-cut), and every resulting block is
+// written top to bottom, left to right.
+func layoutPage(chars []textChar) string {
+	chars = normalizeOrientation(chars)
+	if len(chars) == 0 {
+		return ""
+	}
+
+	frags := buildFragments(chars)
+	blocks := cut(frags, 0)
+
+	var sb strings.Builder
+
+	var prev *line
+
+	for _, block := range blocks {
+		for _, ln := range buildLines(block) {
+			if prev != nil {
+				sb.WriteByte('\n')
+
+				size := math.Max(prev.size, ln.size)
+				if ln.y > prev.y+0.5*size || prev.y-ln.y > paragraphGap*size {
+					// New column or a paragraph gap
+					sb.WriteByte('\n')
+				}
+			}
+
+			sb.WriteString(ln.text)
+			prev = ln
+		}
+	}
+
+	return sb.String()
+}
+
+// normalizeOrientation keeps the glyphs written in the dominant direction of
+// the page and rotates them so that it becomes left to right. Text in other
+// directions (vertical labels, watermarks) is dropped.
+func normalizeOrientation(chars []textChar) []textChar {
+	var counts [4]int
+
+	dirs := make([]int, len(chars))
+
+	for i, c := range chars {
+		angle := math.Atan2(c.dy, c.dx)
+		quadrant := math.Round(angle / (math.Pi / 2))
+
+		// Only keep text within a few degrees of an axis
+		if math.Abs(angle-quadrant*math.Pi/2) > 0.1 {
+			dirs[i] = -1
+
+			continue
+		}
+
+		dir := (int(quadrant) + 4) % 4
+		dirs[i] = dir
+		counts[dir]++
+	}
+
+	dominant := 0
+	for d := range counts {
+		if counts[d] > counts[dominant] {
+			dominant = d
+		}
+	}
+
+	rotate := func(x, y float64) (float64, float64) {
+		switch dominant {
+		case 1:
+			return y, -x
+		case 2:
+			return -x, -y
+		case 3:
+			return -y, x
+		}
+
+		return x, y
+	}
+
+	out := make([]textChar, 0, len(chars))
+
+	for i, c := range chars {
+		if dirs[i] != dominant || strings.TrimSpace(c.text) == "" && c.text != " " {
+			continue
+		}
+
+		c.x, c.y = rotate(c.x, c.y)
+		c.ex, c.ey = rotate(c.ex, c.ey)
+		c.size = math.Max(c.size, 1)
+
+		if c.ex < c.x {
+			c.ex = c.x
+		}
+
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// buildFragments groups glyphs into rows by baseline and splits the rows at
+// wide gaps.
+func buildFragments(chars []textChar) []*fragment {
+	sort.SliceStable(chars, func(i, j int) bool { return chars[i].y > chars[j].y })
+
+	var rows [][]textChar
+
+	for _, c := range chars {
+		if n := len(rows); n > 0 {
+			first := rows[n-1][0]
+			if math.Abs(first.y-c.y) <= 0.35*math.Min(first.size, c.size) {
+				rows[n-1] = append(rows[n-1], c)
+
+				continue
+			}
+		}
+
+		rows = append(rows, []textChar{c})
+	}
+
+	var frags []*fragment
+
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].x < row[j].x })
+
+		var cur *fragment
+
+		for _, c := range row {
+			if cur != nil && c.x-cur.x1 <= fragmentGap*math.Max(c.size, cur.size) {
+				cur.chars = append(cur.chars, c)
+				cur.x1 = math.Max(cur.x1, c.ex)
+				cur.size = math.Max(cur.size, c.size)
+
+				continue
+			}
+
+			cur = &fragment{chars: []textChar{c}, x0: c.x, x1: c.ex, y: row[0].y, size: c.size}
+			frags = append(frags, cur)
+		}
+	}
+
+	// Leading and trailing spaces do not make a fragment wider, and
+	// fragments of spaces alone are dropped
+	out := frags[:0]
+
+	for _, f := range frags {
+		f.x0, f.x1 = math.Inf(1), math.Inf(-1)
+
+		for _, c := range f.chars {
+			if c.text != " " {
+				f.x0 = math.Min(f.x0, c.x)
+				f.x1 = math.Max(f.x1, c.ex)
+			}
+		}
+
+		if !math.IsInf(f.x0, 1) {
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
+
+// cut splits a set of fragments into blocks in reading order.
+func cut(frags []*fragment, depth int) [][]*fragment {
+	if len(frags) <= 1 || depth > maxCutDepth {
+		return [][]*fragment{frags}
+	}
+
+	if left, right, ok := splitColumns(frags); ok {
+		return append(cut(left, depth+1), cut(right, depth+1)...)
+	}
+
+	bands := splitBands(frags)
+	if len(bands) == 1 {
+		return [][]*fragment{frags}
+	}
+
+	lo, hi := extent(frags)
+
+	var blocks [][]*fragment
+
+	for i := 0; i < len(bands); {
+		// Bands that share a column gutter form one multi-column region:
+		// a band with text on both sides of a gutter starts the region and
+		// following bands join while they leave the gutter free
+		if gutters := freeIntervals(bands[i], math.NaN(), math.NaN()); len(gutters) > 0 {
+			j := i
+
+			for j+1 < len(bands) {
+				next := intersect(gutters, freeIntervals(bands[j+1], lo, hi), minGutter(frags))
+				if len(next) == 0 {
+					break
+				}
+
+				gutters = next
+				j++
+			}
+
+			if j > i {
+				var group []*fragment
+				for _, b := range bands[i : j+1] {
+					group = append(group, b...)
+				}
+
+				if left, right, ok := splitColumns(group); ok {
+					blocks = append(blocks, cut(left, depth+1)...)
+					blocks = append(blocks, cut(right, depth+1)...)
+					i = j + 1
+
+					continue
+				}
+			}
+		}
+
+		blocks = append(blocks, cut(bands[i], depth+1)...)
+		i++
+	}
+
+	return blocks
+}
+
+// splitColumns splits fragments at the widest vertical gutter that has
+// enough rows of text on both sides.
+func splitColumns(frags []*fragment) ([]*fragment, []*fragment, bool) {
+	gutters := freeIntervals(frags, math.NaN(), math.NaN())
+
+	sort.Slice(gutters, func(i, j int) bool {
+		return gutters[i].hi-gutters[i].lo > gutters[j].hi-gutters[j].lo
+	})
+
+	for _, g := range gutters {
+		var left, right []*fragment
+
+		for _, f := range frags {
+			if f.x1 <= g.lo {
+				left = append(left, f)
+			} else {
+				right = append(right, f)
+			}
+		}
+
+		if isColumn(left, frags) && isColumn(right, frags) {
+			return left, right, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// isColumn reports whether one side of a gutter looks like a column of
+// text rather than, say, the bullets of a list.
+func isColumn(side, all []*fragment) bool {
+	if countRows(side) < minColumnRows {
+		return false
+	}
+
+	lo, hi := extent(side)
+
+	return hi-lo >= 3*minGutter(all)
+}
+
+// splitBands splits fragments at horizontal gaps that no fragment crosses,
+// top to bottom.
+func splitBands(frags []*fragment) [][]*fragment {
+	sorted := append([]*fragment{}, frags...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].top() > sorted[j].top() })
+
+	var bands [][]*fragment
+
+	bottom := math.Inf(1)
+
+	for _, f := range sorted {
+		if len(bands) == 0 || f.top() < bottom {
+			bands = append(bands, []*fragment{f})
+			bottom = f.bottom()
+
+			continue
+		}
+
+		bands[len(bands)-1] = append(bands[len(bands)-1], f)
+		bottom = math.Min(bottom, f.bottom())
+	}
+
+	return bands
+}
+
+// freeIntervals returns the x ranges within [lo, hi] not covered by any
+// fragment and at least minGutter wide. NaN bounds default to the extent of
+// the fragments, so only interior gaps are returned.
+func freeIntervals(frags []*fragment, lo, hi float64) []interval {
+	if len(frags) == 0 {
+		return nil
+	}
+
+	if math.IsNaN(lo) {
+		lo, hi = extent(frags)
+	}
+
+	covered := make([]interval, 0, len(frags))
+	for _, f := range frags {
+		covered = append(covered, interval{f.x0, f.x1})
+	}
+
+	sort.Slice(covered, func(i, j int) bool { return covered[i].lo < covered[j].lo })
+
+	minWidth := minGutter(frags)
+
+	var free []interval
+
+	pos := lo
+
+	for _, c := range covered {
+		if c.lo-pos >= minWidth {
+			free = append(free, interval{pos, c.lo})
+		}
+
+		pos = math.Max(pos, c.hi)
+	}
+
+	if hi-pos >= minWidth {
+		free = append(free, interval{pos, hi})
+	}
+
+	return free
+}
+
+// intersect returns the overlaps of two interval lists at least minWidth wide.
+func intersect(a, b []interval, minWidth float64) []interval {
+	var out []interval
+
+	for _, x := range a {
+		for _, y := range b {
+			lo, hi := math.Max(x.lo, y.lo), math.Min(x.hi, y.hi)
+			if hi-lo >= minWidth {
+				out = append(out, interval{lo, hi})
+			}
+		}
+	}
+
+	return out
+}
+
+// minGutter is the narrowest gap treated as a column gutter.
+func minGutter(frags []*fragment) float64 {
+	sizes := make([]float64, 0, len(frags))
+	for _, f := range frags {
+		sizes = append(sizes, f.size)
+	}
+
+	sort.Float64s(sizes)
+
+	return math.Max(0.8*sizes[len(sizes)/2], 4)
+}
+
+func extent(frags []*fragment) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, f := range frags {
+		lo = math.Min(lo, f.x0)
+		hi = math.Max(hi, f.x1)
+	}
+
+	return lo, hi
+}
+
+// countRows counts the distinct baselines of a set of fragments.
+func countRows(frags []*fragment) int {
+	ys := make([]float64, 0, len(frags))
+	for _, f := range frags {
+		ys = append(ys, f.y)
+	}
+
+	sort.Float64s(ys)
+
+	rows := 0
+
+	for i, y := range ys {
+		if i == 0 || y-ys[i-1] > 0.5*frags[0].size {
+			rows++
+		}
+	}
+
+	return rows
+}
+
+// line is one output line of a block.
+type line struct {
+	text string
+	y    float64
+	size float64
+}
+
+// buildLines writes the fragments of a block as lines, top to bottom.
+// Fragments on the same baseline are separated by a tab.
+func buildLines(block []*fragment) []*line {
+	sorted := append([]*fragment{}, block...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].y > sorted[j].y })
+
+	var groups [][]*fragment
+
+	for _, f := range sorted {
+		if n := len(groups); n > 0 && math.Abs(groups[n-1][0].y-f.y) <= 0.35*math.Min(groups[n-1][0].size, f.size) {
+			groups[n-1] = append(groups[n-1], f)
+
+			continue
+		}
+
+		groups = append(groups, []*fragment{f})
+	}
+
+	lines := make([]*line, 0, len(groups))
+
+	for _, g := range groups {
+		sort.SliceStable(g, func(i, j int) bool { return g[i].x0 < g[j].x0 })
+
+		var sb strings.Builder
+
+		size := 0.0
+
+		for i, f := range g {
+			text := strings.TrimSpace(fragmentText(f))
+			if text == "" {
+				continue
+			}
+
+			if sb.Len() > 0 {
+				if prev := strings.TrimSpace(fragmentText(g[i-1])); isBullet(prev) {
+					sb.WriteByte(' ')
+				} else {
+					sb.WriteByte('\t')
+				}
+			}
+
+			sb.WriteString(text)
+
+			size = math.Max(size, f.size)
+		}
+
+		if sb.Len() == 0 {
+			continue
+		}
+
+		lines = append(lines, &line{text: sb.String(), y: g[0].y, size: size})
+	}
+
+	return lines
+}
+
+// fragmentText joins the glyphs of a fragment, inserting spaces at word
+// gaps and dropping glyphs overprinted to simulate bold text.
+func fragmentText(f *fragment) string {
+	var sb strings.Builder
+
+	var prev *textChar
+
+	for i := range f.chars {
+		c := &f.chars[i]
+
+		if prev != nil {
+			if c.text == prev.text && math.Abs(c.x-prev.x) < 0.1*c.size {
+				continue
+			}
+
+			if c.text != " " && prev.text != " " && c.x-prev.ex > wordGap*c.size {
+				sb.WriteByte(' ')
+			}
+		}
+
+		if c.text == " " && prev != nil && prev.text == " " {
+			continue
+		}
+
+		sb.WriteString(c.text)
+
+		prev = c
+	}
+
+	return sb.String()
+}
+
+func isBullet(s string) bool {
+	r := []rune(s)
+
+	return len(r) == 1 && (strings.ContainsRune("•·▪■□●○◦‣⁃–-*►✓➢", r[0]) || unicode.In(r[0], unicode.Co))
+}
@@ -0,0 +1,456 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PDF object types. Strings are kept as raw bytes in a Go string; their
+// meaning depends on the font or context they are used in.
+type (
+	name    string
+	keyword string
+	array   []object
+	dict    map[name]object
+	object  any
+)
+
+// objref is an indirect object reference ("12 0 R").
+type objref struct {
+	num int
+	gen int
+}
+
+// stream is a stream object. data is still encoded (and encrypted).
+type stream struct {
+	hdr  dict
+	data []byte
+	ref  objref
+}
+
+// maxNesting bounds array and dictionary nesting to protect against
+// malicious input.
+const maxNesting = 64
+
+var errUnexpectedEOF = errors.New("unexpected end of data")
+
+// lexer reads PDF objects from a byte slice.
+type lexer struct {
+	data []byte
+	pos  int
+	// refs enables "n g R" references; content streams have none.
+	refs bool
+}
+
+func newLexer(data []byte, pos int) *lexer {
+	return &lexer{data: data, pos: pos, refs: true}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+
+	return false
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+
+	return false
+}
+
+// skipSpace skips whitespace and comments.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readObject reads the next object. Operators and other bare words are
+// returned as keywords; the closing delimiters of arrays and dictionaries
+// are returned as the keywords "]" and ">>".
+func (l *lexer) readObject() (object, error) {
+	return l.read(0)
+}
+
+func (l *lexer) read(depth int) (object, error) {
+	if depth > maxNesting {
+		return nil, errors.New("objects nested too deeply")
+	}
+
+	l.skipSpace()
+
+	if l.pos >= len(l.data) {
+		return nil, errUnexpectedEOF
+	}
+
+	c := l.data[l.pos]
+
+	switch {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteralString()
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+
+			return l.readDict(depth)
+		}
+
+		return l.readHexString()
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+
+			return keyword(">>"), nil
+		}
+
+		l.pos++
+
+		return nil, errors.New("unexpected '>'")
+	case c == '[':
+		l.pos++
+
+		return l.readArray(depth)
+	case c == ']':
+		l.pos++
+
+		return keyword("]"), nil
+	case c == '{' || c == '}':
+		// PostScript calculator braces; only seen in function streams
+		l.pos++
+
+		return keyword(string(c)), nil
+	case c == ')':
+		l.pos++
+
+		return nil, errors.New("unexpected ')'")
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.readNumber()
+	default:
+		return l.readKeyword(), nil
+	}
+}
+
+func (l *lexer) readName() name {
+	l.pos++ // '/'
+
+	var buf []byte
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isSpace(c) || isDelim(c) {
+			break
+		}
+
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				buf = append(buf, byte(v))
+				l.pos += 3
+
+				continue
+			}
+		}
+
+		buf = append(buf, c)
+		l.pos++
+	}
+
+	return name(buf)
+}
+
+func (l *lexer) readKeyword() keyword {
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+
+	if l.pos == start {
+		// Stray delimiter; consume it so callers always make progress
+		l.pos++
+	}
+
+	return keyword(l.data[start:l.pos])
+}
+
+func (l *lexer) readNumber() (object, error) {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '+' {
+			l.pos++
+
+			continue
+		}
+
+		break
+	}
+
+	tok := string(l.data[start:l.pos])
+
+	if !bytes.ContainsAny(l.data[start:l.pos], ".") {
+		// Malformed numbers such as "--5" are read as zero, as viewers do
+		n, _ := strconv.ParseInt(tok, 10, 64)
+
+		if l.refs && n >= 0 {
+			if r, ok := l.tryRef(n); ok {
+				return r, nil
+			}
+		}
+
+		return n, nil
+	}
+
+	f, _ := strconv.ParseFloat(tok, 64)
+
+	return f, nil
+}
+
+// tryRef checks whether an integer is the start of an "n g R" reference.
+func (l *lexer) tryRef(num int64) (objref, bool) {
+	save := l.pos
+
+	l.skipSpace()
+
+	start := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+
+	if l.pos > start {
+		gen, _ := strconv.Atoi(string(l.data[start:l.pos]))
+
+		l.skipSpace()
+
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isSpace(l.data[l.pos+1]) || isDelim(l.data[l.pos+1])) {
+			l.pos++
+
+			return objref{num: int(num), gen: gen}, true
+		}
+	}
+
+	l.pos = save
+
+	return objref{}, false
+}
+
+func (l *lexer) readLiteralString() (object, error) {
+	l.pos++ // '('
+
+	var buf []byte
+
+	depth := 1
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(buf), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return string(buf), nil
+			}
+
+			c = l.data[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// Line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+
+					c = byte(v)
+				}
+			}
+		}
+
+		buf = append(buf, c)
+	}
+
+	return string(buf), nil
+}
+
+func (l *lexer) readHexString() (object, error) {
+	l.pos++ // '<'
+
+	var buf []byte
+
+	var hi byte
+
+	half := false
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		if c == '>' {
+			break
+		}
+
+		v, ok := unhex(c)
+		if !ok {
+			continue
+		}
+
+		if half {
+			buf = append(buf, hi<<4|v)
+		} else {
+			hi = v
+		}
+
+		half = !half
+	}
+
+	if half {
+		// An odd final digit is followed by an implicit zero
+		buf = append(buf, hi<<4)
+	}
+
+	return string(buf), nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+
+	return 0, false
+}
+
+func (l *lexer) readArray(depth int) (object, error) {
+	arr := array{}
+
+	for {
+		obj, err := l.read(depth + 1)
+		if err != nil {
+			return arr, err
+		}
+
+		if k, ok := obj.(keyword); ok && k == "]" {
+			return arr, nil
+		}
+
+		arr = append(arr, obj)
+	}
+}
+
+func (l *lexer) readDict(depth int) (object, error) {
+	d := dict{}
+
+	for {
+		obj, err := l.read(depth + 1)
+		if err != nil {
+			return d, err
+		}
+
+		if k, ok := obj.(keyword); ok && k == ">>" {
+			return d, nil
+		}
+
+		key, ok := obj.(name)
+		if !ok {
+			// Skip junk keys rather than failing the whole object
+			continue
+		}
+
+		val, err := l.read(depth + 1)
+		if err != nil {
+			return d, err
+		}
+
+		if k, ok := val.(keyword); ok && k == ">>" {
+			return d, nil
+		}
+
+		d[key] = val
+	}
+}
+
+// expectKeyword reads the next token and checks that it is the keyword kw.
+func (l *lexer) expectKeyword(kw keyword) error {
+	l.skipSpace()
+
+	if got := l.readKeyword(); got != kw {
+		return fmt.Errorf("expected %q, found %q", kw, got)
+	}
+
+	return nil
+}
+
+// readInt reads a non-negative integer token.
+func (l *lexer) readInt() (int, bool) {
+	l.skipSpace()
+
+	start := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+
+	if l.pos == start {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(string(l.data[start:l.pos]))
+
+	return n, err == nil
+}
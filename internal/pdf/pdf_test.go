@@ -0,0 +1,319 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// buildPDF assembles a PDF from object bodies numbered from 1, with object 1
+// as the catalog.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects))
+
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func flateStream(content string) string {
+	var buf bytes.Buffer
+
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(content))
+	w.Close()
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.String())
+}
+
+func plainStream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
+
+// singlePage builds a one-page document using Helvetica as /F1.
+func singlePage(content string) []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		flateStream(content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	)
+}
+
+func TestExtractText_FlateContent(t *testing.T) {
+	data := singlePage("BT /F1 12 Tf 72 720 Td (Jane Doe) Tj 0 -14 Td (Senior \\(Go\\) Engineer) Tj ET")
+
+	text, err := ExtractText(data)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	want := "Jane Doe\nSenior (Go) Engineer"
+	if text != want {
+		t.Errorf("ExtractText() = %q, want %q", text, want)
+	}
+}
+
+func TestExtractText_KerningSpaces(t *testing.T) {
+	// Words positioned with TJ adjustments instead of space characters
+	data := singlePage("BT /F1 10 Tf 72 720 Td [(Go)-300(and)-300(Rust)] TJ ET")
+
+	text, err := ExtractText(data)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	if text != "Go and Rust" {
+		t.Errorf("ExtractText() = %q, want %q", text, "Go and Rust")
+	}
+}
+
+func TestExtractText_CompositeFont(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0010> <00E9>
+endbfchar
+1 beginbfrange
+<0020> <0039> <0041>
+endbfrange
+endcmap
+end
+end`
+
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		flateStream("BT /F1 12 Tf 72 720 Td <0022002E0023002400030031001000320034002C0010> Tj ET"),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /ABCDEF+Custom /Encoding /Identity-H /DescendantFonts [6 0 R] /ToUnicode 7 0 R >>",
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /ABCDEF+Custom /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /DW 500 >>",
+		flateStream(cmap),
+	)
+
+	text, err := ExtractText(data)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	if text != "CODE RéSUMé" {
+		t.Errorf("ExtractText() = %q, want %q", text, "CODE RéSUMé")
+	}
+}
+
+func TestExtractText_TwoColumns(t *testing.T) {
+	var content strings.Builder
+
+	content.WriteString("BT /F1 16 Tf 72 740 Td (Jane Doe - Software Engineer) Tj ET\n")
+
+	left := []string{"SKILLS", "Go", "Kubernetes", "PostgreSQL"}
+	right := []string{"EXPERIENCE", "Built payment APIs", "Led a team of five", "Cut latency in half"}
+
+	// Draw rows left to right, as many generators do
+	for i := range left {
+		y := 700 - i*14
+		fmt.Fprintf(&content, "BT /F1 10 Tf 72 %d Td (%s) Tj ET\n", y, left[i])
+		fmt.Fprintf(&content, "BT /F1 10 Tf 300 %d Td (%s) Tj ET\n", y, right[i])
+	}
+
+	text, err := ExtractText(singlePage(content.String()))
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	want := "Jane Doe - Software Engineer\n\n" + strings.Join(left, "\n") + "\n\n" + strings.Join(right, "\n")
+	if text != want {
+		t.Errorf("ExtractText() =\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestExtractText_BrokenXref(t *testing.T) {
+	data := singlePage("BT /F1 12 Tf 72 720 Td (Recovered) Tj ET")
+
+	// Point startxref at garbage so the object table has to be rebuilt
+	i := bytes.LastIndex(data, []byte("startxref"))
+	data = append(data[:i:i], []byte("startxref\n12\n%%EOF\n")...)
+
+	text, err := ExtractText(data)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	if text != "Recovered" {
+		t.Errorf("ExtractText() = %q, want %q", text, "Recovered")
+	}
+}
+
+func TestExtractText_Invalid(t *testing.T) {
+	if _, err := ExtractText([]byte("not a pdf")); err != ErrInvalid {
+		t.Errorf("ExtractText() error = %v, want %v", err, ErrInvalid)
+	}
+}
+
+func TestExtractText_PlainStreamAndHexString(t *testing.T) {
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		plainStream("BT /F1 12 Tf 72 720 Td <43562056657273696F6E> Tj ET"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+
+	text, err := ExtractText(data)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	if text != "CV Version" {
+		t.Errorf("ExtractText() = %q, want %q", text, "CV Version")
+	}
+}
+
+func TestExtractText_BulletList(t *testing.T) {
+	var content strings.Builder
+
+	items := []string{"Designed the billing service", "Mentored four engineers", "Migrated CI to GitHub Actions"}
+
+	// Bullets drawn apart from their text must not be read as a column
+	for i, item := range items {
+		y := 700 - i*14
+		fmt.Fprintf(&content, "BT /F1 10 Tf 72 %d Td (\\225) Tj ET\n", y)
+		fmt.Fprintf(&content, "BT /F1 10 Tf 90 %d Td (%s) Tj ET\n", y, item)
+	}
+
+	text, err := ExtractText(singlePage(content.String()))
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	want := "• " + strings.Join(items, "\n• ")
+	if text != want {
+		t.Errorf("ExtractText() =\n%s\nwant\n%s", text, want)
+	}
+}
+
+// imagePage builds a one-page document of the given size that paints image
+// object 5 with the given content.
+func imagePage(size int, content, image string) []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /XObject << /Im0 5 0 R >> >> /Contents 4 0 R >>", size, size),
+		plainStream(content),
+		image,
+	)
+}
+
+func TestRenderPage_Scanned(t *testing.T) {
+	// A 2x2 checkerboard stretched over the page, plus an inline image
+	// stencil in the bottom-right corner
+	data := imagePage(200, "q 200 0 0 200 0 0 cm /Im0 Do Q q 20 0 0 20 170 10 cm BI /W 1 /H 1 /IM true ID \x00 EI Q",
+		"<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 4 >>\nstream\n\x00\xff\xff\x80\nendstream")
+
+	doc, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if !doc.IsScanned(0) {
+		t.Error("expected the page to count as scanned")
+	}
+
+	img, err := doc.RenderPage(0, 72)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+
+	if img.Format != "png" || img.DPI != 72 {
+		t.Fatalf("unexpected image %s at %d DPI", img.Format, img.DPI)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+
+	gray, _ := decoded.(*image.Gray)
+	if gray == nil || gray.Rect.Dx() != 200 || gray.Rect.Dy() != 200 {
+		t.Fatalf("unexpected rendered page %T %v", decoded, decoded.Bounds())
+	}
+
+	for _, tc := range []struct {
+		x, y int
+		want uint8
+	}{{50, 50, 0}, {150, 50, 0xFF}, {50, 150, 0xFF}, {120, 120, 0x80}, {180, 180, 0}} {
+		if got := gray.GrayAt(tc.x, tc.y).Y; got != tc.want {
+			t.Errorf("pixel (%d, %d) = %#x, want %#x", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	text, err := Open(singlePage("BT /F1 12 Tf 72 720 Td (Jane Doe) Tj ET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text.IsScanned(0) {
+		t.Error("expected a text page not to count as scanned")
+	}
+
+	if _, err := text.RenderPage(0, 72); !errors.Is(err, ErrNoImages) {
+		t.Errorf("expected ErrNoImages, got %v", err)
+	}
+}
+
+func TestRenderPage_NativeJPEG(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewGray(image.Rect(0, 0, 150, 150)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := imagePage(72, "q 72 0 0 72 0 0 cm /Im0 Do Q", fmt.Sprintf(
+		"<< /Type /XObject /Subtype /Image /Width 150 /Height 150 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream",
+		jpg.Len(), jpg.String()))
+
+	doc, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	img, err := doc.RenderPage(0, 300)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+
+	if img.Format != "jpeg" || img.DPI != 150 || !bytes.Equal(img.Data, jpg.Bytes()) {
+		t.Errorf("expected the embedded JPEG at 150 DPI, got %s at %d DPI", img.Format, img.DPI)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pdf extracts text from PDF documents. It reads classic and
+// compressed cross-reference data (rebuilding it when broken), object
+// streams, the common stream filters, simple and composite (CID) fonts with
+// their encodings and ToUnicode maps, and documents encrypted with an owner
+// password only. Text is returned in reading order, with multi-column
+// layouts read column by column and line breaks preserved.
+package pdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// textReplacer expands ligatures and maps the private-use bullets of symbol
+// fonts, which are common in CVs exported from word processors.
+var textReplacer = strings.NewReplacer(
+	"\ufb00", "ff", "\ufb01", "fi", "\ufb02", "fl", "\ufb03", "ffi", "\ufb04", "ffl",
+	"\ufb05", "st", "\ufb06", "st",
+	"\u00a0", " ", "\u00ad", "", "\ufffd", "", "\ufeff", "",
+	"\uf0b7", "•", "\uf0a7", "•", "\uf076", "•", "\uf0d8", "•", "\uf0fc", "•",
+	"\uf06c", "•", "\uf06e", "•", "\uf0a8", "•", "\uf0a0", "•",
+)
+
+// ExtractText extracts the text of every page of a PDF document.
+func ExtractText(data []byte) (string, error) {
+	doc, err := Open(data)
+	if err != nil {
+		return "", err
+	}
+
+	return doc.Text(), nil
+}
+
+// Text returns the text of every page, with pages separated by a blank line.
+func (d *Document) Text() string {
+	pages := make([]string, 0, len(d.pages))
+
+	for i := range d.pages {
+		if text := d.PageText(i); text != "" {
+			pages = append(pages, text)
+		}
+	}
+
+	return strings.Join(pages, "\n\n")
+}
+
+// PageText returns the text of page i, counting from zero.
+func (d *Document) PageText(i int) string {
+	if i < 0 || i >= len(d.pages) {
+		return ""
+	}
+
+	text := layoutPage(d.runPage(d.pages[i]).chars)
+
+	return cleanText(textReplacer.Replace(text))
+}
+
+// cleanText drops control characters and trailing spaces on every line.
+func cleanText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package skills
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tax := Default()
+
+	tests := map[string]string{
+		"K8s":            "kubernetes",
+		"postgres":       "postgresql",
+		"Golang":         "go",
+		"go":             "go",
+		"C#":             "csharp",
+		"c++":            "cpp",
+		"Spring Boot":    "spring",
+		"chef":           "",
+		" React.js ":     "react",
+		"bases de datos": "databases",
+	}
+
+	for term, want := range tests {
+		if s, ok := tax.Resolve(term); ok != (want != "") || ok && s.ID != want {
+			t.Errorf("Resolve(%q) = %v, want %s", term, s, want)
+		}
+	}
+
+	if _, ok := tax.Resolve("Underwater basket weaving"); ok {
+		t.Error("Expected an unknown term not to resolve")
+	}
+
+	if got := tax.Normalize([]string{"k8s", "Kubernetes", "golang", "Leadership"}); !slices.Equal(got, []string{"Kubernetes", "Go", "Leadership"}) {
+		t.Errorf("Normalize = %q", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	tax := Default()
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Ran K8s clusters backed by Postgres", []string{"kubernetes", "postgresql"}},
+		{"Skills: Go, Python, Docker", []string{"python", "docker", "go"}},
+		{"Go\nRust", []string{"go", "rust"}},
+		{"Built services in Go with gRPC", []string{"grpc", "go"}},
+		{"Ready to go the extra mile", nil},
+		{"Go to market strategy for new regions", nil},
+		{"Senior Go Engineer. Go and build it.", []string{"go"}},
+		{"Hired in Spring 2020 as Head Chef", nil},
+		{"Go to market. Then rebuilt billing in Go", []string{"go"}},
+		{"Led R&D for C-level reports", nil},
+		{"Learned to react quickly to incidents and excel under pressure", nil},
+		{"Frontend in React and Express", []string{"frontend", "react", "express"}},
+		{"Modern C++ and C# codebases", []string{"cpp", "csharp"}},
+		{"Statistical modelling in R", []string{"r"}},
+	}
+
+	for _, tt := range tests {
+		if got := tax.IDs(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("IDs(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	tax := Default()
+
+	if got := tax.Ancestors("eks"); !slices.Contains(got, "kubernetes") || !slices.Contains(got, "containers") || !slices.Contains(got, "cloud") {
+		t.Errorf("Ancestors(eks) = %q", got)
+	}
+
+	if !tax.Covers([]string{"postgresql"}, "sql") || tax.Covers([]string{"sql"}, "postgresql") {
+		t.Error("Expected PostgreSQL to cover SQL, but not SQL to cover PostgreSQL")
+	}
+
+	score, covered, missing := tax.Coverage(
+		"Operated EKS clusters, wrote Golang services on Postgres.",
+		"Requirements: Kubernetes, SQL, Go and Kafka experience.")
+	if score != 0.75 || !slices.Equal(covered, []string{"Kubernetes", "SQL", "Go"}) || !slices.Equal(missing, []string{"Kafka"}) {
+		t.Errorf("Coverage = %v, %q, %q", score, covered, missing)
+	}
+
+	if score, _, _ := tax.Coverage("Anything", "A friendly team"); score != 0 {
+		t.Errorf("Coverage without job skills = %v, want 0", score)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for name, data := range map[string]string{
+		"bad json":       `{`,
+		"missing name":   `{"skills": [{"id": "go"}]}`,
+		"duplicate id":   `{"skills": [{"id": "go", "name": "Go"}, {"id": "go", "name": "Golang"}]}`,
+		"unknown parent": `{"skills": [{"id": "go", "name": "Go", "parents": ["programming"]}]}`,
+		"shared alias":   `{"skills": [{"id": "a", "name": "A", "aliases": ["x"]}, {"id": "b", "name": "B", "aliases": ["x"]}]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
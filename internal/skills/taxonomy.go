@@ -0,0 +1,370 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+// Package skills maps skill mentions in CVs and job postings to canonical
+// skills, so that "K8s" counts as Kubernetes, "Postgres" as PostgreSQL and
+// the verb "go" not as the Go language.
+package skills
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sammyoina/vibe-cv/internal/i18n"
+)
+
+//go:embed taxonomy.json
+var taxonomyJSON []byte
+
+// Skill is a canonical skill of the taxonomy.
+type Skill struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Parents are broader skills this one implies, e.g. PostgreSQL implies SQL.
+	Parents []string `json:"parents,omitempty"`
+	// Ambiguous are spellings that are also common words, such as "Go" or
+	// "React". They only match as written, and only within a sentence, in a
+	// list of skills, on a line of their own, or on a line that also
+	// mentions another skill or a Context word; "Go to market" does not.
+	Ambiguous []string `json:"ambiguous,omitempty"`
+	Context   []string `json:"context,omitempty"`
+}
+
+// Taxonomy is a set of skills with their aliases and relations.
+type Taxonomy struct {
+	skills  []*Skill
+	byID    map[string]*Skill
+	byAlias map[string]*Skill
+	// plain matches the unambiguous spellings in folded text, and ambiguous
+	// the ambiguous ones in the original text.
+	plain     []matcher
+	ambiguous []matcher
+	context   map[string]*regexp.Regexp
+}
+
+// matcher finds a spelling of a skill.
+type matcher struct {
+	skill *Skill
+	re    *regexp.Regexp
+}
+
+// Word boundaries of a mention: letters and digits continue a word, and so
+// do "+" and "#" after it, so that "C" does not match "C++" or "C#". Around
+// ambiguous spellings "&", "-" and apostrophes do too, so that "R" does not
+// match "R&D" nor "C" "C-level".
+const (
+	wordBefore      = `(?:^|[^\p{L}\p{N}])`
+	wordAfter       = `(?:$|[^\p{L}\p{N}+#])`
+	ambiguousBefore = `(?:^|[^\p{L}\p{N}&'’-])`
+	ambiguousAfter  = `(?:$|[^\p{L}\p{N}+#&'’-])`
+)
+
+var (
+	// listItem matches a line that is only a list of short items, as in
+	// "Go, Python, Kubernetes" or "Languages: Go / Rust".
+	listItem = regexp.MustCompile(`^(?:[^,;/|•·:]{0,30}:)?\s*[^,;/|•·]{1,30}(?:\s*[,;/|•·]\s*[^,;/|•·]{1,30})+\s*\.?$`)
+	// sentenceStart matches the text before the first word of a sentence.
+	sentenceStart = regexp.MustCompile(`(?:^|[.!?:]\s+|[.!?:]$)[\s\-*•·"'(]*$`)
+)
+
+var (
+	defaultOnce     sync.Once
+	defaultTaxonomy *Taxonomy
+)
+
+// Default returns the taxonomy embedded in the binary.
+func Default() *Taxonomy {
+	defaultOnce.Do(func() {
+		t, err := Parse(taxonomyJSON)
+		if err != nil {
+			panic(fmt.Sprintf("skills: invalid embedded taxonomy: %v", err))
+		}
+
+		defaultTaxonomy = t
+	})
+
+	return defaultTaxonomy
+}
+
+// Parse reads a taxonomy from JSON of the form {"skills": [...]}. Skill IDs
+// and spellings must be unique, and parents must exist.
+func Parse(data []byte) (*Taxonomy, error) {
+	var doc struct {
+		Skills []*Skill `json:"skills"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	t := &Taxonomy{
+		skills:  doc.Skills,
+		byID:    make(map[string]*Skill),
+		byAlias: make(map[string]*Skill),
+		context: make(map[string]*regexp.Regexp),
+	}
+
+	for _, s := range t.skills {
+		if s.ID == "" || s.Name == "" {
+			return nil, fmt.Errorf("skill %q: missing id or name", s.ID)
+		}
+
+		if _, ok := t.byID[s.ID]; ok {
+			return nil, fmt.Errorf("skill %q: duplicate id", s.ID)
+		}
+
+		t.byID[s.ID] = s
+	}
+
+	for _, s := range t.skills {
+		for _, parent := range s.Parents {
+			if _, ok := t.byID[parent]; !ok {
+				return nil, fmt.Errorf("skill %q: unknown parent %q", s.ID, parent)
+			}
+		}
+
+		spellings := append([]string{s.Name}, s.Aliases...)
+		for _, spelling := range append(spellings, s.Ambiguous...) {
+			key := i18n.Fold(spelling)
+			if other, ok := t.byAlias[key]; ok && other != s {
+				return nil, fmt.Errorf("skill %q: spelling %q is also used by %q", s.ID, spelling, other.ID)
+			}
+
+			t.byAlias[key] = s
+		}
+
+		for _, spelling := range spellings {
+			if !s.isAmbiguous(spelling) {
+				t.plain = append(t.plain, matcher{skill: s, re: wordPattern(i18n.Fold(spelling))})
+			}
+		}
+
+		for _, spelling := range s.Ambiguous {
+			t.ambiguous = append(t.ambiguous, matcher{skill: s, re: regexp.MustCompile(
+				ambiguousBefore + `(` + regexp.QuoteMeta(spelling) + `)` + ambiguousAfter)})
+		}
+
+		for _, word := range s.Context {
+			t.context[word] = wordPattern(i18n.Fold(word))
+		}
+	}
+
+	return t, nil
+}
+
+// wordPattern matches a spelling as a whole word.
+func wordPattern(spelling string) *regexp.Regexp {
+	return regexp.MustCompile(wordBefore + `(` + regexp.QuoteMeta(spelling) + `)` + wordAfter)
+}
+
+// isAmbiguous reports whether a spelling of the skill is ambiguous.
+func (s *Skill) isAmbiguous(spelling string) bool {
+	key := i18n.Fold(spelling)
+
+	return slices.ContainsFunc(s.Ambiguous, func(a string) bool {
+		return i18n.Fold(a) == key
+	})
+}
+
+// Skills returns every skill of the taxonomy.
+func (t *Taxonomy) Skills() []*Skill {
+	return t.skills
+}
+
+// Skill returns the skill with an ID.
+func (t *Taxonomy) Skill(id string) (*Skill, bool) {
+	s, ok := t.byID[id]
+
+	return s, ok
+}
+
+// Resolve returns the skill a term names, such as "k8s" or "Golang", in any
+// case. Ambiguous spellings resolve too, as a term is a skill by intent.
+func (t *Taxonomy) Resolve(term string) (*Skill, bool) {
+	s, ok := t.byAlias[i18n.Fold(strings.TrimSpace(term))]
+
+	return s, ok
+}
+
+// Canonical returns the canonical name of a term, or the term itself when
+// the taxonomy does not know it.
+func (t *Taxonomy) Canonical(term string) string {
+	if s, ok := t.Resolve(term); ok {
+		return s.Name
+	}
+
+	return strings.TrimSpace(term)
+}
+
+// Normalize returns the canonical names of terms, without duplicates, in
+// the order they first appear.
+func (t *Taxonomy) Normalize(terms []string) []string {
+	var names []string
+
+	for _, term := range terms {
+		if name := t.Canonical(term); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Find returns the skills mentioned in a text, each once, in the order they
+// are first mentioned.
+func (t *Taxonomy) Find(text string) []*Skill {
+	var (
+		found []*Skill
+		seen  = make(map[string]bool)
+	)
+
+	add := func(s *Skill) {
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			found = append(found, s)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		folded := i18n.Fold(line)
+
+		// Plain spellings, leftmost first
+		type mention struct {
+			at    int
+			skill *Skill
+		}
+
+		var plain []mention
+
+		for _, m := range t.plain {
+			if loc := m.re.FindStringSubmatchIndex(folded); loc != nil {
+				plain = append(plain, mention{at: loc[2], skill: m.skill})
+			}
+		}
+
+		slices.SortStableFunc(plain, func(a, b mention) int { return a.at - b.at })
+
+		for _, p := range plain {
+			add(p.skill)
+		}
+
+		item := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•·"))
+
+		for _, m := range t.ambiguous {
+			if seen[m.skill.ID] {
+				continue
+			}
+
+			for _, loc := range m.re.FindAllStringSubmatchIndex(line, -1) {
+				if len(plain) > 0 || item == line[loc[2]:loc[3]] || !sentenceStart.MatchString(line[:loc[2]]) ||
+					listItem.MatchString(item) || t.hasContext(m.skill, folded) || t.nearAmbiguous(m.skill, line) {
+					add(m.skill)
+
+					break
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// hasContext reports whether a folded line has a context word of a skill.
+func (t *Taxonomy) hasContext(s *Skill, folded string) bool {
+	for _, word := range s.Context {
+		if t.context[word].MatchString(folded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nearAmbiguous reports whether a line also has an ambiguous spelling of
+// another skill, as in "React and Express".
+func (t *Taxonomy) nearAmbiguous(s *Skill, line string) bool {
+	for _, m := range t.ambiguous {
+		if m.skill != s && m.re.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IDs returns the IDs of the skills mentioned in a text.
+func (t *Taxonomy) IDs(text string) []string {
+	var ids []string
+
+	for _, s := range t.Find(text) {
+		ids = append(ids, s.ID)
+	}
+
+	return ids
+}
+
+// Ancestors returns the IDs of the broader skills a skill implies, nearest
+// first.
+func (t *Taxonomy) Ancestors(id string) []string {
+	var ancestors []string
+
+	queue := []string{id}
+	for len(queue) > 0 {
+		s, ok := t.byID[queue[0]]
+		queue = queue[1:]
+
+		if !ok {
+			continue
+		}
+
+		for _, parent := range s.Parents {
+			if parent != id && !slices.Contains(ancestors, parent) {
+				ancestors = append(ancestors, parent)
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return ancestors
+}
+
+// Covers reports whether a set of skill IDs satisfies a required skill: it
+// has the skill itself or a narrower one, as PostgreSQL satisfies SQL.
+func (t *Taxonomy) Covers(have []string, id string) bool {
+	for _, h := range have {
+		if h == id || slices.Contains(t.Ancestors(h), id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Coverage compares the skills mentioned in a CV with those a job asks for,
+// and returns the share of the job's skills the CV covers with the names of
+// the covered and missing ones. It is zero when the job names no skill.
+func (t *Taxonomy) Coverage(cvText, jobText string) (float64, []string, []string) {
+	have := t.IDs(cvText)
+
+	var covered, missing []string
+
+	for _, id := range t.IDs(jobText) {
+		if t.Covers(have, id) {
+			covered = append(covered, t.byID[id].Name)
+		} else {
+			missing = append(missing, t.byID[id].Name)
+		}
+	}
+
+	if len(covered)+len(missing) == 0 {
+		return 0, nil, nil
+	}
+
+	return float64(len(covered)) / float64(len(covered)+len(missing)), covered, missing
+}
@@ -13,14 +13,51 @@ type CustomizeCVRequest struct {
 	AdditionalContext []ContextItem `json:"additional_context,omitempty"`
 	LLMConfig         *LLMConfig    `json:"llm_config,omitempty"`
 	InputSources      []InputSource `json:"input_sources,omitempty"` // Phase 2
+	Language          string        `json:"language,omitempty"`      // ISO 639-1 code of the customized CV; detected from the CV when empty
 }
 
 // InputSource represents a source of input (Phase 2).
 type InputSource struct {
-	Type     string `json:"type"`     // "text", "url", "pdf", "docx", "linkedin"
-	Content  string `json:"content"`  // For text input or base64 for files
-	URL      string `json:"url"`      // For URL input
-	FileName string `json:"filename"` // Original filename for files
+	Type     string `json:"type"`           // "text", "url", "pdf", "docx", "doc", "odt", "rtf", "linkedin"
+	Content  string `json:"content"`        // For text input or base64 for files
+	URL      string `json:"url"`            // For URL input
+	FileName string `json:"filename"`       // Original filename for files
+	Role     string `json:"role,omitempty"` // "cv", "job_description" or "context"; defaults by type
+}
+
+// Input source roles.
+const (
+	RoleCV             = "cv"
+	RoleJobDescription = "job_description"
+	RoleContext        = "context"
+)
+
+// InputSourceResult reports how one input source of a request was resolved.
+type InputSourceResult struct {
+	Source   string     `json:"source"`             // Request field, e.g. "cv_file" or "input_sources[1]"
+	Type     string     `json:"type"`               // "text", "url", "pdf", "docx", "doc", "odt", "rtf", "linkedin"
+	Role     string     `json:"role"`               // "cv", "job_description" or "context"
+	Status   string     `json:"status"`             // "resolved" or "failed"
+	Chars    int        `json:"chars,omitempty"`    // Length of the resolved text
+	Language string     `json:"language,omitempty"` // Detected language of the resolved text, as an ISO 639-1 code
+	Error    string     `json:"error,omitempty"`
+	OCR      *OCRReport `json:"ocr,omitempty"` // Set when scanned pages were recognized
+}
+
+// OCRReport describes the text recognized on the scanned pages of a source.
+type OCRReport struct {
+	Engine     string      `json:"engine"`
+	Pages      []int       `json:"pages"`            // Recognized pages, counting from 1
+	Confidence float64     `json:"confidence"`       // Mean word confidence, 0-100
+	Review     []OCRRegion `json:"review,omitempty"` // Low-confidence lines the user should check
+}
+
+// OCRRegion is a line of recognized text with a low confidence.
+type OCRRegion struct {
+	Page       int     `json:"page"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	Box        [4]int  `json:"box"` // x, y, width and height in pixels of the page rendered for OCR
 }
 
 // ContextItem represents additional context (text or URL).
@@ -38,11 +75,30 @@ type LLMConfig struct {
 
 // CustomizeCVResponse represents the response from CV customization.
 type CustomizeCVResponse struct {
-	Status          string   `json:"status"`
-	CustomizedCVURL string   `json:"customized_cv_url"`
-	MatchScore      float64  `json:"match_score"`
-	Modifications   []string `json:"modifications"`
-	Error           string   `json:"error,omitempty"`
+	Status          string              `json:"status"`
+	CustomizedCVURL string              `json:"customized_cv_url"`
+	MatchScore      float64             `json:"match_score"`
+	Modifications   []string            `json:"modifications"`
+	Language        string              `json:"language"` // ISO 639-1 code of the customized CV
+	Sources         []InputSourceResult `json:"sources,omitempty"`
+	MatchDetails    *MatchDetails       `json:"match_details,omitempty"` // How MatchScore was computed
+	Error           string              `json:"error,omitempty"`
+}
+
+// MatchDetails explains a match score requirement by requirement.
+type MatchDetails struct {
+	Embedder     string             `json:"embedder"` // Embedding backend and model, e.g. "local" or "openai/text-embedding-3-small"
+	Requirements []RequirementMatch `json:"requirements"`
+}
+
+// RequirementMatch is how well a CV covers one requirement of a job.
+type RequirementMatch struct {
+	Requirement string  `json:"requirement"`
+	Kind        string  `json:"kind"`       // "required" or "preferred"
+	Similarity  float64 `json:"similarity"` // Cosine similarity of the closest CV passage
+	Coverage    float64 `json:"coverage"`   // 0 to 1
+	Covered     bool    `json:"covered"`
+	Evidence    string  `json:"evidence,omitempty"` // The closest CV passage
 }
 
 // CVContent represents parsed CV content.
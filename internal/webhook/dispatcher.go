@@ -0,0 +1,293 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sammyoina/vibe-cv/internal/db"
+	"github.com/sammyoina/vibe-cv/internal/egress"
+)
+
+const (
+	// defaultPollInterval is how often idle workers look for due deliveries.
+	defaultPollInterval = 2 * time.Second
+	// defaultLeaseDuration is how long a claimed delivery stays owned by a worker.
+	defaultLeaseDuration = time.Minute
+	// defaultMaxAttempts is how many times a delivery is tried before it is failed.
+	defaultMaxAttempts = 8
+	// defaultRequestTimeout bounds a single delivery request.
+	defaultRequestTimeout = 10 * time.Second
+	// claimBatchSize is how many deliveries a worker claims at once.
+	claimBatchSize = 5
+	// retryBackoff is the delay before the second attempt; it doubles on each retry.
+	retryBackoff = 30 * time.Second
+	// maxRetryBackoff caps the delay between attempts.
+	maxRetryBackoff = 6 * time.Hour
+	// maxLoggedResponse is how much of a response body is kept in the delivery log.
+	maxLoggedResponse = 2048
+)
+
+// Envelope is the JSON body posted to webhook endpoints.
+type Envelope struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatcher publishes events to the webhook outbox and delivers them to
+// registered endpoints. Events are written to Postgres before any delivery is
+// attempted, so they survive restarts; deliveries are claimed with
+// FOR UPDATE SKIP LOCKED and retried with exponential backoff.
+type Dispatcher struct {
+	repo          *db.Repository
+	client        *http.Client
+	workers       int
+	pollInterval  time.Duration
+	leaseDuration time.Duration
+	maxAttempts   int
+	wake          chan struct{}
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher.
+func NewDispatcher(repo *db.Repository, workers int) *Dispatcher {
+	return &Dispatcher{
+		repo:          repo,
+		client:        newClient(egress.DefaultPolicy()),
+		workers:       workers,
+		pollInterval:  defaultPollInterval,
+		leaseDuration: defaultLeaseDuration,
+		maxAttempts:   defaultMaxAttempts,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// newClient creates the delivery client. Endpoints are reached under the
+// egress policy, and redirects are reported as failures rather than followed.
+func newClient(policy *egress.Policy) *http.Client {
+	return &http.Client{
+		Timeout:   defaultRequestTimeout,
+		Transport: policy.Transport(),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// SetPolicy sets the egress policy endpoints are reached under. It must be
+// called before Start.
+func (d *Dispatcher) SetPolicy(policy *egress.Policy) {
+	d.client = newClient(policy)
+}
+
+// Publish stores an event for an identity in the outbox. Events of anonymous
+// requests are dropped, since only authenticated users can register endpoints.
+// It is safe to call on a nil dispatcher.
+func (d *Dispatcher) Publish(identityID *int, eventType string, data any) error {
+	if d == nil || d.repo == nil || identityID == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	created, err := d.repo.CreateWebhookEvent(*identityID, eventType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to store %s event: %w", eventType, err)
+	}
+
+	if created > 0 {
+		d.notify()
+	}
+
+	return nil
+}
+
+// Replay schedules a new delivery of an earlier delivery's event.
+func (d *Dispatcher) Replay(deliveryID, endpointID int) (*db.WebhookDelivery, error) {
+	delivery, err := d.repo.ReplayWebhookDelivery(deliveryID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.notify()
+
+	return delivery, nil
+}
+
+// Start starts the delivery workers.
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	for range d.workers {
+		d.wg.Add(1)
+
+		go d.worker(ctx)
+	}
+}
+
+// Stop stops the delivery workers and waits for them to exit. Deliveries in
+// flight are retried by another worker once their lease expires.
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.wg.Wait()
+}
+
+// notify wakes an idle worker without blocking.
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker claims and sends due deliveries until ctx is done.
+func (d *Dispatcher) worker(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := d.repo.ClaimWebhookDeliveries(claimBatchSize, d.leaseDuration)
+		if err != nil {
+			log.Printf("webhook: failed to claim deliveries: %v", err)
+		}
+
+		for _, p := range pending {
+			if ctx.Err() != nil {
+				return
+			}
+
+			d.deliver(ctx, p)
+		}
+
+		if len(pending) == claimBatchSize {
+			// More work is probably waiting
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver sends one delivery and records the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, p *db.PendingWebhookDelivery) {
+	entry := &db.WebhookDeliveryLog{
+		DeliveryID: p.Delivery.ID,
+		Attempt:    p.Delivery.Attempts,
+	}
+
+	start := time.Now()
+	statusCode, body, err := d.send(ctx, p)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	if statusCode != 0 {
+		entry.StatusCode = &statusCode
+		entry.ResponseBody = &body
+	}
+
+	if err == nil {
+		if recErr := d.repo.RecordWebhookAttempt(entry, "delivered", nil, nil); recErr != nil {
+			log.Printf("webhook: failed to record delivery %d: %v", p.Delivery.ID, recErr)
+		}
+
+		return
+	}
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the delivery for the next lease holder
+		return
+	}
+
+	errMsg := err.Error()
+	entry.Error = &errMsg
+
+	status := "failed"
+
+	var nextAttemptAt *time.Time
+
+	if p.Delivery.Attempts < d.maxAttempts {
+		status = "pending"
+		next := time.Now().Add(retryDelay(p.Delivery.Attempts))
+		nextAttemptAt = &next
+	}
+
+	if recErr := d.repo.RecordWebhookAttempt(entry, status, nextAttemptAt, &errMsg); recErr != nil {
+		log.Printf("webhook: failed to record delivery %d: %v", p.Delivery.ID, recErr)
+	}
+}
+
+// send posts the signed event envelope and returns the response status code
+// and a truncated response body. Any non-2xx response is an error.
+func (d *Dispatcher) send(ctx context.Context, p *db.PendingWebhookDelivery) (int, string, error) {
+	body, err := json.Marshal(Envelope{
+		ID:        p.Event.ID,
+		Type:      p.Event.EventType,
+		CreatedAt: p.Event.CreatedAt.UTC(),
+		Data:      p.Event.Payload,
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vibe-cv-webhooks/1.0")
+	req.Header.Set(HeaderEvent, p.Event.EventType)
+	req.Header.Set(HeaderEventID, strconv.Itoa(p.Event.ID))
+	req.Header.Set(HeaderDelivery, strconv.Itoa(p.Delivery.ID))
+	req.Header.Set(HeaderSignature, Sign(p.Secret, body, time.Now()))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(respBody), nil
+}
+
+// retryDelay returns the backoff after the given failed attempt.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBackoff
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryBackoff)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types delivered to webhook endpoints.
+const (
+	EventBatchCompleted          = "batch.completed"
+	EventBatchItemFailed         = "batch.item_failed"
+	EventLinkedInImportCompleted = "linkedin_import.completed"
+	EventATSAnalysisCompleted    = "ats_analysis.completed"
+)
+
+// Headers set on every webhook request.
+const (
+	HeaderSignature = "X-Vibe-CV-Signature"
+	HeaderEvent     = "X-Vibe-CV-Event"
+	HeaderEventID   = "X-Vibe-CV-Event-ID"
+	HeaderDelivery  = "X-Vibe-CV-Delivery"
+)
+
+// EventTypes lists every supported event type.
+var EventTypes = []string{
+	EventBatchCompleted,
+	EventBatchItemFailed,
+	EventLinkedInImportCompleted,
+	EventATSAnalysisCompleted,
+}
+
+var (
+	// ErrInvalidSignature is returned when a signature header does not match the payload.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired is returned when a signature timestamp is outside the tolerance.
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for a payload sent at the given time.
+// The header has the form "t=<unix seconds>,v1=<hex HMAC-SHA256>", where the
+// HMAC is computed over "<unix seconds>.<payload>" with the endpoint secret.
+func Sign(secret string, payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+
+	return "t=" + ts + ",v1=" + computeMAC(secret, ts, payload)
+}
+
+// Verify checks a signature header against a payload. Signatures older or newer
+// than tolerance are rejected; a zero tolerance disables the timestamp check.
+func Verify(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var (
+		ts         string
+		signatures []string
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if ts == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := computeMAC(secret, ts, payload)
+	if slices.ContainsFunc(signatures, func(sig string) bool {
+		return hmac.Equal([]byte(sig), []byte(expected))
+	}) {
+		return nil
+	}
+
+	return ErrInvalidSignature
+}
+
+// computeMAC returns the hex HMAC-SHA256 of "<ts>.<payload>".
+func computeMAC(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateEndpointURL checks that a webhook URL is an absolute http(s) URL.
+func ValidateEndpointURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return errors.New("url must use http or https")
+	}
+
+	if u.Host == "" {
+		return errors.New("url must include a host")
+	}
+
+	return nil
+}
+
+// ValidateEventTypes checks that every event type is supported.
+func ValidateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return fmt.Errorf("unsupported event type %q", eventType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":1,"type":"batch.completed"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := Sign(secret, payload, now)
+
+	if err := Verify(secret, header, payload, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("expected signature to verify, got %v", err)
+	}
+
+	if err := Verify("other", header, payload, 5*time.Minute, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+
+	if err := Verify(secret, header, []byte(`{}`), 5*time.Minute, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for modified payload, got %v", err)
+	}
+
+	if err := Verify(secret, header, payload, 5*time.Minute, now.Add(time.Hour)); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expected ErrSignatureExpired, got %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if got := retryDelay(1); got != retryBackoff {
+		t.Errorf("expected first retry after %v, got %v", retryBackoff, got)
+	}
+
+	if got := retryDelay(3); got != 4*retryBackoff {
+		t.Errorf("expected third retry after %v, got %v", 4*retryBackoff, got)
+	}
+
+	if got := retryDelay(40); got != maxRetryBackoff {
+		t.Errorf("expected delay capped at %v, got %v", maxRetryBackoff, got)
+	}
+}
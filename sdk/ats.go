@@ -12,27 +12,68 @@ import (
 type ATSAnalysisResponse struct {
 	ID                  int                    `json:"id"`
 	CVVersionID         int                    `json:"cv_version_id"`
+	ATSProfile          string                 `json:"ats_profile"`
 	OverallScore        float64                `json:"overall_score"`
 	KeywordMatches      map[string]interface{} `json:"keyword_matches"`
 	FormattingIssues    []interface{}          `json:"formatting_issues"`
 	SectionCompleteness map[string]float64     `json:"section_completeness"`
+	Experience          *ExperienceMatch       `json:"experience,omitempty"`
+	PDFCheck            *PDFCheck              `json:"pdf_check,omitempty"`
 	Recommendations     []interface{}          `json:"recommendations"`
 	CreatedAt           string                 `json:"created_at,omitempty"`
 }
 
+// ExperienceMatch compares the CV's tenure with the years of experience the
+// job asks for. It is only returned by AnalyzeATS.
+type ExperienceMatch struct {
+	Years         float64 `json:"years"`
+	RequiredYears int     `json:"required_years"`
+	Meets         bool    `json:"meets"`
+}
+
 // AnalyzeATSRequest represents the request for ATS analysis.
 type AnalyzeATSRequest struct {
 	CVVersionID    int    `json:"cv_version_id"`
 	JobDescription string `json:"job_description"`
+	ATSProfile     string `json:"ats_profile,omitempty"` // ATS to simulate, e.g. "workday"; defaults to "generic"
+	FileType       string `json:"file_type,omitempty"`   // Type of the submitted file; defaults to "pdf"
+	CheckPDF       bool   `json:"check_pdf,omitempty"`   // Render the CV and check the text extracted from the PDF
+}
+
+// PDFCheck compares the text extracted from the rendered PDF of a CV with
+// the CV. It is only returned by AnalyzeATSWith with CheckPDF.
+type PDFCheck struct {
+	Coverage  float64  `json:"coverage"`            // Share of the CV's words found in the PDF
+	Lost      []string `json:"lost,omitempty"`      // Lines missing words in the PDF
+	Scrambled []string `json:"scrambled,omitempty"` // Lines whose words come out of order
+	Ligatures []string `json:"ligatures,omitempty"` // Words whose ligatures did not survive
+	Hyphens   int      `json:"hyphenated_words"`
+	Symbols   int      `json:"lost_symbols"`
+	Error     string   `json:"error,omitempty"` // Why the PDF could not be checked
+}
+
+// ATSProfile describes how a simulated ATS parses and ranks CVs.
+type ATSProfile struct {
+	Name        string             `json:"name"`
+	Vendor      string             `json:"vendor"`
+	Description string             `json:"description"`
+	Weights     map[string]float64 `json:"weights"` // Shares of keywords, formatting and sections in the overall score
+	FileTypes   []string           `json:"file_types,omitempty"`
+	Severities  map[string]string  `json:"severities,omitempty"`
 }
 
-// AnalyzeATS analyzes a CV version for ATS compatibility.
+// AnalyzeATS analyzes a CV version for ATS compatibility with the generic
+// profile.
 func (c *Client) AnalyzeATS(ctx context.Context, cvVersionID int, jobDescription string, opts ...RequestOption) (*ATSAnalysisResponse, error) {
-	req := AnalyzeATSRequest{
+	return c.AnalyzeATSWith(ctx, AnalyzeATSRequest{
 		CVVersionID:    cvVersionID,
 		JobDescription: jobDescription,
-	}
+	}, opts...)
+}
 
+// AnalyzeATSWith analyzes a CV version as the ATS of a profile would, such
+// as "workday" or "taleo".
+func (c *Client) AnalyzeATSWith(ctx context.Context, req AnalyzeATSRequest, opts ...RequestOption) (*ATSAnalysisResponse, error) {
 	var result ATSAnalysisResponse
 	err := c.doRequest(ctx, "POST", "/api/latest/ats/analyze", req, &result, opts...)
 	if err != nil {
@@ -42,7 +83,7 @@ func (c *Client) AnalyzeATS(ctx context.Context, cvVersionID int, jobDescription
 	return &result, nil
 }
 
-// GetATSAnalysis retrieves an existing ATS analysis for a CV version.
+// GetATSAnalysis retrieves the latest ATS analysis of a CV version.
 func (c *Client) GetATSAnalysis(ctx context.Context, cvVersionID int, opts ...RequestOption) (*ATSAnalysisResponse, error) {
 	path := fmt.Sprintf("/api/latest/ats/%d", cvVersionID)
 
@@ -54,3 +95,120 @@ func (c *Client) GetATSAnalysis(ctx context.Context, cvVersionID int, opts ...Re
 
 	return &result, nil
 }
+
+// ListATSProfiles retrieves the ATS profiles analyses can simulate.
+func (c *Client) ListATSProfiles(ctx context.Context, opts ...RequestOption) ([]ATSProfile, error) {
+	var result struct {
+		Profiles []ATSProfile `json:"profiles"`
+	}
+
+	if err := c.doRequest(ctx, "GET", "/api/latest/ats/profiles", nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list ATS profiles: %w", err)
+	}
+
+	return result.Profiles, nil
+}
+
+// ATSHistory lists the ATS analyses of a CV version or of every version of a
+// CV, oldest first, with how scores evolved per profile.
+type ATSHistory struct {
+	CVID        int               `json:"cv_id,omitempty"`
+	CVVersionID int               `json:"cv_version_id,omitempty"`
+	Analyses    []ATSHistoryEntry `json:"analyses"`
+	Trends      []ATSTrend        `json:"trends"`
+}
+
+// ATSHistoryEntry is an analysis of an ATS history. Change compares it with
+// the previous analysis of the same profile, and is nil for the first one.
+type ATSHistoryEntry struct {
+	ID               int                    `json:"id"`
+	CVVersionID      int                    `json:"cv_version_id"`
+	ATSProfile       string                 `json:"ats_profile"`
+	OverallScore     float64                `json:"overall_score"`
+	KeywordMatches   map[string]interface{} `json:"keyword_matches"`
+	FormattingIssues []interface{}          `json:"formatting_issues"`
+	CreatedAt        string                 `json:"created_at"`
+	Change           *ATSChange             `json:"change,omitempty"`
+}
+
+// ATSChange is how an ATS analysis differs from the previous one.
+type ATSChange struct {
+	PreviousID     int           `json:"previous_id"`
+	ScoreDelta     float64       `json:"score_delta"`
+	KeywordsGained []string      `json:"keywords_gained"`
+	KeywordsLost   []string      `json:"keywords_lost"`
+	IssuesFixed    []interface{} `json:"issues_fixed"`
+	IssuesNew      []interface{} `json:"issues_new"`
+}
+
+// ATSTrend sums up how the score of one ATS profile evolved.
+type ATSTrend struct {
+	ATSProfile  string  `json:"ats_profile"`
+	Analyses    int     `json:"analyses"`
+	FirstScore  float64 `json:"first_score"`
+	LatestScore float64 `json:"latest_score"`
+	ScoreDelta  float64 `json:"score_delta"`
+}
+
+// GetATSHistory retrieves every ATS analysis of a CV version.
+func (c *Client) GetATSHistory(ctx context.Context, cvVersionID int, opts ...RequestOption) (*ATSHistory, error) {
+	path := fmt.Sprintf("/api/latest/ats/%d/history", cvVersionID)
+
+	var result ATSHistory
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get ATS history: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetCVATSHistory retrieves every ATS analysis of every version of a CV.
+func (c *Client) GetCVATSHistory(ctx context.Context, cvID int, opts ...RequestOption) (*ATSHistory, error) {
+	path := fmt.Sprintf("/api/latest/cvs/%d/ats-history", cvID)
+
+	var result ATSHistory
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get ATS history: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ApplyATSRequest selects the recommendations of the latest ATS analysis of
+// a CV version to apply. With no indexes and no categories, all of them are
+// applied.
+type ApplyATSRequest struct {
+	Recommendations []int    `json:"recommendations,omitempty"` // Indexes of the analysis' recommendations
+	Categories      []string `json:"categories,omitempty"`      // Categories of recommendations, e.g. "keywords"
+	JobDescription  string   `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	ATSProfile      string   `json:"ats_profile,omitempty"`     // Defaults to the profile of the latest analysis
+	FileType        string   `json:"file_type,omitempty"`
+	CheckPDF        bool     `json:"check_pdf,omitempty"`
+}
+
+// ApplyATSResponse is the new CV version recommendations were applied to,
+// with its analysis before and after.
+type ApplyATSResponse struct {
+	CVVersionID         int                 `json:"cv_version_id"`          // The new version
+	PreviousCVVersionID int                 `json:"previous_cv_version_id"` // The version recommendations were applied to
+	ATSProfile          string              `json:"ats_profile"`
+	Applied             []interface{}       `json:"applied"`
+	Modifications       []string            `json:"modifications"`
+	MatchScore          float64             `json:"match_score"`
+	Before              ATSAnalysisResponse `json:"before"`
+	After               ATSAnalysisResponse `json:"after"`
+	Change              ATSChange           `json:"change"`
+}
+
+// ApplyATSRecommendations rewrites a CV version to follow recommendations of
+// its latest ATS analysis, and analyzes the new version.
+func (c *Client) ApplyATSRecommendations(ctx context.Context, cvVersionID int, req ApplyATSRequest, opts ...RequestOption) (*ApplyATSResponse, error) {
+	path := fmt.Sprintf("/api/latest/ats/%d/apply", cvVersionID)
+
+	var result ApplyATSResponse
+	if err := c.doRequest(ctx, "POST", path, req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to apply ATS recommendations: %w", err)
+	}
+
+	return &result, nil
+}
@@ -6,6 +6,9 @@ package sdk
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -54,6 +57,77 @@ func (c *Client) DownloadBatchResults(ctx context.Context, jobID int, opts ...Re
 	return &results, nil
 }
 
+// DownloadBatchArchive downloads a ZIP archive with one rendered CV per completed
+// item plus manifest.csv and manifest.json, and saves it to destPath. fileType
+// selects the rendered format (FileTypePDF or FileTypeDOCX). The archive is
+// streamed to disk and only moved into place once the download succeeds.
+func (c *Client) DownloadBatchArchive(ctx context.Context, jobID int, fileType, destPath string, opts ...RequestOption) error {
+	if jobID <= 0 {
+		return &ValidationError{Field: "jobID", Message: "job ID must be positive"}
+	}
+	if destPath == "" {
+		return &ValidationError{Field: "destPath", Message: "destination path is required"}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), ".batch-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	query := url.Values{"format": {"zip"}}
+	if fileType != "" {
+		query.Set("file_type", fileType)
+	}
+
+	path := fmt.Sprintf("/api/latest/batch/%d/download", jobID)
+	if err := c.doRequestStream(ctx, path, query, tmp, opts...); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to download batch archive: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write batch archive: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return fmt.Errorf("failed to save batch archive: %w", err)
+	}
+
+	return nil
+}
+
+// CancelBatch cancels a pending or running batch job.
+func (c *Client) CancelBatch(ctx context.Context, jobID int, opts ...RequestOption) (*BatchActionResponse, error) {
+	if jobID <= 0 {
+		return nil, &ValidationError{Field: "jobID", Message: "job ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/batch/%d/cancel", jobID)
+	var resp BatchActionResponse
+	if err := c.doRequest(ctx, "POST", path, nil, &resp, opts...); err != nil {
+		return nil, fmt.Errorf("failed to cancel batch job: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// RetryFailedBatchItems requeues the failed, cancelled and unfinished items of a finished batch job.
+func (c *Client) RetryFailedBatchItems(ctx context.Context, jobID int, opts ...RequestOption) (*BatchActionResponse, error) {
+	if jobID <= 0 {
+		return nil, &ValidationError{Field: "jobID", Message: "job ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/batch/%d/retry-failed", jobID)
+	var resp BatchActionResponse
+	if err := c.doRequest(ctx, "POST", path, nil, &resp, opts...); err != nil {
+		return nil, fmt.Errorf("failed to retry batch items: %w", err)
+	}
+
+	return &resp, nil
+}
+
 // WaitForBatch polls a batch job until it completes or the context is cancelled.
 // It returns the final results when the job is complete.
 func (c *Client) WaitForBatch(ctx context.Context, jobID int, pollInterval time.Duration, opts ...RequestOption) (*BatchResults, error) {
@@ -79,6 +153,8 @@ func (c *Client) WaitForBatch(ctx context.Context, jobID int, pollInterval time.
 				return c.DownloadBatchResults(ctx, jobID, opts...)
 			case "failed":
 				return nil, fmt.Errorf("batch job %d failed", jobID)
+			case "cancelled":
+				return nil, fmt.Errorf("batch job %d was cancelled", jobID)
 			case "pending", "processing":
 				// Continue polling
 				continue
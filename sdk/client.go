@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -100,10 +101,15 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		reqBody = bytes.NewReader(jsonData)
 	}
 
+	// JoinPath escapes "?", so the query of a path is appended afterwards
+	path, rawQuery, _ := strings.Cut(path, "?")
 	fullURL, err := url.JoinPath(c.baseURL, path)
 	if err != nil {
 		return fmt.Errorf("failed to build URL: %w", err)
 	}
+	if rawQuery != "" {
+		fullURL += "?" + rawQuery
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
@@ -188,3 +194,46 @@ func (c *Client) doRequestRaw(ctx context.Context, method, path string, body int
 
 	return data, nil
 }
+
+// doRequestStream performs a GET request and copies the response body to w
+// without buffering it in memory.
+func (c *Client) doRequestStream(ctx context.Context, path string, query url.Values, w io.Writer, opts ...RequestOption) error {
+	reqConfig := buildRequestConfig(opts...)
+
+	fullURL, err := url.JoinPath(c.baseURL, path)
+	if err != nil {
+		return fmt.Errorf("failed to build URL: %w", err)
+	}
+
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("User-Agent", c.userAgent)
+	if reqConfig.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+reqConfig.authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for error status codes
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseAPIError(resp)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return nil
+}
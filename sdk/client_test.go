@@ -7,6 +7,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -184,6 +186,17 @@ func TestBatchValidation(t *testing.T) {
 	if !IsValidationError(err) {
 		t.Errorf("expected validation error, got %T", err)
 	}
+
+	// Test invalid job IDs for job actions
+	_, err = client.CancelBatch(context.Background(), 0)
+	if !IsValidationError(err) {
+		t.Errorf("expected validation error, got %T", err)
+	}
+
+	_, err = client.RetryFailedBatchItems(context.Background(), -1)
+	if !IsValidationError(err) {
+		t.Errorf("expected validation error, got %T", err)
+	}
 }
 
 func TestVersionValidation(t *testing.T) {
@@ -216,3 +229,37 @@ func TestVersionValidation(t *testing.T) {
 		t.Errorf("expected validation error, got %T", err)
 	}
 }
+
+func TestDownloadBatchArchive(t *testing.T) {
+	archive := []byte("PK\x03\x04 fake archive")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/latest/batch/7/download" {
+			t.Errorf("expected path /api/latest/batch/7/download, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("format") != "zip" {
+			t.Errorf("expected format=zip, got %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("file_type") != FileTypeDOCX {
+			t.Errorf("expected file_type=docx, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "batch.zip")
+
+	client := NewClient(server.URL)
+	if err := client.DownloadBatchArchive(context.Background(), 7, FileTypeDOCX, dest); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("expected archive to be saved, got %v", err)
+	}
+	if string(data) != string(archive) {
+		t.Errorf("expected saved archive to match response body")
+	}
+}
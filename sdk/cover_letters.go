@@ -0,0 +1,102 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CoverLetterRequest represents the request to write a cover letter for a
+// CV version.
+type CoverLetterRequest struct {
+	CVVersionID    int    `json:"cv_version_id"`
+	JobDescription string `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	Tone           string `json:"tone,omitempty"`            // professional (default), enthusiastic, formal or conversational
+	Length         string `json:"length,omitempty"`          // short, medium (default) or long
+	Language       string `json:"language,omitempty"`        // ISO 639-1 code; defaults to the language of the CV
+	Company        string `json:"company,omitempty"`
+	HiringManager  string `json:"hiring_manager,omitempty"`
+}
+
+// CoverLetter is a cover letter written for a CV version. The letters of a
+// version are numbered from 1.
+type CoverLetter struct {
+	ID            int       `json:"id"`
+	CVVersionID   int       `json:"cv_version_id"`
+	Version       int       `json:"version"`
+	Tone          string    `json:"tone"`
+	Length        string    `json:"length"`
+	Language      string    `json:"language"`
+	Company       string    `json:"company,omitempty"`
+	HiringManager string    `json:"hiring_manager,omitempty"`
+	Content       string    `json:"content"`
+	WordCount     int       `json:"word_count"`
+	Highlights    []string  `json:"highlights"` // Requirements of the job the letter cites
+	Provider      string    `json:"provider"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CoverLetterResponse is a cover letter just written, with the match
+// analysis it was written from.
+type CoverLetterResponse struct {
+	CoverLetter  CoverLetter   `json:"cover_letter"`
+	DownloadURL  string        `json:"download_url"`
+	MatchDetails *MatchDetails `json:"match_details,omitempty"`
+}
+
+// CreateCoverLetter writes a cover letter for a CV version.
+func (c *Client) CreateCoverLetter(ctx context.Context, req CoverLetterRequest, opts ...RequestOption) (*CoverLetterResponse, error) {
+	if req.CVVersionID <= 0 {
+		return nil, &ValidationError{Field: "cv_version_id", Message: "CV version ID must be positive"}
+	}
+
+	var result CoverLetterResponse
+	if err := c.doRequest(ctx, "POST", "/api/latest/cover-letter", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create cover letter: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetCoverLetter retrieves a cover letter.
+func (c *Client) GetCoverLetter(ctx context.Context, coverLetterID int, opts ...RequestOption) (*CoverLetter, error) {
+	path := fmt.Sprintf("/api/latest/cover-letter/%d", coverLetterID)
+
+	var result CoverLetter
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get cover letter: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListCoverLetters retrieves the cover letters of a CV version, newest
+// first.
+func (c *Client) ListCoverLetters(ctx context.Context, versionID int, opts ...RequestOption) ([]CoverLetter, error) {
+	path := fmt.Sprintf("/api/latest/versions/%d/cover-letters", versionID)
+
+	var result struct {
+		CoverLetters []CoverLetter `json:"cover_letters"`
+	}
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list cover letters: %w", err)
+	}
+
+	return result.CoverLetters, nil
+}
+
+// DownloadCoverLetter downloads a cover letter as a PDF, or as a text file
+// when it cannot be rendered.
+func (c *Client) DownloadCoverLetter(ctx context.Context, coverLetterID int, opts ...RequestOption) ([]byte, error) {
+	path := fmt.Sprintf("/api/latest/cover-letter/%d/download", coverLetterID)
+
+	data, err := c.doRequestRaw(ctx, "GET", path, nil, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download cover letter: %w", err)
+	}
+
+	return data, nil
+}
@@ -13,10 +13,10 @@ func (c *Client) CustomizeCV(ctx context.Context, req *CustomizeCVRequest, opts
 	if req == nil {
 		return nil, &ValidationError{Field: "request", Message: "request cannot be nil"}
 	}
-	if req.CV == "" {
+	if req.CV == "" && req.CVFile == "" && req.LinkedInProfile == "" && len(req.InputSources) == 0 {
 		return nil, &ValidationError{Field: "cv", Message: "CV content is required"}
 	}
-	if req.JobDescription == "" {
+	if req.JobDescription == "" && req.JobDescriptionURL == "" && len(req.InputSources) == 0 {
 		return nil, &ValidationError{Field: "job_description", Message: "job description is required"}
 	}
 
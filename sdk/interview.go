@@ -0,0 +1,88 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// InterviewPrepRequest represents the request for an interview preparation
+// pack.
+type InterviewPrepRequest struct {
+	CVVersionID    int    `json:"cv_version_id"`
+	JobDescription string `json:"job_description,omitempty"` // Defaults to the job the version was customized for
+	Language       string `json:"language,omitempty"`        // ISO 639-1 code; defaults to the language of the CV
+	Format         string `json:"format,omitempty"`          // json (default), markdown or pdf; see DownloadInterviewPrep
+}
+
+// STARAnswer outlines an answer as situation, task, action and result.
+type STARAnswer struct {
+	Situation string `json:"situation"`
+	Task      string `json:"task"`
+	Action    string `json:"action"`
+	Result    string `json:"result"`
+}
+
+// RequirementPrep prepares one requirement of the job.
+type RequirementPrep struct {
+	Requirement string      `json:"requirement"`
+	Kind        string      `json:"kind"` // "required" or "preferred"
+	Covered     bool        `json:"covered"`
+	Evidence    string      `json:"evidence,omitempty"` // Passage of the CV the answer draws on
+	Questions   []string    `json:"questions"`
+	Answer      *STARAnswer `json:"star_answer,omitempty"` // Only for covered requirements
+}
+
+// SkillPrep gives talking points for a skill the CV is missing.
+type SkillPrep struct {
+	Skill         string   `json:"skill"`
+	TalkingPoints []string `json:"talking_points"`
+}
+
+// InterviewPrep is an interview preparation pack.
+type InterviewPrep struct {
+	CVVersionID   int               `json:"cv_version_id"`
+	Language      string            `json:"language"`
+	Requirements  []RequirementPrep `json:"requirements"`
+	MissingSkills []SkillPrep       `json:"missing_skills"`
+	GeneratedBy   string            `json:"generated_by"` // LLM provider, or "template"
+	Markdown      string            `json:"markdown"`
+}
+
+// CreateInterviewPrep prepares likely interview questions, STAR answer
+// outlines and talking points for a CV version and its job.
+func (c *Client) CreateInterviewPrep(ctx context.Context, req InterviewPrepRequest, opts ...RequestOption) (*InterviewPrep, error) {
+	if req.CVVersionID <= 0 {
+		return nil, &ValidationError{Field: "cv_version_id", Message: "CV version ID must be positive"}
+	}
+
+	req.Format = "json"
+
+	var result InterviewPrep
+	if err := c.doRequest(ctx, "POST", "/api/latest/interview-prep", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create interview prep: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DownloadInterviewPrep prepares an interview pack as a document: a PDF, or
+// Markdown with the "markdown" format or when the PDF cannot be rendered.
+func (c *Client) DownloadInterviewPrep(ctx context.Context, req InterviewPrepRequest, opts ...RequestOption) ([]byte, error) {
+	if req.CVVersionID <= 0 {
+		return nil, &ValidationError{Field: "cv_version_id", Message: "CV version ID must be positive"}
+	}
+
+	if req.Format == "" || req.Format == "json" {
+		req.Format = "pdf"
+	}
+
+	data, err := c.doRequestRaw(ctx, "POST", "/api/latest/interview-prep", req, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download interview prep: %w", err)
+	}
+
+	return data, nil
+}
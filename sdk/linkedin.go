@@ -5,6 +5,7 @@ package sdk
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 )
 
@@ -23,7 +24,8 @@ type LinkedInImportResponse struct {
 // ImportLinkedInRequest represents the request for LinkedIn import.
 type ImportLinkedInRequest struct {
 	LinkedInURL string `json:"linkedin_url"`
-	ProfileText string `json:"profile_text"`
+	ProfileText string `json:"profile_text,omitempty"`
+	ExportFile  string `json:"export_file,omitempty"` // Base64 ZIP of a LinkedIn data export
 }
 
 // ImportLinkedInText imports a LinkedIn profile from text.
@@ -42,6 +44,27 @@ func (c *Client) ImportLinkedInText(ctx context.Context, url string, profileText
 	return &result, nil
 }
 
+// ImportLinkedInExport imports the ZIP archive of LinkedIn's "Download your
+// data" export.
+func (c *Client) ImportLinkedInExport(ctx context.Context, url string, export []byte, opts ...RequestOption) (*LinkedInImportResponse, error) {
+	if len(export) == 0 {
+		return nil, &ValidationError{Field: "export", Message: "export archive is required"}
+	}
+
+	req := ImportLinkedInRequest{
+		LinkedInURL: url,
+		ExportFile:  base64.StdEncoding.EncodeToString(export),
+	}
+
+	var result LinkedInImportResponse
+	err := c.doRequest(ctx, "POST", "/api/latest/linkedin/import", req, &result, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("LinkedIn import failed: %w", err)
+	}
+
+	return &result, nil
+}
+
 // GetLinkedInImports retrieves all LinkedIn imports for the authenticated user.
 func (c *Client) GetLinkedInImports(ctx context.Context, opts ...RequestOption) ([]LinkedInImportResponse, error) {
 	var result []LinkedInImportResponse
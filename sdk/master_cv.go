@@ -0,0 +1,85 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// MasterCVRequest represents the request for a master CV built for several
+// similar jobs. The base CV is text or a stored version, and the jobs saved
+// jobs, descriptions, or both: between 2 and 10 in all.
+type MasterCVRequest struct {
+	CV              string   `json:"cv,omitempty"`
+	CVVersionID     int      `json:"cv_version_id,omitempty"`
+	SavedJobIDs     []int    `json:"saved_job_ids,omitempty"` // Requires authentication
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+	ATSProfile      string   `json:"ats_profile,omitempty"` // ATS to simulate; defaults to "generic"
+}
+
+// MasterKeyword is a keyword of the jobs with how many of them ask for it.
+type MasterKeyword struct {
+	Keyword string  `json:"keyword"`
+	Jobs    int     `json:"jobs"`
+	Weight  float64 `json:"weight"` // Share of the jobs asking for it
+}
+
+// MasterDecision is what was decided for a bullet or skill list of the CV.
+type MasterDecision struct {
+	Action    string   `json:"action"` // "keep", "reorder" or "drop"
+	Line      string   `json:"line"`
+	Reordered string   `json:"reordered,omitempty"` // A skill list with its items reordered
+	Keywords  []string `json:"keywords"`
+	Relevance float64  `json:"relevance"`
+}
+
+// MasterJobDelta is how the master CV compares with the base CV for one job.
+type MasterJobDelta struct {
+	SavedJobID     int       `json:"saved_job_id,omitempty"`
+	Title          string    `json:"title,omitempty"`
+	ScoreBefore    float64   `json:"score_before"`
+	ScoreAfter     float64   `json:"score_after"`
+	CoverageBefore float64   `json:"coverage_before"`
+	CoverageAfter  float64   `json:"coverage_after"`
+	Change         ATSChange `json:"change"`
+	Missing        []string  `json:"missing_keywords"`
+}
+
+// MasterCV is a master CV stored as a new CV version.
+type MasterCV struct {
+	CVVersionID         int              `json:"cv_version_id"`
+	PreviousCVVersionID int              `json:"previous_cv_version_id,omitempty"`
+	CVID                int              `json:"cv_id"`
+	MasterCV            string           `json:"master_cv"`
+	ATSProfile          string           `json:"ats_profile"`
+	Keywords            []MasterKeyword  `json:"keywords"`
+	Decisions           []MasterDecision `json:"decisions"`
+	CoverageBefore      float64          `json:"coverage_before"`
+	CoverageAfter       float64          `json:"coverage_after"`
+	Jobs                []MasterJobDelta `json:"jobs"`
+	MatchScore          *float64         `json:"match_score,omitempty"`
+	MatchScores         []float64        `json:"match_scores,omitempty"` // In the order of the jobs
+	GeneratedBy         string           `json:"generated_by"`
+	DownloadURL         string           `json:"download_url"`
+}
+
+// CreateMasterCV builds one CV for several similar jobs, keeping, reordering
+// and dropping content by how many of the jobs ask for it.
+func (c *Client) CreateMasterCV(ctx context.Context, req MasterCVRequest, opts ...RequestOption) (*MasterCV, error) {
+	if req.CVVersionID <= 0 && req.CV == "" {
+		return nil, &ValidationError{Field: "cv", Message: "CV version ID or CV text is required"}
+	}
+
+	if len(req.SavedJobIDs)+len(req.JobDescriptions) < 2 {
+		return nil, &ValidationError{Field: "job_descriptions", Message: "at least two jobs are required"}
+	}
+
+	var result MasterCV
+	if err := c.doRequest(ctx, "POST", "/api/latest/master-cv", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create master CV: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SavedJob is a job posting collected from an email or a feed. Its ID can
+// be used as the SavedJobID of a BatchItem.
+type SavedJob struct {
+	ID               int        `json:"id"`
+	Origin           string     `json:"origin"` // email, feed
+	FeedID           *int       `json:"feed_id,omitempty"`
+	ExternalID       string     `json:"external_id"`
+	URL              *string    `json:"url,omitempty"`
+	Title            string     `json:"title"`
+	Company          string     `json:"company"`
+	Location         string     `json:"location"`
+	Salary           string     `json:"salary,omitempty"`
+	EmploymentType   string     `json:"employment_type,omitempty"`
+	ExtractedBy      string     `json:"extracted_by,omitempty"`
+	Description      string     `json:"description"`
+	Requirements     []string   `json:"requirements"`
+	Responsibilities []string   `json:"responsibilities"`
+	PreferredSkills  []string   `json:"preferred_skills"`
+	PostedAt         *time.Time `json:"posted_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// EmailImportResult is the outcome of importing an email.
+type EmailImportResult struct {
+	Subject    string     `json:"subject"`
+	SavedJobs  []SavedJob `json:"saved_jobs"`
+	Duplicates int        `json:"duplicates"` // Postings that were saved before
+	Errors     []string   `json:"errors"`     // Posting links that could not be read
+}
+
+// ListSavedJobsOptions pages and filters the saved jobs list.
+type ListSavedJobsOptions struct {
+	Limit  int
+	Offset int
+	FeedID int
+}
+
+// FeedRequest represents the request to register a job feed.
+type FeedRequest struct {
+	URL                 string `json:"url"`
+	PollIntervalMinutes int    `json:"poll_interval_minutes,omitempty"`
+	Active              *bool  `json:"active,omitempty"`
+}
+
+// Feed is an RSS or Atom feed of job postings polled for new postings.
+type Feed struct {
+	ID                  int        `json:"id"`
+	URL                 string     `json:"url"`
+	Title               *string    `json:"title,omitempty"`
+	PollIntervalMinutes int        `json:"poll_interval_minutes"`
+	Active              bool       `json:"active"`
+	NextPollAt          *time.Time `json:"next_poll_at,omitempty"`
+	LastPolledAt        *time.Time `json:"last_polled_at,omitempty"`
+	LastNewJobs         int        `json:"last_new_jobs"`
+	LastError           *string    `json:"last_error,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// ImportEmail saves the job postings of an email, such as a job alert. The
+// message is the content of an .eml file.
+func (c *Client) ImportEmail(ctx context.Context, message []byte, opts ...RequestOption) (*EmailImportResult, error) {
+	if len(message) == 0 {
+		return nil, &ValidationError{Field: "message", Message: "message is required"}
+	}
+
+	req := map[string]string{"message": base64.StdEncoding.EncodeToString(message)}
+	var result EmailImportResult
+	if err := c.doRequest(ctx, "POST", "/api/latest/saved-jobs/email", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to import email: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListSavedJobs retrieves the saved jobs of the authenticated user, newest first.
+func (c *Client) ListSavedJobs(ctx context.Context, options *ListSavedJobsOptions, opts ...RequestOption) ([]SavedJob, error) {
+	path := "/api/latest/saved-jobs"
+	if options != nil {
+		query := url.Values{}
+		if options.Limit > 0 {
+			query.Set("limit", strconv.Itoa(options.Limit))
+		}
+		if options.Offset > 0 {
+			query.Set("offset", strconv.Itoa(options.Offset))
+		}
+		if options.FeedID > 0 {
+			query.Set("feed_id", strconv.Itoa(options.FeedID))
+		}
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+	}
+
+	var result []SavedJob
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list saved jobs: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetSavedJob retrieves a saved job.
+func (c *Client) GetSavedJob(ctx context.Context, savedJobID int, opts ...RequestOption) (*SavedJob, error) {
+	if savedJobID <= 0 {
+		return nil, &ValidationError{Field: "savedJobID", Message: "saved job ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/saved-jobs/%d", savedJobID)
+	var result SavedJob
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get saved job: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteSavedJob removes a saved job.
+func (c *Client) DeleteSavedJob(ctx context.Context, savedJobID int, opts ...RequestOption) error {
+	if savedJobID <= 0 {
+		return &ValidationError{Field: "savedJobID", Message: "saved job ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/saved-jobs/%d", savedJobID)
+	if err := c.doRequest(ctx, "DELETE", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to delete saved job: %w", err)
+	}
+
+	return nil
+}
+
+// CreateFeed registers an RSS or Atom job feed. Active feeds are polled at
+// once, then every PollIntervalMinutes (60 by default).
+func (c *Client) CreateFeed(ctx context.Context, req *FeedRequest, opts ...RequestOption) (*Feed, error) {
+	if req == nil {
+		return nil, &ValidationError{Field: "request", Message: "request cannot be nil"}
+	}
+	if req.URL == "" {
+		return nil, &ValidationError{Field: "url", Message: "url is required"}
+	}
+
+	var result Feed
+	if err := c.doRequest(ctx, "POST", "/api/latest/feeds", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create feed: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListFeeds retrieves the job feeds of the authenticated user.
+func (c *Client) ListFeeds(ctx context.Context, opts ...RequestOption) ([]Feed, error) {
+	var result []Feed
+	if err := c.doRequest(ctx, "GET", "/api/latest/feeds", nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteFeed removes a job feed. Jobs it already saved are kept.
+func (c *Client) DeleteFeed(ctx context.Context, feedID int, opts ...RequestOption) error {
+	if feedID <= 0 {
+		return &ValidationError{Field: "feedID", Message: "feed ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/feeds/%d", feedID)
+	if err := c.doRequest(ctx, "DELETE", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	return nil
+}
+
+// PollFeed makes a job feed due for polling immediately.
+func (c *Client) PollFeed(ctx context.Context, feedID int, opts ...RequestOption) error {
+	if feedID <= 0 {
+		return &ValidationError{Field: "feedID", Message: "feed ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/feeds/%d/poll", feedID)
+	if err := c.doRequest(ctx, "POST", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to poll feed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ScheduleJobSource is the saved query a schedule resolves to job descriptions
+// on every run. URLs are fetched at run time.
+type ScheduleJobSource struct {
+	URLs            []string `json:"urls,omitempty"`
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+}
+
+// ScheduleRequest represents the request to create or update a batch schedule.
+type ScheduleRequest struct {
+	Name           string            `json:"name"`
+	Cron           string            `json:"cron"`
+	Timezone       string            `json:"timezone,omitempty"`
+	CandidateCVIDs []int             `json:"candidate_cv_ids"`
+	JobSource      ScheduleJobSource `json:"job_source"`
+	OnlyNewJobs    bool              `json:"only_new_jobs"`
+	Active         *bool             `json:"active,omitempty"`
+}
+
+// Schedule represents a recurring batch job.
+type Schedule struct {
+	ID             int               `json:"id"`
+	Name           string            `json:"name"`
+	Cron           string            `json:"cron"`
+	Timezone       string            `json:"timezone"`
+	CandidateCVIDs []int             `json:"candidate_cv_ids"`
+	JobSource      ScheduleJobSource `json:"job_source"`
+	OnlyNewJobs    bool              `json:"only_new_jobs"`
+	Active         bool              `json:"active"`
+	NextRunAt      *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt      *time.Time        `json:"last_run_at,omitempty"`
+	LastJobID      *int              `json:"last_job_id,omitempty"`
+	LastError      *string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// ScheduleRun is a batch job created by a schedule.
+type ScheduleRun struct {
+	ID             int        `json:"id"`
+	Status         string     `json:"status"`
+	TotalItems     int        `json:"total_items"`
+	CompletedItems int        `json:"completed_items"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// ScheduleDetail is a schedule together with its most recent runs.
+type ScheduleDetail struct {
+	Schedule Schedule      `json:"schedule"`
+	Runs     []ScheduleRun `json:"runs"`
+}
+
+// validateScheduleRequest performs client-side validation of a schedule request.
+func validateScheduleRequest(req *ScheduleRequest) error {
+	if req == nil {
+		return &ValidationError{Field: "request", Message: "request cannot be nil"}
+	}
+	if req.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if req.Cron == "" {
+		return &ValidationError{Field: "cron", Message: "cron expression is required"}
+	}
+	if len(req.CandidateCVIDs) == 0 {
+		return &ValidationError{Field: "candidate_cv_ids", Message: "at least one candidate CV is required"}
+	}
+	if len(req.JobSource.URLs) == 0 && len(req.JobSource.JobDescriptions) == 0 {
+		return &ValidationError{Field: "job_source", Message: "job source must include URLs or job descriptions"}
+	}
+
+	return nil
+}
+
+// CreateSchedule creates a recurring batch schedule.
+func (c *Client) CreateSchedule(ctx context.Context, req *ScheduleRequest, opts ...RequestOption) (*Schedule, error) {
+	if err := validateScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	var result Schedule
+	if err := c.doRequest(ctx, "POST", "/api/latest/schedules", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListSchedules retrieves the batch schedules of the authenticated user.
+func (c *Client) ListSchedules(ctx context.Context, opts ...RequestOption) ([]Schedule, error) {
+	var result []Schedule
+	if err := c.doRequest(ctx, "GET", "/api/latest/schedules", nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetSchedule retrieves a batch schedule and its most recent runs.
+func (c *Client) GetSchedule(ctx context.Context, scheduleID int, opts ...RequestOption) (*ScheduleDetail, error) {
+	if scheduleID <= 0 {
+		return nil, &ValidationError{Field: "scheduleID", Message: "schedule ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/schedules/%d", scheduleID)
+	var result ScheduleDetail
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UpdateSchedule replaces a batch schedule.
+func (c *Client) UpdateSchedule(ctx context.Context, scheduleID int, req *ScheduleRequest, opts ...RequestOption) (*Schedule, error) {
+	if scheduleID <= 0 {
+		return nil, &ValidationError{Field: "scheduleID", Message: "schedule ID must be positive"}
+	}
+	if err := validateScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/latest/schedules/%d", scheduleID)
+	var result Schedule
+	if err := c.doRequest(ctx, "PUT", path, req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteSchedule removes a batch schedule. Jobs it already created are kept.
+func (c *Client) DeleteSchedule(ctx context.Context, scheduleID int, opts ...RequestOption) error {
+	if scheduleID <= 0 {
+		return &ValidationError{Field: "scheduleID", Message: "schedule ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/schedules/%d", scheduleID)
+	if err := c.doRequest(ctx, "DELETE", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	return nil
+}
+
+// RunSchedule makes a schedule due immediately.
+func (c *Client) RunSchedule(ctx context.Context, scheduleID int, opts ...RequestOption) error {
+	if scheduleID <= 0 {
+		return &ValidationError{Field: "scheduleID", Message: "schedule ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/schedules/%d/run", scheduleID)
+	if err := c.doRequest(ctx, "POST", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to run schedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// SkillGapRequest represents the request for a skill gap report. The CV is
+// a stored version or text; without jobs, a version is compared with the job
+// it was customized for.
+type SkillGapRequest struct {
+	CVVersionID     int      `json:"cv_version_id,omitempty"`
+	CV              string   `json:"cv,omitempty"`
+	SavedJobIDs     []int    `json:"saved_job_ids,omitempty"` // Requires authentication
+	JobDescriptions []string `json:"job_descriptions,omitempty"`
+}
+
+// SkillGap is a skill the CV is missing or weak in, across the jobs.
+type SkillGap struct {
+	Skill     string  `json:"skill"`
+	Status    string  `json:"status"` // "missing" or "weak"
+	Jobs      int     `json:"jobs"`   // How many of the jobs ask for it
+	Required  int     `json:"required"`
+	Preferred int     `json:"preferred"`
+	Months    int     `json:"months,omitempty"` // Tenure in dated roles, for weak skills
+	Score     float64 `json:"score"`
+	Priority  string  `json:"priority"` // "high", "medium" or "low"
+	Reason    string  `json:"reason"`
+}
+
+// JobSkillGaps is the skills one job asks for and the gaps of the CV for it.
+type JobSkillGaps struct {
+	SavedJobID int      `json:"saved_job_id,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Company    string   `json:"company,omitempty"`
+	Required   []string `json:"required_skills"`
+	Preferred  []string `json:"preferred_skills"`
+	Missing    []string `json:"missing_skills"`
+	Weak       []string `json:"weak_skills"`
+}
+
+// LearningStep suggests projects and certifications for a gap.
+type LearningStep struct {
+	Skill          string   `json:"skill"`
+	Status         string   `json:"status"`
+	Priority       string   `json:"priority"`
+	Projects       []string `json:"projects"`
+	Certifications []string `json:"certifications"`
+}
+
+// SkillGapReport is the skill gap report of a CV for a set of jobs.
+type SkillGapReport struct {
+	CVVersionID  int            `json:"cv_version_id,omitempty"`
+	Jobs         int            `json:"jobs"`
+	Gaps         []SkillGap     `json:"gaps"` // Highest score first
+	PerJob       []JobSkillGaps `json:"per_job"`
+	LearningPlan []LearningStep `json:"learning_plan"`
+}
+
+// CreateSkillGapReport ranks the skills a CV is missing or weak in across
+// saved jobs and job descriptions, with a learning plan for the top ones.
+func (c *Client) CreateSkillGapReport(ctx context.Context, req SkillGapRequest, opts ...RequestOption) (*SkillGapReport, error) {
+	if req.CVVersionID <= 0 && req.CV == "" {
+		return nil, &ValidationError{Field: "cv", Message: "CV version ID or CV text is required"}
+	}
+
+	var result SkillGapReport
+	if err := c.doRequest(ctx, "POST", "/api/latest/skill-gap", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create skill gap report: %w", err)
+	}
+
+	return &result, nil
+}
@@ -15,6 +15,7 @@ type CustomizeCVRequest struct {
 	AdditionalContext []ContextItem `json:"additional_context,omitempty"`
 	LLMConfig         *LLMConfig    `json:"llm_config,omitempty"`
 	InputSources      []InputSource `json:"input_sources,omitempty"`
+	Language          string        `json:"language,omitempty"` // "en", "de", "fr", "es", "pt" or "nl"; detected from the CV when empty
 }
 
 // ContextItem represents additional context (text or URL).
@@ -25,10 +26,40 @@ type ContextItem struct {
 
 // InputSource represents a source of input.
 type InputSource struct {
-	Type     string `json:"type"`     // "text", "url", "pdf", "docx", "linkedin"
-	Content  string `json:"content"`  // For text input or base64 for files
-	URL      string `json:"url"`      // For URL input
-	FileName string `json:"filename"` // Original filename for files
+	Type     string `json:"type"`           // "text", "url", "pdf", "docx", "doc", "odt", "rtf", "linkedin"
+	Content  string `json:"content"`        // For text input or base64 for files
+	URL      string `json:"url"`            // For URL input
+	FileName string `json:"filename"`       // Original filename for files
+	Role     string `json:"role,omitempty"` // "cv", "job_description" or "context"; defaults by type
+}
+
+// InputSourceResult reports how one input source of a customization request
+// was resolved.
+type InputSourceResult struct {
+	Source   string     `json:"source"`             // Request field, e.g. "cv_file" or "input_sources[1]"
+	Type     string     `json:"type"`               // "text", "url", "pdf", "docx", "doc", "odt", "rtf", "linkedin"
+	Role     string     `json:"role"`               // "cv", "job_description" or "context"
+	Status   string     `json:"status"`             // "resolved" or "failed"
+	Chars    int        `json:"chars,omitempty"`    // Length of the resolved text
+	Language string     `json:"language,omitempty"` // Detected language of the resolved text
+	Error    string     `json:"error,omitempty"`
+	OCR      *OCRReport `json:"ocr,omitempty"` // Set when scanned pages were recognized
+}
+
+// OCRReport describes the text recognized on the scanned pages of a source.
+type OCRReport struct {
+	Engine     string      `json:"engine"`
+	Pages      []int       `json:"pages"`            // Recognized pages, counting from 1
+	Confidence float64     `json:"confidence"`       // Mean word confidence, 0-100
+	Review     []OCRRegion `json:"review,omitempty"` // Low-confidence lines the user should check
+}
+
+// OCRRegion is a line of recognized text with a low confidence.
+type OCRRegion struct {
+	Page       int     `json:"page"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	Box        [4]int  `json:"box"` // x, y, width and height in pixels of the page rendered for OCR
 }
 
 // LLMConfig allows per-request override of LLM provider settings.
@@ -40,17 +71,38 @@ type LLMConfig struct {
 
 // CustomizeCVResponse represents the response from CV customization.
 type CustomizeCVResponse struct {
-	Status          string   `json:"status"`
-	CustomizedCVURL string   `json:"customized_cv_url"`
-	MatchScore      float64  `json:"match_score"`
-	Modifications   []string `json:"modifications"`
-	Error           string   `json:"error,omitempty"`
+	Status          string              `json:"status"`
+	CustomizedCVURL string              `json:"customized_cv_url"`
+	MatchScore      float64             `json:"match_score"`
+	Modifications   []string            `json:"modifications"`
+	Language        string              `json:"language"` // Language the CV was customized in
+	Sources         []InputSourceResult `json:"sources,omitempty"`
+	MatchDetails    *MatchDetails       `json:"match_details,omitempty"` // How MatchScore was computed
+	Error           string              `json:"error,omitempty"`
+}
+
+// MatchDetails explains a match score requirement by requirement.
+type MatchDetails struct {
+	Embedder     string             `json:"embedder"` // Embedding backend and model, e.g. "local"
+	Requirements []RequirementMatch `json:"requirements"`
+}
+
+// RequirementMatch is how well a CV covers one requirement of a job.
+type RequirementMatch struct {
+	Requirement string  `json:"requirement"`
+	Kind        string  `json:"kind"`       // "required" or "preferred"
+	Similarity  float64 `json:"similarity"` // Cosine similarity of the closest CV passage
+	Coverage    float64 `json:"coverage"`   // 0 to 1
+	Covered     bool    `json:"covered"`
+	Evidence    string  `json:"evidence,omitempty"` // The closest CV passage
 }
 
 // BatchItem represents a single item in a batch customization request.
 type BatchItem struct {
 	CV             string `json:"cv"`
-	JobDescription string `json:"job_description"`
+	JobDescription string `json:"job_description,omitempty"`
+	// SavedJobID customizes for a saved job posting instead of JobDescription.
+	SavedJobID int `json:"saved_job_id,omitempty"`
 }
 
 // BatchCustomizeRequest represents a batch customization request.
@@ -64,6 +116,13 @@ type BatchJobResponse struct {
 	Status string `json:"status"`
 }
 
+// BatchActionResponse represents the response from cancelling or retrying a batch job.
+type BatchActionResponse struct {
+	JobID         int    `json:"job_id"`
+	Status        string `json:"status"`
+	RequeuedItems int    `json:"requeued_items,omitempty"`
+}
+
 // BatchJobStatus represents the status of a batch job.
 type BatchJobStatus struct {
 	ID             int        `json:"id"`
@@ -126,6 +185,31 @@ type CompareVersionsRequest struct {
 // Analytics represents user analytics data.
 type Analytics struct {
 	Snapshots []AnalyticsSnapshot `json:"snapshots"`
+	SkillGaps []SkillGapMetric    `json:"skill_gaps"`
+	ATS       *ATSMetrics         `json:"ats,omitempty"`
+}
+
+// SkillGapMetric is a skill the customized CVs miss, with the number of
+// target jobs asking for it and their share of the jobs measured.
+type SkillGapMetric struct {
+	Skill string  `json:"skill"`
+	Jobs  int     `json:"jobs"`
+	Share float64 `json:"share"`
+}
+
+// ATSMetrics sums up recent ATS analyses.
+type ATSMetrics struct {
+	Analyses     int                `json:"analyses"`
+	AverageScore float64            `json:"average_score"`
+	ByProfile    map[string]float64 `json:"average_score_by_profile"`
+	ScoreTrend   []DailyATSMetric   `json:"score_trend"` // Oldest first
+}
+
+// DailyATSMetric is the average overall score of a day's ATS analyses.
+type DailyATSMetric struct {
+	Date         time.Time `json:"date"`
+	OverallScore float64   `json:"overall_score"`
+	Count        int       `json:"count"`
 }
 
 // AnalyticsSnapshot represents a single analytics snapshot.
@@ -140,12 +224,13 @@ type AnalyticsSnapshot struct {
 
 // Dashboard represents global dashboard statistics.
 type Dashboard struct {
-	TotalCVs          int     `json:"total_cvs"`
-	TotalVersions     int     `json:"total_versions"`
-	TotalBatchJobs    int     `json:"total_batch_jobs"`
-	AverageMatchScore float64 `json:"average_match_score"`
-	RecentActivity    int     `json:"recent_activity"`
-	ActiveUsers       int     `json:"active_users"`
+	TotalCVs          int         `json:"total_cvs"`
+	TotalVersions     int         `json:"total_versions"`
+	TotalBatchJobs    int         `json:"total_batch_jobs"`
+	AverageMatchScore float64     `json:"average_match_score"`
+	RecentActivity    int         `json:"recent_activity"`
+	ActiveUsers       int         `json:"active_users"`
+	ATS               *ATSMetrics `json:"ats,omitempty"`
 }
 
 // HealthResponse represents the health check response.
@@ -157,6 +242,12 @@ type HealthResponse struct {
 	Auth      map[string]interface{} `json:"auth"`
 }
 
+// Rendered file types for batch archives.
+const (
+	FileTypePDF  = "pdf"
+	FileTypeDOCX = "docx"
+)
+
 // LLM Provider constants.
 const (
 	ProviderOpenAI    = "openai"
@@ -0,0 +1,225 @@
+// Copyright (c) Ultraviolet
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook event types.
+const (
+	WebhookEventBatchCompleted          = "batch.completed"
+	WebhookEventBatchItemFailed         = "batch.item_failed"
+	WebhookEventLinkedInImportCompleted = "linkedin_import.completed"
+	WebhookEventATSAnalysisCompleted    = "ats_analysis.completed"
+)
+
+// WebhookSignatureHeader is the request header carrying the webhook signature.
+const WebhookSignatureHeader = "X-Vibe-CV-Signature"
+
+// ErrInvalidWebhookSignature is returned when a webhook signature does not verify.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// CreateWebhookRequest represents the request to register a webhook endpoint.
+// An empty EventTypes subscribes to every event.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// Webhook represents a registered webhook endpoint. Secret is only set in the
+// response to CreateWebhook.
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery represents the delivery of one event to a webhook endpoint.
+type WebhookDelivery struct {
+	ID            int        `json:"id"`
+	EventID       int        `json:"event_id"`
+	EndpointID    int        `json:"endpoint_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     *string    `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebhookDeliveryAttempt records a single delivery attempt.
+type WebhookDeliveryAttempt struct {
+	Attempt      int       `json:"attempt"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	ResponseBody *string   `json:"response_body,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryDetail is a delivery together with its attempt log.
+type WebhookDeliveryDetail struct {
+	Delivery WebhookDelivery          `json:"delivery"`
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+}
+
+// WebhookEvent is the JSON body posted to webhook endpoints.
+type WebhookEvent struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CreateWebhook registers a webhook endpoint. The returned secret is needed to
+// verify deliveries and is not returned again.
+func (c *Client) CreateWebhook(ctx context.Context, req *CreateWebhookRequest, opts ...RequestOption) (*Webhook, error) {
+	if req == nil || req.URL == "" {
+		return nil, &ValidationError{Field: "url", Message: "webhook URL is required"}
+	}
+
+	var result Webhook
+	if err := c.doRequest(ctx, "POST", "/api/latest/webhooks", req, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListWebhooks retrieves the webhook endpoints of the authenticated user.
+func (c *Client) ListWebhooks(ctx context.Context, opts ...RequestOption) ([]Webhook, error) {
+	var result []Webhook
+	if err := c.doRequest(ctx, "GET", "/api/latest/webhooks", nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteWebhook removes a webhook endpoint.
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID int, opts ...RequestOption) error {
+	if webhookID <= 0 {
+		return &ValidationError{Field: "webhookID", Message: "webhook ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/webhooks/%d", webhookID)
+	if err := c.doRequest(ctx, "DELETE", path, nil, nil, opts...); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries retrieves the most recent deliveries of a webhook endpoint.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, webhookID int, opts ...RequestOption) ([]WebhookDelivery, error) {
+	if webhookID <= 0 {
+		return nil, &ValidationError{Field: "webhookID", Message: "webhook ID must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/webhooks/%d/deliveries", webhookID)
+	var result []WebhookDelivery
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetWebhookDelivery retrieves a delivery and its attempt log.
+func (c *Client) GetWebhookDelivery(ctx context.Context, webhookID, deliveryID int, opts ...RequestOption) (*WebhookDeliveryDetail, error) {
+	if webhookID <= 0 || deliveryID <= 0 {
+		return nil, &ValidationError{Field: "deliveryID", Message: "webhook and delivery IDs must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/webhooks/%d/deliveries/%d", webhookID, deliveryID)
+	var result WebhookDeliveryDetail
+	if err := c.doRequest(ctx, "GET", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ReplayWebhookDelivery schedules a new delivery of the event of an earlier delivery.
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID int, opts ...RequestOption) (*WebhookDelivery, error) {
+	if webhookID <= 0 || deliveryID <= 0 {
+		return nil, &ValidationError{Field: "deliveryID", Message: "webhook and delivery IDs must be positive"}
+	}
+
+	path := fmt.Sprintf("/api/latest/webhooks/%d/deliveries/%d/replay", webhookID, deliveryID)
+	var result WebhookDelivery
+	if err := c.doRequest(ctx, "POST", path, nil, &result, opts...); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return &result, nil
+}
+
+// VerifyWebhookSignature checks the X-Vibe-CV-Signature header of a webhook
+// request against its raw body and decodes the event. Requests signed more than
+// tolerance away from now are rejected; pass 0 to skip the timestamp check.
+func VerifyWebhookSignature(secret, signatureHeader string, body []byte, tolerance time.Duration) (*WebhookEvent, error) {
+	var (
+		ts         string
+		signatures []string
+	)
+
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	if tolerance > 0 {
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return nil, fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			var event WebhookEvent
+			if err := json.Unmarshal(body, &event); err != nil {
+				return nil, fmt.Errorf("failed to decode webhook event: %w", err)
+			}
+
+			return &event, nil
+		}
+	}
+
+	return nil, ErrInvalidWebhookSignature
+}